@@ -28,6 +28,7 @@ import (
 	dogstatsdServer "github.com/DataDog/datadog-agent/comp/dogstatsd/server"
 	dogstatsdDebug "github.com/DataDog/datadog-agent/comp/dogstatsd/serverDebug"
 	"github.com/DataDog/datadog-agent/pkg/autodiscovery"
+	"github.com/DataDog/datadog-agent/pkg/collector/corechecks/snmp"
 	"github.com/DataDog/datadog-agent/pkg/config"
 	settingshttp "github.com/DataDog/datadog-agent/pkg/config/settings/http"
 	"github.com/DataDog/datadog-agent/pkg/epforwarder"
@@ -35,6 +36,7 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/logs/diagnostic"
 	"github.com/DataDog/datadog-agent/pkg/metadata/inventories"
 	v5 "github.com/DataDog/datadog-agent/pkg/metadata/v5"
+	"github.com/DataDog/datadog-agent/pkg/networkdevice/topology"
 	"github.com/DataDog/datadog-agent/pkg/secrets"
 	"github.com/DataDog/datadog-agent/pkg/status"
 	"github.com/DataDog/datadog-agent/pkg/status/health"
@@ -71,6 +73,8 @@ func SetupHandlers(r *mux.Router, flareComp flare.Component, server dogstatsdSer
 	r.HandleFunc("/workload-list", getWorkloadList).Methods("GET")
 	r.HandleFunc("/secrets", secretInfo).Methods("GET")
 	r.HandleFunc("/metadata/{payload}", metadataPayload).Methods("GET")
+	r.HandleFunc("/snmp/topology", getSNMPTopology).Methods("GET")
+	r.HandleFunc("/snmp/fetch-stats", getSNMPFetchStats).Methods("GET")
 
 	// Some agent subcommands do not provide these dependencies (such as JMX)
 	if server != nil && serverDebug != nil {
@@ -402,6 +406,36 @@ func getWorkloadList(w http.ResponseWriter, r *http.Request) {
 	w.Write(jsonDump)
 }
 
+func getSNMPTopology(w http.ResponseWriter, r *http.Request) {
+	graph := topology.GetStore().Graph()
+
+	if r.URL.Query().Get("format") == "dot" {
+		w.Header().Set("Content-Type", "text/vnd.graphviz")
+		if err := graph.WriteDOT(w); err != nil {
+			log.Errorf("Unable to write SNMP topology response: %v", err)
+		}
+		return
+	}
+
+	jsonGraph, err := json.Marshal(graph)
+	if err != nil {
+		setJSONError(w, log.Errorf("Unable to marshal SNMP topology response: %v", err), 500)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(jsonGraph)
+}
+
+func getSNMPFetchStats(w http.ResponseWriter, r *http.Request) {
+	jsonStats, err := json.Marshal(snmp.GetFetchStats())
+	if err != nil {
+		setJSONError(w, log.Errorf("Unable to marshal SNMP fetch stats response: %v", err), 500)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(jsonStats)
+}
+
 func secretInfo(w http.ResponseWriter, r *http.Request) {
 	secrets.GetDebugInfo(w)
 }
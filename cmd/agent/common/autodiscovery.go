@@ -40,11 +40,19 @@ var (
 func setupAutoDiscovery(confSearchPaths []string, metaScheduler *scheduler.MetaScheduler) *autodiscovery.AutoConfig {
 	ad := autodiscovery.NewAutoConfig(metaScheduler)
 	providers.InitConfigFilesReader(confSearchPaths)
-	ad.AddConfigProvider(
-		providers.NewFileConfigProvider(),
-		config.Datadog.GetBool("autoconf_config_files_poll"),
-		time.Duration(config.Datadog.GetInt("autoconf_config_files_poll_interval"))*time.Second,
-	)
+
+	fileProvider := providers.NewFileConfigProvider()
+	pollFiles := config.Datadog.GetBool("autoconf_config_files_poll")
+	pollFilesInterval := time.Duration(config.Datadog.GetInt("autoconf_config_files_poll_interval")) * time.Second
+	if config.Datadog.GetBool("autoconf_config_files_watch") {
+		if err := fileProvider.Watch(); err != nil {
+			log.Errorf("Unable to watch the configuration files: %s", err)
+		} else {
+			pollFiles = true
+			pollFilesInterval = providers.FileWatchPollInterval
+		}
+	}
+	ad.AddConfigProvider(fileProvider, pollFiles, pollFilesInterval)
 
 	// Autodiscovery cannot easily use config.RegisterOverrideFunc() due to Unmarshalling
 	extraConfigProviders, extraConfigListeners := confad.DiscoverComponentsFromConfig()
@@ -8,7 +8,12 @@ package configcheck
 
 import (
 	"bytes"
+	"context"
 	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/fatih/color"
 	"github.com/spf13/cobra"
@@ -27,8 +32,12 @@ type cliParams struct {
 	*command.GlobalParams
 
 	verbose bool
+	watch   bool
 }
 
+// watchInterval is how often the configurations are polled with --watch
+const watchInterval = 2 * time.Second
+
 // Commands returns a slice of subcommands for the 'agent' command.
 func Commands(globalParams *command.GlobalParams) []*cobra.Command {
 	cliParams := &cliParams{
@@ -51,11 +60,18 @@ func Commands(globalParams *command.GlobalParams) []*cobra.Command {
 		},
 	}
 	configCheckCommand.Flags().BoolVarP(&cliParams.verbose, "verbose", "v", false, "print additional debug info")
+	configCheckCommand.Flags().BoolVarP(&cliParams.watch, "watch", "w", false, "keep printing the configurations scheduled and unscheduled, and the configuration errors, until interrupted")
 
 	return []*cobra.Command{configCheckCommand}
 }
 
 func run(config config.Component, cliParams *cliParams) error {
+	if cliParams.watch {
+		ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer cancel()
+		return flare.WatchConfigCheck(ctx, color.Output, watchInterval)
+	}
+
 	var b bytes.Buffer
 	color.Output = &b
 	err := flare.GetConfigCheck(color.Output, cliParams.verbose)
@@ -25,3 +25,14 @@ func TestCommand(t *testing.T) {
 			require.Equal(t, true, coreParams.ConfigLoadSecrets())
 		})
 }
+
+func TestCommandWatch(t *testing.T) {
+	fxutil.TestOneShotSubcommand(t,
+		Commands(&command.GlobalParams{}),
+		[]string{"configcheck", "--watch"},
+		run,
+		func(cliParams *cliParams, coreParams core.BundleParams) {
+			require.Equal(t, true, cliParams.watch)
+			require.Equal(t, false, cliParams.verbose)
+		})
+}
@@ -47,6 +47,9 @@ const (
 	// general communication options
 	defaultTimeout = 10 // Timeout better suited to walking
 	defaultRetries = 3
+
+	// record the whole tree by default, the profiles using vendor specific OIDs
+	defaultRecordOID = "1.3.6.1"
 )
 
 // cliParams are the command-line arguments for this subcommand
@@ -77,6 +80,15 @@ type cliParams struct {
 	// communication
 	retries int
 	timeout int
+
+	// walk --profile-test
+	profileTest string
+
+	// fetch-stats
+	jsonStats bool
+
+	// topology
+	dotTopology bool
 }
 
 // Commands returns a slice of subcommands for the 'agent' command.
@@ -101,23 +113,8 @@ func Commands(globalParams *command.GlobalParams) []*cobra.Command {
 		},
 	}
 
-	snmpWalkCmd.Flags().StringVarP(&cliParams.snmpVersion, "snmp-version", "v", defaultVersion, "Specify SNMP version to use")
-
-	// snmp v1 or v2c specific
-	snmpWalkCmd.Flags().StringVarP(&cliParams.communityString, "community-string", "C", "", "Set the community string")
-
-	// snmp v3 specific
-	snmpWalkCmd.Flags().StringVarP(&cliParams.authProt, "auth-protocol", "a", defaultAuthProtocol, "Set authentication protocol (MD5|SHA|SHA-224|SHA-256|SHA-384|SHA-512)")
-	snmpWalkCmd.Flags().StringVarP(&cliParams.authKey, "auth-key", "A", defaultAuthKey, "Set authentication protocol pass phrase")
-	snmpWalkCmd.Flags().StringVarP(&cliParams.securityLevel, "security-level", "l", defaultSecurityLevel, "set security level (noAuthNoPriv|authNoPriv|authPriv)")
-	snmpWalkCmd.Flags().StringVarP(&cliParams.snmpContext, "context", "N", defaultContext, "Set context name")
-	snmpWalkCmd.Flags().StringVarP(&cliParams.user, "user-name", "u", defaultUserName, "Set security name")
-	snmpWalkCmd.Flags().StringVarP(&cliParams.privProt, "priv-protocol", "x", defaultPrivProtocol, "Set privacy protocol (DES|AES|AES192|AES192C|AES256|AES256C)")
-	snmpWalkCmd.Flags().StringVarP(&cliParams.privKey, "priv-key", "X", defaultPrivKey, "Set privacy protocol pass phrase")
-
-	// general communication options
-	snmpWalkCmd.Flags().IntVarP(&cliParams.retries, "retries", "r", defaultRetries, "Set the number of retries")
-	snmpWalkCmd.Flags().IntVarP(&cliParams.timeout, "timeout", "t", defaultTimeout, "Set the request timeout (in seconds)")
+	addConnectionFlags(snmpWalkCmd, cliParams)
+	snmpWalkCmd.Flags().StringVar(&cliParams.profileTest, "profile-test", "", "Instead of walking a device, print the metrics, tags and metadata the check produces with the given profile file for the walk recorded in the file given as argument")
 
 	snmpWalkCmd.SetArgs([]string{})
 
@@ -128,21 +125,95 @@ func Commands(globalParams *command.GlobalParams) []*cobra.Command {
 	}
 	snmpCmd.AddCommand(snmpWalkCmd)
 
+	snmpRecordCmd := &cobra.Command{
+		Use:   "record <IP Address>[:Port] <file> [OID] [OPTIONS]",
+		Short: "Perform a snmpwalk and save it in the snmprec format, replayable with `walk --profile-test`",
+		Long:  ``,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cliParams.args = args
+			cliParams.cmd = cmd
+			return fxutil.OneShot(snmprecord,
+				fx.Supply(cliParams),
+				fx.Supply(core.BundleParams{
+					ConfigParams: config.NewAgentParamsWithSecrets(globalParams.ConfFilePath),
+					LogParams:    log.LogForOneShot(command.LoggerName, "off", true)}),
+				core.Bundle,
+			)
+		},
+	}
+	addConnectionFlags(snmpRecordCmd, cliParams)
+	snmpCmd.AddCommand(snmpRecordCmd)
+
+	fetchStatsCmd := &cobra.Command{
+		Use:   "fetch-stats [device ID]",
+		Short: "Print the duration of the last fetch of the devices monitored by the SNMP check, and the timing of each OID",
+		Long:  ``,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cliParams.args = args
+			cliParams.cmd = cmd
+			return fxutil.OneShot(fetchStats,
+				fx.Supply(cliParams),
+				fx.Supply(core.BundleParams{
+					ConfigParams: config.NewAgentParamsWithSecrets(globalParams.ConfFilePath),
+					LogParams:    log.LogForOneShot(command.LoggerName, "off", true)}),
+				core.Bundle,
+			)
+		},
+	}
+	fetchStatsCmd.Flags().BoolVarP(&cliParams.jsonStats, "json", "j", false, "print out raw json")
+	snmpCmd.AddCommand(fetchStatsCmd)
+
+	topologyCmd := &cobra.Command{
+		Use:   "topology",
+		Short: "Print the LLDP/CDP topology graph of the devices monitored by the SNMP check",
+		Long:  ``,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cliParams.args = args
+			cliParams.cmd = cmd
+			return fxutil.OneShot(printTopology,
+				fx.Supply(cliParams),
+				fx.Supply(core.BundleParams{
+					ConfigParams: config.NewAgentParamsWithoutSecrets(globalParams.ConfFilePath),
+					LogParams:    log.LogForOneShot(command.LoggerName, "off", true)}),
+				core.Bundle,
+			)
+		},
+	}
+	topologyCmd.Flags().BoolVar(&cliParams.dotTopology, "dot", false, "print the graph in the GraphViz DOT format instead of JSON")
+	snmpCmd.AddCommand(topologyCmd)
+
 	return []*cobra.Command{snmpCmd}
 }
 
+func addConnectionFlags(cmd *cobra.Command, cliParams *cliParams) {
+	cmd.Flags().StringVarP(&cliParams.snmpVersion, "snmp-version", "v", defaultVersion, "Specify SNMP version to use")
+
+	// snmp v1 or v2c specific
+	cmd.Flags().StringVarP(&cliParams.communityString, "community-string", "C", "", "Set the community string")
+
+	// snmp v3 specific
+	cmd.Flags().StringVarP(&cliParams.authProt, "auth-protocol", "a", defaultAuthProtocol, "Set authentication protocol (MD5|SHA|SHA-224|SHA-256|SHA-384|SHA-512)")
+	cmd.Flags().StringVarP(&cliParams.authKey, "auth-key", "A", defaultAuthKey, "Set authentication protocol pass phrase")
+	cmd.Flags().StringVarP(&cliParams.securityLevel, "security-level", "l", defaultSecurityLevel, "set security level (noAuthNoPriv|authNoPriv|authPriv)")
+	cmd.Flags().StringVarP(&cliParams.snmpContext, "context", "N", defaultContext, "Set context name")
+	cmd.Flags().StringVarP(&cliParams.user, "user-name", "u", defaultUserName, "Set security name")
+	cmd.Flags().StringVarP(&cliParams.privProt, "priv-protocol", "x", defaultPrivProtocol, "Set privacy protocol (DES|AES|AES192|AES192C|AES256|AES256C)")
+	cmd.Flags().StringVarP(&cliParams.privKey, "priv-key", "X", defaultPrivKey, "Set privacy protocol pass phrase")
+
+	// general communication options
+	cmd.Flags().IntVarP(&cliParams.retries, "retries", "r", defaultRetries, "Set the number of retries")
+	cmd.Flags().IntVarP(&cliParams.timeout, "timeout", "t", defaultTimeout, "Set the request timeout (in seconds)")
+}
+
 func snmpwalk(config config.Component, cliParams *cliParams) error {
 	var (
-		address      string
-		deviceIP     string
-		oid          string
-		port         uint16
-		value        uint64
-		setVersion   gosnmp.SnmpVersion
-		authProtocol gosnmp.SnmpV3AuthProtocol
-		privProtocol gosnmp.SnmpV3PrivProtocol
-		msgFlags     gosnmp.SnmpV3MsgFlags
+		address string
+		oid     string
 	)
+	if cliParams.profileTest != "" {
+		return profileTest(cliParams)
+	}
+
 	// Get args
 	if len(cliParams.args) == 0 {
 		fmt.Print("Missing argument: IP address\n")
@@ -161,6 +232,21 @@ func snmpwalk(config config.Component, cliParams *cliParams) error {
 		os.Exit(1)
 		return nil
 	}
+
+	return walk(cliParams, address, oid, printValue)
+}
+
+// walk performs a snmpwalk of the device at `address`, calling `walkFn` for each variable
+func walk(cliParams *cliParams, address string, oid string, walkFn gosnmp.WalkFunc) error {
+	var (
+		deviceIP     string
+		port         uint16
+		value        uint64
+		setVersion   gosnmp.SnmpVersion
+		authProtocol gosnmp.SnmpV3AuthProtocol
+		privProtocol gosnmp.SnmpV3PrivProtocol
+		msgFlags     gosnmp.SnmpV3MsgFlags
+	)
 	if strings.Contains(address, ":") {
 		deviceIP = address[:strings.Index(address, ":")]
 		value, _ = strconv.ParseUint(address[strings.Index(address, ":")+1:], 0, 16)
@@ -336,7 +422,7 @@ func snmpwalk(config config.Component, cliParams *cliParams) error {
 	defer snmp.Conn.Close()
 
 	// Perform a snmpwalk using Walk for all versions
-	err = snmp.Walk(oid, printValue)
+	err = snmp.Walk(oid, walkFn)
 	if err != nil {
 		fmt.Printf("Walk Error: %v\n", err)
 		os.Exit(1)
@@ -26,3 +26,46 @@ func TestCommand(t *testing.T) {
 			require.Equal(t, 10, cliParams.retries)
 		})
 }
+
+func TestFetchStatsCommand(t *testing.T) {
+	fxutil.TestOneShotSubcommand(t,
+		Commands(&command.GlobalParams{}),
+		[]string{"snmp", "fetch-stats", "default:1.2.3.4", "--json"},
+		fetchStats,
+		func(cliParams *cliParams) {
+			require.Equal(t, []string{"default:1.2.3.4"}, cliParams.args)
+			require.True(t, cliParams.jsonStats)
+		})
+}
+
+func TestTopologyCommand(t *testing.T) {
+	fxutil.TestOneShotSubcommand(t,
+		Commands(&command.GlobalParams{}),
+		[]string{"snmp", "topology", "--dot"},
+		printTopology,
+		func(cliParams *cliParams) {
+			require.True(t, cliParams.dotTopology)
+		})
+}
+
+func TestWalkProfileTestCommand(t *testing.T) {
+	fxutil.TestOneShotSubcommand(t,
+		Commands(&command.GlobalParams{}),
+		[]string{"snmp", "walk", "--profile-test", "my-device.yaml", "my-device.snmprec"},
+		snmpwalk,
+		func(cliParams *cliParams) {
+			require.Equal(t, []string{"my-device.snmprec"}, cliParams.args)
+			require.Equal(t, "my-device.yaml", cliParams.profileTest)
+		})
+}
+
+func TestRecordCommand(t *testing.T) {
+	fxutil.TestOneShotSubcommand(t,
+		Commands(&command.GlobalParams{}),
+		[]string{"snmp", "record", "1.2.3.4", "my-device.snmprec", "-C", "public"},
+		snmprecord,
+		func(cliParams *cliParams) {
+			require.Equal(t, []string{"1.2.3.4", "my-device.snmprec"}, cliParams.args)
+			require.Equal(t, "public", cliParams.communityString)
+		})
+}
@@ -0,0 +1,143 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023-present Datadog, Inc.
+
+package snmp
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"text/tabwriter"
+	"time"
+
+	"github.com/DataDog/datadog-agent/comp/core/config"
+	"github.com/DataDog/datadog-agent/pkg/api/util"
+	pkgconfig "github.com/DataDog/datadog-agent/pkg/config"
+)
+
+// deviceFetchStats mirrors the stats of each device served by the agent API
+// at /agent/snmp/fetch-stats
+type deviceFetchStats struct {
+	Timestamp          int64                   `json:"timestamp"`
+	Duration           time.Duration           `json:"duration"`
+	Error              string                  `json:"error,omitempty"`
+	Strategy           string                  `json:"strategy"`
+	Requests           map[string]int          `json:"requests"`
+	BulkMaxRepetitions uint32                  `json:"bulk_max_repetitions"`
+	MaxVarbinds        int                     `json:"max_varbinds,omitempty"`
+	GetBulkDisabled    bool                    `json:"getbulk_disabled,omitempty"`
+	OIDs               map[string]oidFetchStat `json:"oids"`
+}
+
+type oidFetchStat struct {
+	Requests int           `json:"requests"`
+	Duration time.Duration `json:"duration"`
+	Rows     int           `json:"rows,omitempty"`
+	Cached   bool          `json:"cached,omitempty"`
+}
+
+func fetchStats(config config.Component, cliParams *cliParams) error {
+	if len(cliParams.args) > 1 {
+		fmt.Printf("At most one argument is expected: the device ID. %d arguments were given.\n", len(cliParams.args))
+		cliParams.cmd.Help() //nolint:errcheck
+		os.Exit(1)
+		return nil
+	}
+
+	c := util.GetClient(false) // FIX: get certificates right then make this true
+
+	// Set session token
+	err := util.SetAuthToken()
+	if err != nil {
+		return err
+	}
+
+	ipcAddress, err := pkgconfig.GetIPCAddress()
+	if err != nil {
+		return err
+	}
+	url := fmt.Sprintf("https://%v:%v/agent/snmp/fetch-stats", ipcAddress, config.GetInt("cmd_port"))
+
+	r, err := util.DoGet(c, url, util.CloseConnection)
+	if err != nil {
+		if r != nil && string(r) != "" {
+			return fmt.Errorf("the agent ran into an error while getting the SNMP fetch stats: %s", string(r))
+		}
+		return fmt.Errorf("failed to query the agent (running?): %s", err)
+	}
+
+	var stats map[string]deviceFetchStats
+	if err := json.Unmarshal(r, &stats); err != nil {
+		return fmt.Errorf("could not parse the agent response: %v", err)
+	}
+	if len(cliParams.args) == 1 {
+		deviceID := cliParams.args[0]
+		deviceStats, ok := stats[deviceID]
+		if !ok {
+			return fmt.Errorf("no fetch stats for device %s", deviceID)
+		}
+		stats = map[string]deviceFetchStats{deviceID: deviceStats}
+	}
+
+	if cliParams.jsonStats {
+		out, err := json.MarshalIndent(stats, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(out))
+		return nil
+	}
+
+	printFetchStats(os.Stdout, stats)
+	return nil
+}
+
+func printFetchStats(out io.Writer, stats map[string]deviceFetchStats) {
+	if len(stats) == 0 {
+		fmt.Fprintln(out, "No SNMP device fetched yet")
+		return
+	}
+
+	deviceIDs := make([]string, 0, len(stats))
+	for deviceID := range stats {
+		deviceIDs = append(deviceIDs, deviceID)
+	}
+	sort.Strings(deviceIDs)
+
+	for _, deviceID := range deviceIDs {
+		deviceStats := stats[deviceID]
+		fmt.Fprintf(out, "Device %s\n", deviceID)
+		fmt.Fprintf(out, "  Last fetch: %s, took %s using %s\n", time.Unix(deviceStats.Timestamp, 0).Format(time.RFC3339), deviceStats.Duration, deviceStats.Strategy)
+		if deviceStats.Error != "" {
+			fmt.Fprintf(out, "  Error: %s\n", deviceStats.Error)
+		}
+		fmt.Fprintf(out, "  Requests: get=%d getnext=%d getbulk=%d\n", deviceStats.Requests["get"], deviceStats.Requests["getnext"], deviceStats.Requests["getbulk"])
+		fmt.Fprintf(out, "  Learned limits: bulk max repetitions=%d max varbinds=%d getbulk disabled=%t\n", deviceStats.BulkMaxRepetitions, deviceStats.MaxVarbinds, deviceStats.GetBulkDisabled)
+
+		// slowest OIDs first
+		oids := make([]string, 0, len(deviceStats.OIDs))
+		for oid := range deviceStats.OIDs {
+			oids = append(oids, oid)
+		}
+		sort.Slice(oids, func(i, j int) bool {
+			durationI, durationJ := deviceStats.OIDs[oids[i]].Duration, deviceStats.OIDs[oids[j]].Duration
+			if durationI != durationJ {
+				return durationI > durationJ
+			}
+			return oids[i] < oids[j]
+		})
+
+		w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "  OID\tREQUESTS\tDURATION\tROWS\tCACHED")
+		for _, oid := range oids {
+			oidStats := deviceStats.OIDs[oid]
+			fmt.Fprintf(w, "  %s\t%d\t%s\t%d\t%t\n", oid, oidStats.Requests, oidStats.Duration, oidStats.Rows, oidStats.Cached)
+		}
+		w.Flush()
+		fmt.Fprintln(out)
+	}
+}
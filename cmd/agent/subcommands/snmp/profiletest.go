@@ -0,0 +1,68 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023-present Datadog, Inc.
+
+package snmp
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/DataDog/datadog-agent/pkg/collector/corechecks/snmp/profiletest"
+)
+
+func profileTest(cliParams *cliParams) error {
+	if len(cliParams.args) != 1 {
+		fmt.Printf("A walk file recorded with `agent snmp record` must be given as argument. %d arguments were given.\n", len(cliParams.args))
+		cliParams.cmd.Help() //nolint:errcheck
+		os.Exit(1)
+		return nil
+	}
+
+	result, err := profiletest.Run(cliParams.profileTest, cliParams.args[0])
+	if err != nil {
+		return err
+	}
+	if err := printProfileTestResult(os.Stdout, result); err != nil {
+		return err
+	}
+	if len(result.Errors) > 0 {
+		return fmt.Errorf("the profile has %d errors", len(result.Errors))
+	}
+	return nil
+}
+
+func printProfileTestResult(out io.Writer, result *profiletest.Result) error {
+	fmt.Fprintf(out, "=== Metrics (%d) ===\n", len(result.Metrics))
+	for _, metric := range result.Metrics {
+		fmt.Fprintf(out, "%s (%s) %v [%s]\n", metric.Name, metric.Type, metric.Value, strings.Join(metric.Tags, ", "))
+	}
+
+	fmt.Fprintf(out, "\n=== Service checks (%d) ===\n", len(result.ServiceChecks))
+	for _, serviceCheck := range result.ServiceChecks {
+		fmt.Fprintf(out, "%s %s [%s]", serviceCheck.Name, serviceCheck.Status, strings.Join(serviceCheck.Tags, ", "))
+		if serviceCheck.Message != "" {
+			fmt.Fprintf(out, ": %s", serviceCheck.Message)
+		}
+		fmt.Fprintln(out)
+	}
+
+	fmt.Fprintf(out, "\n=== Metadata (%d) ===\n", len(result.Metadata))
+	for _, payload := range result.Metadata {
+		prettyPayload, err := json.MarshalIndent(payload, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(out, string(prettyPayload))
+	}
+
+	fmt.Fprintf(out, "\n=== Errors (%d) ===\n", len(result.Errors))
+	for _, err := range result.Errors {
+		fmt.Fprintln(out, err)
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023-present Datadog, Inc.
+
+package snmp
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+
+	"github.com/gosnmp/gosnmp"
+
+	"github.com/DataDog/datadog-agent/comp/core/config"
+	"github.com/DataDog/datadog-agent/pkg/snmp/gosnmplib"
+)
+
+func snmprecord(config config.Component, cliParams *cliParams) error {
+	var (
+		address  string
+		walkFile string
+		oid      string
+	)
+	// Get args
+	if len(cliParams.args) == 2 {
+		address = cliParams.args[0]
+		walkFile = cliParams.args[1]
+		oid = defaultRecordOID
+	} else if len(cliParams.args) == 3 {
+		address = cliParams.args[0]
+		walkFile = cliParams.args[1]
+		oid = cliParams.args[2]
+	} else {
+		fmt.Printf("The number of arguments must be between 2 and 3. %d arguments were given.\n", len(cliParams.args))
+		cliParams.cmd.Help() //nolint:errcheck
+		os.Exit(1)
+		return nil
+	}
+
+	f, err := os.Create(walkFile)
+	if err != nil {
+		return fmt.Errorf("failed to create walk file: %s", err)
+	}
+	defer f.Close()
+	w := bufio.NewWriter(f)
+
+	recorded := 0
+	err = walk(cliParams, address, oid, func(pdu gosnmp.SnmpPDU) error {
+		if err := gosnmplib.WriteSnmprecRecord(w, pdu); err != nil {
+			fmt.Fprintf(os.Stderr, "Skipping variable: %s\n", err)
+			return nil
+		}
+		recorded++
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if err := w.Flush(); err != nil {
+		return fmt.Errorf("failed to write walk file: %s", err)
+	}
+
+	fmt.Printf("Recorded %d variables in %s\n", recorded, walkFile)
+	return nil
+}
@@ -11,10 +11,13 @@ import (
 
 	"github.com/DataDog/datadog-agent/pkg/aggregator"
 	"github.com/DataDog/datadog-agent/pkg/aggregator/ckey"
+	"github.com/DataDog/datadog-agent/pkg/aggregator/rollup"
+	"github.com/DataDog/datadog-agent/pkg/config"
 	"github.com/DataDog/datadog-agent/pkg/metrics"
 	"github.com/DataDog/datadog-agent/pkg/metrics/event"
 	"github.com/DataDog/datadog-agent/pkg/metrics/servicecheck"
 	"github.com/DataDog/datadog-agent/pkg/tagset"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
 )
 
 // batcher batches multiple metrics before submission
@@ -48,6 +51,9 @@ type batcher struct {
 	tagsBuffer    *tagset.HashingTagsAccumulator
 	keyGenerator  *ckey.KeyGenerator
 	pipelineCount int
+	// rollups are the metric rollup rules: all the samples of a rolled-up
+	// metric are sent to the same pipeline so that a single sampler aggregates them.
+	rollups *rollup.Rules
 	// the batcher has to know if the no-aggregation pipeline is enabled or not:
 	// in the case of the no agg pipeline disabled, it would send them as usual to
 	// the demux which only choice would be to send them on an arbitrary sampler
@@ -106,6 +112,7 @@ func newBatcher(demux aggregator.DemultiplexerWithAggregator) *batcher {
 		pipelineCount: pipelineCount,
 		tagsBuffer:    tagset.NewHashingTagsAccumulator(),
 		keyGenerator:  ckey.NewKeyGenerator(),
+		rollups:       newRollupRules(),
 
 		noAggPipelineEnabled: demux.Options().EnableNoAggregationPipeline,
 	}
@@ -135,9 +142,20 @@ func newServerlessBatcher(demux aggregator.Demultiplexer) *batcher {
 		pipelineCount: pipelineCount,
 		tagsBuffer:    tagset.NewHashingTagsAccumulator(),
 		keyGenerator:  ckey.NewKeyGenerator(),
+		rollups:       newRollupRules(),
 	}
 }
 
+// newRollupRules returns the rules configured by `metric_rollups`, or nil if
+// there is none.
+func newRollupRules() *rollup.Rules {
+	rollups, err := rollup.FromConfig(config.Datadog)
+	if err != nil {
+		log.Errorf("Metric rollups are disabled: %v", err)
+	}
+	return rollups
+}
+
 // Batching data
 // -------------
 
@@ -147,7 +165,10 @@ func (b *batcher) appendSample(sample metrics.MetricSample) {
 		// TODO(remy): re-using this tagsBuffer later in the pipeline (by sharing
 		// it in the sample?) would reduce CPU usage, avoiding to recompute
 		// the tags hashes while generating the context key.
-		b.tagsBuffer.Append(sample.Tags...)
+		// rolled-up metrics are sharded by name only
+		if b.rollups.Match(sample.Name, sample.Mtype) == nil {
+			b.tagsBuffer.Append(sample.Tags...)
+		}
 		h := b.keyGenerator.Generate(sample.Name, sample.Host, b.tagsBuffer)
 		b.tagsBuffer.Reset()
 		shardKey = fastrange(h, b.pipelineCount)
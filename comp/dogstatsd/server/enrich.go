@@ -135,6 +135,8 @@ func enrichMetricType(dogstatsdMetricType metricType) metrics.MetricType {
 		return metrics.SetType
 	case timingType:
 		return metrics.HistogramType
+	case exponentialHistogramType:
+		return metrics.DistributionType
 	}
 	return metrics.GaugeType
 }
@@ -203,18 +205,19 @@ func enrichMetricSample(dest []metrics.MetricSample, ddSample dogstatsdMetricSam
 
 	// only one value contained, simple append it
 	return append(dest, metrics.MetricSample{
-		Host:             hostnameFromTags,
-		Name:             metricName,
-		Tags:             tags,
-		Mtype:            mtype,
-		Value:            ddSample.value,
-		SampleRate:       ddSample.sampleRate,
-		RawValue:         ddSample.setValue,
-		Timestamp:        tsToFloatForSamples(ddSample.ts),
-		OriginFromUDS:    udsOrigin,
-		OriginFromClient: clientOrigin,
-		Cardinality:      cardinality,
-		Source:           metricSource,
+		Host:                 hostnameFromTags,
+		Name:                 metricName,
+		Tags:                 tags,
+		Mtype:                mtype,
+		Value:                ddSample.value,
+		SampleRate:           ddSample.sampleRate,
+		RawValue:             ddSample.setValue,
+		Timestamp:            tsToFloatForSamples(ddSample.ts),
+		OriginFromUDS:        udsOrigin,
+		OriginFromClient:     clientOrigin,
+		Cardinality:          cardinality,
+		Source:               metricSource,
+		ExponentialHistogram: ddSample.exponentialHistogram,
 	})
 }
 
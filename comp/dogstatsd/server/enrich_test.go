@@ -250,6 +250,24 @@ func TestConvertParseSet(t *testing.T) {
 	assert.InEpsilon(t, 1.0, parsed.SampleRate, epsilon)
 }
 
+func TestConvertParseExponentialHistogram(t *testing.T) {
+	conf := enrichConfig{
+		defaultHostname: "default-hostname",
+	}
+
+	parsed, err := parseAndEnrichSingleMetricMessage(t, []byte("daemon:2;10;1;0;3|eh|#foo:bar"), conf)
+
+	assert.NoError(t, err)
+
+	assert.Equal(t, "daemon", parsed.Name)
+	assert.Equal(t, metrics.DistributionType, parsed.Mtype)
+	assert.Equal(t, []string{"foo:bar"}, parsed.Tags)
+	assert.Equal(t, "default-hostname", parsed.Host)
+	require.NotNil(t, parsed.ExponentialHistogram)
+	assert.EqualValues(t, 2, parsed.ExponentialHistogram.Scale)
+	assert.EqualValues(t, 4, parsed.ExponentialHistogram.Count())
+}
+
 func TestConvertParseSetUnicode(t *testing.T) {
 	conf := enrichConfig{
 		defaultHostname: "default-hostname",
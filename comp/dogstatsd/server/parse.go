@@ -13,6 +13,7 @@ import (
 	"unsafe"
 
 	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/metrics"
 )
 
 type messageType int
@@ -131,8 +132,14 @@ func (p *parser) parseMetricSample(message []byte) (dogstatsdMetricSample, error
 	var setValue []byte
 	var values []float64
 	var value float64
+	var histogram *metrics.ExponentialHistogram
 	if metricType == setType {
 		setValue = rawValue // special case for the set type, we obviously don't support multiple values for this type
+	} else if metricType == exponentialHistogramType {
+		histogram, err = parseExponentialHistogram(rawValue)
+		if err != nil {
+			return dogstatsdMetricSample{}, fmt.Errorf("could not parse dogstatsd exponential histogram: %v", err)
+		}
 	} else {
 		// In case the list contains only one value, dogstatsd 1.0
 		// protocol, we directly parse it as a float64. This avoids
@@ -188,15 +195,16 @@ func (p *parser) parseMetricSample(message []byte) (dogstatsdMetricSample, error
 	}
 
 	return dogstatsdMetricSample{
-		name:        p.interner.LoadOrStore(name),
-		value:       value,
-		values:      values,
-		setValue:    string(setValue),
-		metricType:  metricType,
-		sampleRate:  sampleRate,
-		tags:        tags,
-		containerID: containerID,
-		ts:          timestamp,
+		name:                 p.interner.LoadOrStore(name),
+		value:                value,
+		values:               values,
+		setValue:             string(setValue),
+		exponentialHistogram: histogram,
+		metricType:           metricType,
+		sampleRate:           sampleRate,
+		tags:                 tags,
+		containerID:          containerID,
+		ts:                   timestamp,
 	}, nil
 }
 
@@ -258,6 +266,10 @@ func parseInt64(rawInt []byte) (int64, error) {
 	return strconv.ParseInt(*(*string)(unsafe.Pointer(&rawInt)), 10, 64)
 }
 
+func parseUint64(rawInt []byte) (uint64, error) {
+	return strconv.ParseUint(*(*string)(unsafe.Pointer(&rawInt)), 10, 64)
+}
+
 func parseInt(rawInt []byte) (int, error) {
 	return strconv.Atoi(*(*string)(unsafe.Pointer(&rawInt)))
 }
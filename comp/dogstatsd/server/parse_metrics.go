@@ -9,6 +9,8 @@ import (
 	"bytes"
 	"fmt"
 	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/metrics"
 )
 
 type metricType int
@@ -20,6 +22,7 @@ const (
 	histogramType
 	setType
 	timingType
+	exponentialHistogramType
 )
 
 var (
@@ -29,6 +32,9 @@ var (
 	distributionSymbol = []byte("d")
 	setSymbol          = []byte("s")
 	timingSymbol       = []byte("ms")
+	expHistogramSymbol = []byte("eh")
+
+	expHistogramFieldSeparator = []byte(";")
 
 	tagsFieldPrefix       = []byte("#")
 	sampleRateFieldPrefix = []byte("@")
@@ -50,6 +56,8 @@ type dogstatsdMetricSample struct {
 	containerID []byte
 	// timestamp read in the message if any
 	ts time.Time
+	// use to store exponential histogram buckets
+	exponentialHistogram *metrics.ExponentialHistogram
 }
 
 // sanity checks a given message against the metric sample format
@@ -91,6 +99,8 @@ func parseMetricSampleMetricType(rawMetricType []byte) (metricType, error) {
 		return setType, nil
 	case bytes.Equal(rawMetricType, timingSymbol):
 		return timingType, nil
+	case bytes.Equal(rawMetricType, expHistogramSymbol):
+		return exponentialHistogramType, nil
 	}
 	return 0, fmt.Errorf("invalid metric type: %q", rawMetricType)
 }
@@ -98,3 +108,94 @@ func parseMetricSampleMetricType(rawMetricType []byte) (metricType, error) {
 func parseMetricSampleSampleRate(rawSampleRate []byte) (float64, error) {
 	return parseFloat64(rawSampleRate)
 }
+
+// parseExponentialHistogram parses the value of an exponential histogram message:
+//
+//	<scale>;<sum>;<zero_count>;<positive_offset>;<positive_counts>[;<negative_offset>;<negative_counts>]
+//
+// where the bucket counts are comma separated, the first one being the count
+// of the bucket at the given offset.
+func parseExponentialHistogram(rawValue []byte) (*metrics.ExponentialHistogram, error) {
+	rawScale, rawValue := nextExpHistogramField(rawValue)
+	rawSum, rawValue := nextExpHistogramField(rawValue)
+	rawZeroCount, rawValue := nextExpHistogramField(rawValue)
+	if rawValue == nil {
+		return nil, fmt.Errorf("invalid exponential histogram: missing fields")
+	}
+
+	scale, err := parseInt(rawScale)
+	if err != nil {
+		return nil, fmt.Errorf("invalid exponential histogram scale %q: %v", rawScale, err)
+	}
+	sum, err := parseFloat64(rawSum)
+	if err != nil {
+		return nil, fmt.Errorf("invalid exponential histogram sum %q: %v", rawSum, err)
+	}
+	zeroCount, err := parseUint64(rawZeroCount)
+	if err != nil {
+		return nil, fmt.Errorf("invalid exponential histogram zero count %q: %v", rawZeroCount, err)
+	}
+
+	histogram := &metrics.ExponentialHistogram{
+		Scale:     int32(scale),
+		Sum:       sum,
+		ZeroCount: zeroCount,
+	}
+
+	if histogram.Positive, rawValue, err = parseExponentialBuckets(rawValue); err != nil {
+		return nil, fmt.Errorf("invalid exponential histogram positive buckets: %v", err)
+	}
+	if rawValue != nil {
+		if histogram.Negative, _, err = parseExponentialBuckets(rawValue); err != nil {
+			return nil, fmt.Errorf("invalid exponential histogram negative buckets: %v", err)
+		}
+	}
+
+	if err := histogram.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid exponential histogram: %v", err)
+	}
+	return histogram, nil
+}
+
+// parseExponentialBuckets parses an `<offset>;<counts>` pair and returns the remainder
+func parseExponentialBuckets(rawValue []byte) (metrics.ExponentialBuckets, []byte, error) {
+	rawOffset, rawValue := nextExpHistogramField(rawValue)
+	rawCounts, rawValue := nextExpHistogramField(rawValue)
+
+	offset, err := parseInt(rawOffset)
+	if err != nil {
+		return metrics.ExponentialBuckets{}, nil, fmt.Errorf("invalid offset %q: %v", rawOffset, err)
+	}
+
+	buckets := metrics.ExponentialBuckets{Offset: int32(offset)}
+	if len(rawCounts) == 0 {
+		return buckets, rawValue, nil
+	}
+
+	buckets.Counts = make([]uint64, 0, bytes.Count(rawCounts, commaSeparator)+1)
+	for rawCounts != nil {
+		var rawCount []byte
+		if idx := bytes.Index(rawCounts, commaSeparator); idx == -1 {
+			rawCount, rawCounts = rawCounts, nil
+		} else {
+			rawCount, rawCounts = rawCounts[:idx], rawCounts[idx+len(commaSeparator):]
+		}
+
+		count, err := parseUint64(rawCount)
+		if err != nil {
+			return metrics.ExponentialBuckets{}, nil, fmt.Errorf("invalid count %q: %v", rawCount, err)
+		}
+		buckets.Counts = append(buckets.Counts, count)
+	}
+	return buckets, rawValue, nil
+}
+
+// nextExpHistogramField returns the data found before the first expHistogramFieldSeparator and
+// the remainder. If the separator is not found, the remainder is nil.
+func nextExpHistogramField(rawValue []byte) ([]byte, []byte) {
+	sepIndex := bytes.Index(rawValue, expHistogramFieldSeparator)
+	if sepIndex == -1 {
+		return rawValue, nil
+	}
+	return rawValue[:sepIndex], rawValue[sepIndex+len(expHistogramFieldSeparator):]
+}
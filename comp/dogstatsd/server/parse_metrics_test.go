@@ -10,6 +10,7 @@ import (
 	"time"
 
 	"github.com/DataDog/datadog-agent/comp/core/config"
+	"github.com/DataDog/datadog-agent/pkg/metrics"
 	"github.com/DataDog/datadog-agent/pkg/util/fxutil"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -491,6 +492,59 @@ func TestParseGaugeTimestampMalformed(t *testing.T) {
 	assert.Error(t, err)
 }
 
+func TestParseExponentialHistogram(t *testing.T) {
+	sample, err := parseMetricSample(t, make(map[string]any), []byte("daemon:3;42.5;2;-1;1,0,4;0;3|eh|#sometag1:somevalue1"))
+
+	require.NoError(t, err)
+
+	assert.Equal(t, "daemon", sample.name)
+	assert.Equal(t, exponentialHistogramType, sample.metricType)
+	require.Nil(t, sample.values)
+	require.NotNil(t, sample.exponentialHistogram)
+	assert.Equal(t, &metrics.ExponentialHistogram{
+		Scale:     3,
+		Sum:       42.5,
+		ZeroCount: 2,
+		Positive:  metrics.ExponentialBuckets{Offset: -1, Counts: []uint64{1, 0, 4}},
+		Negative:  metrics.ExponentialBuckets{Offset: 0, Counts: []uint64{3}},
+	}, sample.exponentialHistogram)
+	require.Equal(t, 1, len(sample.tags))
+	assert.Equal(t, "sometag1:somevalue1", sample.tags[0])
+}
+
+func TestParseExponentialHistogramPositiveOnly(t *testing.T) {
+	sample, err := parseMetricSample(t, make(map[string]any), []byte("daemon:0;12;0;3;1,1|eh"))
+
+	require.NoError(t, err)
+
+	assert.Equal(t, &metrics.ExponentialHistogram{
+		Scale:    0,
+		Sum:      12,
+		Positive: metrics.ExponentialBuckets{Offset: 3, Counts: []uint64{1, 1}},
+	}, sample.exponentialHistogram)
+}
+
+func TestParseExponentialHistogramError(t *testing.T) {
+	for _, message := range []string{
+		// missing buckets
+		"daemon:3;42.5;2|eh",
+		// invalid scale
+		"daemon:a;42.5;2;0;1|eh",
+		"daemon:25;42.5;2;0;1|eh",
+		// invalid sum
+		"daemon:3;a;2;0;1|eh",
+		// invalid zero count
+		"daemon:3;42.5;-2;0;1|eh",
+		// invalid counts
+		"daemon:3;42.5;2;0;1,-1|eh",
+		"daemon:3;42.5;2;0;1,,1|eh",
+		"daemon:3;42.5;2;0;1;0;a|eh",
+	} {
+		_, err := parseMetricSample(t, make(map[string]any), []byte(message))
+		assert.Error(t, err, message)
+	}
+}
+
 func TestParseManyPipes(t *testing.T) {
 	t.Run("Sample rate and container ID (4 pipes)", func(t *testing.T) {
 		sample, err := parseMetricSample(t, make(map[string]any), []byte("example.metric:2.39283|d|@1.000000|#environment:dev|c:2a25f7fc8fbf573d62053d7263dd2d440c07b6ab4d2b107e50b0d4df1f2ee15f"))
@@ -267,6 +267,10 @@ func NewDefaultForwarder(config config.Component, log log.Component, options *Op
 	}
 
 	flushToDiskMemRatio := config.GetFloat64("forwarder_flush_to_disk_mem_ratio")
+	retryQueueBudgets, err := retry.ParsePayloadKindBudgets(config.Get("forwarder_retry_queue_budgets"))
+	if err != nil {
+		log.Errorf("Retry queue budgets are disabled, invalid forwarder_retry_queue_budgets: %v", err)
+	}
 	domainForwarderSort := transaction.SortByCreatedTimeAndPriority{HighPriorityFirst: true}
 	transactionContainerSort := transaction.SortByCreatedTimeAndPriority{HighPriorityFirst: false}
 
@@ -290,6 +294,7 @@ func NewDefaultForwarder(config config.Component, log log.Component, options *Op
 				log,
 				options.RetryQueuePayloadsTotalMaxSize,
 				flushToDiskMemRatio,
+				retryQueueBudgets,
 				domainFolderPath,
 				diskUsageLimit,
 				transactionContainerSort,
@@ -439,7 +444,12 @@ func (f *DefaultForwarder) createAdvancedHTTPTransactions(endpoint transaction.E
 	allowArbitraryTags := f.config.GetBool("allow_arbitrary_tags")
 
 	for _, payload := range payloads {
+		// the serializer restricts the destinations of the payloads matching a routing rule
+		destinations := payload.GetDestinations()
 		for domain, dr := range f.domainResolvers {
+			if destinations != nil && !containsDomain(destinations, domain) {
+				continue
+			}
 			for _, apiKey := range dr.GetAPIKeys() {
 				t := transaction.NewHTTPTransaction()
 				t.Domain, _ = dr.Resolve(endpoint)
@@ -473,6 +483,15 @@ func (f *DefaultForwarder) createAdvancedHTTPTransactions(endpoint transaction.E
 	return transactions
 }
 
+func containsDomain(domains []string, domain string) bool {
+	for _, d := range domains {
+		if d == domain {
+			return true
+		}
+	}
+	return false
+}
+
 func (f *DefaultForwarder) sendHTTPTransactions(transactions []*transaction.HTTPTransaction) error {
 	if f.internalState.Load() == Stopped {
 		return fmt.Errorf("the forwarder is not started")
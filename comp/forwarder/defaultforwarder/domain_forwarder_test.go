@@ -277,6 +277,7 @@ func TestDomainForwarderRetryQueueAllPayloadsMaxSize(t *testing.T) {
 		nil,
 		1+2,
 		0,
+		nil,
 		telemetry,
 		retry.NewPointCountTelemetryMock())
 	mockConfig := pkgconfig.Mock(t)
@@ -340,6 +341,7 @@ func newDomainForwarderForTest(config config.Component, log log.Component, conne
 		nil,
 		2,
 		0,
+		nil,
 		telemetry,
 		retry.NewPointCountTelemetryMock())
 
@@ -212,6 +212,23 @@ func TestCreateHTTPTransactionsWithMultipleDomains(t *testing.T) {
 	assert.Equal(t, txBar[0].Headers.Get("DD-Api-Key"), "api-key-3")
 }
 
+func TestCreateHTTPTransactionsWithDestinations(t *testing.T) {
+	mockConfig := pkgconfig.Mock(t)
+	log := fxutil.Test[log.Component](t, log.MockModule)
+	forwarder := NewDefaultForwarder(mockConfig, log, NewOptionsWithResolvers(mockConfig, log, resolver.NewSingleDomainResolvers(keysWithMultipleDomains)))
+	endpoint := transaction.Endpoint{Route: "/api/foo", Name: "foo"}
+	p1 := []byte("A payload")
+	p2 := []byte("Another payload")
+	payloads := transaction.NewBytesPayloadsWithoutMetaData([]*[]byte{&p1, &p2})
+	payloads[0].SetDestinations([]string{"datadog.bar"})
+	payloads[1].SetDestinations([]string{})
+
+	transactions := forwarder.createHTTPTransactions(endpoint, payloads, make(http.Header))
+	require.Len(t, transactions, 1)
+	assert.Equal(t, "datadog.bar", transactions[0].Domain)
+	assert.Equal(t, p1, transactions[0].Payload.GetContent())
+}
+
 func TestCreateHTTPTransactionsWithDifferentResolvers(t *testing.T) {
 	resolvers := resolver.NewSingleDomainResolvers(keysWithMultipleDomains)
 	additionalResolver := resolver.NewMultiDomainResolver("datadog.vector", []string{"api-key-4"})
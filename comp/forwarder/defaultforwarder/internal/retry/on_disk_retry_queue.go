@@ -22,12 +22,16 @@ const retryTransactionsExtension = ".retry"
 const retryFileFormat = "2006_01_02__15_04_05_"
 
 type onDiskRetryQueue struct {
-	log                 log.Component
-	serializer          *HTTPTransactionsSerializer
-	storagePath         string
-	diskUsageLimit      *DiskUsageLimit
-	filenames           []string
-	currentSizeInBytes  int64
+	log                log.Component
+	serializer         *HTTPTransactionsSerializer
+	storagePath        string
+	diskUsageLimit     *DiskUsageLimit
+	filenames          []string
+	currentSizeInBytes int64
+	// The size of the files by payload kind is approximated from the payload
+	// sizes. Files reloaded from a previous run of the Agent have no payload kind.
+	sizeInBytesByKind   map[string]int64
+	fileSizesByKind     map[string]map[string]int64
 	telemetry           onDiskRetryQueueTelemetry
 	pointCountTelemetry *PointCountTelemetry
 }
@@ -49,6 +53,8 @@ func newOnDiskRetryQueue(
 		serializer:          serializer,
 		storagePath:         storagePath,
 		diskUsageLimit:      diskUsageLimit,
+		sizeInBytesByKind:   make(map[string]int64),
+		fileSizesByKind:     make(map[string]map[string]int64),
 		telemetry:           telemetry,
 		pointCountTelemetry: pointCountTelemetry,
 	}
@@ -105,6 +111,7 @@ func (s *onDiskRetryQueue) Store(transactions []transaction.Transaction) error {
 	}
 	s.currentSizeInBytes += bufferSize
 	s.filenames = append(s.filenames, file.Name())
+	s.addFileSizesByKind(file.Name(), transactions, bufferSize)
 	s.telemetry.setFileSize(bufferSize)
 	s.telemetry.setCurrentSizeInBytes(s.GetDiskSpaceUsed())
 	s.telemetry.setFilesCount(s.getFilesCount())
@@ -151,6 +158,48 @@ func (s *onDiskRetryQueue) GetDiskSpaceUsed() int64 {
 	return s.currentSizeInBytes
 }
 
+// GetDiskSpaceUsedByKind returns the approximate disk space used by the transactions of a payload kind.
+func (s *onDiskRetryQueue) GetDiskSpaceUsedByKind(kind string) int64 {
+	return s.sizeInBytesByKind[kind]
+}
+
+// GetMaxSizeInBytes returns the maximum disk space used for storing transactions.
+func (s *onDiskRetryQueue) GetMaxSizeInBytes() int64 {
+	return s.diskUsageLimit.getMaxSizeInBytes()
+}
+
+// addFileSizesByKind splits the size of a file between the payload kinds of
+// its transactions, in proportion to their payload sizes.
+func (s *onDiskRetryQueue) addFileSizesByKind(filename string, transactions []transaction.Transaction, fileSize int64) {
+	payloadSizesByKind := make(map[string]int64)
+	totalPayloadSize := int64(0)
+	for _, t := range transactions {
+		size := int64(t.GetPayloadSize())
+		payloadSizesByKind[PayloadKindForEndpoint(t.GetEndpointName())] += size
+		totalPayloadSize += size
+	}
+	if totalPayloadSize == 0 {
+		return
+	}
+
+	sizesByKind := make(map[string]int64, len(payloadSizesByKind))
+	for kind, payloadSize := range payloadSizesByKind {
+		size := fileSize * payloadSize / totalPayloadSize
+		sizesByKind[kind] = size
+		s.sizeInBytesByKind[kind] += size
+		s.telemetry.addCurrentSizeInBytesByKind(size, kind)
+	}
+	s.fileSizesByKind[filename] = sizesByKind
+}
+
+func (s *onDiskRetryQueue) removeFileSizesByKind(filename string) {
+	for kind, size := range s.fileSizesByKind[filename] {
+		s.sizeInBytesByKind[kind] -= size
+		s.telemetry.addCurrentSizeInBytesByKind(-size, kind)
+	}
+	delete(s.fileSizesByKind, filename)
+}
+
 func (s *onDiskRetryQueue) makeRoomFor(bufferSize int64) error {
 	maxSizeInBytes := s.diskUsageLimit.getMaxSizeInBytes()
 	if bufferSize > maxSizeInBytes {
@@ -199,6 +248,7 @@ func (s *onDiskRetryQueue) removeFileAt(index int) error {
 	// Remove the file from s.filenames also in case of error to not
 	// fail on the next call.
 	s.filenames = append(s.filenames[:index], s.filenames[index+1:]...)
+	s.removeFileSizesByKind(filename)
 
 	size, err := util.GetFileSize(filename)
 	if err != nil {
@@ -0,0 +1,114 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package retry
+
+import (
+	"fmt"
+
+	"github.com/mitchellh/mapstructure"
+
+	"github.com/DataDog/datadog-agent/comp/forwarder/defaultforwarder/endpoints"
+)
+
+// Payload kinds group the endpoints sharing a retry queue budget
+const (
+	PayloadKindMetrics       = "metrics"
+	PayloadKindServiceChecks = "service_checks"
+	PayloadKindEvents        = "events"
+	PayloadKindIntake        = "intake"
+	PayloadKindMetadata      = "metadata"
+	PayloadKindProcesses     = "processes"
+	PayloadKindOrchestrator  = "orchestrator"
+	PayloadKindOther         = "other"
+)
+
+// PayloadKinds lists all the payload kinds
+var PayloadKinds = []string{
+	PayloadKindMetrics,
+	PayloadKindServiceChecks,
+	PayloadKindEvents,
+	PayloadKindIntake,
+	PayloadKindMetadata,
+	PayloadKindProcesses,
+	PayloadKindOrchestrator,
+	PayloadKindOther,
+}
+
+var payloadKindsByEndpoint = map[string]string{
+	endpoints.V1SeriesEndpoint.Name:             PayloadKindMetrics,
+	endpoints.SeriesEndpoint.Name:               PayloadKindMetrics,
+	endpoints.SketchSeriesEndpoint.Name:         PayloadKindMetrics,
+	endpoints.V1CheckRunsEndpoint.Name:          PayloadKindServiceChecks,
+	endpoints.ServiceChecksEndpoint.Name:        PayloadKindServiceChecks,
+	endpoints.EventsEndpoint.Name:               PayloadKindEvents,
+	endpoints.V1IntakeEndpoint.Name:             PayloadKindIntake,
+	endpoints.V1MetadataEndpoint.Name:           PayloadKindMetadata,
+	endpoints.HostMetadataEndpoint.Name:         PayloadKindMetadata,
+	endpoints.ProcessesEndpoint.Name:            PayloadKindProcesses,
+	endpoints.ProcessDiscoveryEndpoint.Name:     PayloadKindProcesses,
+	endpoints.ProcessLifecycleEndpoint.Name:     PayloadKindProcesses,
+	endpoints.RtProcessesEndpoint.Name:          PayloadKindProcesses,
+	endpoints.ContainerEndpoint.Name:            PayloadKindProcesses,
+	endpoints.RtContainerEndpoint.Name:          PayloadKindProcesses,
+	endpoints.ConnectionsEndpoint.Name:          PayloadKindProcesses,
+	endpoints.OrchestratorEndpoint.Name:         PayloadKindOrchestrator,
+	endpoints.OrchestratorManifestEndpoint.Name: PayloadKindOrchestrator,
+}
+
+// PayloadKindForEndpoint returns the payload kind of the transactions sent to an endpoint
+func PayloadKindForEndpoint(endpointName string) string {
+	if kind, found := payloadKindsByEndpoint[endpointName]; found {
+		return kind
+	}
+	return PayloadKindOther
+}
+
+// PayloadKindBudget limits the share of the retry queue a payload kind can use.
+// A zero ratio means no limit.
+type PayloadKindBudget struct {
+	// MaxMemRatio is the maximum ratio of the in-memory retry queue used by the payload kind
+	MaxMemRatio float64 `mapstructure:"max_mem_ratio"`
+	// MaxDiskRatio is the maximum ratio of the on-disk retry queue used by the payload kind
+	MaxDiskRatio float64 `mapstructure:"max_disk_ratio"`
+}
+
+// PayloadKindBudgets are the budgets of the payload kinds. Payload kinds
+// without a budget are only limited by the size of the retry queue.
+type PayloadKindBudgets map[string]PayloadKindBudget
+
+// ParsePayloadKindBudgets parses the `forwarder_retry_queue_budgets` setting
+func ParsePayloadKindBudgets(raw interface{}) (PayloadKindBudgets, error) {
+	if raw == nil {
+		return nil, nil
+	}
+
+	var budgets PayloadKindBudgets
+	if err := mapstructure.Decode(raw, &budgets); err != nil {
+		return nil, err
+	}
+
+	for kind, budget := range budgets {
+		if !isPayloadKind(kind) {
+			return nil, fmt.Errorf("unknown payload kind %q, valid payload kinds are %v", kind, PayloadKinds)
+		}
+		if budget.MaxMemRatio < 0 || budget.MaxMemRatio > 1 {
+			return nil, fmt.Errorf("max_mem_ratio of %q must be between 0 and 1", kind)
+		}
+		if budget.MaxDiskRatio < 0 || budget.MaxDiskRatio > 1 {
+			return nil, fmt.Errorf("max_disk_ratio of %q must be between 0 and 1", kind)
+		}
+	}
+	return budgets, nil
+}
+
+func isPayloadKind(kind string) bool {
+	for _, k := range PayloadKinds {
+		if k == kind {
+			return true
+		}
+	}
+	return false
+}
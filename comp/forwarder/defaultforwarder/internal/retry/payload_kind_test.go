@@ -0,0 +1,49 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build test
+
+package retry
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/comp/forwarder/defaultforwarder/endpoints"
+)
+
+func TestPayloadKindForEndpoint(t *testing.T) {
+	assert.Equal(t, PayloadKindMetrics, PayloadKindForEndpoint(endpoints.SeriesEndpoint.Name))
+	assert.Equal(t, PayloadKindMetrics, PayloadKindForEndpoint(endpoints.SketchSeriesEndpoint.Name))
+	assert.Equal(t, PayloadKindServiceChecks, PayloadKindForEndpoint(endpoints.V1CheckRunsEndpoint.Name))
+	assert.Equal(t, PayloadKindMetadata, PayloadKindForEndpoint(endpoints.HostMetadataEndpoint.Name))
+	assert.Equal(t, PayloadKindIntake, PayloadKindForEndpoint(endpoints.V1IntakeEndpoint.Name))
+	assert.Equal(t, PayloadKindOther, PayloadKindForEndpoint(endpoints.V1ValidateEndpoint.Name))
+	assert.Equal(t, PayloadKindOther, PayloadKindForEndpoint(""))
+}
+
+func TestParsePayloadKindBudgets(t *testing.T) {
+	budgets, err := ParsePayloadKindBudgets(nil)
+	require.NoError(t, err)
+	assert.Nil(t, budgets)
+
+	budgets, err = ParsePayloadKindBudgets(map[string]interface{}{
+		"metadata":  map[string]interface{}{"max_mem_ratio": 0.1, "max_disk_ratio": 0.2},
+		"processes": map[string]interface{}{"max_mem_ratio": 0.5},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, PayloadKindBudgets{
+		PayloadKindMetadata:  {MaxMemRatio: 0.1, MaxDiskRatio: 0.2},
+		PayloadKindProcesses: {MaxMemRatio: 0.5},
+	}, budgets)
+
+	_, err = ParsePayloadKindBudgets(map[string]interface{}{"logs": map[string]interface{}{"max_mem_ratio": 0.1}})
+	assert.Error(t, err)
+
+	_, err = ParsePayloadKindBudgets(map[string]interface{}{"metadata": map[string]interface{}{"max_mem_ratio": 1.5}})
+	assert.Error(t, err)
+}
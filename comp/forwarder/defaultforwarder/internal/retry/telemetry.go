@@ -51,6 +51,46 @@ func (g *gaugeExpvar) set(v float64, tagsValue ...string) {
 	g.expvar.Set(int64(v))
 }
 
+// payloadKindExpvar is a counter or a gauge tagged by domain and payload kind.
+// Its expvars are the sums across domains, one for each payload kind.
+type payloadKindExpvar struct {
+	metric  addableMetric
+	expvars map[string]*expvar.Int
+}
+
+// addableMetric is implemented by both telemetry.Counter and telemetry.Gauge
+type addableMetric interface {
+	Add(value float64, tagsValue ...string)
+}
+
+func newPayloadKindCounterExpvar(subsystem string, name string, expvarName string, help string, parent *expvar.Map) *payloadKindExpvar {
+	return newPayloadKindExpvar(telemetry.NewCounter(subsystem, name, []string{"domain", "payload_kind"}, help), expvarName, parent)
+}
+
+func newPayloadKindGaugeExpvar(subsystem string, name string, expvarName string, help string, parent *expvar.Map) *payloadKindExpvar {
+	return newPayloadKindExpvar(telemetry.NewGauge(subsystem, name, []string{"domain", "payload_kind"}, help), expvarName, parent)
+}
+
+func newPayloadKindExpvar(metric addableMetric, expvarName string, parent *expvar.Map) *payloadKindExpvar {
+	e := &payloadKindExpvar{
+		metric:  metric,
+		expvars: make(map[string]*expvar.Int, len(PayloadKinds)),
+	}
+	for _, kind := range PayloadKinds {
+		v := &expvar.Int{}
+		parent.Get(kind).(*expvar.Map).Set(expvarName, v)
+		e.expvars[kind] = v
+	}
+	return e
+}
+
+func (e *payloadKindExpvar) add(v float64, domainName string, kind string) {
+	e.metric.Add(v, domainName, kind)
+	if i, found := e.expvars[kind]; found {
+		i.Add(int64(v))
+	}
+}
+
 var (
 	removalPolicyExpvar                  = expvar.Map{}
 	newRemovalPolicyCountTelemetry       *gaugeExpvar
@@ -66,6 +106,12 @@ var (
 
 	transactionContainerPointDroppedCountTelemetry *counterExpvar
 
+	payloadKindsExpvar                  = expvar.Map{}
+	payloadKindRetriedCountTelemetry    *payloadKindExpvar
+	payloadKindDroppedCountTelemetry    *payloadKindExpvar
+	payloadKindMemSizeInBytesTelemetry  *payloadKindExpvar
+	payloadKindDiskSizeInBytesTelemetry *payloadKindExpvar
+
 	fileStorageExpvar                       = expvar.Map{}
 	serializeCountTelemetry                 *counterExpvar
 	deserializeCountTelemetry               *counterExpvar
@@ -139,6 +185,35 @@ func init() {
 		"The number of points dropped",
 		&transactionContainerExpvar)
 
+	transactionContainerExpvar.Set("PayloadKinds", &payloadKindsExpvar)
+	for _, kind := range PayloadKinds {
+		payloadKindsExpvar.Set(kind, &expvar.Map{})
+	}
+	payloadKindRetriedCountTelemetry = newPayloadKindCounterExpvar(
+		"transaction_container",
+		"payload_kind_transactions_retried_count",
+		"TransactionsRetriedCount",
+		"The number of transactions added to the retry queue by payload kind",
+		&payloadKindsExpvar)
+	payloadKindDroppedCountTelemetry = newPayloadKindCounterExpvar(
+		"transaction_container",
+		"payload_kind_transactions_dropped_count",
+		"TransactionsDroppedCount",
+		"The number of transactions dropped from the retry queue by payload kind",
+		&payloadKindsExpvar)
+	payloadKindMemSizeInBytesTelemetry = newPayloadKindGaugeExpvar(
+		"transaction_container",
+		"payload_kind_mem_size_in_bytes",
+		"MemSizeInBytes",
+		"The in-memory retry queue size by payload kind",
+		&payloadKindsExpvar)
+	payloadKindDiskSizeInBytesTelemetry = newPayloadKindGaugeExpvar(
+		"transaction_container",
+		"payload_kind_disk_size_in_bytes",
+		"DiskSizeInBytes",
+		"The approximate on-disk retry queue size by payload kind",
+		&payloadKindsExpvar)
+
 	transaction.ForwarderExpvars.Set("FileStorage", &fileStorageExpvar)
 	serializeCountTelemetry = newCounterExpvar(
 		"file_storage",
@@ -254,6 +329,18 @@ func (t TransactionRetryQueueTelemetry) addPointDroppedCount(count int) {
 	transactionContainerPointDroppedCountTelemetry.add(float64(count), t.domainName)
 }
 
+func (t TransactionRetryQueueTelemetry) incRetriedCountByKind(kind string) {
+	payloadKindRetriedCountTelemetry.add(1, t.domainName, kind)
+}
+
+func (t TransactionRetryQueueTelemetry) addDroppedCountByKind(count int, kind string) {
+	payloadKindDroppedCountTelemetry.add(float64(count), t.domainName, kind)
+}
+
+func (t TransactionRetryQueueTelemetry) addMemSizeInBytesByKind(count int, kind string) {
+	payloadKindMemSizeInBytesTelemetry.add(float64(count), t.domainName, kind)
+}
+
 type onDiskRetryQueueTelemetry struct {
 	domainName string
 }
@@ -296,6 +383,10 @@ func (t onDiskRetryQueueTelemetry) addPointDroppedCount(count int) {
 	fileStoragePointDroppedCountTelemetry.add(float64(count), t.domainName)
 }
 
+func (t onDiskRetryQueueTelemetry) addCurrentSizeInBytesByKind(count int64, kind string) {
+	payloadKindDiskSizeInBytesTelemetry.add(float64(count), t.domainName, kind)
+}
+
 func (t onDiskRetryQueueTelemetry) addDeserializeErrorsCount(count int) {
 	deserializeErrorsCountTelemetry.add(float64(count), t.domainName)
 }
@@ -21,6 +21,8 @@ type TransactionDiskStorage interface {
 	Store([]transaction.Transaction) error
 	ExtractLast() ([]transaction.Transaction, error)
 	GetDiskSpaceUsed() int64
+	GetDiskSpaceUsedByKind(kind string) int64
+	GetMaxSizeInBytes() int64
 }
 
 // TransactionPrioritySorter is an interface to sort transactions.
@@ -35,6 +37,8 @@ type TransactionRetryQueue struct {
 	currentMemSizeInBytes int
 	maxMemSizeInBytes     int
 	flushToStorageRatio   float64
+	budgets               PayloadKindBudgets
+	memSizeInBytesByKind  map[string]int
 	dropPrioritySorter    TransactionPrioritySorter
 	optionalStorage       TransactionDiskStorage
 	telemetry             TransactionRetryQueueTelemetry
@@ -47,6 +51,7 @@ func BuildTransactionRetryQueue(
 	log log.Component,
 	maxMemSizeInBytes int,
 	flushToStorageRatio float64,
+	budgets PayloadKindBudgets,
 	optionalDomainFolderPath string,
 	optionalDiskUsageLimit *DiskUsageLimit,
 	dropPrioritySorter TransactionPrioritySorter,
@@ -72,6 +77,7 @@ func BuildTransactionRetryQueue(
 		storage,
 		maxMemSizeInBytes,
 		flushToStorageRatio,
+		budgets,
 		NewTransactionRetryQueueTelemetry(domain),
 		pointCountTelemetry)
 }
@@ -82,15 +88,18 @@ func NewTransactionRetryQueue(
 	optionalTransactionStorage TransactionDiskStorage,
 	maxMemSizeInBytes int,
 	flushToStorageRatio float64,
+	budgets PayloadKindBudgets,
 	telemetry TransactionRetryQueueTelemetry,
 	pointCountTelemetry *PointCountTelemetry) *TransactionRetryQueue {
 	return &TransactionRetryQueue{
-		maxMemSizeInBytes:   maxMemSizeInBytes,
-		flushToStorageRatio: flushToStorageRatio,
-		dropPrioritySorter:  dropPrioritySorter,
-		optionalStorage:     optionalTransactionStorage,
-		telemetry:           telemetry,
-		pointCountTelemetry: pointCountTelemetry,
+		maxMemSizeInBytes:    maxMemSizeInBytes,
+		flushToStorageRatio:  flushToStorageRatio,
+		budgets:              budgets,
+		memSizeInBytesByKind: make(map[string]int),
+		dropPrioritySorter:   dropPrioritySorter,
+		optionalStorage:      optionalTransactionStorage,
+		telemetry:            telemetry,
+		pointCountTelemetry:  pointCountTelemetry,
 	}
 }
 
@@ -104,51 +113,151 @@ func NewTransactionRetryQueue(
 // The first 3 transactions are flushed to the disk as 10 + 20 + 30 >= 60
 // If disk serialization failed or is not enabled, remove old transactions such as
 // `currentMemSizeInBytes` <= `maxMemSizeInBytes`
+//
+// A payload kind with a memory budget in `budgets` cannot use more than its share of
+// `maxMemSizeInBytes` and only evicts its own transactions: when there is no room left,
+// its oldest transactions are flushed or dropped, and if it is still not enough the new
+// transaction is dropped. This way low value payloads never evict metrics or service checks.
+// Transactions of a payload kind exceeding its disk budget are dropped instead of being
+// flushed to disk.
 func (tc *TransactionRetryQueue) Add(t transaction.Transaction) (int, error) {
 	tc.mutex.Lock()
 	defer tc.mutex.Unlock()
 
-	var diskErr error
+	kind := PayloadKindForEndpoint(t.GetEndpointName())
+	tc.telemetry.incRetriedCountByKind(kind)
 	payloadSize := t.GetPayloadSize()
-	if tc.optionalStorage != nil {
-		payloadsGroupToFlush := tc.extractTransactionsForDisk(payloadSize)
-		for _, payloads := range payloadsGroupToFlush {
-			if err := tc.optionalStorage.Store(payloads); err != nil {
-				diskErr = multierror.Append(diskErr, err)
-				// Assuming all payloads failed during serialization
-				pointCountDroppped := 0
-				for _, payload := range payloads {
-					pointCountDroppped += payload.GetPointCount()
-				}
-				tc.onDropPoints(pointCountDroppped)
+	maxKindMemSizeInBytes, hasMemBudget := tc.getMaxMemSizeInBytesForKind(kind)
+
+	var payloadsGroupToFlush [][]transaction.Transaction
+	var transactionsToDrop []transaction.Transaction
+	if hasMemBudget {
+		sizeInBytesToEvict := maxInt(
+			tc.memSizeInBytesByKind[kind]+payloadSize-maxKindMemSizeInBytes,
+			tc.currentMemSizeInBytes+payloadSize-tc.maxMemSizeInBytes)
+		if sizeInBytesToEvict > 0 {
+			transactions := tc.extractKindTransactionsFromMemory(kind, sizeInBytesToEvict)
+			if tc.optionalStorage == nil {
+				transactionsToDrop = transactions
+			} else if len(transactions) > 0 {
+				payloadsGroupToFlush = append(payloadsGroupToFlush, transactions)
 			}
 		}
-		if diskErr != nil {
-			diskErr = fmt.Errorf("Cannot store transactions on disk: %v", diskErr)
-			tc.telemetry.incErrorsCount()
-		}
+	} else if tc.optionalStorage != nil {
+		payloadsGroupToFlush = tc.extractTransactionsForDisk(payloadSize)
+	}
+
+	var diskErr error
+	if len(payloadsGroupToFlush) > 0 {
+		var transactionsOverDiskBudget []transaction.Transaction
+		transactionsOverDiskBudget, diskErr = tc.flushToDisk(payloadsGroupToFlush)
+		transactionsToDrop = append(transactionsToDrop, transactionsOverDiskBudget...)
 	}
 
 	// If disk serialization failed or is not enabled, make sure `currentMemSizeInBytes` <= `maxMemSizeInBytes`
 	payloadSizeInBytesToDrop := (tc.currentMemSizeInBytes + payloadSize) - tc.maxMemSizeInBytes
-	inMemTransactionDroppedCount := 0
-	if payloadSizeInBytesToDrop > 0 {
-		transactions := tc.extractTransactionsFromMemory(payloadSizeInBytesToDrop)
-		pointCountDroppped := 0
-		for _, tr := range transactions {
-			pointCountDroppped += tr.GetPointCount()
+	if hasMemBudget {
+		if payloadSizeInBytesToDrop > 0 || tc.memSizeInBytesByKind[kind]+payloadSize > maxKindMemSizeInBytes {
+			transactionsToDrop = append(transactionsToDrop, t)
+			t = nil
 		}
-		tc.onDropPoints(pointCountDroppped)
-		inMemTransactionDroppedCount = len(transactions)
-		tc.telemetry.addTransactionsDroppedCount(inMemTransactionDroppedCount)
+	} else if payloadSizeInBytesToDrop > 0 {
+		transactionsToDrop = append(transactionsToDrop, tc.extractTransactionsFromMemory(payloadSizeInBytesToDrop)...)
 	}
+	tc.onDropTransactions(transactionsToDrop)
 
-	tc.transactions = append(tc.transactions, t)
-	tc.currentMemSizeInBytes += payloadSize
+	if t != nil {
+		tc.transactions = append(tc.transactions, t)
+		tc.currentMemSizeInBytes += payloadSize
+		tc.addMemSizeInBytesByKind(kind, payloadSize)
+	}
 	tc.telemetry.setCurrentMemSizeInBytes(tc.currentMemSizeInBytes)
 	tc.telemetry.setTransactionsCount(len(tc.transactions))
 
-	return inMemTransactionDroppedCount, diskErr
+	return len(transactionsToDrop), diskErr
+}
+
+// flushToDisk stores the transactions on disk and returns the transactions
+// dropped because their payload kind exceeds its disk budget.
+func (tc *TransactionRetryQueue) flushToDisk(payloadsGroupToFlush [][]transaction.Transaction) ([]transaction.Transaction, error) {
+	var diskErr error
+	var transactionsOverDiskBudget []transaction.Transaction
+	for _, payloads := range payloadsGroupToFlush {
+		payloads, overBudget := tc.filterByDiskBudget(payloads)
+		transactionsOverDiskBudget = append(transactionsOverDiskBudget, overBudget...)
+		if len(payloads) == 0 {
+			continue
+		}
+		if err := tc.optionalStorage.Store(payloads); err != nil {
+			diskErr = multierror.Append(diskErr, err)
+			// Assuming all payloads failed during serialization
+			pointCountDroppped := 0
+			for _, payload := range payloads {
+				pointCountDroppped += payload.GetPointCount()
+			}
+			tc.onDropPoints(pointCountDroppped)
+		}
+	}
+	if diskErr != nil {
+		diskErr = fmt.Errorf("Cannot store transactions on disk: %v", diskErr)
+		tc.telemetry.incErrorsCount()
+	}
+	return transactionsOverDiskBudget, diskErr
+}
+
+// filterByDiskBudget splits the transactions between the ones fitting in the disk
+// budget of their payload kind and the others. The disk space used by a transaction
+// is approximated by its payload size.
+func (tc *TransactionRetryQueue) filterByDiskBudget(transactions []transaction.Transaction) ([]transaction.Transaction, []transaction.Transaction) {
+	if len(tc.budgets) == 0 {
+		return transactions, nil
+	}
+
+	maxSizeInBytes := tc.optionalStorage.GetMaxSizeInBytes()
+	pendingSizeInBytesByKind := make(map[string]int64)
+	var kept, overBudget []transaction.Transaction
+	for _, t := range transactions {
+		kind := PayloadKindForEndpoint(t.GetEndpointName())
+		size := int64(t.GetPayloadSize())
+		if ratio := tc.budgets[kind].MaxDiskRatio; ratio > 0 {
+			maxKindSizeInBytes := int64(float64(maxSizeInBytes) * ratio)
+			if tc.optionalStorage.GetDiskSpaceUsedByKind(kind)+pendingSizeInBytesByKind[kind]+size > maxKindSizeInBytes {
+				overBudget = append(overBudget, t)
+				continue
+			}
+		}
+		pendingSizeInBytesByKind[kind] += size
+		kept = append(kept, t)
+	}
+	return kept, overBudget
+}
+
+func (tc *TransactionRetryQueue) onDropTransactions(transactions []transaction.Transaction) {
+	if len(transactions) == 0 {
+		return
+	}
+
+	pointCountDroppped := 0
+	for _, t := range transactions {
+		pointCountDroppped += t.GetPointCount()
+		tc.telemetry.addDroppedCountByKind(1, PayloadKindForEndpoint(t.GetEndpointName()))
+	}
+	tc.onDropPoints(pointCountDroppped)
+	tc.telemetry.addTransactionsDroppedCount(len(transactions))
+}
+
+// getMaxMemSizeInBytesForKind returns the memory budget of a payload kind, if any.
+func (tc *TransactionRetryQueue) getMaxMemSizeInBytesForKind(kind string) (int, bool) {
+	budget, found := tc.budgets[kind]
+	if !found || budget.MaxMemRatio == 0 {
+		return 0, false
+	}
+	return int(float64(tc.maxMemSizeInBytes) * budget.MaxMemRatio), true
+}
+
+func (tc *TransactionRetryQueue) addMemSizeInBytesByKind(kind string, size int) {
+	tc.memSizeInBytesByKind[kind] += size
+	tc.telemetry.addMemSizeInBytesByKind(size, kind)
 }
 
 func (tc *TransactionRetryQueue) onDropPoints(count int) {
@@ -177,6 +286,9 @@ func (tc *TransactionRetryQueue) ExtractTransactions() ([]transaction.Transactio
 		}
 	}
 	tc.currentMemSizeInBytes = 0
+	for kind, size := range tc.memSizeInBytesByKind {
+		tc.addMemSizeInBytesByKind(kind, -size)
+	}
 	tc.telemetry.setCurrentMemSizeInBytes(tc.currentMemSizeInBytes)
 	tc.telemetry.setTransactionsCount(len(tc.transactions))
 	return transactions, nil
@@ -235,6 +347,7 @@ func (tc *TransactionRetryQueue) extractTransactionsFromMemory(payloadSizeInByte
 	for ; i < len(tc.transactions) && sizeInBytesExtracted < payloadSizeInBytesToExtract; i++ {
 		transaction := tc.transactions[i]
 		sizeInBytesExtracted += transaction.GetPayloadSize()
+		tc.addMemSizeInBytesByKind(PayloadKindForEndpoint(transaction.GetEndpointName()), -transaction.GetPayloadSize())
 		transactionsExtracted = append(transactionsExtracted, transaction)
 	}
 
@@ -242,3 +355,33 @@ func (tc *TransactionRetryQueue) extractTransactionsFromMemory(payloadSizeInByte
 	tc.currentMemSizeInBytes -= sizeInBytesExtracted
 	return transactionsExtracted
 }
+
+// extractKindTransactionsFromMemory extracts the transactions of a payload kind, in the
+// drop priority order, until their payload size sum is greater than `payloadSizeInBytesToExtract`.
+func (tc *TransactionRetryQueue) extractKindTransactionsFromMemory(kind string, payloadSizeInBytesToExtract int) []transaction.Transaction {
+	sizeInBytesExtracted := 0
+	var transactionsExtracted []transaction.Transaction
+	var transactionsKept []transaction.Transaction
+
+	tc.dropPrioritySorter.Sort(tc.transactions)
+	for _, transaction := range tc.transactions {
+		if sizeInBytesExtracted >= payloadSizeInBytesToExtract || PayloadKindForEndpoint(transaction.GetEndpointName()) != kind {
+			transactionsKept = append(transactionsKept, transaction)
+			continue
+		}
+		sizeInBytesExtracted += transaction.GetPayloadSize()
+		transactionsExtracted = append(transactionsExtracted, transaction)
+	}
+
+	tc.transactions = transactionsKept
+	tc.currentMemSizeInBytes -= sizeInBytesExtracted
+	tc.addMemSizeInBytesByKind(kind, -sizeInBytesExtracted)
+	return transactionsExtracted
+}
+
+func maxInt(v1, v2 int) int {
+	if v1 > v2 {
+		return v1
+	}
+	return v2
+}
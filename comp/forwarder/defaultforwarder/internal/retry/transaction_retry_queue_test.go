@@ -13,6 +13,7 @@ import (
 	"github.com/stretchr/testify/assert"
 
 	"github.com/DataDog/datadog-agent/comp/core/log"
+	"github.com/DataDog/datadog-agent/comp/forwarder/defaultforwarder/endpoints"
 	"github.com/DataDog/datadog-agent/comp/forwarder/defaultforwarder/transaction"
 	"github.com/DataDog/datadog-agent/pkg/config/resolver"
 	"github.com/DataDog/datadog-agent/pkg/util/filesystem"
@@ -33,7 +34,7 @@ func TestTransactionRetryQueueAdd(t *testing.T) {
 	pointDropped := transactionContainerPointDroppedCountTelemetry.expvar.Value()
 	q := newOnDiskRetryQueueTest(t, a)
 
-	container := NewTransactionRetryQueue(createDropPrioritySorter(), q, 100, 0.6, NewTransactionRetryQueueTelemetry("domain"), NewPointCountTelemetryMock())
+	container := NewTransactionRetryQueue(createDropPrioritySorter(), q, 100, 0.6, nil, NewTransactionRetryQueueTelemetry("domain"), NewPointCountTelemetryMock())
 
 	// When adding the last element `15`, the buffer becomes full and the first 3
 	// transactions are flushed to the disk as 10 + 20 + 30 >= 100 * 0.6
@@ -61,7 +62,7 @@ func TestTransactionRetryQueueSeveralFlushToDisk(t *testing.T) {
 	a := assert.New(t)
 	q := newOnDiskRetryQueueTest(t, a)
 
-	container := NewTransactionRetryQueue(createDropPrioritySorter(), q, 50, 0.1, NewTransactionRetryQueueTelemetry("domain"), NewPointCountTelemetryMock())
+	container := NewTransactionRetryQueue(createDropPrioritySorter(), q, 50, 0.1, nil, NewTransactionRetryQueueTelemetry("domain"), NewPointCountTelemetryMock())
 
 	// Flush to disk when adding `40`
 	for _, payloadSize := range []int{9, 10, 11, 40} {
@@ -81,7 +82,7 @@ func TestTransactionRetryQueueSeveralFlushToDisk(t *testing.T) {
 func TestTransactionRetryQueueNoTransactionStorage(t *testing.T) {
 	a := assert.New(t)
 	pointDropped := transactionContainerPointDroppedCountTelemetry.expvar.Value()
-	container := NewTransactionRetryQueue(createDropPrioritySorter(), nil, 50, 0.1, NewTransactionRetryQueueTelemetry("domain"), NewPointCountTelemetryMock())
+	container := NewTransactionRetryQueue(createDropPrioritySorter(), nil, 50, 0.1, nil, NewTransactionRetryQueueTelemetry("domain"), NewPointCountTelemetryMock())
 
 	for _, payloadSize := range []int{9, 10, 11} {
 		dropCount, err := container.Add(createTransactionWithPayloadSize(payloadSize))
@@ -106,7 +107,7 @@ func TestTransactionRetryQueueZeroMaxMemSizeInBytes(t *testing.T) {
 
 	maxMemSizeInBytes := 0
 	pointDropped := transactionContainerPointDroppedCountTelemetry.expvar.Value()
-	container := NewTransactionRetryQueue(createDropPrioritySorter(), q, maxMemSizeInBytes, 0.1, NewTransactionRetryQueueTelemetry("domain"), NewPointCountTelemetryMock())
+	container := NewTransactionRetryQueue(createDropPrioritySorter(), q, maxMemSizeInBytes, 0.1, nil, NewTransactionRetryQueueTelemetry("domain"), NewPointCountTelemetryMock())
 
 	inMemTrDropped, err := container.Add(createTransactionWithPayloadSize(10))
 	a.NoError(err)
@@ -120,6 +121,117 @@ func TestTransactionRetryQueueZeroMaxMemSizeInBytes(t *testing.T) {
 	a.Equal(pointDropped+1, transactionContainerPointDroppedCountTelemetry.expvar.Value())
 }
 
+func TestTransactionRetryQueueMemBudget(t *testing.T) {
+	a := assert.New(t)
+	budgets := PayloadKindBudgets{PayloadKindMetadata: {MaxMemRatio: 0.3}}
+	container := NewTransactionRetryQueue(createDropPrioritySorter(), nil, 100, 0.1, budgets, NewTransactionRetryQueueTelemetry("domain"), NewPointCountTelemetryMock())
+
+	for _, tr := range []*transaction.HTTPTransaction{
+		createTransactionWithEndpoint(endpoints.SeriesEndpoint, 20),
+		createTransactionWithEndpoint(endpoints.V1MetadataEndpoint, 10),
+		createTransactionWithEndpoint(endpoints.SeriesEndpoint, 20),
+		createTransactionWithEndpoint(endpoints.V1MetadataEndpoint, 10),
+		createTransactionWithEndpoint(endpoints.V1MetadataEndpoint, 10),
+	} {
+		dropCount, err := container.Add(tr)
+		a.NoError(err)
+		a.Equal(0, dropCount)
+	}
+
+	// The metadata budget is exceeded: the oldest metadata transaction is dropped
+	dropCount, err := container.Add(createTransactionWithEndpoint(endpoints.V1MetadataEndpoint, 5))
+	a.NoError(err)
+	a.Equal(1, dropCount)
+	a.Equal(20+20+10+10+5, container.getCurrentMemSizeInBytes())
+	a.Equal(25, container.memSizeInBytesByKind[PayloadKindMetadata])
+
+	// A transaction bigger than the budget is dropped, as well as every metadata transaction
+	dropCount, err = container.Add(createTransactionWithEndpoint(endpoints.V1MetadataEndpoint, 40))
+	a.NoError(err)
+	a.Equal(4, dropCount)
+	a.Equal(20+20, container.getCurrentMemSizeInBytes())
+	a.Equal(0, container.memSizeInBytesByKind[PayloadKindMetadata])
+
+	// When the retry queue is full, metadata transactions never evict series
+	dropCount, err = container.Add(createTransactionWithEndpoint(endpoints.SeriesEndpoint, 55))
+	a.NoError(err)
+	a.Equal(0, dropCount)
+	dropCount, err = container.Add(createTransactionWithEndpoint(endpoints.V1MetadataEndpoint, 10))
+	a.NoError(err)
+	a.Equal(1, dropCount)
+	a.Equal(20+20+55, container.getCurrentMemSizeInBytes())
+
+	assertPayloadSizeFromExtractTransactions(a, container, []int{20, 20, 55})
+	a.Equal(0, container.memSizeInBytesByKind[PayloadKindMetrics])
+}
+
+func TestTransactionRetryQueueMemBudgetFlushToDisk(t *testing.T) {
+	a := assert.New(t)
+	q := newOnDiskRetryQueueTest(t, a)
+	budgets := PayloadKindBudgets{PayloadKindMetadata: {MaxMemRatio: 0.5}}
+	container := NewTransactionRetryQueue(createDropPrioritySorter(), q, 100, 0.1, budgets, NewTransactionRetryQueueTelemetry("domain"), NewPointCountTelemetryMock())
+
+	for _, tr := range []*transaction.HTTPTransaction{
+		createTransactionWithEndpoint(endpoints.SeriesEndpoint, 20),
+		createTransactionWithEndpoint(endpoints.V1MetadataEndpoint, 30),
+		createTransactionWithEndpoint(endpoints.V1MetadataEndpoint, 30),
+	} {
+		dropCount, err := container.Add(tr)
+		a.NoError(err)
+		a.Equal(0, dropCount)
+	}
+
+	// Only the oldest metadata transaction is flushed to disk
+	a.Equal(1, q.getFilesCount())
+	a.Equal(q.GetDiskSpaceUsed(), q.GetDiskSpaceUsedByKind(PayloadKindMetadata))
+	a.Equal(20+30, container.getCurrentMemSizeInBytes())
+
+	assertPayloadSizeFromExtractTransactions(a, container, []int{20, 30})
+	assertPayloadSizeFromExtractTransactions(a, container, []int{30})
+	a.Equal(int64(0), q.GetDiskSpaceUsedByKind(PayloadKindMetadata))
+}
+
+func TestTransactionRetryQueueDiskBudget(t *testing.T) {
+	a := assert.New(t)
+	q := newOnDiskRetryQueueTest(t, a)
+	budgets := PayloadKindBudgets{}
+	container := NewTransactionRetryQueue(createDropPrioritySorter(), q, 20, 1, budgets, NewTransactionRetryQueueTelemetry("domain"), NewPointCountTelemetryMock())
+
+	// Flush to disk when adding the second transaction
+	for _, payloadSize := range []int{10, 15} {
+		dropCount, err := container.Add(createTransactionWithEndpoint(endpoints.V1MetadataEndpoint, payloadSize))
+		a.NoError(err)
+		a.Equal(0, dropCount)
+	}
+	a.Equal(1, q.getFilesCount())
+	diskSpaceUsed := q.GetDiskSpaceUsedByKind(PayloadKindMetadata)
+	a.Greater(diskSpaceUsed, int64(0))
+
+	// The metadata disk budget is exceeded: the transaction is dropped instead of being flushed to disk
+	budgets[PayloadKindMetadata] = PayloadKindBudget{MaxDiskRatio: float64(diskSpaceUsed+1) / float64(q.GetMaxSizeInBytes())}
+	dropCount, err := container.Add(createTransactionWithEndpoint(endpoints.V1MetadataEndpoint, 10))
+	a.NoError(err)
+	a.Equal(1, dropCount)
+	a.Equal(1, q.getFilesCount())
+
+	// The last metadata transaction is dropped when flushed to disk, while series are stored on disk
+	dropCount, err = container.Add(createTransactionWithEndpoint(endpoints.SeriesEndpoint, 15))
+	a.NoError(err)
+	a.Equal(1, dropCount)
+	dropCount, err = container.Add(createTransactionWithEndpoint(endpoints.SeriesEndpoint, 15))
+	a.NoError(err)
+	a.Equal(0, dropCount)
+	a.Equal(2, q.getFilesCount())
+	a.Equal(diskSpaceUsed, q.GetDiskSpaceUsedByKind(PayloadKindMetadata))
+	a.Greater(q.GetDiskSpaceUsedByKind(PayloadKindMetrics), int64(0))
+}
+
+func createTransactionWithEndpoint(endpoint transaction.Endpoint, payloadSize int) *transaction.HTTPTransaction {
+	tr := createTransactionWithPayloadSize(payloadSize)
+	tr.Endpoint = endpoint
+	return tr
+}
+
 func createTransactionWithPayloadSize(payloadSize int) *transaction.HTTPTransaction {
 	tr := transaction.NewHTTPTransaction()
 	payload := make([]byte, payloadSize)
@@ -0,0 +1,249 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+// Package routing implements the rules deciding which domains receive a
+// payload, or the subset of a payload, when dual shipping to several orgs.
+package routing
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	pkgconfig "github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/tagset"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+
+	"github.com/mitchellh/mapstructure"
+)
+
+// Payload types supported by the routing rules
+const (
+	PayloadTypeSeries        = "series"
+	PayloadTypeSketches      = "sketches"
+	PayloadTypeServiceChecks = "service_checks"
+	PayloadTypeEvents        = "events"
+	PayloadTypeMetadata      = "metadata"
+)
+
+var payloadTypes = map[string]struct{}{
+	PayloadTypeSeries:        {},
+	PayloadTypeSketches:      {},
+	PayloadTypeServiceChecks: {},
+	PayloadTypeEvents:        {},
+	PayloadTypeMetadata:      {},
+}
+
+// RuleConfig is the configuration of a routing rule, as found under
+// `forwarder_routing_rules`.
+type RuleConfig struct {
+	Name string `mapstructure:"name" json:"name"`
+	// PayloadTypes restricts the rule to some payload types. All payload types
+	// match if empty.
+	PayloadTypes []string `mapstructure:"payload_types" json:"payload_types"`
+	// MetricNames are patterns, where `*` matches any sequence of characters.
+	// At least one of them must match the metric name.
+	MetricNames []string `mapstructure:"metric_names" json:"metric_names"`
+	// Tags are patterns, where `*` matches any sequence of characters. Each of
+	// them must match at least one of the metric tags.
+	Tags []string `mapstructure:"tags" json:"tags"`
+	// Domains are the domains, as configured in `dd_url` or `additional_endpoints`,
+	// receiving the data matching the rule.
+	Domains []string `mapstructure:"domains" json:"domains"`
+}
+
+// Route is a set of domains data is sent to
+type Route struct {
+	Name    string
+	Domains []string
+}
+
+type rule struct {
+	route        *Route
+	payloadTypes map[string]struct{}
+	metricNames  []*regexp.Regexp
+	tags         []*regexp.Regexp
+}
+
+// matchesItems returns whether the rule looks at the individual metrics of a payload
+func (r *rule) matchesItems() bool {
+	return len(r.metricNames) > 0 || len(r.tags) > 0
+}
+
+func (r *rule) matchesPayloadType(payloadType string) bool {
+	if len(r.payloadTypes) == 0 {
+		return true
+	}
+	_, found := r.payloadTypes[payloadType]
+	return found
+}
+
+func (r *rule) matchesMetric(name string, tags tagset.CompositeTags) bool {
+	if len(r.metricNames) > 0 && !matchesAny(r.metricNames, name) {
+		return false
+	}
+	for _, pattern := range r.tags {
+		if !tags.Find(pattern.MatchString) {
+			return false
+		}
+	}
+	return true
+}
+
+// Router evaluates the routing rules, in order. Data matching no rule is sent
+// to the default route.
+type Router struct {
+	rules        []*rule
+	defaultRoute *Route
+}
+
+// NewRouter creates a Router from the rules configuration. `domains` are all
+// the domains data can be sent to. Data matching no rule is sent to
+// `defaultDomains` or, if empty, to every domain no rule sends data to.
+func NewRouter(configs []RuleConfig, domains []string, defaultDomains []string) (*Router, error) {
+	known := make(map[string]struct{}, len(domains))
+	for _, d := range domains {
+		known[normalizeDomain(d)] = struct{}{}
+	}
+
+	router := &Router{}
+	targeted := make(map[string]struct{})
+	for i, c := range configs {
+		name := c.Name
+		if name == "" {
+			name = fmt.Sprintf("rule_%d", i)
+		}
+
+		routeDomains, err := checkDomains(c.Domains, known)
+		if err != nil {
+			return nil, fmt.Errorf("invalid routing rule %q: %v", name, err)
+		}
+		if len(routeDomains) == 0 {
+			return nil, fmt.Errorf("invalid routing rule %q: no domains", name)
+		}
+		for _, d := range routeDomains {
+			targeted[d] = struct{}{}
+		}
+
+		r := &rule{
+			route:       &Route{Name: name, Domains: routeDomains},
+			metricNames: compileGlobs(c.MetricNames),
+			tags:        compileGlobs(c.Tags),
+		}
+		if len(c.PayloadTypes) > 0 {
+			r.payloadTypes = make(map[string]struct{}, len(c.PayloadTypes))
+			for _, t := range c.PayloadTypes {
+				if _, found := payloadTypes[t]; !found {
+					return nil, fmt.Errorf("invalid routing rule %q: unknown payload type %q", name, t)
+				}
+				r.payloadTypes[t] = struct{}{}
+			}
+		}
+		router.rules = append(router.rules, r)
+	}
+
+	routeDomains, err := checkDomains(defaultDomains, known)
+	if err != nil {
+		return nil, fmt.Errorf("invalid default routing domains: %v", err)
+	}
+	if len(defaultDomains) == 0 {
+		for d := range known {
+			if _, found := targeted[d]; !found {
+				routeDomains = append(routeDomains, d)
+			}
+		}
+		sort.Strings(routeDomains)
+	}
+	if len(routeDomains) == 0 {
+		log.Warn("Every domain is the destination of a routing rule and no default routing domains are set: data matching no routing rule will be dropped")
+	}
+	router.defaultRoute = &Route{Name: "default", Domains: routeDomains}
+
+	return router, nil
+}
+
+// FromConfig creates a Router from the `forwarder_routing_rules` and
+// `forwarder_routing_default_domains` settings. It returns nil if no routing
+// rule is configured.
+func FromConfig(cfg pkgconfig.ConfigReader, domains []string) (*Router, error) {
+	if !cfg.IsSet("forwarder_routing_rules") {
+		return nil, nil
+	}
+
+	var configs []RuleConfig
+	if err := mapstructure.Decode(cfg.Get("forwarder_routing_rules"), &configs); err != nil {
+		return nil, fmt.Errorf("could not parse forwarder_routing_rules: %v", err)
+	}
+	if len(configs) == 0 {
+		return nil, nil
+	}
+	return NewRouter(configs, domains, cfg.GetStringSlice("forwarder_routing_default_domains"))
+}
+
+// DefaultRoute returns the route of the data matching no rule
+func (r *Router) DefaultRoute() *Route {
+	return r.defaultRoute
+}
+
+// RouteMetric returns the route of a single metric of a payload
+func (r *Router) RouteMetric(payloadType string, name string, tags tagset.CompositeTags) *Route {
+	for _, rule := range r.rules {
+		if rule.matchesPayloadType(payloadType) && rule.matchesMetric(name, tags) {
+			return rule.route
+		}
+	}
+	return r.defaultRoute
+}
+
+// RoutePayload returns the route of a whole payload. Rules looking at metric
+// names or tags never match a whole payload.
+func (r *Router) RoutePayload(payloadType string) *Route {
+	for _, rule := range r.rules {
+		if !rule.matchesItems() && rule.matchesPayloadType(payloadType) {
+			return rule.route
+		}
+	}
+	return r.defaultRoute
+}
+
+func checkDomains(domains []string, known map[string]struct{}) ([]string, error) {
+	normalized := make([]string, 0, len(domains))
+	for _, d := range domains {
+		n := normalizeDomain(d)
+		if _, found := known[n]; !found {
+			return nil, fmt.Errorf("domain %q is neither dd_url nor one of the additional_endpoints", d)
+		}
+		normalized = append(normalized, n)
+	}
+	return normalized, nil
+}
+
+// normalizeDomain returns the domain as used by the forwarder
+func normalizeDomain(domain string) string {
+	if d, err := pkgconfig.AddAgentVersionToDomain(domain, "app"); err == nil {
+		return d
+	}
+	return domain
+}
+
+// compileGlobs compiles glob patterns where `*` matches any sequence of characters
+func compileGlobs(patterns []string) []*regexp.Regexp {
+	res := make([]*regexp.Regexp, 0, len(patterns))
+	for _, pattern := range patterns {
+		quoted := strings.ReplaceAll(regexp.QuoteMeta(pattern), `\*`, ".*")
+		res = append(res, regexp.MustCompile("^"+quoted+"$"))
+	}
+	return res
+}
+
+func matchesAny(patterns []*regexp.Regexp, s string) bool {
+	for _, pattern := range patterns {
+		if pattern.MatchString(s) {
+			return true
+		}
+	}
+	return false
+}
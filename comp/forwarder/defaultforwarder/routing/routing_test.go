@@ -0,0 +1,136 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package routing
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/tagset"
+)
+
+const (
+	mainDomain     = "https://app.datadoghq.com"
+	paymentsDomain = "https://payments.example.com"
+	otherDomain    = "https://other.example.com"
+)
+
+var mainVersionDomain, _ = config.AddAgentVersionToDomain(mainDomain, "app")
+
+func TestRouteMetric(t *testing.T) {
+	router, err := NewRouter([]RuleConfig{
+		{
+			Name:         "payments",
+			PayloadTypes: []string{PayloadTypeSeries},
+			Tags:         []string{"team:payments"},
+			Domains:      []string{paymentsDomain},
+		},
+		{
+			Name:        "other",
+			MetricNames: []string{"other.*", "custom.other"},
+			Tags:        []string{"env:*"},
+			Domains:     []string{otherDomain, mainDomain},
+		},
+	}, []string{mainDomain, paymentsDomain, otherDomain}, nil)
+	require.NoError(t, err)
+
+	route := router.RouteMetric(PayloadTypeSeries, "foo", tagset.CompositeTagsFromSlice([]string{"a:b", "team:payments"}))
+	assert.Equal(t, "payments", route.Name)
+	assert.Equal(t, []string{paymentsDomain}, route.Domains)
+
+	// the first rule only applies to series
+	route = router.RouteMetric(PayloadTypeSketches, "foo", tagset.CompositeTagsFromSlice([]string{"team:payments"}))
+	assert.Equal(t, "default", route.Name)
+
+	route = router.RouteMetric(PayloadTypeSketches, "other.requests", tagset.NewCompositeTags([]string{"a:b"}, []string{"env:prod/eu"}))
+	assert.Equal(t, "other", route.Name)
+	assert.Equal(t, []string{otherDomain, mainVersionDomain}, route.Domains)
+
+	// every tag pattern must match
+	route = router.RouteMetric(PayloadTypeSeries, "other.requests", tagset.CompositeTagsFromSlice([]string{"a:b"}))
+	assert.Equal(t, "default", route.Name)
+
+	// no domain is left for the default route as they are all rules destinations
+	assert.Empty(t, route.Domains)
+}
+
+func TestRoutePayload(t *testing.T) {
+	router, err := NewRouter([]RuleConfig{
+		{
+			Tags:    []string{"team:payments"},
+			Domains: []string{paymentsDomain},
+		},
+		{
+			PayloadTypes: []string{PayloadTypeMetadata, PayloadTypeEvents},
+			Domains:      []string{mainDomain, paymentsDomain},
+		},
+	}, []string{mainDomain, paymentsDomain}, nil)
+	require.NoError(t, err)
+
+	route := router.RoutePayload(PayloadTypeMetadata)
+	assert.Equal(t, "rule_1", route.Name)
+	assert.Equal(t, []string{mainVersionDomain, paymentsDomain}, route.Domains)
+
+	// rules looking at tags never match whole payloads
+	route = router.RoutePayload(PayloadTypeSeries)
+	assert.Equal(t, "default", route.Name)
+	assert.Empty(t, route.Domains)
+}
+
+func TestDefaultDomains(t *testing.T) {
+	router, err := NewRouter([]RuleConfig{
+		{Tags: []string{"team:payments"}, Domains: []string{paymentsDomain}},
+	}, []string{mainDomain, paymentsDomain, otherDomain}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{mainVersionDomain, otherDomain}, router.DefaultRoute().Domains)
+
+	router, err = NewRouter([]RuleConfig{
+		{Tags: []string{"team:payments"}, Domains: []string{paymentsDomain}},
+	}, []string{mainDomain, paymentsDomain, otherDomain}, []string{mainDomain, paymentsDomain})
+	require.NoError(t, err)
+	assert.Equal(t, []string{mainVersionDomain, paymentsDomain}, router.DefaultRoute().Domains)
+}
+
+func TestNewRouterErrors(t *testing.T) {
+	domains := []string{mainDomain, paymentsDomain}
+
+	_, err := NewRouter([]RuleConfig{{Domains: []string{otherDomain}}}, domains, nil)
+	assert.Error(t, err)
+
+	_, err = NewRouter([]RuleConfig{{Tags: []string{"a:b"}}}, domains, nil)
+	assert.Error(t, err)
+
+	_, err = NewRouter([]RuleConfig{{PayloadTypes: []string{"logs"}, Domains: []string{mainDomain}}}, domains, nil)
+	assert.Error(t, err)
+
+	_, err = NewRouter([]RuleConfig{{Domains: []string{mainDomain}}}, domains, []string{otherDomain})
+	assert.Error(t, err)
+}
+
+func TestFromConfig(t *testing.T) {
+	mockConfig := config.Mock(t)
+
+	router, err := FromConfig(mockConfig, []string{mainDomain, paymentsDomain})
+	require.NoError(t, err)
+	assert.Nil(t, router)
+
+	mockConfig.Set("forwarder_routing_rules", []interface{}{
+		map[string]interface{}{
+			"name":          "payments",
+			"payload_types": []interface{}{"series"},
+			"tags":          []interface{}{"team:payments"},
+			"domains":       []interface{}{paymentsDomain},
+		},
+	})
+	router, err = FromConfig(mockConfig, []string{mainDomain, paymentsDomain})
+	require.NoError(t, err)
+	require.NotNil(t, router)
+	route := router.RouteMetric(PayloadTypeSeries, "foo", tagset.CompositeTagsFromSlice([]string{"team:payments"}))
+	assert.Equal(t, "payments", route.Name)
+}
@@ -8,8 +8,9 @@ package transaction
 // BytesPayload is a payload stored as bytes.
 // It contains metadata about the payload.
 type BytesPayload struct {
-	content    []byte
-	pointCount int
+	content      []byte
+	pointCount   int
+	destinations []string
 }
 
 // NewBytesPayload creates a new instance of BytesPayload.
@@ -40,6 +41,17 @@ func (p *BytesPayload) GetPointCount() int {
 	return p.pointCount
 }
 
+// SetDestinations restricts the domains the payload is sent to.
+func (p *BytesPayload) SetDestinations(domains []string) {
+	p.destinations = domains
+}
+
+// GetDestinations returns the domains the payload is sent to, or nil if the
+// payload is sent to every domain.
+func (p *BytesPayload) GetDestinations() []string {
+	return p.destinations
+}
+
 // BytesPayloads is a collection of BytesPayload
 type BytesPayloads []*BytesPayload
 
@@ -113,6 +113,7 @@ var (
 	aggregatorDogstatsdMetricSample            = expvar.Int{}
 	aggregatorChecksMetricSample               = expvar.Int{}
 	aggregatorCheckHistogramBucketMetricSample = expvar.Int{}
+	aggregatorCheckExponentialHistogramSample  = expvar.Int{}
 	aggregatorServiceCheck                     = expvar.Int{}
 	aggregatorEvent                            = expvar.Int{}
 	aggregatorHostnameUpdate                   = expvar.Int{}
@@ -167,6 +168,7 @@ func init() {
 	aggregatorExpvars.Set("DogstatsdMetricSample", &aggregatorDogstatsdMetricSample)
 	aggregatorExpvars.Set("ChecksMetricSample", &aggregatorChecksMetricSample)
 	aggregatorExpvars.Set("ChecksHistogramBucketMetricSample", &aggregatorCheckHistogramBucketMetricSample)
+	aggregatorExpvars.Set("ChecksExponentialHistogramSample", &aggregatorCheckExponentialHistogramSample)
 	aggregatorExpvars.Set("ServiceCheck", &aggregatorServiceCheck)
 	aggregatorExpvars.Set("Event", &aggregatorEvent)
 	aggregatorExpvars.Set("HostnameUpdate", &aggregatorHostnameUpdate)
@@ -420,6 +422,21 @@ func (agg *BufferedAggregator) handleSenderBucket(checkBucket senderHistogramBuc
 	}
 }
 
+func (agg *BufferedAggregator) handleSenderExponentialHistogram(checkHistogram senderExponentialHistogram) {
+	agg.mu.Lock()
+	defer agg.mu.Unlock()
+
+	aggregatorCheckExponentialHistogramSample.Add(1)
+	tlmProcessed.Inc("exponential_histogram")
+
+	if checkSampler, ok := agg.checkSamplers[checkHistogram.id]; ok {
+		checkHistogram.sample.Tags = util.SortUniqInPlace(checkHistogram.sample.Tags)
+		checkSampler.addExponentialHistogram(checkHistogram.sample)
+	} else {
+		log.Debugf("CheckSampler with ID '%s' doesn't exist, can't handle exponential histogram", checkHistogram.id)
+	}
+}
+
 func (agg *BufferedAggregator) handleEventPlatformEvent(event senderEventPlatformEvent) error {
 	if agg.eventPlatformForwarder == nil {
 		return errors.New("event platform forwarder not initialized")
@@ -25,6 +25,7 @@ type CheckSampler struct {
 	metrics         metrics.CheckMetrics
 	sketchMap       sketchMap
 	lastBucketValue map[ckey.ContextKey]int64
+	lastExpHisto    map[ckey.ContextKey]*metrics.ExponentialHistogram
 	deregistered    bool
 }
 
@@ -37,6 +38,7 @@ func newCheckSampler(expirationCount int, expireMetrics bool, statefulTimeout ti
 		metrics:         metrics.NewCheckMetrics(expireMetrics, statefulTimeout),
 		sketchMap:       make(sketchMap),
 		lastBucketValue: make(map[ckey.ContextKey]int64),
+		lastExpHisto:    make(map[ckey.ContextKey]*metrics.ExponentialHistogram),
 	}
 }
 
@@ -120,6 +122,42 @@ func (cs *CheckSampler) addBucket(bucket *metrics.HistogramBucket) {
 	cs.sketchMap.insertInterp(int64(bucket.Timestamp), contextKey, bucket.LowerBound, bucket.UpperBound, uint(bucket.Value))
 }
 
+func (cs *CheckSampler) addExponentialHistogram(sample *metrics.ExponentialHistogramSample) {
+	contextKey := cs.contextResolver.trackContext(sample)
+	histogram := sample.Histogram
+
+	// if the histogram is monotonic and we have already seen it we only send the delta
+	if sample.Monotonic {
+		lastHistogram, found := cs.lastExpHisto[contextKey]
+		cs.lastExpHisto[contextKey] = histogram
+
+		// Return early so we don't report the first raw value instead of the delta which will cause spikes
+		if !found && !sample.FlushFirstValue {
+			return
+		}
+
+		if found {
+			var ok bool
+			if histogram, ok = histogram.Sub(lastHistogram); !ok {
+				log.Debugf("Exponential histogram %s was reset, discarding", sample.Name)
+				return
+			}
+		}
+	}
+
+	if histogram.Count() == 0 {
+		// noop
+		return
+	}
+
+	sketch, err := histogram.ToSketch()
+	if err != nil {
+		log.Warnf("Invalid exponential histogram for metric %s discarding: %s", sample.Name, err)
+		return
+	}
+	cs.sketchMap.insertSketch(int64(sample.Timestamp), contextKey, sketch)
+}
+
 func (cs *CheckSampler) commitSeries(timestamp float64) {
 	series, errors := cs.metrics.Flush(timestamp)
 	for ckey, err := range errors {
@@ -173,6 +211,7 @@ func (cs *CheckSampler) commit(timestamp float64) {
 	// garbage collect unused buckets
 	for _, ctxKey := range expiredContextKeys {
 		delete(cs.lastBucketValue, ctxKey)
+		delete(cs.lastExpHisto, ctxKey)
 	}
 
 	cs.metrics.Expire(expiredContextKeys, timestamp)
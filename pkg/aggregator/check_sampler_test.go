@@ -374,3 +374,67 @@ func testCheckHistogramBucketInfinityBucket(t *testing.T, store *tags.Store) {
 func TestCheckHistogramBucketInfinityBucket(t *testing.T) {
 	testWithTagsStore(t, testCheckHistogramBucketInfinityBucket)
 }
+
+func testCheckExponentialHistogramSampling(t *testing.T, store *tags.Store) {
+	checkSampler := newCheckSampler(1, true, 1*time.Second, store)
+
+	sample1 := &metrics.ExponentialHistogramSample{
+		Name: "my.histogram",
+		Histogram: &metrics.ExponentialHistogram{
+			Scale:    0,
+			Positive: metrics.ExponentialBuckets{Offset: 3, Counts: []uint64{2}},
+			Sum:      24,
+		},
+		Tags:      []string{"foo", "bar"},
+		Timestamp: 12345.0,
+		Monotonic: true,
+	}
+	checkSampler.addExponentialHistogram(sample1)
+	assert.Equal(t, 1, len(checkSampler.lastExpHisto))
+
+	checkSampler.commit(12349.0)
+	_, flushed := checkSampler.flush()
+	assert.Equal(t, 0, len(flushed))
+
+	sample2 := &metrics.ExponentialHistogramSample{
+		Name: "my.histogram",
+		Histogram: &metrics.ExponentialHistogram{
+			Scale:    0,
+			Positive: metrics.ExponentialBuckets{Offset: 3, Counts: []uint64{4, 1}},
+			Sum:      64,
+		},
+		Tags:      []string{"foo", "bar"},
+		Timestamp: 12400.0,
+		Monotonic: true,
+	}
+	checkSampler.addExponentialHistogram(sample2)
+
+	checkSampler.commit(12401.0)
+	_, flushed = checkSampler.flush()
+
+	// only the delta is flushed: 2 values in (8, 16] and 1 in (16, 32]
+	require.Equal(t, 1, len(flushed))
+	assert.Equal(t, "my.histogram", flushed[0].Name)
+	assert.Equal(t, generateContextKey(sample1), flushed[0].ContextKey)
+	require.Equal(t, 1, len(flushed[0].Points))
+	assert.EqualValues(t, 12400, flushed[0].Points[0].Ts)
+
+	sketch := flushed[0].Points[0].Sketch
+	assert.EqualValues(t, 3, sketch.Basic.Cnt)
+	assert.EqualValues(t, 40, sketch.Basic.Sum)
+	// the values are spread over the sketch bins of their bucket
+	assert.True(t, sketch.Basic.Min > 8 && sketch.Basic.Min <= 16, "unexpected min %f", sketch.Basic.Min)
+	assert.True(t, sketch.Basic.Max > 16 && sketch.Basic.Max <= 32, "unexpected max %f", sketch.Basic.Max)
+	median := sketch.Quantile(quantile.Default(), 0.5)
+	assert.True(t, median > 7.9 && median < 16.2, "unexpected median %f", median)
+
+	// garbage collection
+	time.Sleep(11 * time.Millisecond)
+	checkSampler.flush()
+	checkSampler.commit(12402.0)
+	checkSampler.commit(12403.0)
+	assert.Equal(t, 0, len(checkSampler.lastExpHisto))
+}
+func TestCheckExponentialHistogramSampling(t *testing.T) {
+	testWithTagsStore(t, testCheckExponentialHistogramSampling)
+}
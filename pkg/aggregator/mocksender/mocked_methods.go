@@ -7,6 +7,7 @@ package mocksender
 
 import (
 	"github.com/DataDog/datadog-agent/pkg/collector/check/stats"
+	"github.com/DataDog/datadog-agent/pkg/metrics"
 	"github.com/DataDog/datadog-agent/pkg/metrics/event"
 	"github.com/DataDog/datadog-agent/pkg/metrics/servicecheck"
 	"github.com/DataDog/datadog-agent/pkg/serializer/types"
@@ -82,6 +83,11 @@ func (m *MockSender) HistogramBucket(metric string, value int64, lowerBound, upp
 	m.Called(metric, value, lowerBound, upperBound, monotonic, hostname, tags, flushFirstValue)
 }
 
+// ExponentialHistogram enables the exponential histogram mock call.
+func (m *MockSender) ExponentialHistogram(metric string, histogram *metrics.ExponentialHistogram, monotonic bool, hostname string, tags []string, flushFirstValue bool) {
+	m.Called(metric, histogram, monotonic, hostname, tags, flushFirstValue)
+}
+
 // Commit enables the commit mock call.
 func (m *MockSender) Commit() {
 	m.Called()
@@ -62,6 +62,14 @@ func (m *MockSender) SetupAcceptAll() {
 		mock.AnythingOfType("[]string"), // Tags
 		mock.AnythingOfType("bool"),     // FlushFirstValue
 	).Return()
+	m.On("ExponentialHistogram",
+		mock.AnythingOfType("string"),                        // metric name
+		mock.AnythingOfType("*metrics.ExponentialHistogram"), // histogram
+		mock.AnythingOfType("bool"),                          // monotonic
+		mock.AnythingOfType("string"),                        // hostname
+		mock.AnythingOfType("[]string"),                      // tags
+		mock.AnythingOfType("bool"),                          // FlushFirstValue
+	).Return()
 	m.On("ServiceCheck",
 		mock.AnythingOfType("string"),                          // checkName (e.g: docker.exit)
 		mock.AnythingOfType("servicecheck.ServiceCheckStatus"), // (e.g: servicecheck.ServiceCheckOK)
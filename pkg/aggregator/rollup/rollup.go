@@ -0,0 +1,224 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+// Package rollup implements the rules aggregating DogStatsD metrics across
+// some of their tags before they are flushed.
+package rollup
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/mitchellh/mapstructure"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+	"github.com/DataDog/datadog-agent/pkg/tagset"
+)
+
+// RuleConfig is the configuration of a rollup rule, as found under `metric_rollups`.
+type RuleConfig struct {
+	// MetricNames are patterns, where `*` matches any sequence of characters.
+	// At least one of them must match the metric name.
+	MetricNames []string `mapstructure:"metric_names" json:"metric_names"`
+	// RemoveTags are the keys of the tags removed from the rolled-up series.
+	RemoveTags []string `mapstructure:"remove_tags" json:"remove_tags"`
+	// KeepRaw sends the raw series along with the rolled-up ones.
+	KeepRaw bool `mapstructure:"keep_raw" json:"keep_raw"`
+	// NameSuffix is appended to the name of the rolled-up series. It is
+	// required with KeepRaw, so that the rolled-up series don't count the raw
+	// ones twice.
+	NameSuffix string `mapstructure:"name_suffix" json:"name_suffix"`
+}
+
+// rule removes some tags from the metrics it matches
+type rule struct {
+	metricNames []*regexp.Regexp
+	removeTags  map[string]struct{}
+	keepRaw     bool
+	nameSuffix  string
+}
+
+// Match is the rule matching a metric name
+type Match struct {
+	rule *rule
+	// name is the name of the rolled-up series
+	name string
+}
+
+// KeepRaw returns whether the raw series must be sent along with the rolled-up ones
+func (m *Match) KeepRaw() bool {
+	return m.rule.keepRaw
+}
+
+// RollUp sets c to the context of the rolled-up series of a sample, and returns it
+func (m *Match) RollUp(c *Context, sampleContext metrics.MetricSampleContext) metrics.MetricSampleContext {
+	c.MetricSampleContext = sampleContext
+	c.name = m.name
+	c.taggerBuffer.removeTags = m.rule.removeTags
+	c.metricBuffer.removeTags = m.rule.removeTags
+	return c
+}
+
+// maxCachedNames bounds the number of metric names whose match is cached
+const maxCachedNames = 10000
+
+// Rules are the rollup rules, evaluated in order. Rules cache the rule matching
+// each metric name so they must not be shared between goroutines.
+type Rules struct {
+	rules   []*rule
+	matches map[string]*Match
+}
+
+// NewRules creates Rules from their configuration. It returns nil if there is no rule.
+func NewRules(configs []RuleConfig) (*Rules, error) {
+	if len(configs) == 0 {
+		return nil, nil
+	}
+
+	rules := &Rules{matches: make(map[string]*Match)}
+	for i, c := range configs {
+		if len(c.MetricNames) == 0 {
+			return nil, fmt.Errorf("invalid rollup rule %d: no metric_names", i)
+		}
+		if len(c.RemoveTags) == 0 {
+			return nil, fmt.Errorf("invalid rollup rule %d: no remove_tags", i)
+		}
+		if c.KeepRaw && c.NameSuffix == "" {
+			return nil, fmt.Errorf("invalid rollup rule %d: keep_raw requires a name_suffix", i)
+		}
+
+		r := &rule{
+			metricNames: compileGlobs(c.MetricNames),
+			removeTags:  make(map[string]struct{}, len(c.RemoveTags)),
+			keepRaw:     c.KeepRaw,
+			nameSuffix:  c.NameSuffix,
+		}
+		for _, key := range c.RemoveTags {
+			r.removeTags[key] = struct{}{}
+		}
+		rules.rules = append(rules.rules, r)
+	}
+	return rules, nil
+}
+
+// FromConfig creates Rules from the `metric_rollups` setting. It returns nil
+// if no rollup rule is configured.
+func FromConfig(cfg config.ConfigReader) (*Rules, error) {
+	if !cfg.IsSet("metric_rollups") {
+		return nil, nil
+	}
+
+	var configs []RuleConfig
+	if err := mapstructure.Decode(cfg.Get("metric_rollups"), &configs); err != nil {
+		return nil, fmt.Errorf("could not parse metric_rollups: %v", err)
+	}
+	return NewRules(configs)
+}
+
+// Match returns the first rule matching a metric, or nil if none does. Only
+// the metric types whose samples can be merged are rolled up: gauges, for
+// instance, would keep the last value of an arbitrary series.
+func (r *Rules) Match(name string, mtype metrics.MetricType) *Match {
+	if r == nil || !rollsUp(mtype) {
+		return nil
+	}
+	if m, found := r.matches[name]; found {
+		return m
+	}
+
+	var m *Match
+	if rule := r.match(name); rule != nil {
+		m = &Match{rule: rule, name: name + rule.nameSuffix}
+	}
+	if len(r.matches) >= maxCachedNames {
+		r.matches = make(map[string]*Match)
+	}
+	r.matches[name] = m
+	return m
+}
+
+func (r *Rules) match(name string) *rule {
+	for _, rule := range r.rules {
+		for _, pattern := range rule.metricNames {
+			if pattern.MatchString(name) {
+				return rule
+			}
+		}
+	}
+	return nil
+}
+
+// rollsUp returns whether the samples of a metric type can be merged across series
+func rollsUp(mtype metrics.MetricType) bool {
+	switch mtype {
+	case metrics.CounterType, metrics.CountType, metrics.RateType,
+		metrics.HistogramType, metrics.HistorateType, metrics.DistributionType, metrics.SetType:
+		return true
+	default:
+		return false
+	}
+}
+
+// Context is the context of the rolled-up series of a sample. It is reused
+// across samples so that rolling up a sample doesn't allocate.
+type Context struct {
+	metrics.MetricSampleContext
+	name         string
+	taggerBuffer filteringAccumulator
+	metricBuffer filteringAccumulator
+}
+
+// GetName returns the name of the rolled-up series
+func (c *Context) GetName() string {
+	return c.name
+}
+
+// GetTags appends the tags of the sample, except the removed ones
+func (c *Context) GetTags(taggerBuffer, metricBuffer tagset.TagsAccumulator) {
+	c.taggerBuffer.dst = taggerBuffer
+	c.metricBuffer.dst = metricBuffer
+	c.MetricSampleContext.GetTags(&c.taggerBuffer, &c.metricBuffer)
+	c.taggerBuffer.dst = nil
+	c.metricBuffer.dst = nil
+}
+
+// filteringAccumulator is a tagset.TagsAccumulator dropping the tags with some keys
+type filteringAccumulator struct {
+	dst        tagset.TagsAccumulator
+	removeTags map[string]struct{}
+}
+
+func (f *filteringAccumulator) Append(tags ...string) {
+	for _, tag := range tags {
+		if !f.removed(tag) {
+			f.dst.Append(tag)
+		}
+	}
+}
+
+func (f *filteringAccumulator) AppendHashed(tags tagset.HashedTags) {
+	f.Append(tags.Get()...)
+}
+
+func (f *filteringAccumulator) removed(tag string) bool {
+	key := tag
+	if i := strings.IndexByte(tag, ':'); i >= 0 {
+		key = tag[:i]
+	}
+	_, found := f.removeTags[key]
+	return found
+}
+
+// compileGlobs compiles glob patterns where `*` matches any sequence of characters
+func compileGlobs(patterns []string) []*regexp.Regexp {
+	res := make([]*regexp.Regexp, 0, len(patterns))
+	for _, pattern := range patterns {
+		quoted := strings.ReplaceAll(regexp.QuoteMeta(pattern), `\*`, ".*")
+		res = append(res, regexp.MustCompile("^"+quoted+"$"))
+	}
+	return res
+}
@@ -0,0 +1,92 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package rollup
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+	"github.com/DataDog/datadog-agent/pkg/tagset"
+)
+
+func TestMatch(t *testing.T) {
+	rules, err := NewRules([]RuleConfig{
+		{MetricNames: []string{"app.requests.*"}, RemoveTags: []string{"pod_name"}},
+		{MetricNames: []string{"app.*"}, RemoveTags: []string{"pod_name"}, KeepRaw: true, NameSuffix: ".rollup"},
+	})
+	require.NoError(t, err)
+
+	match := rules.Match("app.requests.count", metrics.CounterType)
+	require.NotNil(t, match)
+	assert.False(t, match.KeepRaw())
+
+	match = rules.Match("app.latency", metrics.DistributionType)
+	require.NotNil(t, match)
+	assert.True(t, match.KeepRaw())
+	// the match is cached
+	assert.Same(t, match, rules.Match("app.latency", metrics.HistogramType))
+
+	assert.Nil(t, rules.Match("other.requests.count", metrics.CounterType))
+
+	// gauges can't be merged across series
+	assert.Nil(t, rules.Match("app.requests.count", metrics.GaugeType))
+
+	var noRules *Rules
+	assert.Nil(t, noRules.Match("app.latency", metrics.CounterType))
+}
+
+func TestRollUp(t *testing.T) {
+	rules, err := NewRules([]RuleConfig{
+		{MetricNames: []string{"app.latency"}, RemoveTags: []string{"pod_name", "bare"}, NameSuffix: ".by_deployment"},
+	})
+	require.NoError(t, err)
+
+	sample := &metrics.MetricSample{
+		Name: "app.latency",
+		Tags: []string{"bare", "bare_not", "kube_deployment:web", "pod_name:web-1", "pod_name_not:x"},
+	}
+	context := rules.Match(sample.Name, metrics.DistributionType).RollUp(&Context{}, sample)
+
+	taggerBuffer := tagset.NewHashingTagsAccumulator()
+	metricBuffer := tagset.NewHashingTagsAccumulator()
+	context.GetTags(taggerBuffer, metricBuffer)
+
+	assert.Equal(t, "app.latency.by_deployment", context.GetName())
+	assert.ElementsMatch(t, []string{"bare_not", "kube_deployment:web", "pod_name_not:x"}, metricBuffer.Get())
+}
+
+func TestNewRulesErrors(t *testing.T) {
+	_, err := NewRules([]RuleConfig{{RemoveTags: []string{"pod_name"}}})
+	assert.Error(t, err)
+
+	_, err = NewRules([]RuleConfig{{MetricNames: []string{"app.*"}}})
+	assert.Error(t, err)
+
+	_, err = NewRules([]RuleConfig{{MetricNames: []string{"app.*"}, RemoveTags: []string{"pod_name"}, KeepRaw: true}})
+	assert.Error(t, err)
+}
+
+func TestFromConfig(t *testing.T) {
+	mockConfig := config.Mock(t)
+
+	rules, err := FromConfig(mockConfig)
+	require.NoError(t, err)
+	assert.Nil(t, rules)
+
+	mockConfig.Set("metric_rollups", []interface{}{
+		map[string]interface{}{
+			"metric_names": []interface{}{"app.*"},
+			"remove_tags":  []interface{}{"pod_name"},
+		},
+	})
+	rules, err = FromConfig(mockConfig)
+	require.NoError(t, err)
+	assert.NotNil(t, rules.Match("app.latency", metrics.CounterType))
+}
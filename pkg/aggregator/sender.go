@@ -71,6 +71,15 @@ func (s *senderHistogramBucket) handle(agg *BufferedAggregator) {
 	agg.handleSenderBucket(*s)
 }
 
+type senderExponentialHistogram struct {
+	id     checkid.ID
+	sample *metrics.ExponentialHistogramSample
+}
+
+func (s *senderExponentialHistogram) handle(agg *BufferedAggregator) {
+	agg.handleSenderExponentialHistogram(*s)
+}
+
 type senderEventPlatformEvent struct {
 	id        checkid.ID
 	rawEvent  []byte
@@ -329,6 +338,41 @@ func (s *checkSender) HistogramBucket(metric string, value int64, lowerBound, up
 	s.statsLock.Unlock()
 }
 
+// ExponentialHistogram should be called to directly send exponential histograms to be submitted as distribution metrics
+func (s *checkSender) ExponentialHistogram(metric string, histogram *metrics.ExponentialHistogram, monotonic bool, hostname string, tags []string, flushFirstValue bool) {
+	tags = append(tags, s.checkTags...)
+
+	log.Tracef(
+		"Exponential Histogram %s submitted: scale %d, count %d, monotonic: %v for host %s tags: %v",
+		metric,
+		histogram.Scale,
+		histogram.Count(),
+		monotonic,
+		hostname,
+		tags,
+	)
+
+	sample := &metrics.ExponentialHistogramSample{
+		Name:            metric,
+		Histogram:       histogram,
+		Monotonic:       monotonic,
+		Host:            hostname,
+		Tags:            tags,
+		Timestamp:       timeNowNano(),
+		FlushFirstValue: flushFirstValue,
+	}
+
+	if hostname == "" && !s.defaultHostnameDisabled {
+		sample.Host = s.defaultHostname
+	}
+
+	s.itemsOut <- &senderExponentialHistogram{s.id, sample}
+
+	s.statsLock.Lock()
+	s.metricStats.ExponentialHistograms++
+	s.statsLock.Unlock()
+}
+
 // Historate should be used to create a histogram metric for "rate" like metrics.
 // Warning this doesn't use the harmonic mean, beware of what it means when using it.
 func (s *checkSender) Historate(metric string, value float64, hostname string, tags []string) {
@@ -600,3 +600,21 @@ func TestCheckSenderHostname(t *testing.T) {
 		})
 	}
 }
+
+func TestCheckSenderExponentialHistogramStats(t *testing.T) {
+	// this test not using anything global
+	// -
+
+	s := initSender(checkID1, "")
+	s.sender.HistogramBucket("my.histogram_bucket", 42, 1.0, 2.0, true, "my-hostname", nil, false)
+	<-s.itemChan
+	histogram := &metrics.ExponentialHistogram{Scale: 1, Positive: metrics.ExponentialBuckets{Counts: []uint64{1, 2}}}
+	s.sender.ExponentialHistogram("my.exponential_histogram", histogram, false, "my-hostname", nil, false)
+	<-s.itemChan
+	s.sender.Commit()
+	<-s.itemChan
+
+	stats := s.sender.GetSenderStats()
+	assert.Equal(t, int64(1), stats.HistogramBuckets)
+	assert.Equal(t, int64(1), stats.ExponentialHistograms)
+}
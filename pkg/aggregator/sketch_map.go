@@ -13,6 +13,8 @@ import (
 	"github.com/DataDog/opentelemetry-mapping-go/pkg/quantile"
 )
 
+var sketchConfig = quantile.Default()
+
 type sketchMap map[int64]map[ckey.ContextKey]*quantile.Agent
 
 // Len returns the number of sketches stored
@@ -48,6 +50,11 @@ func (m sketchMap) insertInterp(ts int64, ck ckey.ContextKey, lower float64, upp
 	return true
 }
 
+// insertSketch merges s into the sketch for the given (ts, contextKey)
+func (m sketchMap) insertSketch(ts int64, ck ckey.ContextKey, s *quantile.Sketch) {
+	m.getOrCreate(ts, ck).Sketch.Merge(sketchConfig, s)
+}
+
 func (m sketchMap) getOrCreate(ts int64, ck ckey.ContextKey) *quantile.Agent {
 	// level 1: ts -> ctx
 	byCtx, ok := m[ts]
@@ -12,6 +12,7 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/aggregator/internal/limiter"
 	"github.com/DataDog/datadog-agent/pkg/aggregator/internal/tags"
 	"github.com/DataDog/datadog-agent/pkg/aggregator/internal/tags_limiter"
+	"github.com/DataDog/datadog-agent/pkg/aggregator/rollup"
 	"github.com/DataDog/datadog-agent/pkg/config"
 	"github.com/DataDog/datadog-agent/pkg/metrics"
 	"github.com/DataDog/datadog-agent/pkg/util/log"
@@ -36,6 +37,12 @@ type TimeSampler struct {
 	lastCutOffTime              int64
 	sketchMap                   sketchMap
 
+	// rollups aggregate some metrics across some of their tags. It is nil when
+	// no rollup rule is configured.
+	rollups *rollup.Rules
+	// rollupContext is the context of the rolled-up series, reused across samples
+	rollupContext rollup.Context
+
 	// id is a number to differentiate multiple time samplers
 	// since we start running more than one with the demultiplexer introduction
 	id TimeSamplerID
@@ -51,6 +58,11 @@ func NewTimeSampler(id TimeSamplerID, interval int64, cache *tags.Store, context
 
 	log.Infof("Creating TimeSampler #%d", id)
 
+	rollups, err := rollup.FromConfig(config.Datadog)
+	if err != nil {
+		log.Errorf("Metric rollups are disabled: %v", err)
+	}
+
 	s := &TimeSampler{
 		interval:                    interval,
 		contextResolver:             newTimestampContextResolver(cache, contextsLimiter, tagsLimiter),
@@ -59,6 +71,7 @@ func NewTimeSampler(id TimeSamplerID, interval int64, cache *tags.Store, context
 		sketchMap:                   make(sketchMap),
 		id:                          id,
 		hostname:                    hostname,
+		rollups:                     rollups,
 	}
 
 	return s
@@ -78,8 +91,20 @@ func (s *TimeSampler) sample(metricSample *metrics.MetricSample, timestamp float
 		timestamp = metricSample.Timestamp
 	}
 
+	match := s.rollups.Match(metricSample.Name, metricSample.Mtype)
+	if match == nil || match.KeepRaw() {
+		s.sampleContext(metricSample, metricSample, timestamp)
+	}
+	if match != nil {
+		s.sampleContext(match.RollUp(&s.rollupContext, metricSample), metricSample, timestamp)
+	}
+}
+
+// sampleContext adds the sample to the series of the given context, which is
+// either the sample context or its rolled-up context.
+func (s *TimeSampler) sampleContext(sampleContext metrics.MetricSampleContext, metricSample *metrics.MetricSample, timestamp float64) {
 	// Keep track of the context
-	contextKey, ok := s.contextResolver.trackContext(metricSample, timestamp)
+	contextKey, ok := s.contextResolver.trackContext(sampleContext, timestamp)
 	if !ok {
 		return
 	}
@@ -88,7 +113,11 @@ func (s *TimeSampler) sample(metricSample *metrics.MetricSample, timestamp float
 
 	switch metricSample.Mtype {
 	case metrics.DistributionType:
-		s.sketchMap.insert(bucketStart, contextKey, metricSample.Value, metricSample.SampleRate)
+		if metricSample.ExponentialHistogram != nil {
+			s.sampleExponentialHistogram(bucketStart, contextKey, metricSample)
+		} else {
+			s.sketchMap.insert(bucketStart, contextKey, metricSample.Value, metricSample.SampleRate)
+		}
 	default:
 		// If it's a new bucket, initialize it
 		bucketMetrics, ok := s.metricsByTimestamp[bucketStart]
@@ -107,6 +136,16 @@ func (s *TimeSampler) sample(metricSample *metrics.MetricSample, timestamp float
 		}
 	}
 }
+
+func (s *TimeSampler) sampleExponentialHistogram(bucketStart int64, contextKey ckey.ContextKey, metricSample *metrics.MetricSample) {
+	sketch, err := metricSample.ExponentialHistogram.ToSketch()
+	if err != nil {
+		log.Debugf("TimeSampler #%d Ignoring exponential histogram '%s' on host '%s' and tags '%s': %s", s.id, metricSample.Name, metricSample.Host, metricSample.Tags, err)
+		return
+	}
+	s.sketchMap.insertSketch(bucketStart, contextKey, sketch)
+}
+
 func (s *TimeSampler) newSketchSeries(ck ckey.ContextKey, points []metrics.SketchPoint) *metrics.SketchSeries {
 	ctx, _ := s.contextResolver.get(ck)
 	ss := &metrics.SketchSeries{
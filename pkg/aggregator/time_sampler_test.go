@@ -20,6 +20,7 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/aggregator/internal/limiter"
 	"github.com/DataDog/datadog-agent/pkg/aggregator/internal/tags"
 	"github.com/DataDog/datadog-agent/pkg/aggregator/internal/tags_limiter"
+	"github.com/DataDog/datadog-agent/pkg/config"
 	"github.com/DataDog/datadog-agent/pkg/metrics"
 	"github.com/DataDog/datadog-agent/pkg/tagset"
 	"github.com/DataDog/opentelemetry-mapping-go/pkg/quantile"
@@ -403,6 +404,49 @@ func TestSketchBucketSampling(t *testing.T) {
 	testWithTagsStore(t, testSketchBucketSampling)
 }
 
+func testSketchExponentialHistogramSampling(t *testing.T, store *tags.Store) {
+	sampler := testTimeSampler()
+
+	mSample1 := metrics.MetricSample{
+		Name:       "test.metric.name",
+		Value:      1,
+		Mtype:      metrics.DistributionType,
+		Tags:       []string{"a", "b"},
+		SampleRate: 1,
+	}
+	mSample2 := metrics.MetricSample{
+		Name:  "test.metric.name",
+		Mtype: metrics.DistributionType,
+		Tags:  []string{"a", "b"},
+		ExponentialHistogram: &metrics.ExponentialHistogram{
+			Scale:     0,
+			ZeroCount: 1,
+			Positive:  metrics.ExponentialBuckets{Offset: 5, Counts: []uint64{2}},
+			Sum:       100,
+		},
+	}
+	sampler.sample(&mSample1, 10001)
+	sampler.sample(&mSample2, 10002)
+
+	_, flushed := flushSerie(sampler, 10020.0)
+
+	require.Equal(t, 1, len(flushed))
+	assert.Equal(t, generateContextKey(&mSample1), flushed[0].ContextKey)
+	require.Equal(t, 1, len(flushed[0].Points))
+
+	sketch := flushed[0].Points[0].Sketch
+	assert.EqualValues(t, 10000, flushed[0].Points[0].Ts)
+	assert.EqualValues(t, 4, sketch.Basic.Cnt)
+	assert.EqualValues(t, 101, sketch.Basic.Sum)
+	assert.EqualValues(t, 0, sketch.Basic.Min)
+	// 2 values in (32, 64], spread over the sketch bins of the bucket
+	assert.Greater(t, sketch.Basic.Max, 32.0)
+	assert.LessOrEqual(t, sketch.Basic.Max, 64.0)
+}
+func TestSketchExponentialHistogramSampling(t *testing.T) {
+	testWithTagsStore(t, testSketchExponentialHistogramSampling)
+}
+
 func testSketchContextSampling(t *testing.T, store *tags.Store) {
 	sampler := testTimeSampler()
 
@@ -567,6 +611,87 @@ func BenchmarkTimeSamplerWithLimiter(b *testing.B) {
 	}
 }
 
+func TestRollupSampling(t *testing.T) {
+	config.Datadog.Set("metric_rollups", []map[string]interface{}{
+		{"metric_names": []string{"my.counter"}, "remove_tags": []string{"pod_name"}},
+		{"metric_names": []string{"my.requests*"}, "remove_tags": []string{"pod_name", "env"}, "keep_raw": true, "name_suffix": ".rollup"},
+		{"metric_names": []string{"my.gauge"}, "remove_tags": []string{"pod_name"}},
+	})
+	defer config.Datadog.Set("metric_rollups", nil)
+
+	sampler := testTimeSampler()
+	for _, pod := range []string{"a", "b", "c"} {
+		sampler.sample(&metrics.MetricSample{
+			Name:       "my.counter",
+			Value:      1,
+			Mtype:      metrics.CounterType,
+			Tags:       []string{"kube_deployment:web", "pod_name:" + pod},
+			SampleRate: 1,
+		}, 12345.0)
+	}
+	sampler.sample(&metrics.MetricSample{
+		Name:       "my.requests",
+		Value:      2,
+		Mtype:      metrics.CounterType,
+		Tags:       []string{"env:prod", "pod_name:a", "version:1"},
+		SampleRate: 1,
+	}, 12345.0)
+	// gauges are never rolled up
+	for _, pod := range []string{"a", "b"} {
+		sampler.sample(&metrics.MetricSample{
+			Name:       "my.gauge",
+			Value:      3,
+			Mtype:      metrics.GaugeType,
+			Tags:       []string{"pod_name:" + pod},
+			SampleRate: 1,
+		}, 12345.0)
+	}
+
+	series, _ := flushSerie(sampler, 12360.0)
+
+	expectedSeries := metrics.Series{
+		{
+			Name:     "my.counter",
+			Points:   []metrics.Point{{Ts: 12340.0, Value: .3}},
+			Tags:     tagset.CompositeTagsFromSlice([]string{"kube_deployment:web"}),
+			MType:    metrics.APIRateType,
+			Interval: 10,
+		},
+		{
+			Name:     "my.requests",
+			Points:   []metrics.Point{{Ts: 12340.0, Value: .2}},
+			Tags:     tagset.CompositeTagsFromSlice([]string{"env:prod", "pod_name:a", "version:1"}),
+			MType:    metrics.APIRateType,
+			Interval: 10,
+		},
+		{
+			Name:     "my.requests.rollup",
+			Points:   []metrics.Point{{Ts: 12340.0, Value: .2}},
+			Tags:     tagset.CompositeTagsFromSlice([]string{"version:1"}),
+			MType:    metrics.APIRateType,
+			Interval: 10,
+		},
+		{
+			Name:     "my.gauge",
+			Points:   []metrics.Point{{Ts: 12340.0, Value: 3}},
+			Tags:     tagset.CompositeTagsFromSlice([]string{"pod_name:a"}),
+			MType:    metrics.APIGaugeType,
+			Interval: 10,
+		},
+		{
+			Name:     "my.gauge",
+			Points:   []metrics.Point{{Ts: 12340.0, Value: 3}},
+			Tags:     tagset.CompositeTagsFromSlice([]string{"pod_name:b"}),
+			MType:    metrics.APIGaugeType,
+			Interval: 10,
+		},
+	}
+	for _, serie := range expectedSeries {
+		serie.ContextKey = generateSerieContextKey(serie)
+	}
+	metrics.AssertSeriesEqual(t, expectedSeries, series)
+}
+
 func flushSerie(sampler *TimeSampler, timestamp float64) (metrics.Series, metrics.SketchSeriesList) {
 	var series metrics.Series
 	var sketches metrics.SketchSeriesList
@@ -196,16 +196,6 @@ func (ac *AutoConfig) LoadAndRun(ctx context.Context) {
 		} else {
 			log.Infof("Started config provider %q", cp.provider.String())
 		}
-
-		// TODO: this probably belongs somewhere inside the file config
-		// provider itself, but since it already lived in AD it's been
-		// moved here for the moment.
-		if fileConfPd, ok := cp.provider.(*providers.FileConfigProvider); ok {
-			// Grab any errors that occurred when reading the YAML file
-			for name, e := range fileConfPd.Errors {
-				errorStats.setConfigError(name, e)
-			}
-		}
 	}
 
 	ac.ranOnce.Store(true)
@@ -196,6 +196,15 @@ func (cp *configPoller) collectOnce(ctx context.Context, provider providers.Coll
 		ac.applyChanges(changes)
 	}
 
+	// TODO: this probably belongs somewhere inside the file config
+	// provider itself, but since it already lived in AD it's been
+	// moved here for the moment.
+	if fileConfPd, ok := cp.provider.(*providers.FileConfigProvider); ok {
+		// Grab any errors that occurred when reading the YAML files. They
+		// are all read again after a change, so the errors of the files
+		// that were fixed or removed are dropped.
+		errorStats.replaceConfigErrors(fileConfPd.Errors)
+	}
 }
 
 // collect is just a convenient wrapper to fetch configurations from a provider and
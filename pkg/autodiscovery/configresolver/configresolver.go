@@ -30,13 +30,33 @@ import (
 type variableGetter func(ctx context.Context, key string, svc listeners.Service) (string, error)
 
 var templateVariables = map[string]variableGetter{
-	"host":     getHost,
-	"pid":      getPid,
-	"port":     getPort,
-	"hostname": getHostname,
-	"env":      getEnvvar,
-	"extra":    getAdditionalTplVariables,
-	"kube":     getAdditionalTplVariables,
+	"host":      getHost,
+	"pid":       getPid,
+	"port":      getPort,
+	"hostname":  getHostname,
+	"env":       getEnvvar,
+	"extra":     getAdditionalTplVariables,
+	"kube":      getAdditionalTplVariables,
+	"container": getContainerTplVariables,
+}
+
+// kubeTplVariables lists the keys supported by the %%kube_*%% template variable,
+// on top of the pod_label_* and pod_annotation_* ones
+var kubeTplVariables = map[string]struct{}{
+	"namespace":  {},
+	"pod_name":   {},
+	"pod_uid":    {},
+	"node_name":  {},
+	"owner_kind": {},
+	"owner_name": {},
+}
+
+// containerTplVariables lists the keys supported by the %%container_*%% template variable
+var containerTplVariables = map[string]struct{}{
+	"name":             {},
+	"image_name":       {},
+	"image_short_name": {},
+	"image_tag":        {},
 }
 
 type NoServiceError struct {
@@ -106,6 +126,59 @@ func Resolve(tpl integration.Config, svc listeners.Service) (integration.Config,
 	return resolvedConfig, nil
 }
 
+// ValidateTemplateVariables checks the template variables used in the init,
+// instances and logs config of a template without resolving them, and returns
+// an error for each unknown variable or malformed key.
+func ValidateTemplateVariables(config integration.Config) []error {
+	var errs []error
+
+	for _, toValidate := range listDataToResolve(&config) {
+		data := strings.ReplaceAll(string(*toValidate.data), "%%", "‰")
+		for _, match := range varPattern.FindAllStringSubmatch(data, -1) {
+			if err := validateTemplateVariable(match[1], match[2]); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+
+	return errs
+}
+
+func validateTemplateVariable(name, key string) error {
+	tag := name
+	if key != "" {
+		tag = name + "_" + key
+	}
+
+	if _, found := templateVariables[name]; !found {
+		return fmt.Errorf("invalid %%%%%s%%%% tag", tag)
+	}
+
+	switch name {
+	case "env":
+		if key == "" {
+			return fmt.Errorf("invalid %%%%%s%%%% tag: envvar name is missing", tag)
+		}
+	case "extra":
+		if key == "" {
+			return fmt.Errorf("invalid %%%%%s%%%% tag: key is missing", tag)
+		}
+	case "kube":
+		if key == "" || key == "pod_label_" || key == "pod_annotation_" {
+			return fmt.Errorf("invalid %%%%%s%%%% tag: key is missing", tag)
+		}
+		if _, found := kubeTplVariables[key]; !found && !strings.HasPrefix(key, "pod_label_") && !strings.HasPrefix(key, "pod_annotation_") {
+			return fmt.Errorf("invalid %%%%%s%%%% tag: unsupported kube variable %q", tag, key)
+		}
+	case "container":
+		if _, found := containerTplVariables[key]; !found {
+			return fmt.Errorf("invalid %%%%%s%%%% tag: unsupported container variable %q", tag, key)
+		}
+	}
+
+	return nil
+}
+
 // substituteTemplateVariables replaces %%VARIABLES%% in the config init,
 // instances, and logs config.
 // When there is an error, it stops processing.
@@ -603,6 +676,20 @@ func getAdditionalTplVariables(_ context.Context, tplVar string, svc listeners.S
 	return value, nil
 }
 
+// getContainerTplVariables returns the template variables describing the
+// container of the service, prefixed with container_
+func getContainerTplVariables(_ context.Context, tplVar string, svc listeners.Service) (string, error) {
+	if svc == nil {
+		return "", NewNoServiceError("No service. %%%%container_*%%%% is not allowed")
+	}
+
+	value, err := svc.GetExtraConfig("container_" + tplVar)
+	if err != nil {
+		return "", fmt.Errorf("failed to get container info for service %s, skipping config - %s", svc.GetServiceID(), err)
+	}
+	return value, nil
+}
+
 // getEnvvar returns a system environment variable if found
 func getEnvvar(_ context.Context, envVar string, svc listeners.Service) (string, error) {
 	if len(envVar) == 0 {
@@ -16,6 +16,7 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/autodiscovery/providers/names"
 	"github.com/DataDog/datadog-agent/pkg/util/containers"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	// we need some valid check in the catalog to run tests
 	_ "github.com/DataDog/datadog-agent/pkg/collector/corechecks/system"
@@ -606,6 +607,31 @@ func TestResolve(t *testing.T) {
 				ServiceID:     "a5901276aed1",
 			},
 		},
+		{
+			testName: "pod metadata and container template variables",
+			svc: &dummyService{
+				ID:            "a5901276aed1",
+				ADIdentifiers: []string{"redis"},
+				ExtraConfig: map[string]string{
+					"pod_label_app":              "redis",
+					"pod_annotation_team":        "storage",
+					"owner_name":                 "redis-cache",
+					"container_image_tag":        "7.0.5",
+					"container_image_short_name": "redis",
+				},
+			},
+			tpl: integration.Config{
+				Name:          "redis",
+				ADIdentifiers: []string{"redis"},
+				Instances:     []integration.Data{integration.Data("app: %%kube_pod_label_app%%\nteam: %%kube_pod_annotation_team%%\nowner: %%kube_owner_name%%\nimage: %%container_image_short_name%%:%%container_image_tag%%")},
+			},
+			out: integration.Config{
+				Name:          "redis",
+				ADIdentifiers: []string{"redis"},
+				Instances:     []integration.Data{integration.Data("app: redis\nimage: redis:7.0.5\nowner: redis-cache\ntags:\n- foo:bar\nteam: storage\n")},
+				ServiceID:     "a5901276aed1",
+			},
+		},
 		{
 			testName: "IPv6 %%host%%",
 			svc: &dummyService{
@@ -781,6 +807,26 @@ func newFakeContainerPorts() []listeners.ContainerPort {
 	}
 }
 
+func TestValidateTemplateVariables(t *testing.T) {
+	tpl := integration.Config{
+		Name:          "redis",
+		ADIdentifiers: []string{"redis"},
+		InitConfig:    integration.Data("password: %%env%%"),
+		Instances: []integration.Data{
+			integration.Data("host: %%host%%\nport: %%port_6379%%\napp: %%kube_pod_label_app%%\nimage: %%container_image_tag%%\nnode: %%kube_node_name%%"),
+			integration.Data("app: %%kube_pod_label_%%\nimage: %%container_image_digest%%\nuser: %%foo_bar%%\nnode: %%kube_node%%"),
+		},
+	}
+
+	errs := ValidateTemplateVariables(tpl)
+	require.Len(t, errs, 5)
+	assert.EqualError(t, errs[0], "invalid %%env%% tag: envvar name is missing")
+	assert.EqualError(t, errs[1], "invalid %%kube_pod_label_%% tag: key is missing")
+	assert.EqualError(t, errs[2], `invalid %%container_image_digest%% tag: unsupported container variable "image_digest"`)
+	assert.EqualError(t, errs[3], "invalid %%foo_bar%% tag")
+	assert.EqualError(t, errs[4], `invalid %%kube_node%% tag: unsupported kube variable "node"`)
+}
+
 func BenchmarkResolve(b *testing.B) {
 	// Prepare envvars for test
 	b.Setenv("test_envvar_key", "test_value")
@@ -150,6 +150,7 @@ func (l *KubeletListener) createContainerService(
 	entity := containers.BuildEntityName(string(container.Runtime), container.ID)
 	svc := &service{
 		entity: container,
+		pod:    pod,
 		ready:  pod.Ready,
 		ports:  ports,
 		extraConfig: map[string]string{
@@ -240,6 +240,7 @@ func TestKubeletCreateContainerService(t *testing.T) {
 					parent: "kubernetes_pod://foobar",
 					service: &service{
 						entity: basicContainer,
+						pod:    pod,
 						adIdentifiers: []string{
 							"docker://foobarquux",
 							"gcr.io/foobar:latest",
@@ -272,6 +273,7 @@ func TestKubeletCreateContainerService(t *testing.T) {
 					parent: "kubernetes_pod://foobar",
 					service: &service{
 						entity: recentlyStoppedContainer,
+						pod:    pod,
 						adIdentifiers: []string{
 							"docker://foobarquux",
 							"foobar",
@@ -327,6 +329,7 @@ func TestKubeletCreateContainerService(t *testing.T) {
 					parent: "kubernetes_pod://foobar",
 					service: &service{
 						entity: runningContainerWithFinishedAtTime,
+						pod:    pod,
 						adIdentifiers: []string{
 							"docker://foobarquux",
 							"foobar",
@@ -358,6 +361,7 @@ func TestKubeletCreateContainerService(t *testing.T) {
 					parent: "kubernetes_pod://foobar",
 					service: &service{
 						entity: multiplePortsContainer,
+						pod:    pod,
 						adIdentifiers: []string{
 							"docker://foobarquux",
 							"foobar",
@@ -398,6 +402,7 @@ func TestKubeletCreateContainerService(t *testing.T) {
 					parent: "kubernetes_pod://foobar",
 					service: &service{
 						entity: customIDsContainer,
+						pod:    podWithAnnotations,
 						adIdentifiers: []string{
 							"customid",
 							"docker://foobarquux",
@@ -442,6 +447,7 @@ func TestKubeletCreateContainerService(t *testing.T) {
 					parent: "kubernetes_pod://foobar",
 					service: &service{
 						entity: customIDsContainer,
+						pod:    podWithMetricsExcludeAnnotation,
 						adIdentifiers: []string{
 							"customid",
 							"docker://foobarquux",
@@ -476,6 +482,7 @@ func TestKubeletCreateContainerService(t *testing.T) {
 					parent: "kubernetes_pod://foobar",
 					service: &service{
 						entity: customIDsContainer,
+						pod:    podWithLogsExcludeAnnotation,
 						adIdentifiers: []string{
 							"customid",
 							"docker://foobarquux",
@@ -9,12 +9,14 @@ import (
 	"context"
 	"fmt"
 	"reflect"
+	"strings"
 
 	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
 	"github.com/DataDog/datadog-agent/pkg/autodiscovery/providers/names"
 	"github.com/DataDog/datadog-agent/pkg/config"
 	"github.com/DataDog/datadog-agent/pkg/tagger"
 	"github.com/DataDog/datadog-agent/pkg/util/containers"
+	"github.com/DataDog/datadog-agent/pkg/util/kubernetes"
 	"github.com/DataDog/datadog-agent/pkg/util/kubernetes/kubelet"
 	"github.com/DataDog/datadog-agent/pkg/util/log"
 	"github.com/DataDog/datadog-agent/pkg/workloadmeta"
@@ -24,6 +26,7 @@ import (
 // workloadmeta.Store.
 type service struct {
 	entity          workloadmeta.Entity
+	pod             *workloadmeta.KubernetesPod // pod of the container entities running in Kubernetes
 	adIdentifiers   []string
 	hosts           map[string]string
 	ports           []ContainerPort
@@ -193,14 +196,112 @@ func (s *service) filterTemplatesContainerCollectAll(configs map[string]integrat
 	}
 }
 
-// GetExtraConfig returns extra configuration associated with the service.
+// GetExtraConfig returns extra configuration associated with the service, or
+// resolved from the workloadmeta entities of the service.
 func (s *service) GetExtraConfig(key string) (string, error) {
-	result, found := s.extraConfig[key]
-	if !found {
-		return "", fmt.Errorf("extra config %q is not supported", key)
+	if result, found := s.extraConfig[key]; found {
+		return result, nil
 	}
 
-	return result, nil
+	switch {
+	case strings.HasPrefix(key, "pod_label_"), strings.HasPrefix(key, "pod_annotation_"),
+		key == "node_name", key == "owner_kind", key == "owner_name":
+		return s.getPodConfig(key)
+	case strings.HasPrefix(key, "container_"):
+		return s.getContainerConfig(key)
+	}
+
+	return "", fmt.Errorf("extra config %q is not supported", key)
+}
+
+// kubePod returns the pod of the service: the entity itself for pod services,
+// the pod of the container for container services running in Kubernetes.
+func (s *service) kubePod() *workloadmeta.KubernetesPod {
+	if pod, ok := s.entity.(*workloadmeta.KubernetesPod); ok {
+		return pod
+	}
+	return s.pod
+}
+
+// getPodConfig resolves the extra config keys describing the pod of the service
+func (s *service) getPodConfig(key string) (string, error) {
+	pod := s.kubePod()
+	if pod == nil {
+		return "", fmt.Errorf("extra config %q is only supported for Kubernetes pods and their containers", key)
+	}
+
+	if label, found := cutPrefix(key, "pod_label_"); found {
+		if value, found := pod.Labels[label]; found {
+			return value, nil
+		}
+		return "", fmt.Errorf("label %q not found on pod %s/%s", label, pod.Namespace, pod.Name)
+	}
+	if annotation, found := cutPrefix(key, "pod_annotation_"); found {
+		if value, found := pod.Annotations[annotation]; found {
+			return value, nil
+		}
+		return "", fmt.Errorf("annotation %q not found on pod %s/%s", annotation, pod.Namespace, pod.Name)
+	}
+	if key == "node_name" {
+		if pod.NodeName == "" {
+			return "", fmt.Errorf("pod %s/%s is not scheduled on a node", pod.Namespace, pod.Name)
+		}
+		return pod.NodeName, nil
+	}
+
+	if len(pod.Owners) == 0 {
+		return "", fmt.Errorf("pod %s/%s has no owner", pod.Namespace, pod.Name)
+	}
+	kind, name := podOwner(pod.Owners[0])
+	if key == "owner_kind" {
+		return kind, nil
+	}
+	return name, nil
+}
+
+// podOwner returns the kind and the name of the workload owning a pod: the
+// deployments and the cronjobs are returned instead of the replicasets and the
+// jobs they own.
+func podOwner(owner workloadmeta.KubernetesPodOwner) (string, string) {
+	switch owner.Kind {
+	case kubernetes.ReplicaSetKind:
+		if deployment := kubernetes.ParseDeploymentForReplicaSet(owner.Name); deployment != "" {
+			return kubernetes.DeploymentKind, deployment
+		}
+	case kubernetes.JobKind:
+		if cronJob, _ := kubernetes.ParseCronJobForJob(owner.Name); cronJob != "" {
+			return kubernetes.CronJobKind, cronJob
+		}
+	}
+	return owner.Kind, owner.Name
+}
+
+// getContainerConfig resolves the extra config keys describing the container of the service
+func (s *service) getContainerConfig(key string) (string, error) {
+	container, ok := s.entity.(*workloadmeta.Container)
+	if !ok {
+		return "", fmt.Errorf("extra config %q is only supported for containers", key)
+	}
+
+	switch key {
+	case "container_name":
+		return container.Name, nil
+	case "container_image_name":
+		return container.Image.Name, nil
+	case "container_image_short_name":
+		return container.Image.ShortName, nil
+	case "container_image_tag":
+		return container.Image.Tag, nil
+	}
+
+	return "", fmt.Errorf("extra config %q is not supported", key)
+}
+
+func cutPrefix(s, prefix string) (string, bool) {
+	if !strings.HasPrefix(s, prefix) {
+		return s, false
+	}
+	return s[len(prefix):], true
 }
 
 // svcEqual checks that two Services are equal to each other by doing a deep
@@ -254,5 +355,30 @@ func svcEqual(a, b Service) bool {
 		return false
 	}
 
+	// The %%kube_*%% template variables are resolved from the pod of the
+	// service, so the service must be re-created when its pod metadata change.
+	if !podMetadataEqual(a, b) {
+		return false
+	}
+
 	return a.IsReady(ctx) == b.IsReady(ctx)
 }
+
+// podMetadataEqual checks that the pods of two Services have the same metadata
+func podMetadataEqual(a, b Service) bool {
+	svcA, okA := a.(*service)
+	svcB, okB := b.(*service)
+	if !okA || !okB {
+		return okA == okB
+	}
+
+	podA, podB := svcA.kubePod(), svcB.kubePod()
+	if podA == nil || podB == nil {
+		return podA == podB
+	}
+
+	return reflect.DeepEqual(podA.Labels, podB.Labels) &&
+		reflect.DeepEqual(podA.Annotations, podB.Annotations) &&
+		reflect.DeepEqual(podA.Owners, podB.Owners) &&
+		podA.NodeName == podB.NodeName
+}
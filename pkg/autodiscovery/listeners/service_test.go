@@ -150,3 +150,138 @@ func TestServiceFilterTemplatesCCA(t *testing.T) {
 			filterDrops(&service{}, noLogsTpl, logsTpl, ccaTpl))
 	})
 }
+
+func TestServiceGetExtraConfig(t *testing.T) {
+	pod := &workloadmeta.KubernetesPod{
+		EntityID: workloadmeta.EntityID{Kind: workloadmeta.KindKubernetesPod, ID: "pod-uid"},
+		EntityMeta: workloadmeta.EntityMeta{
+			Name:        "web-5d8f9c7b6-x2x4z",
+			Namespace:   "default",
+			Labels:      map[string]string{"app": "web"},
+			Annotations: map[string]string{"team": "frontend"},
+		},
+		Owners:   []workloadmeta.KubernetesPodOwner{{Kind: "ReplicaSet", Name: "web-5d8f9c7b6"}},
+		NodeName: "node-1",
+	}
+	container := &workloadmeta.Container{
+		EntityID:   workloadmeta.EntityID{Kind: workloadmeta.KindContainer, ID: "container-id"},
+		EntityMeta: workloadmeta.EntityMeta{Name: "nginx"},
+		Image: workloadmeta.ContainerImage{
+			Name:      "docker.io/library/nginx",
+			ShortName: "nginx",
+			Tag:       "1.23",
+		},
+	}
+
+	tests := []struct {
+		name        string
+		svc         *service
+		key         string
+		expected    string
+		expectedErr string
+	}{
+		{
+			name:     "extra config",
+			svc:      &service{entity: container, extraConfig: map[string]string{"namespace": "default"}},
+			key:      "namespace",
+			expected: "default",
+		},
+		{
+			name:     "pod label of a pod",
+			svc:      &service{entity: pod},
+			key:      "pod_label_app",
+			expected: "web",
+		},
+		{
+			name:     "pod annotation of a container",
+			svc:      &service{entity: container, pod: pod},
+			key:      "pod_annotation_team",
+			expected: "frontend",
+		},
+		{
+			name:        "missing pod label",
+			svc:         &service{entity: container, pod: pod},
+			key:         "pod_label_tier",
+			expectedErr: `label "tier" not found on pod default/web-5d8f9c7b6-x2x4z`,
+		},
+		{
+			name:        "pod label outside of kubernetes",
+			svc:         &service{entity: container},
+			key:         "pod_label_app",
+			expectedErr: `extra config "pod_label_app" is only supported for Kubernetes pods and their containers`,
+		},
+		{
+			name:     "node name",
+			svc:      &service{entity: container, pod: pod},
+			key:      "node_name",
+			expected: "node-1",
+		},
+		{
+			name:        "node name of an unscheduled pod",
+			svc:         &service{entity: &workloadmeta.KubernetesPod{EntityMeta: workloadmeta.EntityMeta{Name: "web", Namespace: "default"}}},
+			key:         "node_name",
+			expectedErr: "pod default/web is not scheduled on a node",
+		},
+		{
+			name:     "owner kind",
+			svc:      &service{entity: container, pod: pod},
+			key:      "owner_kind",
+			expected: "Deployment",
+		},
+		{
+			name:     "owner name",
+			svc:      &service{entity: container, pod: pod},
+			key:      "owner_name",
+			expected: "web",
+		},
+		{
+			name:     "container image tag",
+			svc:      &service{entity: container, pod: pod},
+			key:      "container_image_tag",
+			expected: "1.23",
+		},
+		{
+			name:        "container image tag of a pod",
+			svc:         &service{entity: pod},
+			key:         "container_image_tag",
+			expectedErr: `extra config "container_image_tag" is only supported for containers`,
+		},
+		{
+			name:        "unknown key",
+			svc:         &service{entity: container},
+			key:         "foo",
+			expectedErr: `extra config "foo" is not supported`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			value, err := tt.svc.GetExtraConfig(tt.key)
+			if tt.expectedErr != "" {
+				assert.EqualError(t, err, tt.expectedErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, value)
+		})
+	}
+}
+
+func TestSvcEqualPodMetadata(t *testing.T) {
+	pod := func(labels map[string]string) *workloadmeta.KubernetesPod {
+		return &workloadmeta.KubernetesPod{
+			EntityID:   workloadmeta.EntityID{Kind: workloadmeta.KindKubernetesPod, ID: "pod-uid"},
+			EntityMeta: workloadmeta.EntityMeta{Name: "web", Namespace: "default", Labels: labels},
+		}
+	}
+	container := &workloadmeta.Container{
+		EntityID: workloadmeta.EntityID{Kind: workloadmeta.KindContainer, ID: "container-id"},
+	}
+	newService := func(pod *workloadmeta.KubernetesPod) *service {
+		return &service{entity: container, pod: pod, adIdentifiers: []string{"docker://container-id"}}
+	}
+
+	assert.True(t, svcEqual(newService(pod(map[string]string{"app": "web"})), newService(pod(map[string]string{"app": "web"}))))
+	assert.False(t, svcEqual(newService(pod(map[string]string{"app": "web"})), newService(pod(map[string]string{"app": "api"}))))
+	assert.False(t, svcEqual(newService(nil), newService(pod(nil))))
+}
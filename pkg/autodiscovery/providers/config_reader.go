@@ -140,6 +140,25 @@ func ReadConfigFiles(keep FilterFunc) ([]integration.Config, map[string]string,
 	return filterConfigs(configs, keep), errs, nil
 }
 
+// resetConfigFilesCache makes the next ReadConfigFiles read the files again.
+// It waits for an in-flight read, which would otherwise cache the files as they
+// were before the change.
+func resetConfigFilesCache() {
+	if reader != nil {
+		reader.Lock()
+		defer reader.Unlock()
+		reader.cache.Flush()
+	}
+}
+
+// configFilesPaths returns the paths searched for configuration files
+func configFilesPaths() []string {
+	if reader == nil {
+		return nil
+	}
+	return reader.paths
+}
+
 func filterConfigs(configs []integration.Config, keep FilterFunc) []integration.Config {
 	filteredConfigs := []integration.Config{}
 	for _, config := range configs {
@@ -344,15 +363,27 @@ func collectDir(parentPath string, folder os.DirEntry, integrationErrors map[str
 
 // GetIntegrationConfigFromFile returns an instance of integration.Config if `fpath` points to a valid config file
 func GetIntegrationConfigFromFile(name, fpath string) (integration.Config, error) {
-	cf := configFormat{}
-	conf := integration.Config{Name: name}
-
 	// Read file contents
 	// FIXME: ReadFile reads the entire file, possible security implications
 	yamlFile, err := os.ReadFile(fpath)
+	if err != nil {
+		return integration.Config{Name: name}, err
+	}
+
+	conf, err := parseIntegrationConfig(name, fpath, yamlFile)
 	if err != nil {
 		return conf, err
 	}
+	conf.Source = "file:" + fpath
+
+	return conf, nil
+}
+
+// parseIntegrationConfig returns an instance of integration.Config if `yamlFile` is
+// a valid config file. `origin` describes where the config comes from in logs.
+func parseIntegrationConfig(name, origin string, yamlFile []byte) (integration.Config, error) {
+	cf := configFormat{}
+	conf := integration.Config{Name: name}
 
 	// Parse configuration
 	// Try UnmarshalStrict first, so we can warn about duplicated keys
@@ -360,7 +391,7 @@ func GetIntegrationConfigFromFile(name, fpath string) (integration.Config, error
 		if err := yaml.Unmarshal(yamlFile, &cf); err != nil {
 			return conf, err
 		}
-		log.Warnf("reading config file %v: %v\n", fpath, strictErr)
+		log.Warnf("reading config file %v: %v\n", origin, strictErr)
 	}
 
 	// If no valid instances were found & this is neither a metrics file, nor a logs file
@@ -386,7 +417,7 @@ func GetIntegrationConfigFromFile(name, fpath string) (integration.Config, error
 			tags := config.GetGlobalConfiguredTags(false)
 			err := dataConf.MergeAdditionalTags(tags)
 			if err != nil {
-				log.Debugf("Could not add agent-level tags to instance of %v: %v", fpath, err)
+				log.Debugf("Could not add agent-level tags to instance of %v: %v", origin, err)
 			}
 		}
 		conf.Instances = append(conf.Instances, dataConf)
@@ -429,9 +460,7 @@ func GetIntegrationConfigFromFile(name, fpath string) (integration.Config, error
 		}
 	}
 
-	conf.Source = "file:" + fpath
-
-	return conf, err
+	return conf, nil
 }
 
 func containsString(slice []string, str string) bool {
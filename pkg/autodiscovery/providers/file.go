@@ -7,6 +7,7 @@ package providers
 
 import (
 	"context"
+	"errors"
 
 	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
 	"github.com/DataDog/datadog-agent/pkg/autodiscovery/providers/names"
@@ -15,7 +16,9 @@ import (
 
 // FileConfigProvider collect configuration files from disk
 type FileConfigProvider struct {
-	Errors map[string]string
+	Errors    map[string]string
+	watcher   *configFilesWatcher
+	collected bool
 }
 
 // NewFileConfigProvider creates a new FileConfigProvider.
@@ -25,23 +28,58 @@ func NewFileConfigProvider() *FileConfigProvider {
 	}
 }
 
+// Watch makes the provider watch the configuration directories, so that the
+// files are only read again after a change. The check configs are then split
+// by instance: a change on an instance doesn't reschedule the other instances
+// of the same file, as the check IDs depend on the digest of the config.
+// InitConfigFilesReader should be called before this function.
+func (c *FileConfigProvider) Watch() error {
+	paths := configFilesPaths()
+	if len(paths) == 0 {
+		return errors.New("no configuration path to watch")
+	}
+
+	watcher, err := newConfigFilesWatcher(paths)
+	if err != nil {
+		return err
+	}
+	c.watcher = watcher
+	return nil
+}
+
 // Collect returns the check configurations defined in Yaml files.
 // Configs with advanced AD identifiers are filtered-out. They're handled by other file-based config providers.
 func (c *FileConfigProvider) Collect(ctx context.Context) ([]integration.Config, error) {
 	configs, errors, err := ReadConfigFiles(WithoutAdvancedAD)
+	if c.watcher != nil && c.collected {
+		telemetry.ConfigFileReloads.Inc()
+		if err != nil || len(errors) > 0 {
+			telemetry.ConfigFileReloadErrors.Inc()
+		}
+	}
 	if err != nil {
 		return nil, err
 	}
+	c.collected = true
 
 	c.Errors = errors
 	telemetry.Errors.Set(float64(len(errors)), names.File)
 
+	if c.watcher != nil {
+		configs = splitInstances(configs)
+	}
+
 	return configs, nil
 }
 
-// IsUpToDate is not implemented for the file Providers as the files are not meant to change very often.
+// IsUpToDate returns whether the files changed since the last Collect when
+// they are watched. Otherwise, it always returns false so that the files are
+// read again on every poll.
 func (c *FileConfigProvider) IsUpToDate(ctx context.Context) (bool, error) {
-	return false, nil
+	if c.watcher == nil {
+		return false, nil
+	}
+	return !c.watcher.hasChanged(), nil
 }
 
 // String returns a string representation of the FileConfigProvider
@@ -53,3 +91,29 @@ func (c *FileConfigProvider) String() string {
 func (c *FileConfigProvider) GetConfigErrors() map[string]ErrorMsgSet {
 	return make(map[string]ErrorMsgSet)
 }
+
+// splitInstances returns a config for each instance of the check configs. The
+// logs config goes in a config of its own, and the configs with JMX metrics
+// are left untouched.
+func splitInstances(configs []integration.Config) []integration.Config {
+	res := make([]integration.Config, 0, len(configs))
+	for _, config := range configs {
+		if config.MetricConfig != nil || len(config.Instances) == 0 || (len(config.Instances) == 1 && config.LogsConfig == nil) {
+			res = append(res, config)
+			continue
+		}
+
+		if config.LogsConfig != nil {
+			logsConfig := config
+			logsConfig.Instances = nil
+			res = append(res, logsConfig)
+		}
+		for _, instance := range config.Instances {
+			instanceConfig := config
+			instanceConfig.Instances = []integration.Data{instance}
+			instanceConfig.LogsConfig = nil
+			res = append(res, instanceConfig)
+		}
+	}
+	return res
+}
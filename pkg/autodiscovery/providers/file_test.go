@@ -8,12 +8,15 @@ package providers
 import (
 	"context"
 	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
 	"github.com/DataDog/datadog-agent/pkg/config"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestCollect(t *testing.T) {
@@ -108,3 +111,103 @@ func TestEnvVarReplacement(t *testing.T) {
 	assert.Len(t, rc[0].Instances, 2)
 	assert.Contains(t, string(rc[0].Instances[1]), "test_envvar_not_set")
 }
+
+func TestSplitInstances(t *testing.T) {
+	configs := splitInstances([]integration.Config{
+		{Name: "single", Instances: []integration.Data{integration.Data("a: 1")}},
+		{Name: "multiple", Instances: []integration.Data{integration.Data("a: 1"), integration.Data("a: 2")}, InitConfig: integration.Data("b: 1")},
+		{Name: "logs", Instances: []integration.Data{integration.Data("a: 1")}, LogsConfig: integration.Data("logs: []")},
+		{Name: "logs_only", LogsConfig: integration.Data("logs: []")},
+		{Name: "jmx", Instances: []integration.Data{integration.Data("a: 1"), integration.Data("a: 2")}, MetricConfig: integration.Data("c: 1")},
+	})
+
+	assert.Equal(t, []integration.Config{
+		{Name: "single", Instances: []integration.Data{integration.Data("a: 1")}},
+		{Name: "multiple", Instances: []integration.Data{integration.Data("a: 1")}, InitConfig: integration.Data("b: 1")},
+		{Name: "multiple", Instances: []integration.Data{integration.Data("a: 2")}, InitConfig: integration.Data("b: 1")},
+		{Name: "logs", LogsConfig: integration.Data("logs: []")},
+		{Name: "logs", Instances: []integration.Data{integration.Data("a: 1")}},
+		{Name: "logs_only", LogsConfig: integration.Data("logs: []")},
+		{Name: "jmx", Instances: []integration.Data{integration.Data("a: 1"), integration.Data("a: 2")}, MetricConfig: integration.Data("c: 1")},
+	}, configs)
+}
+
+func TestWatch(t *testing.T) {
+	ctx := context.Background()
+	confd := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(confd, "foo.yaml"), []byte("instances:\n- a: 1\n- a: 2\n"), 0o640))
+	ResetReader([]string{confd})
+	defer ResetReader(nil)
+
+	provider := NewFileConfigProvider()
+	require.NoError(t, provider.Watch())
+	defer provider.watcher.stop()
+
+	configs, err := provider.Collect(ctx)
+	require.NoError(t, err)
+	assert.Len(t, configs, 2)
+
+	upToDate, err := provider.IsUpToDate(ctx)
+	require.NoError(t, err)
+	assert.True(t, upToDate)
+
+	// a change in a new check directory
+	require.NoError(t, os.Mkdir(filepath.Join(confd, "bar.d"), 0o750))
+	require.Eventually(t, func() bool {
+		upToDate, _ := provider.IsUpToDate(ctx)
+		return !upToDate
+	}, 5*time.Second, 10*time.Millisecond)
+
+	require.NoError(t, os.WriteFile(filepath.Join(confd, "bar.d", "conf.yaml"), []byte("instances:\n- b: 1\n"), 0o640))
+	require.Eventually(t, func() bool {
+		upToDate, _ := provider.IsUpToDate(ctx)
+		return !upToDate
+	}, 5*time.Second, 10*time.Millisecond)
+
+	configs, err = provider.Collect(ctx)
+	require.NoError(t, err)
+	assert.Len(t, configs, 3)
+}
+
+func TestWatchDebounce(t *testing.T) {
+	confd := t.TempDir()
+	ResetReader([]string{confd})
+	defer ResetReader(nil)
+
+	watcher, err := newConfigFilesWatcher([]string{confd})
+	require.NoError(t, err)
+	defer watcher.stop()
+
+	// a burst of events is flagged once
+	for i := 0; i < 10; i++ {
+		require.NoError(t, os.WriteFile(filepath.Join(confd, "foo.yaml"), []byte("instances:\n- a: 1\n"), 0o640))
+	}
+	require.Eventually(t, watcher.hasChanged, 5*time.Second, 10*time.Millisecond)
+	assert.Never(t, watcher.hasChanged, 5*fileWatchDebounce, 10*time.Millisecond)
+}
+
+func TestResetConfigFilesCacheWaitsForRead(t *testing.T) {
+	ResetReader([]string{t.TempDir()})
+	defer ResetReader(nil)
+
+	// a read in progress holds the lock, the cache is flushed once it's done
+	reader.Lock()
+	reset := make(chan struct{})
+	go func() {
+		resetConfigFilesCache()
+		close(reset)
+	}()
+
+	reader.cache.SetDefault("configs", []integration.Config{})
+	reader.cache.SetDefault("errors", map[string]string{})
+	select {
+	case <-reset:
+		t.Fatal("the cache was flushed during a read")
+	case <-time.After(50 * time.Millisecond):
+	}
+	reader.Unlock()
+
+	<-reset
+	_, found := reader.cache.Get("configs")
+	assert.False(t, found)
+}
@@ -0,0 +1,146 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package providers
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"go.uber.org/atomic"
+
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/telemetry"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// FileWatchPollInterval is how often the FileConfigProvider is polled when it
+// watches the configuration files. Polling is cheap as the files are only read
+// again after a change.
+const FileWatchPollInterval = time.Second
+
+// fileWatchDebounce is how long the watcher waits for more events before
+// flagging a change, so that an editor saving a file or a directory being
+// copied only makes the files be read once
+const fileWatchDebounce = 100 * time.Millisecond
+
+// configFilesWatcher watches the configuration directories and their
+// `<check>.d` sub-directories, and flags any change made to them
+type configFilesWatcher struct {
+	paths   []string
+	watcher *fsnotify.Watcher
+	changed *atomic.Bool
+}
+
+func newConfigFilesWatcher(paths []string) (*configFilesWatcher, error) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+
+	w := &configFilesWatcher{
+		paths:   paths,
+		watcher: watcher,
+		changed: atomic.NewBool(false),
+	}
+	for _, path := range paths {
+		// like the reader, skip the paths that don't exist
+		if err := w.watchDir(path); err != nil {
+			log.Warnf("Unable to watch the configuration files in %s: %s", path, err)
+		}
+	}
+
+	go w.run()
+	return w, nil
+}
+
+// watchDir watches a configuration directory and its `<check>.d`
+// sub-directories, as the reader only supports one level of nesting
+func (w *configFilesWatcher) watchDir(path string) error {
+	if err := w.watcher.Add(path); err != nil {
+		return err
+	}
+
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if entry.IsDir() && filepath.Ext(entry.Name()) == ".d" {
+			w.watchSubDir(filepath.Join(path, entry.Name()))
+		}
+	}
+	return nil
+}
+
+func (w *configFilesWatcher) watchSubDir(path string) {
+	if err := w.watcher.Add(path); err != nil {
+		log.Warnf("Unable to watch the configuration files in %s: %s", path, err)
+		telemetry.ConfigFileReloadErrors.Inc()
+	}
+}
+
+func (w *configFilesWatcher) run() {
+	debounce := time.NewTimer(fileWatchDebounce)
+	debounce.Stop()
+	defer debounce.Stop()
+
+	for {
+		select {
+		case event, ok := <-w.watcher.Events:
+			if !ok {
+				return
+			}
+			if w.handleEvent(event) {
+				debounce.Reset(fileWatchDebounce)
+			}
+		case err, ok := <-w.watcher.Errors:
+			if !ok {
+				return
+			}
+			// the events may have been lost, read the files again to be safe
+			log.Warnf("Error watching the configuration files: %s", err)
+			telemetry.ConfigFileReloadErrors.Inc()
+			debounce.Reset(fileWatchDebounce)
+		case <-debounce.C:
+			w.flagChange()
+		}
+	}
+}
+
+// handleEvent returns whether the event changes the configuration files
+func (w *configFilesWatcher) handleEvent(event fsnotify.Event) bool {
+	// the content of a file doesn't change with its permissions
+	if event.Op == fsnotify.Chmod {
+		return false
+	}
+	log.Debugf("Configuration files changed: %s", event)
+
+	// new `<check>.d` directories must be watched too
+	if event.Op&fsnotify.Create != 0 && filepath.Ext(event.Name) == ".d" && containsString(w.paths, filepath.Dir(event.Name)) {
+		if info, err := os.Stat(event.Name); err == nil && info.IsDir() {
+			w.watchSubDir(event.Name)
+		}
+	}
+
+	return true
+}
+
+func (w *configFilesWatcher) flagChange() {
+	resetConfigFilesCache()
+	w.changed.Store(true)
+}
+
+// hasChanged returns whether the configuration files changed since the last
+// call, and resets the flag
+func (w *configFilesWatcher) hasChanged() bool {
+	return w.changed.Swap(false)
+}
+
+// stop stops watching, closing the channels of the watcher ends run
+func (w *configFilesWatcher) stop() {
+	w.watcher.Close()
+}
@@ -0,0 +1,294 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build kubeapiserver
+
+package providers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/atomic"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	listersv1 "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/providers/names"
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/util/kubernetes/apiserver"
+	"github.com/DataDog/datadog-agent/pkg/util/kubernetes/apiserver/common"
+	"github.com/DataDog/datadog-agent/pkg/util/kubernetes/hostinfo"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+const (
+	// kubeConfigMapNodeSelectorAnnotation restricts the configs of a ConfigMap
+	// to the nodes matching its label selector
+	kubeConfigMapNodeSelectorAnnotation = "ad.datadoghq.com/node-selector"
+	kubeConfigMapResyncPeriod           = 300 // in seconds
+	// kubeConfigMapAllNamespaces is the namespace setting watching all the namespaces
+	kubeConfigMapAllNamespaces = "*"
+)
+
+// KubeConfigMapConfigProvider implements the ConfigProvider interface for the
+// check configs declared in ConfigMaps. Each key of a ConfigMap matching the label
+// selector, named after a check and suffixed by `.yaml`, holds a config file like
+// the ones of conf.d.
+type KubeConfigMapConfigProvider struct {
+	lister     listersv1.ConfigMapLister
+	hasSynced  cache.InformerSynced
+	nodeLabels func(ctx context.Context) (map[string]string, error)
+	upToDate   *atomic.Bool
+	errors     map[string]ErrorMsgSet
+	mu         sync.RWMutex // to protect errors
+}
+
+// NewKubeConfigMapConfigProvider returns a new ConfigProvider watching the ConfigMaps
+// through the apiserver. Connectivity is not checked at this stage to allow for
+// retries, Collect will do it.
+func NewKubeConfigMapConfigProvider(*config.ConfigurationProviders) (ConfigProvider, error) {
+	// Using GetAPIClient() (no retry)
+	ac, err := apiserver.GetAPIClient()
+	if err != nil {
+		return nil, fmt.Errorf("cannot connect to apiserver: %s", err)
+	}
+
+	selector := config.Datadog.GetString("kube_configmaps_provider.label_selector")
+	if _, err := labels.Parse(selector); err != nil {
+		return nil, fmt.Errorf("invalid label selector %q: %s", selector, err)
+	}
+
+	return newKubeConfigMapConfigProvider(
+		ac.Cl,
+		kubeConfigMapNamespace(config.Datadog.GetString("kube_configmaps_provider.namespace")),
+		selector,
+		getNodeLabels,
+		make(chan struct{}), // the provider lives as long as the agent
+	), nil
+}
+
+// kubeConfigMapNamespace returns the namespace of the ConfigMaps to watch. The
+// checks run with the permissions of the Agent, so only the Agent namespace is
+// watched unless all the namespaces are explicitly requested.
+func kubeConfigMapNamespace(namespace string) string {
+	switch namespace {
+	case "":
+		return common.GetMyNamespace()
+	case kubeConfigMapAllNamespaces:
+		log.Warnf("Scheduling the check configs of the ConfigMaps of all the namespaces, anyone able to create a ConfigMap can run checks with the Agent permissions")
+		return metav1.NamespaceAll
+	default:
+		return namespace
+	}
+}
+
+func newKubeConfigMapConfigProvider(
+	client kubernetes.Interface,
+	namespace, selector string,
+	nodeLabels func(ctx context.Context) (map[string]string, error),
+	stopCh <-chan struct{},
+) *KubeConfigMapConfigProvider {
+	factory := informers.NewSharedInformerFactoryWithOptions(
+		client,
+		kubeConfigMapResyncPeriod*time.Second,
+		informers.WithNamespace(namespace),
+		informers.WithTweakListOptions(func(opts *metav1.ListOptions) {
+			opts.LabelSelector = selector
+		}),
+	)
+	configMapsInformer := factory.Core().V1().ConfigMaps()
+
+	p := &KubeConfigMapConfigProvider{
+		lister:     configMapsInformer.Lister(),
+		hasSynced:  configMapsInformer.Informer().HasSynced,
+		nodeLabels: nodeLabels,
+		upToDate:   atomic.NewBool(false),
+		errors:     make(map[string]ErrorMsgSet),
+	}
+
+	configMapsInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    p.invalidate,
+		UpdateFunc: p.invalidateIfChanged,
+		DeleteFunc: p.invalidate,
+	})
+	go configMapsInformer.Informer().Run(stopCh)
+
+	return p
+}
+
+// getNodeLabels returns the labels of the node the agent runs on
+func getNodeLabels(ctx context.Context) (map[string]string, error) {
+	nodeInfo, err := hostinfo.NewNodeInfo()
+	if err != nil {
+		return nil, err
+	}
+	return nodeInfo.GetNodeLabels(ctx)
+}
+
+// String returns a string representation of the KubeConfigMapConfigProvider
+func (k *KubeConfigMapConfigProvider) String() string {
+	return names.KubeConfigMaps
+}
+
+// Collect retrieves the ConfigMaps from the informer cache, builds Config objects and returns them
+func (k *KubeConfigMapConfigProvider) Collect(ctx context.Context) ([]integration.Config, error) {
+	if !k.hasSynced() {
+		return nil, errors.New("configmaps informer is not synced yet")
+	}
+
+	configMaps, err := k.lister.List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+	k.upToDate.Store(true)
+
+	var nodeLabels labels.Set
+	var nodeLabelsErr error
+	for _, cm := range configMaps {
+		if _, found := cm.Annotations[kubeConfigMapNodeSelectorAnnotation]; found {
+			var l map[string]string
+			l, nodeLabelsErr = k.nodeLabels(ctx)
+			nodeLabels = labels.Set(l)
+			break
+		}
+	}
+
+	configs, errs := parseConfigMaps(configMaps, nodeLabels, nodeLabelsErr)
+
+	k.mu.Lock()
+	k.errors = errs
+	k.mu.Unlock()
+
+	return configs, nil
+}
+
+// IsUpToDate allows to cache configs as long as no changes are detected in the apiserver
+func (k *KubeConfigMapConfigProvider) IsUpToDate(ctx context.Context) (bool, error) {
+	return k.upToDate.Load(), nil
+}
+
+func (k *KubeConfigMapConfigProvider) invalidate(obj interface{}) {
+	if obj != nil {
+		log.Trace("Invalidating configs on new/deleted configmap")
+		k.upToDate.Store(false)
+	}
+}
+
+func (k *KubeConfigMapConfigProvider) invalidateIfChanged(old, obj interface{}) {
+	// Cast the updated object, don't invalidate on casting error.
+	// nil pointers are safely handled by the casting logic.
+	castedObj, ok := obj.(*v1.ConfigMap)
+	if !ok {
+		log.Errorf("Expected a *v1.ConfigMap type, got: %T", obj)
+		return
+	}
+	// Cast the old object, invalidate on casting error
+	castedOld, ok := old.(*v1.ConfigMap)
+	if !ok {
+		log.Errorf("Expected a *v1.ConfigMap type, got: %T", old)
+		k.upToDate.Store(false)
+		return
+	}
+	// Quick exit if resversion did not change
+	if castedObj.ResourceVersion == castedOld.ResourceVersion {
+		return
+	}
+	log.Trace("Invalidating configs on configmap change")
+	k.upToDate.Store(false)
+}
+
+// parseConfigMaps builds the configs of the ConfigMaps, skipping the ones whose
+// node selector doesn't match the node labels, and returns them with the errors
+// found, indexed by ConfigMap.
+func parseConfigMaps(configMaps []*v1.ConfigMap, nodeLabels labels.Set, nodeLabelsErr error) ([]integration.Config, map[string]ErrorMsgSet) {
+	var configs []integration.Config
+	errs := make(map[string]ErrorMsgSet)
+
+	addError := func(cm *v1.ConfigMap, err error) {
+		key := cm.Namespace + "/" + cm.Name
+		if _, found := errs[key]; !found {
+			errs[key] = make(ErrorMsgSet)
+		}
+		errs[key][err.Error()] = struct{}{}
+		log.Errorf("Cannot parse configmap %s: %s", key, err)
+	}
+
+	for _, cm := range configMaps {
+		if cm == nil {
+			continue
+		}
+
+		if selector, found := cm.Annotations[kubeConfigMapNodeSelectorAnnotation]; found {
+			nodeSelector, err := labels.Parse(selector)
+			if err != nil {
+				addError(cm, fmt.Errorf("invalid node selector %q: %s", selector, err))
+				continue
+			}
+			if nodeLabelsErr != nil {
+				addError(cm, fmt.Errorf("cannot get the node labels to match the node selector: %s", nodeLabelsErr))
+				continue
+			}
+			if !nodeSelector.Matches(nodeLabels) {
+				log.Debugf("Ignoring configmap %s/%s, its node selector %q doesn't match this node", cm.Namespace, cm.Name, selector)
+				continue
+			}
+		}
+
+		// sort the keys for the configs to be returned in a stable order
+		keys := make([]string, 0, len(cm.Data))
+		for key := range cm.Data {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			ext := filepath.Ext(key)
+			if ext != ".yaml" && ext != ".yml" {
+				log.Debugf("Ignoring key %s of configmap %s/%s, not a yaml file", key, cm.Namespace, cm.Name)
+				continue
+			}
+			name := strings.TrimSuffix(key, ext)
+			origin := fmt.Sprintf("%s/%s/%s", cm.Namespace, cm.Name, key)
+
+			conf, err := parseIntegrationConfig(name, "configmap "+origin, []byte(cm.Data[key]))
+			if err != nil {
+				addError(cm, fmt.Errorf("%s: %s", key, err))
+				continue
+			}
+			conf.Source = "kube_configmaps:" + origin
+			configs = append(configs, conf)
+		}
+	}
+
+	return configs, errs
+}
+
+// GetConfigErrors returns the errors of the ConfigMaps found by the last Collect
+func (k *KubeConfigMapConfigProvider) GetConfigErrors() map[string]ErrorMsgSet {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+
+	errs := make(map[string]ErrorMsgSet, len(k.errors))
+	for key, set := range k.errors {
+		errs[key] = set
+	}
+	return errs
+}
+
+func init() {
+	RegisterProvider(names.KubeConfigMapsRegisterName, NewKubeConfigMapConfigProvider)
+}
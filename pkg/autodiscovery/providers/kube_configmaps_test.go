@@ -0,0 +1,205 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build kubeapiserver
+
+package providers
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+	"github.com/DataDog/datadog-agent/pkg/util/kubernetes/apiserver/common"
+)
+
+const redisConfig = `
+ad_identifiers:
+  - redis
+init_config:
+instances:
+  - host: "%%host%%"
+    port: 6379
+`
+
+func newConfigMap(namespace, name string, labels, annotations, data map[string]string) *v1.ConfigMap {
+	return &v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:   namespace,
+			Name:        name,
+			Labels:      labels,
+			Annotations: annotations,
+		},
+		Data: data,
+	}
+}
+
+func TestParseConfigMaps(t *testing.T) {
+	for _, tc := range []struct {
+		name          string
+		configMap     *v1.ConfigMap
+		nodeLabels    map[string]string
+		nodeLabelsErr error
+		expectedOut   []integration.Config
+		expectedErrs  map[string]ErrorMsgSet
+	}{
+		{
+			name:         "nil input",
+			configMap:    nil,
+			expectedErrs: map[string]ErrorMsgSet{},
+		},
+		{
+			name: "check config",
+			configMap: newConfigMap("ns", "checks", nil, nil, map[string]string{
+				"redisdb.yaml": redisConfig,
+				"README.md":    "ignored",
+			}),
+			expectedOut: []integration.Config{
+				{
+					Name:          "redisdb",
+					ADIdentifiers: []string{"redis"},
+					Instances:     []integration.Data{integration.Data("host: '%%host%%'\nport: 6379\n")},
+					Source:        "kube_configmaps:ns/checks/redisdb.yaml",
+				},
+			},
+			expectedErrs: map[string]ErrorMsgSet{},
+		},
+		{
+			name: "invalid config",
+			configMap: newConfigMap("ns", "checks", nil, nil, map[string]string{
+				"redisdb.yaml": "init_config:\n",
+			}),
+			expectedErrs: map[string]ErrorMsgSet{
+				"ns/checks": {"redisdb.yaml: Configuration file contains no valid instances": struct{}{}},
+			},
+		},
+		{
+			name: "matching node selector",
+			configMap: newConfigMap("ns", "checks", nil, map[string]string{
+				"ad.datadoghq.com/node-selector": "role=db,zone in (a, b)",
+			}, map[string]string{
+				"redisdb.yaml": redisConfig,
+			}),
+			nodeLabels: map[string]string{"role": "db", "zone": "a"},
+			expectedOut: []integration.Config{
+				{
+					Name:          "redisdb",
+					ADIdentifiers: []string{"redis"},
+					Instances:     []integration.Data{integration.Data("host: '%%host%%'\nport: 6379\n")},
+					Source:        "kube_configmaps:ns/checks/redisdb.yaml",
+				},
+			},
+			expectedErrs: map[string]ErrorMsgSet{},
+		},
+		{
+			name: "node selector not matching",
+			configMap: newConfigMap("ns", "checks", nil, map[string]string{
+				"ad.datadoghq.com/node-selector": "role=db",
+			}, map[string]string{
+				"redisdb.yaml": redisConfig,
+			}),
+			nodeLabels:   map[string]string{"role": "web"},
+			expectedErrs: map[string]ErrorMsgSet{},
+		},
+		{
+			name: "invalid node selector",
+			configMap: newConfigMap("ns", "checks", nil, map[string]string{
+				"ad.datadoghq.com/node-selector": "role in db",
+			}, map[string]string{
+				"redisdb.yaml": redisConfig,
+			}),
+			nodeLabels: map[string]string{"role": "db"},
+			expectedErrs: map[string]ErrorMsgSet{
+				"ns/checks": {`invalid node selector "role in db": unable to parse requirement: found 'db' expected: '('`: struct{}{}},
+			},
+		},
+		{
+			name: "node labels unavailable",
+			configMap: newConfigMap("ns", "checks", nil, map[string]string{
+				"ad.datadoghq.com/node-selector": "role=db",
+			}, map[string]string{
+				"redisdb.yaml": redisConfig,
+			}),
+			nodeLabelsErr: errors.New("no kubelet"),
+			expectedErrs: map[string]ErrorMsgSet{
+				"ns/checks": {"cannot get the node labels to match the node selector: no kubelet": struct{}{}},
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			configs, errs := parseConfigMaps([]*v1.ConfigMap{tc.configMap}, tc.nodeLabels, tc.nodeLabelsErr)
+			assert.EqualValues(t, tc.expectedOut, configs)
+			assert.Equal(t, tc.expectedErrs, errs)
+		})
+	}
+}
+
+func TestKubeConfigMapConfigProvider(t *testing.T) {
+	client := fake.NewSimpleClientset([]runtime.Object{
+		newConfigMap("ns", "checks", map[string]string{"ad.datadoghq.com/checks": "true"}, nil, map[string]string{
+			"redisdb.yaml": redisConfig,
+		}),
+		newConfigMap("ns", "db-checks", map[string]string{"ad.datadoghq.com/checks": "true"}, map[string]string{
+			"ad.datadoghq.com/node-selector": "role=db",
+		}, map[string]string{
+			"postgres.yaml": "instances:\n  - host: localhost\n",
+		}),
+		newConfigMap("ns", "other", nil, nil, map[string]string{
+			"nginx.yaml": "instances:\n  - nginx_status_url: http://localhost/\n",
+		}),
+	}...)
+
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+
+	nodeLabels := func(context.Context) (map[string]string, error) {
+		return map[string]string{"role": "web"}, nil
+	}
+	provider := newKubeConfigMapConfigProvider(client, "", "ad.datadoghq.com/checks=true", nodeLabels, stopCh)
+
+	ctx := context.Background()
+	require.Eventually(t, provider.hasSynced, 5*time.Second, 10*time.Millisecond)
+
+	configs, err := provider.Collect(ctx)
+	require.NoError(t, err)
+	require.Len(t, configs, 1)
+	assert.Equal(t, "redisdb", configs[0].Name)
+	assert.Equal(t, "kube_configmaps:ns/checks/redisdb.yaml", configs[0].Source)
+
+	upToDate, err := provider.IsUpToDate(ctx)
+	require.NoError(t, err)
+	assert.True(t, upToDate)
+
+	// a new configmap invalidates the configs
+	_, err = client.CoreV1().ConfigMaps("ns").Create(ctx, newConfigMap("ns", "more-checks", map[string]string{"ad.datadoghq.com/checks": "true"}, nil, map[string]string{
+		"nginx.yaml": "instances:\n  - nginx_status_url: http://localhost/\n",
+	}), metav1.CreateOptions{})
+	require.NoError(t, err)
+
+	assert.Eventually(t, func() bool {
+		upToDate, _ := provider.IsUpToDate(ctx)
+		return !upToDate
+	}, 5*time.Second, 10*time.Millisecond)
+
+	configs, err = provider.Collect(ctx)
+	require.NoError(t, err)
+	require.Len(t, configs, 2)
+	assert.Empty(t, provider.GetConfigErrors())
+}
+
+func TestKubeConfigMapNamespace(t *testing.T) {
+	assert.Equal(t, common.GetMyNamespace(), kubeConfigMapNamespace(""))
+	assert.Equal(t, "monitoring", kubeConfigMapNamespace("monitoring"))
+	assert.Equal(t, metav1.NamespaceAll, kubeConfigMapNamespace("*"))
+}
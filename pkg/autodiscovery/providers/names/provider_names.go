@@ -14,6 +14,7 @@ const (
 	EndpointsChecks    = "endpoints-checks"
 	Etcd               = "etcd"
 	File               = "file"
+	KubeConfigMaps     = "kubernetes-configmaps"
 	KubeContainer      = "kubernetes-container-allinone"
 	Kubernetes         = "kubernetes"
 	KubeServices       = "kubernetes-services"
@@ -36,6 +37,7 @@ const (
 	EndpointsChecksRegisterName    = "endpointschecks"
 	EtcdRegisterName               = "etcd"
 	KubeletRegisterName            = "kubelet"
+	KubeConfigMapsRegisterName     = "kube_configmaps"
 	KubeContainerRegisterName      = "kubernetes-container-allinone"
 	KubeServicesRegisterName       = "kube_services"
 	KubeServicesFileRegisterName   = "kube_services_file"
@@ -56,6 +56,18 @@ func (es *acErrorStats) removeConfigError(checkName string) {
 	delete(es.config, checkName)
 }
 
+// replaceConfigErrors will safely replace the errors of all the check
+// configuration files, dropping the errors of the files that were removed
+func (es *acErrorStats) replaceConfigErrors(errors map[string]string) {
+	es.m.Lock()
+	defer es.m.Unlock()
+
+	es.config = make(map[string]string, len(errors))
+	for k, v := range errors {
+		es.config[k] = v
+	}
+}
+
 // getConfigErrors will safely get the errors a check config file
 func (es *acErrorStats) getConfigErrors() map[string]string {
 	es.m.RLock()
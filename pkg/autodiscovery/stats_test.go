@@ -42,3 +42,12 @@ func TestGetConfigErrors(t *testing.T) {
 
 	assert.Len(t, err, 1)
 }
+
+func TestReplaceConfigErrors(t *testing.T) {
+	s := newAcErrorStats()
+	s.setConfigError("foo.yaml", "anError")
+	s.setConfigError("bar.yaml", "anError")
+	s.replaceConfigErrors(map[string]string{"bar.yaml": "anotherError"})
+
+	assert.Equal(t, map[string]string{"bar.yaml": "anotherError"}, s.getConfigErrors())
+}
@@ -62,4 +62,24 @@ var (
 		prometheus.DefBuckets,
 		telemetry.Options{NoDoubleUnderscoreSep: true},
 	)
+
+	// ConfigFileReloads tracks the reloads of the configuration files
+	// triggered by a change on disk.
+	ConfigFileReloads = telemetry.NewCounterWithOpts(
+		subsystem,
+		"config_file_reloads",
+		[]string{},
+		"Number of reloads of the configuration files triggered by a change on disk.",
+		commonOpts,
+	)
+
+	// ConfigFileReloadErrors tracks the errors met while watching and
+	// reloading the configuration files.
+	ConfigFileReloadErrors = telemetry.NewCounterWithOpts(
+		subsystem,
+		"config_file_reload_errors",
+		[]string{},
+		"Number of errors met while watching and reloading the configuration files.",
+		commonOpts,
+	)
 )
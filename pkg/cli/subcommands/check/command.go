@@ -84,6 +84,8 @@ type cliParams struct {
 	discoveryRetryInterval    uint
 	discoveryMinInstances     uint
 	generateIntegrationTraces bool
+	goldenFile                string
+	updateGolden              bool
 }
 
 type GlobalParams struct {
@@ -138,6 +140,8 @@ func MakeCommand(globalParamsGetter func() GlobalParams) *cobra.Command {
 	cmd.Flags().UintVarP(&cliParams.discoveryTimeout, "discovery-timeout", "", 5, "max retry duration until Autodiscovery resolves the check template (in seconds)")
 	cmd.Flags().UintVarP(&cliParams.discoveryRetryInterval, "discovery-retry-interval", "", 1, "(unused)")
 	cmd.Flags().UintVarP(&cliParams.discoveryMinInstances, "discovery-min-instances", "", 1, "minimum number of config instances to be discovered before running the check(s)")
+	cmd.Flags().StringVarP(&cliParams.goldenFile, "golden-file", "", "", "compare the series, sketches, service checks and events emitted by the check, stripped of timestamps and hostname, with a golden file and exit with an error on mismatch")
+	cmd.Flags().BoolVarP(&cliParams.updateGolden, "update", "", false, "write the check output to the golden file instead of comparing it")
 
 	pkgconfig.Datadog.BindPFlag("cmd.check.fullsketches", cmd.Flags().Lookup("full-sketches")) //nolint:errcheck
 
@@ -181,6 +185,10 @@ func run(log log.Component, config config.Component, sysprobeconfig sysprobeconf
 		return nil
 	}
 
+	if cliParams.updateGolden && cliParams.goldenFile == "" {
+		return errors.New("the --update flag requires a golden file to be set with --golden-file")
+	}
+
 	// Always disable SBOM collection in `check` command to avoid BoltDB flock issue
 	// and consuming CPU & Memory for asynchronous scans that would not be shown in `agent check` output.
 	pkgconfig.Datadog.Set("sbom.host.enabled", "false")
@@ -368,6 +376,7 @@ func run(log log.Component, config config.Component, sysprobeconfig sysprobeconf
 
 	var checkFileOutput bytes.Buffer
 	var instancesData []interface{}
+	var goldenData []map[string]interface{}
 	printer := aggregator.AgentDemultiplexerPrinter{AgentDemultiplexer: demux}
 	for _, c := range cs {
 		s := runCheck(cliParams, c, printer)
@@ -375,7 +384,9 @@ func run(log log.Component, config config.Component, sysprobeconfig sysprobeconf
 		// Sleep for a while to allow the aggregator to finish ingesting all the metrics/events/sc
 		time.Sleep(time.Duration(cliParams.checkDelay) * time.Millisecond)
 
-		if cliParams.formatJSON {
+		if cliParams.goldenFile != "" {
+			goldenData = append(goldenData, printer.GetMetricsDataForPrint())
+		} else if cliParams.formatJSON {
 			aggregatorData := printer.GetMetricsDataForPrint()
 			var collectorData map[string]interface{}
 
@@ -484,7 +495,7 @@ func run(log log.Component, config config.Component, sysprobeconfig sysprobeconf
 
 		fmt.Println(instanceJSONString)
 		checkFileOutput.WriteString(instanceJSONString + "\n")
-	} else if singleCheckRun(cliParams) {
+	} else if singleCheckRun(cliParams) && cliParams.goldenFile == "" {
 		if cliParams.profileMemory {
 			color.Yellow("Check has run only once, to collect diff data run the check multiple times with the -t/--check-times flag.")
 		} else {
@@ -506,6 +517,14 @@ func run(log log.Component, config config.Component, sysprobeconfig sysprobeconf
 		pkgconfig.Datadog.Set("integration_tracing_exhaustive", previousIntegrationTracingExhaustive)
 	}
 
+	if cliParams.goldenFile != "" {
+		output, err := normalizeGoldenOutput(goldenData, hostnameDetected)
+		if err != nil {
+			return fmt.Errorf("unable to normalize the check output: %v", err)
+		}
+		return compareGolden(cliParams.goldenFile, output, cliParams.updateGolden)
+	}
+
 	return nil
 }
 
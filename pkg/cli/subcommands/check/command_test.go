@@ -34,3 +34,21 @@ func TestCommand(t *testing.T) {
 			require.Equal(t, true, coreParams.ConfigLoadSecrets())
 		})
 }
+
+func TestCommandGolden(t *testing.T) {
+	commands := []*cobra.Command{
+		MakeCommand(func() GlobalParams {
+			return GlobalParams{}
+		}),
+	}
+
+	fxutil.TestOneShotSubcommand(t,
+		commands,
+		[]string{"check", "cleopatra", "--check-times", "3", "--golden-file", "cleopatra.golden.json", "--update"},
+		run,
+		func(cliParams *cliParams, coreParams core.BundleParams) {
+			require.Equal(t, 3, cliParams.checkTimes)
+			require.Equal(t, "cleopatra.golden.json", cliParams.goldenFile)
+			require.True(t, cliParams.updateGolden)
+		})
+}
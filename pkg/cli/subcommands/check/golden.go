@@ -0,0 +1,199 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package check
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/pmezard/go-difflib/difflib"
+)
+
+const goldenHostname = "<hostname>"
+
+// goldenTimestampKeys are the keys holding a timestamp in the data emitted by
+// a check, zeroed in the golden output
+var goldenTimestampKeys = map[string]struct{}{
+	"timestamp":         {},
+	"ts":                {},
+	"collect_timestamp": {},
+}
+
+// goldenHostKeys are the keys holding the hostname in the data emitted by a
+// check, replaced by a placeholder in the golden output along with the
+// host:<hostname> tags
+var goldenHostKeys = map[string]struct{}{
+	"host":      {},
+	"host_name": {},
+}
+
+// normalizeGoldenOutput returns the data emitted by each check instance as
+// indented JSON that doesn't depend on the time or on the host the check ran
+// on: timestamps are zeroed, including the ones of the JSON payloads of the
+// event platform events, the hostname is replaced by a placeholder, and the
+// tags and the payloads of each kind are sorted.
+func normalizeGoldenOutput(instancesData []map[string]interface{}, hostname string) ([]byte, error) {
+	// round-trip through JSON to only deal with generic types
+	raw, err := json.Marshal(instancesData)
+	if err != nil {
+		return nil, err
+	}
+	var data interface{}
+	if err := json.Unmarshal(raw, &data); err != nil {
+		return nil, err
+	}
+
+	data = normalizeGoldenValue("", data, hostname)
+
+	if instances, ok := data.([]interface{}); ok {
+		for _, instance := range instances {
+			payloads, ok := instance.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			for kind, list := range payloads {
+				if l, ok := list.([]interface{}); ok {
+					payloads[kind] = sortByJSON(l)
+				}
+			}
+		}
+	}
+
+	var out bytes.Buffer
+	enc := json.NewEncoder(&out)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(data); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+func normalizeGoldenValue(key string, value interface{}, hostname string) interface{} {
+	if _, found := goldenTimestampKeys[key]; found {
+		if _, ok := value.(float64); ok {
+			return 0
+		}
+	}
+
+	switch v := value.(type) {
+	case string:
+		if _, found := goldenHostKeys[key]; found && hostname != "" && v == hostname {
+			return goldenHostname
+		}
+		return normalizeGoldenPayload(v, hostname)
+	case map[string]interface{}:
+		for k, e := range v {
+			v[k] = normalizeGoldenValue(k, e, hostname)
+		}
+		return v
+	case []interface{}:
+		for i, e := range v {
+			v[i] = normalizeGoldenValue("", e, hostname)
+		}
+		switch key {
+		case "points":
+			// series points are [timestamp, value] pairs
+			for _, e := range v {
+				if point, ok := e.([]interface{}); ok && len(point) == 2 {
+					point[0] = 0
+				}
+			}
+		case "tags":
+			for i, e := range v {
+				if tag, ok := e.(string); ok && hostname != "" && tag == "host:"+hostname {
+					v[i] = "host:" + goldenHostname
+				}
+			}
+			return sortByJSON(v)
+		}
+		return v
+	}
+	return value
+}
+
+// normalizeGoldenPayload normalizes the strings holding a JSON payload, like
+// the raw event platform events, and returns the other strings unchanged
+func normalizeGoldenPayload(s string, hostname string) string {
+	trimmed := strings.TrimSpace(s)
+	if !strings.HasPrefix(trimmed, "{") && !strings.HasPrefix(trimmed, "[") {
+		return s
+	}
+	var payload interface{}
+	if err := json.Unmarshal([]byte(trimmed), &payload); err != nil {
+		return s
+	}
+
+	var out bytes.Buffer
+	enc := json.NewEncoder(&out)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(normalizeGoldenValue("", payload, hostname)); err != nil {
+		return s
+	}
+	return strings.TrimSuffix(out.String(), "\n")
+}
+
+// sortByJSON sorts a list by the JSON representation of its elements
+func sortByJSON(list []interface{}) []interface{} {
+	keys := make([]string, len(list))
+	for i, e := range list {
+		b, _ := json.Marshal(e)
+		keys[i] = string(b)
+	}
+	sort.Sort(byKey{list: list, keys: keys})
+	return list
+}
+
+type byKey struct {
+	list []interface{}
+	keys []string
+}
+
+func (b byKey) Len() int           { return len(b.list) }
+func (b byKey) Less(i, j int) bool { return b.keys[i] < b.keys[j] }
+func (b byKey) Swap(i, j int) {
+	b.list[i], b.list[j] = b.list[j], b.list[i]
+	b.keys[i], b.keys[j] = b.keys[j], b.keys[i]
+}
+
+// compareGolden compares the normalized output of a check with the golden
+// file, and prints their diff and returns an error if they don't match. When
+// update is set, the golden file is overwritten instead.
+func compareGolden(path string, output []byte, update bool) error {
+	if update {
+		if err := os.WriteFile(path, output, 0644); err != nil {
+			return fmt.Errorf("unable to write the golden file: %v", err)
+		}
+		fmt.Printf("Golden file %s updated\n", path)
+		return nil
+	}
+
+	expected, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("unable to read the golden file (use --update to create it): %v", err)
+	}
+	if bytes.Equal(expected, output) {
+		fmt.Printf("Check output matches the golden file %s\n", path)
+		return nil
+	}
+
+	diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(string(expected)),
+		B:        difflib.SplitLines(string(output)),
+		FromFile: path,
+		ToFile:   "check output",
+		Context:  3,
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Print(diff)
+	return fmt.Errorf("check output doesn't match the golden file %s", path)
+}
@@ -0,0 +1,190 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package check
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func goldenRun(ts float64, hostname string, tags ...string) []map[string]interface{} {
+	return []map[string]interface{}{
+		{
+			"metrics": []interface{}{
+				map[string]interface{}{"metric": "redis.net.clients", "points": []interface{}{[]interface{}{ts, 3}}, "tags": tags, "host": hostname},
+				map[string]interface{}{"metric": "redis.mem.used", "points": []interface{}{[]interface{}{ts, 1024}}, "tags": tags, "host": hostname},
+			},
+			"service_checks": []interface{}{
+				map[string]interface{}{"check": "redis.can_connect", "host_name": hostname, "timestamp": ts, "status": 0, "tags": tags},
+			},
+			"sketches": []interface{}{
+				map[string]interface{}{"metric": "redis.latency", "host": hostname, "points": []interface{}{map[string]interface{}{"ts": ts, "sketch": map[string]interface{}{"cnt": 2}}}},
+			},
+		},
+	}
+}
+
+func TestNormalizeGoldenOutput(t *testing.T) {
+	first, err := normalizeGoldenOutput(goldenRun(1680000000, "host-a", "env:prod", "service:redis", "host:host-a"), "host-a")
+	require.NoError(t, err)
+	second, err := normalizeGoldenOutput(goldenRun(1680000042, "host-b", "host:host-b", "service:redis", "env:prod"), "host-b")
+	require.NoError(t, err)
+
+	assert.Equal(t, string(first), string(second))
+
+	expected := `[
+  {
+    "metrics": [
+      {
+        "host": "<hostname>",
+        "metric": "redis.mem.used",
+        "points": [
+          [
+            0,
+            1024
+          ]
+        ],
+        "tags": [
+          "env:prod",
+          "host:<hostname>",
+          "service:redis"
+        ]
+      },
+      {
+        "host": "<hostname>",
+        "metric": "redis.net.clients",
+        "points": [
+          [
+            0,
+            3
+          ]
+        ],
+        "tags": [
+          "env:prod",
+          "host:<hostname>",
+          "service:redis"
+        ]
+      }
+    ],
+    "service_checks": [
+      {
+        "check": "redis.can_connect",
+        "host_name": "<hostname>",
+        "status": 0,
+        "tags": [
+          "env:prod",
+          "host:<hostname>",
+          "service:redis"
+        ],
+        "timestamp": 0
+      }
+    ],
+    "sketches": [
+      {
+        "host": "<hostname>",
+        "metric": "redis.latency",
+        "points": [
+          {
+            "sketch": {
+              "cnt": 2
+            },
+            "ts": 0
+          }
+        ]
+      }
+    ]
+  }
+]
+`
+	assert.Equal(t, expected, string(first))
+}
+
+func TestNormalizeGoldenOutputHostname(t *testing.T) {
+	// only the host fields and the host tag are rewritten, not the metric
+	// names, the other tags or the fields only containing the hostname
+	data := []map[string]interface{}{
+		{
+			"metrics": []interface{}{
+				map[string]interface{}{"metric": "redis.net.clients", "host": "redis", "tags": []interface{}{"host:redis", "redis_role:master", "source:redis"}},
+			},
+			"service_checks": []interface{}{
+				map[string]interface{}{"check": "redis.can_connect", "host_name": "redis", "message": "redis is up"},
+			},
+		},
+	}
+	output, err := normalizeGoldenOutput(data, "redis")
+	require.NoError(t, err)
+
+	expected := `[
+  {
+    "metrics": [
+      {
+        "host": "<hostname>",
+        "metric": "redis.net.clients",
+        "tags": [
+          "host:<hostname>",
+          "redis_role:master",
+          "source:redis"
+        ]
+      }
+    ],
+    "service_checks": [
+      {
+        "check": "redis.can_connect",
+        "host_name": "<hostname>",
+        "message": "redis is up"
+      }
+    ]
+  }
+]
+`
+	assert.Equal(t, expected, string(output))
+}
+
+func TestNormalizeGoldenOutputEventPlatformPayload(t *testing.T) {
+	run := func(ts float64, hostname string) []map[string]interface{} {
+		return []map[string]interface{}{
+			{
+				"dbm-samples": []interface{}{
+					map[string]interface{}{
+						"EventType": "dbm-samples",
+						"RawEvent":  fmt.Sprintf(`[{"host":%q,"timestamp":%v,"query":"SELECT 1"}]`, hostname, ts),
+					},
+				},
+			},
+		}
+	}
+	first, err := normalizeGoldenOutput(run(1680000000, "host-a"), "host-a")
+	require.NoError(t, err)
+	second, err := normalizeGoldenOutput(run(1680000042, "host-b"), "host-b")
+	require.NoError(t, err)
+
+	assert.Equal(t, string(first), string(second))
+	assert.Contains(t, string(first), `"RawEvent": "[{\"host\":\"<hostname>\",\"query\":\"SELECT 1\",\"timestamp\":0}]"`)
+}
+
+func TestCompareGolden(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "redisdb.golden.json")
+	output := []byte("[\n  {\n    \"metrics\": []\n  }\n]\n")
+
+	err := compareGolden(path, output, false)
+	assert.ErrorContains(t, err, "use --update to create it")
+
+	require.NoError(t, compareGolden(path, output, true))
+	written, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, output, written)
+
+	assert.NoError(t, compareGolden(path, output, false))
+
+	err = compareGolden(path, []byte("[\n  {\n    \"events\": []\n  }\n]\n"), false)
+	assert.EqualError(t, err, "check output doesn't match the golden file "+path)
+}
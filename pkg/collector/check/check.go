@@ -6,6 +6,7 @@
 package check
 
 import (
+	"context"
 	"time"
 
 	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
@@ -47,6 +48,31 @@ type Check interface {
 	InstanceConfig() string
 }
 
+// ContextCheck is implemented by the checks whose runs can be interrupted:
+// the context passed to RunContext is cancelled when the run exceeds its timeout.
+type ContextCheck interface {
+	Check
+	// RunContext runs the check until it's done or ctx is cancelled
+	RunContext(ctx context.Context) error
+}
+
+// InterruptibleCheck is implemented by the checks whose runs can be interrupted
+// from another goroutine. Interrupt is only called when a run exceeds its
+// timeout, unlike Stop which is also called when the check is unscheduled.
+type InterruptibleCheck interface {
+	Check
+	// Interrupt interrupts the current run of the check
+	Interrupt()
+}
+
+// TimeoutCheck is implemented by the checks whose instances configure the
+// timeout of their runs
+type TimeoutCheck interface {
+	Check
+	// RunTimeout returns the timeout of the runs of the check, 0 to use the default one
+	RunTimeout() time.Duration
+}
+
 // Info is an interface to pull information from types capable to run checks. This is a subsection from the Check
 // interface with only read only method.
 type Info interface {
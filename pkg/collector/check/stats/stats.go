@@ -46,6 +46,8 @@ var (
 		[]string{"check_name"}, "Service checks count")
 	tlmHistogramBuckets = telemetry.NewCounter("checks", "histogram_buckets",
 		[]string{"check_name"}, "Histogram buckets count")
+	tlmExponentialHistograms = telemetry.NewCounter("checks", "exponential_histograms",
+		[]string{"check_name"}, "Exponential histograms count")
 	tlmExecutionTime = telemetry.NewGauge("checks", "execution_time",
 		[]string{"check_name"}, "Check execution time")
 )
@@ -56,6 +58,8 @@ type SenderStats struct {
 	Events           int64
 	ServiceChecks    int64
 	HistogramBuckets int64
+	// ExponentialHistograms tracks the number of exponential histograms, which aren't buckets
+	ExponentialHistograms int64
 	// EventPlatformEvents tracks the number of events submitted for each eventType
 	EventPlatformEvents map[string]int64
 }
@@ -79,32 +83,34 @@ func (s SenderStats) Copy() (result SenderStats) {
 
 // Stats holds basic runtime statistics about check instances
 type Stats struct {
-	CheckName                string
-	CheckVersion             string
-	CheckConfigSource        string
-	CheckID                  checkid.ID
-	TotalRuns                uint64
-	TotalErrors              uint64
-	TotalWarnings            uint64
-	MetricSamples            int64
-	Events                   int64
-	ServiceChecks            int64
-	HistogramBuckets         int64
-	TotalMetricSamples       uint64
-	TotalEvents              uint64
-	TotalServiceChecks       uint64
-	TotalHistogramBuckets    uint64
-	EventPlatformEvents      map[string]int64
-	TotalEventPlatformEvents map[string]int64
-	ExecutionTimes           [32]int64 // circular buffer of recent run durations, most recent at [(TotalRuns+31) % 32]
-	AverageExecutionTime     int64     // average run duration
-	LastExecutionTime        int64     // most recent run duration, provided for convenience
-	LastSuccessDate          int64     // most recent successful execution date, unix timestamp in seconds
-	LastError                string    // error that occurred in the last run, if any
-	LastWarnings             []string  // warnings that occurred in the last run, if any
-	UpdateTimestamp          int64     // latest update to this instance, unix timestamp in seconds
-	m                        sync.Mutex
-	telemetry                bool // do we want telemetry on this Check
+	CheckName                  string
+	CheckVersion               string
+	CheckConfigSource          string
+	CheckID                    checkid.ID
+	TotalRuns                  uint64
+	TotalErrors                uint64
+	TotalWarnings              uint64
+	MetricSamples              int64
+	Events                     int64
+	ServiceChecks              int64
+	HistogramBuckets           int64
+	ExponentialHistograms      int64
+	TotalMetricSamples         uint64
+	TotalEvents                uint64
+	TotalServiceChecks         uint64
+	TotalHistogramBuckets      uint64
+	TotalExponentialHistograms uint64
+	EventPlatformEvents        map[string]int64
+	TotalEventPlatformEvents   map[string]int64
+	ExecutionTimes             [32]int64 // circular buffer of recent run durations, most recent at [(TotalRuns+31) % 32]
+	AverageExecutionTime       int64     // average run duration
+	LastExecutionTime          int64     // most recent run duration, provided for convenience
+	LastSuccessDate            int64     // most recent successful execution date, unix timestamp in seconds
+	LastError                  string    // error that occurred in the last run, if any
+	LastWarnings               []string  // warnings that occurred in the last run, if any
+	UpdateTimestamp            int64     // latest update to this instance, unix timestamp in seconds
+	m                          sync.Mutex
+	telemetry                  bool // do we want telemetry on this Check
 }
 
 type StatsCheck interface {
@@ -215,6 +221,13 @@ func (cs *Stats) Add(t time.Duration, err error, warnings []error, metricStats S
 			tlmHistogramBuckets.Add(float64(metricStats.HistogramBuckets), cs.CheckName)
 		}
 	}
+	if metricStats.ExponentialHistograms > 0 {
+		cs.ExponentialHistograms = metricStats.ExponentialHistograms
+		cs.TotalExponentialHistograms += uint64(metricStats.ExponentialHistograms)
+		if cs.telemetry {
+			tlmExponentialHistograms.Add(float64(metricStats.ExponentialHistograms), cs.CheckName)
+		}
+	}
 	for k, v := range metricStats.EventPlatformEvents {
 		// translate event types into more descriptive names
 		if humanName, ok := EventPlatformNameTranslations[k]; ok {
@@ -44,6 +44,7 @@ type CheckBase struct {
 	checkID        checkid.ID
 	latestWarnings []error
 	checkInterval  time.Duration
+	runTimeout     time.Duration
 	source         string
 	telemetry      bool
 	initConfig     string
@@ -106,6 +107,11 @@ func (c *CheckBase) CommonConfigure(integrationConfigDigest uint64, initConfig,
 			c.checkInterval = time.Duration(commonOptions.MinCollectionInterval) * time.Second
 		}
 
+		// See if a run timeout was specified
+		if commonOptions.CheckTimeout > 0 {
+			c.runTimeout = time.Duration(commonOptions.CheckTimeout) * time.Second
+		}
+
 		// Disable default hostname if specified
 		if commonOptions.EmptyDefaultHostname {
 			s, err := c.GetSender()
@@ -192,6 +198,12 @@ func (c *CheckBase) Interval() time.Duration {
 	return c.checkInterval
 }
 
+// RunTimeout returns the timeout of the runs configured for the check, or 0
+// to use the default one.
+func (c *CheckBase) RunTimeout() time.Duration {
+	return c.runTimeout
+}
+
 // String returns the name of the check, the same for every instance
 func (c *CheckBase) String() string {
 	return c.checkName
@@ -0,0 +1,239 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+// Package nagios implements a check running Nagios-compatible plugins: the exit
+// code of the plugin is sent as a service check and its performance data as gauges.
+package nagios
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator/sender"
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+	"github.com/DataDog/datadog-agent/pkg/collector/check"
+	core "github.com/DataDog/datadog-agent/pkg/collector/corechecks"
+	"github.com/DataDog/datadog-agent/pkg/metrics/servicecheck"
+	"github.com/DataDog/datadog-agent/pkg/secrets"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+const (
+	checkName = "nagios"
+
+	defaultTimeout = 10
+	// maxOutputSize is the size of the plugin output kept, like the
+	// MAX_PLUGIN_OUTPUT_LENGTH of Nagios
+	maxOutputSize = 8 * 1024
+)
+
+// exitCodeStatus maps the exit codes of the plugins to service check statuses
+var exitCodeStatus = map[int]servicecheck.ServiceCheckStatus{
+	0: servicecheck.ServiceCheckOK,
+	1: servicecheck.ServiceCheckWarning,
+	2: servicecheck.ServiceCheckCritical,
+	3: servicecheck.ServiceCheckUnknown,
+}
+
+var invalidNameChars = regexp.MustCompile(`[^a-zA-Z0-9_.]+`)
+
+// For testing purpose
+var (
+	commandContext = secrets.CommandContext
+	checkRights    = secrets.CheckRights
+)
+
+type instanceConfig struct {
+	Command          string   `yaml:"command"`
+	Args             []string `yaml:"args"`
+	Timeout          int      `yaml:"timeout"`
+	AllowGroupExec   bool     `yaml:"allow_group_exec"`
+	ServiceCheckName string   `yaml:"service_check_name"`
+	MetricPrefix     string   `yaml:"metric_prefix"`
+}
+
+// Check runs a Nagios plugin
+type Check struct {
+	core.CheckBase
+	config  instanceConfig
+	timeout time.Duration
+}
+
+// Configure parses the check configuration and init the check
+func (c *Check) Configure(integrationConfigDigest uint64, rawInstance integration.Data, rawInitConfig integration.Data, source string) error {
+	// Must be called before CommonConfigure that uses checkID
+	c.BuildID(integrationConfigDigest, rawInstance, rawInitConfig)
+
+	if err := c.CommonConfigure(integrationConfigDigest, rawInitConfig, rawInstance, source); err != nil {
+		return err
+	}
+
+	conf := instanceConfig{Timeout: defaultTimeout}
+	if err := yaml.Unmarshal(rawInstance, &conf); err != nil {
+		return err
+	}
+	if conf.Command == "" {
+		return errors.New("instance config `command` must not be empty")
+	}
+	if conf.Timeout <= 0 {
+		return errors.New("instance config `timeout` must be greater than 0")
+	}
+
+	plugin := normalizeName(strings.TrimSuffix(filepath.Base(conf.Command), filepath.Ext(conf.Command)))
+	if conf.ServiceCheckName == "" {
+		conf.ServiceCheckName = checkName + "." + plugin
+	}
+	if conf.MetricPrefix == "" {
+		conf.MetricPrefix = checkName + "." + plugin
+	}
+
+	c.config = conf
+	c.timeout = time.Duration(conf.Timeout) * time.Second
+	return nil
+}
+
+// Run runs the plugin and submits its results
+func (c *Check) Run() error {
+	return c.RunContext(context.Background())
+}
+
+// RunContext runs the plugin and submits its results, killing the plugin once
+// ctx is cancelled
+func (c *Check) RunContext(ctx context.Context) error {
+	sender, err := c.GetSender()
+	if err != nil {
+		return err
+	}
+	defer sender.Commit()
+
+	exitCode, output, err := c.runPlugin(ctx)
+	if err != nil {
+		sender.ServiceCheck(c.config.ServiceCheckName, errorStatus(err), "", nil, err.Error())
+		return err
+	}
+
+	message, perf := parseOutput(output)
+	c.submitPerfData(sender, perf)
+
+	status, found := exitCodeStatus[exitCode]
+	if !found {
+		err := fmt.Errorf("return code of %d is out of bounds", exitCode)
+		sender.ServiceCheck(c.config.ServiceCheckName, servicecheck.ServiceCheckUnknown, "", nil, fmt.Sprintf("%s: %s", err, message))
+		return err
+	}
+	sender.ServiceCheck(c.config.ServiceCheckName, status, "", nil, message)
+	return nil
+}
+
+// errTimeout is returned when the plugin runs longer than the timeout
+var errTimeout = errors.New("plugin timed out")
+
+// errorStatus returns the status of the service check sent when the plugin
+// couldn't run: timeouts are critical, like in Nagios, and other errors unknown.
+func errorStatus(err error) servicecheck.ServiceCheckStatus {
+	if errors.Is(err, errTimeout) {
+		return servicecheck.ServiceCheckCritical
+	}
+	return servicecheck.ServiceCheckUnknown
+}
+
+// runPlugin runs the plugin and returns its exit code and its standard output
+func (c *Check) runPlugin(parent context.Context) (int, string, error) {
+	ctx, cancel := context.WithTimeout(parent, c.timeout)
+	defer cancel()
+
+	cmd, done, err := commandContext(ctx, c.config.Command, c.config.Args...)
+	if err != nil {
+		return 0, "", err
+	}
+	defer done()
+
+	if err := checkRights(cmd.Path, c.config.AllowGroupExec); err != nil {
+		return 0, "", err
+	}
+
+	stdout := &truncatingBuffer{max: maxOutputSize}
+	stderr := &truncatingBuffer{max: maxOutputSize}
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+
+	err = cmd.Run()
+	if stderr.Len() > 0 {
+		log.Debugf("nagios plugin %s stderr: %s", c.config.Command, stderr.String())
+	}
+
+	if parent.Err() != nil {
+		return 0, "", fmt.Errorf("plugin was interrupted: %v", parent.Err())
+	}
+	if ctx.Err() == context.DeadlineExceeded {
+		return 0, "", fmt.Errorf("%w after %s", errTimeout, c.timeout)
+	}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.ExitCode(), stdout.String(), nil
+	}
+	if err != nil {
+		return 0, "", fmt.Errorf("error while running '%s': %s", c.config.Command, err)
+	}
+	return 0, stdout.String(), nil
+}
+
+// submitPerfData submits the performance data as gauges, tagged with their
+// unit of measurement and their thresholds
+func (c *Check) submitPerfData(sender sender.Sender, perf []perfData) {
+	for _, p := range perf {
+		var tags []string
+		for _, tag := range []struct{ key, value string }{
+			{"unit", p.unit},
+			{"warn", p.warn},
+			{"crit", p.crit},
+			{"min", p.min},
+			{"max", p.max},
+		} {
+			if tag.value != "" {
+				tags = append(tags, tag.key+":"+tag.value)
+			}
+		}
+		sender.Gauge(c.config.MetricPrefix+"."+normalizeName(p.label), p.value, "", tags)
+	}
+}
+
+func normalizeName(name string) string {
+	return strings.Trim(invalidNameChars.ReplaceAllString(strings.ToLower(name), "_"), "_.")
+}
+
+// truncatingBuffer is a buffer silently dropping what is written past its max size
+type truncatingBuffer struct {
+	bytes.Buffer
+	max int
+}
+
+func (b *truncatingBuffer) Write(p []byte) (int, error) {
+	if room := b.max - b.Len(); len(p) > room {
+		b.Buffer.Write(p[:room]) //nolint:errcheck
+	} else {
+		b.Buffer.Write(p) //nolint:errcheck
+	}
+	return len(p), nil
+}
+
+func nagiosFactory() check.Check {
+	return &Check{
+		CheckBase: core.NewCheckBase(checkName),
+	}
+}
+
+func init() {
+	core.RegisterCheck(checkName, nagiosFactory)
+}
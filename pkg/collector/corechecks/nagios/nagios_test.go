@@ -0,0 +1,106 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build !windows
+
+package nagios
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator/mocksender"
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+	"github.com/DataDog/datadog-agent/pkg/metrics/servicecheck"
+)
+
+func writePlugin(t *testing.T, script string) string {
+	path := filepath.Join(t.TempDir(), "check_test.sh")
+	require.NoError(t, os.WriteFile(path, []byte("#!/bin/sh\n"+script), 0700))
+	return path
+}
+
+func runCheck(t *testing.T, instance string) (*mocksender.MockSender, error) {
+	defer func(f func(string, bool) error) { checkRights = f }(checkRights)
+	checkRights = func(string, bool) error { return nil }
+
+	c := nagiosFactory()
+	mock := mocksender.NewMockSender(c.ID())
+	mock.SetupAcceptAll()
+	require.NoError(t, c.Configure(integration.FakeConfigHash, integration.Data(instance), nil, "test"))
+	mocksender.SetSender(mock, c.ID())
+
+	err := c.Run()
+	mock.AssertNumberOfCalls(t, "Commit", 1)
+	return mock, err
+}
+
+func TestRun(t *testing.T) {
+	plugin := writePlugin(t, `echo "LOAD WARNING - load average: 5.1 | load1=5.1;5;10;0"; exit 1`)
+
+	mock, err := runCheck(t, fmt.Sprintf("command: %s", plugin))
+	require.NoError(t, err)
+	mock.AssertMetric(t, "Gauge", "nagios.check_test.load1", 5.1, "", []string{"warn:5", "crit:10", "min:0"})
+	mock.AssertServiceCheck(t, "nagios.check_test", servicecheck.ServiceCheckWarning, "", nil, "LOAD WARNING - load average: 5.1")
+}
+
+func TestRunOptions(t *testing.T) {
+	plugin := writePlugin(t, `echo "OK - $1 | time=$2s"`)
+
+	mock, err := runCheck(t, fmt.Sprintf(`
+command: %s
+args: ["up", "0.5"]
+service_check_name: custom.status
+metric_prefix: custom`, plugin))
+	require.NoError(t, err)
+	mock.AssertMetric(t, "Gauge", "custom.time", 0.5, "", []string{"unit:s"})
+	mock.AssertServiceCheck(t, "custom.status", servicecheck.ServiceCheckOK, "", nil, "OK - up")
+}
+
+func TestRunExitCodeOutOfBounds(t *testing.T) {
+	plugin := writePlugin(t, `echo "not found"; exit 127`)
+
+	mock, err := runCheck(t, fmt.Sprintf("command: %s", plugin))
+	assert.EqualError(t, err, "return code of 127 is out of bounds")
+	mock.AssertServiceCheck(t, "nagios.check_test", servicecheck.ServiceCheckUnknown, "", nil, "return code of 127 is out of bounds: not found")
+}
+
+func TestRunTimeout(t *testing.T) {
+	plugin := writePlugin(t, `exec sleep 10`)
+
+	mock, err := runCheck(t, fmt.Sprintf("command: %s\ntimeout: 1", plugin))
+	assert.EqualError(t, err, "plugin timed out after 1s")
+	mock.AssertServiceCheck(t, "nagios.check_test", servicecheck.ServiceCheckCritical, "", nil, "plugin timed out after 1s")
+}
+
+func TestRunInvalidRights(t *testing.T) {
+	plugin := writePlugin(t, `echo "OK"`)
+
+	c := nagiosFactory()
+	mock := mocksender.NewMockSender(c.ID())
+	mock.SetupAcceptAll()
+	require.NoError(t, c.Configure(integration.FakeConfigHash, integration.Data(fmt.Sprintf("command: %s", plugin)), nil, "test"))
+	mocksender.SetSender(mock, c.ID())
+
+	defer func(f func(string, bool) error) { checkRights = f }(checkRights)
+	checkRights = func(path string, _ bool) error { return errors.New("invalid executable " + path) }
+
+	assert.EqualError(t, c.Run(), "invalid executable "+plugin)
+	mock.AssertServiceCheck(t, "nagios.check_test", servicecheck.ServiceCheckUnknown, "", nil, "invalid executable "+plugin)
+}
+
+func TestConfigureErrors(t *testing.T) {
+	for _, instance := range []string{"", "command: foo\ntimeout: -1"} {
+		c := nagiosFactory()
+		mocksender.NewMockSender(c.ID()).SetupAcceptAll()
+		assert.Error(t, c.Configure(integration.FakeConfigHash, integration.Data(instance), nil, "test"))
+	}
+}
@@ -0,0 +1,132 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package nagios
+
+import (
+	"strconv"
+	"strings"
+)
+
+// perfData is a value of the performance data of a plugin, formatted as
+// 'label'=value[UOM];[warn];[crit];[min];[max]
+type perfData struct {
+	label string
+	value float64
+	unit  string
+	warn  string
+	crit  string
+	min   string
+	max   string
+}
+
+// parseOutput splits the output of a plugin into its text and its performance data.
+//
+// The first line holds the short text, optionally followed by `|` and performance
+// data. The following lines hold the long text, and the performance data continue
+// from the first one of them containing a `|` to the end of the output.
+func parseOutput(output string) (string, []perfData) {
+	lines := strings.Split(strings.TrimRight(output, "\n"), "\n")
+
+	var text, perf []string
+	short, shortPerf, _ := strings.Cut(lines[0], "|")
+	text = append(text, strings.TrimSpace(short))
+	perf = append(perf, shortPerf)
+
+	inPerf := false
+	for _, line := range lines[1:] {
+		if inPerf {
+			perf = append(perf, line)
+			continue
+		}
+		if long, longPerf, found := strings.Cut(line, "|"); found {
+			inPerf = true
+			line = long
+			perf = append(perf, longPerf)
+		}
+		text = append(text, line)
+	}
+
+	return strings.TrimSpace(strings.Join(text, "\n")), parsePerfData(strings.Join(perf, " "))
+}
+
+// parsePerfData parses space-separated performance data values. Invalid values,
+// and the ones whose value is undetermined (`U`), are skipped.
+func parsePerfData(perf string) []perfData {
+	var values []perfData
+	for _, field := range splitPerfData(perf) {
+		if p, ok := parsePerfDataValue(field); ok {
+			values = append(values, p)
+		}
+	}
+	return values
+}
+
+// splitPerfData splits performance data on spaces, except in quoted labels
+func splitPerfData(perf string) []string {
+	var fields []string
+	var field strings.Builder
+	quoted := false
+	for _, r := range perf {
+		switch {
+		case r == '\'':
+			quoted = !quoted
+			field.WriteRune(r)
+		case (r == ' ' || r == '\t') && !quoted:
+			if field.Len() > 0 {
+				fields = append(fields, field.String())
+				field.Reset()
+			}
+		default:
+			field.WriteRune(r)
+		}
+	}
+	if field.Len() > 0 {
+		fields = append(fields, field.String())
+	}
+	return fields
+}
+
+func parsePerfDataValue(field string) (perfData, bool) {
+	idx := strings.LastIndex(field, "=")
+	if idx <= 0 {
+		return perfData{}, false
+	}
+
+	label := field[:idx]
+	if len(label) >= 2 && label[0] == '\'' && label[len(label)-1] == '\'' {
+		// quotes in quoted labels are escaped by doubling them
+		label = strings.ReplaceAll(label[1:len(label)-1], "''", "'")
+	}
+	if label == "" {
+		return perfData{}, false
+	}
+
+	parts := strings.Split(field[idx+1:], ";")
+	value, unit := splitUnit(parts[0])
+	v, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return perfData{}, false
+	}
+
+	p := perfData{label: label, value: v, unit: unit}
+	for i, threshold := range []*string{&p.warn, &p.crit, &p.min, &p.max} {
+		if i+1 < len(parts) {
+			*threshold = parts[i+1]
+		}
+	}
+	return p, true
+}
+
+// splitUnit splits a value from its unit of measurement
+func splitUnit(s string) (string, string) {
+	idx := strings.IndexFunc(s, func(r rune) bool {
+		return !(r >= '0' && r <= '9' || r == '.' || r == '-' || r == '+' || r == 'e' || r == 'E')
+	})
+	if idx < 0 {
+		return s, ""
+	}
+	return s[:idx], s[idx:]
+}
@@ -0,0 +1,65 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package nagios
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseOutput(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		output   string
+		message  string
+		perfData []perfData
+	}{
+		{
+			name:    "text only",
+			output:  "OK - all good\n",
+			message: "OK - all good",
+		},
+		{
+			name:    "short perfdata",
+			output:  "DISK OK - free space: / 3326 MB (56%); | /=2643MB;5948;5958;0;5968\n",
+			message: "DISK OK - free space: / 3326 MB (56%);",
+			perfData: []perfData{
+				{label: "/", value: 2643, unit: "MB", warn: "5948", crit: "5958", min: "0", max: "5968"},
+			},
+		},
+		{
+			name: "long text and perfdata",
+			output: "DISK OK - free space: / 3326 MB (56%); | /=2643MB;5948;5958;0;5968\n" +
+				"/ 15272 MB (77%);\n" +
+				"/boot 68 MB (69%);\n" +
+				"/home 69357 MB (27%); | /boot=68MB;88;93;0;98\n" +
+				"/home=69357MB;253404;253409;0;253414\n",
+			message: "DISK OK - free space: / 3326 MB (56%);\n/ 15272 MB (77%);\n/boot 68 MB (69%);\n/home 69357 MB (27%);",
+			perfData: []perfData{
+				{label: "/", value: 2643, unit: "MB", warn: "5948", crit: "5958", min: "0", max: "5968"},
+				{label: "/boot", value: 68, unit: "MB", warn: "88", crit: "93", min: "0", max: "98"},
+				{label: "/home", value: 69357, unit: "MB", warn: "253404", crit: "253409", min: "0", max: "253414"},
+			},
+		},
+		{
+			name:    "quoted labels, ranges and invalid values",
+			output:  "PING WARNING | 'rta time'=1.5ms;@10:20;~:30 'it''s'=-2.5e1% pl=U;1;2 invalid =3 loss=0%\n",
+			message: "PING WARNING",
+			perfData: []perfData{
+				{label: "rta time", value: 1.5, unit: "ms", warn: "@10:20", crit: "~:30"},
+				{label: "it's", value: -25, unit: "%"},
+				{label: "loss", value: 0, unit: "%"},
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			message, perfData := parseOutput(tc.output)
+			assert.Equal(t, tc.message, message)
+			assert.Equal(t, tc.perfData, perfData)
+		})
+	}
+}
@@ -0,0 +1,376 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package net
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptrace"
+	"regexp"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator/sender"
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+	"github.com/DataDog/datadog-agent/pkg/collector/check"
+	core "github.com/DataDog/datadog-agent/pkg/collector/corechecks"
+	"github.com/DataDog/datadog-agent/pkg/metrics/servicecheck"
+	httputils "github.com/DataDog/datadog-agent/pkg/util/http"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// The check is named after the Python integration it replaces on the builds
+// without Python: the Python loader takes precedence when both are available.
+const (
+	httpCheckName = "http_check"
+
+	defaultHTTPTimeout           = 10 // in seconds
+	defaultHTTPStatusCode        = `(1|2|3)\d\d`
+	defaultHTTPCertDaysWarning   = 14
+	defaultHTTPCertDaysCritical  = 7
+	maxHTTPContentLength         = 10 * 1024 * 1024 // to match the content against
+	maxHTTPIncludedContentLength = 200              // in the service check message
+	httpCanConnectServiceCheck   = "http.can_connect"
+	httpSSLCertServiceCheck      = "http.ssl_cert"
+	httpMetricPrefix             = "network.http."
+	httpSSLMetricPrefix          = "http.ssl." // the certificate metrics are not prefixed by the Python check
+	httpTimingMetricPrefix       = httpMetricPrefix + "timing."
+)
+
+// HTTPCheck checks the availability of an HTTP(S) endpoint, and the expiration
+// of its TLS certificate
+type HTTPCheck struct {
+	core.CheckBase
+	cfg    *httpConfig
+	client *http.Client
+}
+
+type httpInstanceConfig struct {
+	Name                       string            `yaml:"name"`
+	URL                        string            `yaml:"url"`
+	Method                     string            `yaml:"method"`
+	Headers                    map[string]string `yaml:"headers"`
+	Data                       string            `yaml:"data"`
+	Timeout                    float64           `yaml:"timeout"`
+	ContentMatch               string            `yaml:"content_match"`
+	ReverseContentMatch        bool              `yaml:"reverse_content_match"`
+	HTTPResponseStatusCode     string            `yaml:"http_response_status_code"`
+	IncludeContent             bool              `yaml:"include_content"`
+	TLSVerify                  bool              `yaml:"tls_verify"`
+	AllowRedirects             bool              `yaml:"allow_redirects"`
+	SkipProxy                  bool              `yaml:"skip_proxy"`
+	CheckCertificateExpiration bool              `yaml:"check_certificate_expiration"`
+	DaysWarning                float64           `yaml:"days_warning"`
+	DaysCritical               float64           `yaml:"days_critical"`
+	SecondsWarning             float64           `yaml:"seconds_warning"`
+	SecondsCritical            float64           `yaml:"seconds_critical"`
+	CollectResponseTime        bool              `yaml:"collect_response_time"`
+	CollectTimingBreakdown     bool              `yaml:"collect_timing_breakdown"`
+}
+
+type httpConfig struct {
+	instance     httpInstanceConfig
+	timeout      time.Duration
+	statusCode   *regexp.Regexp
+	contentMatch *regexp.Regexp
+	certWarning  time.Duration
+	certCritical time.Duration
+	tags         []string
+}
+
+func (c *HTTPCheck) String() string {
+	return httpCheckName
+}
+
+func (c *httpConfig) parse(data []byte) error {
+	instance := httpInstanceConfig{
+		TLSVerify:                  true,
+		AllowRedirects:             true,
+		CheckCertificateExpiration: true,
+		CollectResponseTime:        true,
+	}
+
+	if err := yaml.Unmarshal(data, &instance); err != nil {
+		return err
+	}
+
+	if instance.Name == "" {
+		return errors.New("the name of the instance is required")
+	}
+	if instance.URL == "" {
+		return errors.New("the url of the instance is required")
+	}
+	if !strings.HasPrefix(instance.URL, "http://") && !strings.HasPrefix(instance.URL, "https://") {
+		instance.URL = "http://" + instance.URL
+	}
+	if instance.Method == "" {
+		instance.Method = http.MethodGet
+	}
+	instance.Method = strings.ToUpper(instance.Method)
+
+	if instance.Timeout <= 0 {
+		instance.Timeout = defaultHTTPTimeout
+	}
+	c.timeout = time.Duration(instance.Timeout * float64(time.Second))
+
+	statusCode := instance.HTTPResponseStatusCode
+	if statusCode == "" {
+		statusCode = defaultHTTPStatusCode
+	}
+	var err error
+	if c.statusCode, err = regexp.Compile("^(?:" + statusCode + ")$"); err != nil {
+		return fmt.Errorf("invalid http_response_status_code %q: %s", statusCode, err)
+	}
+	if instance.ContentMatch != "" {
+		if c.contentMatch, err = regexp.Compile(instance.ContentMatch); err != nil {
+			return fmt.Errorf("invalid content_match %q: %s", instance.ContentMatch, err)
+		}
+	}
+
+	// the thresholds in seconds take precedence over the ones in days
+	c.certWarning = time.Duration(defaultHTTPCertDaysWarning*24) * time.Hour
+	if instance.SecondsWarning > 0 {
+		c.certWarning = time.Duration(instance.SecondsWarning * float64(time.Second))
+	} else if instance.DaysWarning > 0 {
+		c.certWarning = time.Duration(instance.DaysWarning * 24 * float64(time.Hour))
+	}
+	c.certCritical = time.Duration(defaultHTTPCertDaysCritical*24) * time.Hour
+	if instance.SecondsCritical > 0 {
+		c.certCritical = time.Duration(instance.SecondsCritical * float64(time.Second))
+	} else if instance.DaysCritical > 0 {
+		c.certCritical = time.Duration(instance.DaysCritical * 24 * float64(time.Hour))
+	}
+
+	// the tags of the instance are added by the sender
+	c.tags = []string{"url:" + instance.URL, "instance:" + instance.Name}
+	c.instance = instance
+
+	return nil
+}
+
+// Configure configures the check from the yaml
+func (c *HTTPCheck) Configure(integrationConfigDigest uint64, data integration.Data, initConfig integration.Data, source string) error {
+	cfg := new(httpConfig)
+	if err := cfg.parse(data); err != nil {
+		log.Errorf("Error parsing configuration file: %s", err)
+		return err
+	}
+
+	c.BuildID(integrationConfigDigest, data, initConfig)
+	c.cfg = cfg
+	c.client = newHTTPCheckClient(cfg)
+
+	return c.CommonConfigure(integrationConfigDigest, initConfig, data, source)
+}
+
+func newHTTPCheckClient(cfg *httpConfig) *http.Client {
+	transport := httputils.CreateHTTPTransport()
+	transport.TLSClientConfig.InsecureSkipVerify = !cfg.instance.TLSVerify
+	// measure a full connection, DNS resolution and TLS handshake included, on every run
+	transport.DisableKeepAlives = true
+	if cfg.instance.SkipProxy {
+		transport.Proxy = nil
+	}
+
+	client := &http.Client{
+		Transport: transport,
+		Timeout:   cfg.timeout,
+	}
+	if !cfg.instance.AllowRedirects {
+		client.CheckRedirect = func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		}
+	}
+	return client
+}
+
+// httpTimings holds the duration of each phase of a request
+type httpTimings struct {
+	dns     time.Duration
+	connect time.Duration
+	tls     time.Duration
+	ttfb    time.Duration
+}
+
+func traceHTTPTimings(ctx context.Context, start time.Time, timings *httpTimings) context.Context {
+	var dnsStart, connectStart, tlsStart time.Time
+	return httptrace.WithClientTrace(ctx, &httptrace.ClientTrace{
+		DNSStart:          func(httptrace.DNSStartInfo) { dnsStart = time.Now() },
+		DNSDone:           func(httptrace.DNSDoneInfo) { timings.dns = time.Since(dnsStart) },
+		ConnectStart:      func(string, string) { connectStart = time.Now() },
+		ConnectDone:       func(string, string, error) { timings.connect = time.Since(connectStart) },
+		TLSHandshakeStart: func() { tlsStart = time.Now() },
+		TLSHandshakeDone: func(tls.ConnectionState, error) {
+			timings.tls = time.Since(tlsStart)
+		},
+		GotFirstResponseByte: func() { timings.ttfb = time.Since(start) },
+	})
+}
+
+// Run runs the check
+func (c *HTTPCheck) Run() error {
+	sender, err := c.GetSender()
+	if err != nil {
+		return err
+	}
+
+	status, message, tlsState, reqErr := c.checkEndpoint(sender)
+
+	sender.ServiceCheck(httpCanConnectServiceCheck, status, "", c.cfg.tags, message)
+	canConnect := 0.0
+	if status == servicecheck.ServiceCheckOK {
+		canConnect = 1
+	}
+	sender.Gauge(httpMetricPrefix+"can_connect", canConnect, "", c.cfg.tags)
+	sender.Gauge(httpMetricPrefix+"cant_connect", 1-canConnect, "", c.cfg.tags)
+
+	if c.cfg.instance.CheckCertificateExpiration && strings.HasPrefix(c.cfg.instance.URL, "https://") {
+		c.checkCertificate(sender, tlsState, reqErr)
+	}
+
+	sender.Commit()
+	return nil
+}
+
+// checkEndpoint sends the request and returns the status of the endpoint, with
+// the TLS state of the connection if one was established or the error of the
+// request if it failed
+func (c *HTTPCheck) checkEndpoint(sender sender.Sender) (servicecheck.ServiceCheckStatus, string, *tls.ConnectionState, error) {
+	instance := c.cfg.instance
+
+	var body io.Reader
+	if instance.Data != "" {
+		body = strings.NewReader(instance.Data)
+	}
+	start := time.Now()
+	var timings httpTimings
+	ctx := traceHTTPTimings(context.Background(), start, &timings)
+
+	req, err := http.NewRequestWithContext(ctx, instance.Method, instance.URL, body)
+	if err != nil {
+		return servicecheck.ServiceCheckCritical, err.Error(), nil, err
+	}
+	for name, value := range instance.Headers {
+		if strings.EqualFold(name, "host") {
+			req.Host = value
+			continue
+		}
+		req.Header.Set(name, value)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		log.Debugf("%s: request to %s failed: %s", c.ID(), instance.URL, err)
+		return servicecheck.ServiceCheckCritical, err.Error(), nil, err
+	}
+	defer resp.Body.Close()
+	content, err := io.ReadAll(io.LimitReader(resp.Body, maxHTTPContentLength))
+	elapsed := time.Since(start)
+	if err != nil {
+		return servicecheck.ServiceCheckCritical, fmt.Sprintf("Unable to read the response of %s: %s", instance.URL, err), resp.TLS, nil
+	}
+
+	if instance.CollectResponseTime {
+		sender.Gauge(httpMetricPrefix+"response_time", elapsed.Seconds(), "", c.cfg.tags)
+	}
+	if instance.CollectTimingBreakdown {
+		sender.Gauge(httpTimingMetricPrefix+"dns", timings.dns.Seconds(), "", c.cfg.tags)
+		sender.Gauge(httpTimingMetricPrefix+"connect", timings.connect.Seconds(), "", c.cfg.tags)
+		sender.Gauge(httpTimingMetricPrefix+"tls", timings.tls.Seconds(), "", c.cfg.tags)
+		sender.Gauge(httpTimingMetricPrefix+"ttfb", timings.ttfb.Seconds(), "", c.cfg.tags)
+	}
+
+	if !c.cfg.statusCode.MatchString(fmt.Sprint(resp.StatusCode)) {
+		message := fmt.Sprintf("Incorrect HTTP return code for url %s. Expected %s, got %d.",
+			instance.URL, c.cfg.statusCode.String(), resp.StatusCode)
+		return servicecheck.ServiceCheckCritical, c.withContent(message, content), resp.TLS, nil
+	}
+
+	if c.cfg.contentMatch != nil {
+		found := c.cfg.contentMatch.Match(content)
+		if !found && !instance.ReverseContentMatch {
+			message := fmt.Sprintf("Content %q not found in response.", instance.ContentMatch)
+			return servicecheck.ServiceCheckCritical, c.withContent(message, content), resp.TLS, nil
+		}
+		if found && instance.ReverseContentMatch {
+			message := fmt.Sprintf("Content %q found in response.", instance.ContentMatch)
+			return servicecheck.ServiceCheckCritical, c.withContent(message, content), resp.TLS, nil
+		}
+	}
+
+	return servicecheck.ServiceCheckOK, "", resp.TLS, nil
+}
+
+// withContent appends the beginning of the response to the message when
+// include_content is set
+func (c *HTTPCheck) withContent(message string, content []byte) string {
+	if !c.cfg.instance.IncludeContent {
+		return message
+	}
+	if len(content) > maxHTTPIncludedContentLength {
+		content = content[:maxHTTPIncludedContentLength]
+	}
+	return message + "\nContent: " + string(content)
+}
+
+// checkCertificate reports the expiration of the certificate presented by the
+// endpoint. When the handshake failed because of the certificate, the error of
+// the request is reported instead.
+func (c *HTTPCheck) checkCertificate(sender sender.Sender, tlsState *tls.ConnectionState, requestErr error) {
+	if tlsState == nil || len(tlsState.PeerCertificates) == 0 {
+		if isCertificateError(requestErr) {
+			sender.ServiceCheck(httpSSLCertServiceCheck, servicecheck.ServiceCheckCritical, "", c.cfg.tags, requestErr.Error())
+		} else {
+			sender.ServiceCheck(httpSSLCertServiceCheck, servicecheck.ServiceCheckUnknown, "", c.cfg.tags, "Unable to get the certificate of the endpoint")
+		}
+		return
+	}
+
+	left := time.Until(tlsState.PeerCertificates[0].NotAfter)
+	sender.Gauge(httpSSLMetricPrefix+"days_left", left.Hours()/24, "", c.cfg.tags)
+	sender.Gauge(httpSSLMetricPrefix+"seconds_left", left.Seconds(), "", c.cfg.tags)
+
+	status := servicecheck.ServiceCheckOK
+	message := ""
+	switch {
+	case left <= 0:
+		status = servicecheck.ServiceCheckCritical
+		message = "Certificate has expired"
+	case left < c.cfg.certCritical:
+		status = servicecheck.ServiceCheckCritical
+		message = fmt.Sprintf("Certificate expires in %.0f days", left.Hours()/24)
+	case left < c.cfg.certWarning:
+		status = servicecheck.ServiceCheckWarning
+		message = fmt.Sprintf("Certificate expires in %.0f days", left.Hours()/24)
+	}
+	sender.ServiceCheck(httpSSLCertServiceCheck, status, "", c.cfg.tags, message)
+}
+
+// isCertificateError returns whether a request failed because of the
+// certificate presented by the server
+func isCertificateError(err error) bool {
+	var invalidErr x509.CertificateInvalidError
+	var authorityErr x509.UnknownAuthorityError
+	var hostnameErr x509.HostnameError
+	return errors.As(err, &invalidErr) || errors.As(err, &authorityErr) || errors.As(err, &hostnameErr)
+}
+
+func httpCheckFactory() check.Check {
+	return &HTTPCheck{
+		CheckBase: core.NewCheckBase(httpCheckName),
+	}
+}
+
+func init() {
+	core.RegisterCheck(httpCheckName, httpCheckFactory)
+}
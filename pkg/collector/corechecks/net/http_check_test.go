@@ -0,0 +1,191 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package net
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator/mocksender"
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+	"github.com/DataDog/datadog-agent/pkg/metrics/servicecheck"
+)
+
+// newHTTPTestServer returns a server answering with the status code and the
+// body of its path, and echoing the method, the X-Test header and the body
+// of the requests
+func newHTTPTestServer(t *testing.T, useTLS bool) *httptest.Server {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if r.URL.Path == "/error" {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+		fmt.Fprintf(w, "method=%s header=%s body=%s status=ok", r.Method, r.Header.Get("X-Test"), body)
+	})
+
+	var server *httptest.Server
+	if useTLS {
+		server = httptest.NewTLSServer(handler)
+	} else {
+		server = httptest.NewServer(handler)
+	}
+	t.Cleanup(server.Close)
+	return server
+}
+
+func runHTTPCheck(t *testing.T, config string) *mocksender.MockSender {
+	check := httpCheckFactory().(*HTTPCheck)
+	require.NoError(t, check.Configure(integration.FakeConfigHash, []byte(config), nil, "test"))
+
+	mockSender := mocksender.NewMockSender(check.ID())
+	mockSender.SetupAcceptAll()
+	require.NoError(t, check.Run())
+	return mockSender
+}
+
+func TestHTTPCheckOK(t *testing.T) {
+	server := newHTTPTestServer(t, false)
+	url := server.URL + "/ok"
+	tags := []string{"url:" + url, "instance:test"}
+
+	mockSender := runHTTPCheck(t, fmt.Sprintf(`
+name: test
+url: %s
+method: put
+headers:
+  X-Test: foo
+data: payload
+content_match: "method=PUT header=foo body=payload"
+collect_timing_breakdown: true
+`, url))
+
+	mockSender.AssertServiceCheck(t, "http.can_connect", servicecheck.ServiceCheckOK, "", tags, "")
+	mockSender.AssertMetric(t, "Gauge", "network.http.can_connect", 1, "", tags)
+	mockSender.AssertMetric(t, "Gauge", "network.http.cant_connect", 0, "", tags)
+	mockSender.AssertMetricInRange(t, "Gauge", "network.http.response_time", 0, 10, "", tags)
+	for _, phase := range []string{"dns", "connect", "tls", "ttfb"} {
+		mockSender.AssertMetricInRange(t, "Gauge", "network.http.timing."+phase, 0, 10, "", tags)
+	}
+	// the certificate isn't checked for plain http
+	mockSender.AssertNotCalled(t, "ServiceCheck", "http.ssl_cert", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	mockSender.AssertNumberOfCalls(t, "Commit", 1)
+}
+
+func TestHTTPCheckCritical(t *testing.T) {
+	server := newHTTPTestServer(t, false)
+
+	tests := []struct {
+		name            string
+		config          string
+		url             string
+		expectedMessage string
+	}{
+		{
+			name:            "unexpected status code",
+			url:             server.URL + "/error",
+			expectedMessage: "Incorrect HTTP return code for url " + server.URL + "/error. Expected ^(?:(1|2|3)\\d\\d)$, got 500.",
+		},
+		{
+			name:            "content not found",
+			url:             server.URL + "/ok",
+			config:          "content_match: status=ko",
+			expectedMessage: `Content "status=ko" not found in response.`,
+		},
+		{
+			name:            "content found with reverse match",
+			url:             server.URL + "/ok",
+			config:          "content_match: status=ok\nreverse_content_match: true\ninclude_content: true",
+			expectedMessage: "Content \"status=ok\" found in response.\nContent: method=GET header= body= status=ok",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tags := []string{"url:" + tt.url, "instance:test"}
+			mockSender := runHTTPCheck(t, fmt.Sprintf("name: test\nurl: %s\n%s", tt.url, tt.config))
+
+			mockSender.AssertServiceCheck(t, "http.can_connect", servicecheck.ServiceCheckCritical, "", tags, tt.expectedMessage)
+			mockSender.AssertMetric(t, "Gauge", "network.http.can_connect", 0, "", tags)
+			mockSender.AssertMetric(t, "Gauge", "network.http.cant_connect", 1, "", tags)
+		})
+	}
+}
+
+func TestHTTPCheckUnreachable(t *testing.T) {
+	server := newHTTPTestServer(t, false)
+	url := server.URL + "/ok"
+	server.Close()
+	tags := []string{"url:" + url, "instance:test"}
+
+	mockSender := runHTTPCheck(t, fmt.Sprintf("name: test\nurl: %s\ntimeout: 1", url))
+
+	mockSender.AssertCalled(t, "ServiceCheck", "http.can_connect", servicecheck.ServiceCheckCritical, "", tags, mock.AnythingOfType("string"))
+	mockSender.AssertMetric(t, "Gauge", "network.http.cant_connect", 1, "", tags)
+	mockSender.AssertNotCalled(t, "Gauge", "network.http.response_time", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestHTTPCheckCertificate(t *testing.T) {
+	server := newHTTPTestServer(t, true)
+	url := server.URL + "/ok"
+	tags := []string{"url:" + url, "instance:test"}
+
+	t.Run("valid", func(t *testing.T) {
+		mockSender := runHTTPCheck(t, fmt.Sprintf("name: test\nurl: %s\ntls_verify: false", url))
+
+		mockSender.AssertServiceCheck(t, "http.can_connect", servicecheck.ServiceCheckOK, "", tags, "")
+		mockSender.AssertServiceCheck(t, "http.ssl_cert", servicecheck.ServiceCheckOK, "", tags, "")
+		mockSender.AssertMetricInRange(t, "Gauge", "http.ssl.days_left", 30, 100*365, "", tags)
+		mockSender.AssertMetricInRange(t, "Gauge", "http.ssl.seconds_left", 30*86400, 100*365*86400, "", tags)
+	})
+
+	t.Run("expiring", func(t *testing.T) {
+		// the certificate of the test server expires in 2084
+		mockSender := runHTTPCheck(t, fmt.Sprintf("name: test\nurl: %s\ntls_verify: false\ndays_warning: 100000\ndays_critical: 10", url))
+
+		mockSender.AssertCalled(t, "ServiceCheck", "http.ssl_cert", servicecheck.ServiceCheckWarning, "", tags, mock.AnythingOfType("string"))
+	})
+
+	t.Run("not verified", func(t *testing.T) {
+		mockSender := runHTTPCheck(t, fmt.Sprintf("name: test\nurl: %s", url))
+
+		mockSender.AssertCalled(t, "ServiceCheck", "http.can_connect", servicecheck.ServiceCheckCritical, "", tags, mock.AnythingOfType("string"))
+		mockSender.AssertCalled(t, "ServiceCheck", "http.ssl_cert", servicecheck.ServiceCheckCritical, "", tags, mock.AnythingOfType("string"))
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		mockSender := runHTTPCheck(t, fmt.Sprintf("name: test\nurl: %s\ntls_verify: false\ncheck_certificate_expiration: false", url))
+
+		mockSender.AssertNotCalled(t, "ServiceCheck", "http.ssl_cert", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestHTTPCheckConfigure(t *testing.T) {
+	for _, tt := range []struct {
+		config      string
+		expectedErr string
+	}{
+		{"url: http://localhost", "the name of the instance is required"},
+		{"name: test", "the url of the instance is required"},
+		{"name: test\nurl: http://localhost\ncontent_match: '('", "invalid content_match \"(\": error parsing regexp: missing closing ): `(`"},
+	} {
+		check := httpCheckFactory()
+		assert.EqualError(t, check.Configure(integration.FakeConfigHash, []byte(tt.config), nil, "test"), tt.expectedErr)
+	}
+
+	var cfg httpConfig
+	require.NoError(t, cfg.parse([]byte("name: test\nurl: localhost:8080\nseconds_warning: 60\ndays_critical: 1")))
+	assert.Equal(t, "http://localhost:8080", cfg.instance.URL)
+	assert.Equal(t, "GET", cfg.instance.Method)
+	assert.Equal(t, "1m0s", cfg.certWarning.String())
+	assert.Equal(t, "24h0m0s", cfg.certCritical.String())
+}
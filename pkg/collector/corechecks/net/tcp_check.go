@@ -0,0 +1,142 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package net
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"time"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+	"github.com/DataDog/datadog-agent/pkg/collector/check"
+	core "github.com/DataDog/datadog-agent/pkg/collector/corechecks"
+	"github.com/DataDog/datadog-agent/pkg/metrics/servicecheck"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// The check is named after the Python integration it replaces on the builds
+// without Python: the Python loader takes precedence when both are available.
+const (
+	tcpCheckName = "tcp_check"
+
+	defaultTCPTimeout         = 10 // in seconds
+	tcpCanConnectServiceCheck = "tcp.can_connect"
+)
+
+// TCPCheck checks that a TCP port accepts connections
+type TCPCheck struct {
+	core.CheckBase
+	cfg *tcpConfig
+}
+
+type tcpInstanceConfig struct {
+	Name                string  `yaml:"name"`
+	Host                string  `yaml:"host"`
+	Port                int     `yaml:"port"`
+	Timeout             float64 `yaml:"timeout"`
+	CollectResponseTime bool    `yaml:"collect_response_time"`
+}
+
+type tcpConfig struct {
+	instance         tcpInstanceConfig
+	address          string
+	timeout          time.Duration
+	tags             []string
+	serviceCheckTags []string
+}
+
+func (c *TCPCheck) String() string {
+	return tcpCheckName
+}
+
+func (c *tcpConfig) parse(data []byte) error {
+	var instance tcpInstanceConfig
+	if err := yaml.Unmarshal(data, &instance); err != nil {
+		return err
+	}
+
+	if instance.Name == "" {
+		return errors.New("the name of the instance is required")
+	}
+	if instance.Host == "" {
+		return errors.New("the host of the instance is required")
+	}
+	if instance.Port <= 0 || instance.Port > 65535 {
+		return fmt.Errorf("invalid port %d", instance.Port)
+	}
+	if instance.Timeout <= 0 {
+		instance.Timeout = defaultTCPTimeout
+	}
+
+	port := strconv.Itoa(instance.Port)
+	c.address = net.JoinHostPort(instance.Host, port)
+	c.timeout = time.Duration(instance.Timeout * float64(time.Second))
+	// the tags of the instance are added by the sender
+	c.tags = []string{"url:" + instance.Host + ":" + port, "instance:" + instance.Name}
+	c.serviceCheckTags = []string{"target_host:" + instance.Host, "port:" + port, "instance:" + instance.Name}
+	c.instance = instance
+
+	return nil
+}
+
+// Configure configures the check from the yaml
+func (c *TCPCheck) Configure(integrationConfigDigest uint64, data integration.Data, initConfig integration.Data, source string) error {
+	cfg := new(tcpConfig)
+	if err := cfg.parse(data); err != nil {
+		log.Errorf("Error parsing configuration file: %s", err)
+		return err
+	}
+
+	c.BuildID(integrationConfigDigest, data, initConfig)
+	c.cfg = cfg
+
+	return c.CommonConfigure(integrationConfigDigest, initConfig, data, source)
+}
+
+// Run runs the check
+func (c *TCPCheck) Run() error {
+	sender, err := c.GetSender()
+	if err != nil {
+		return err
+	}
+
+	start := time.Now()
+	conn, err := net.DialTimeout("tcp", c.cfg.address, c.cfg.timeout)
+	elapsed := time.Since(start)
+
+	if err != nil {
+		log.Debugf("%s: unable to connect to %s: %s", c.ID(), c.cfg.address, err)
+		sender.ServiceCheck(tcpCanConnectServiceCheck, servicecheck.ServiceCheckCritical, "", c.cfg.serviceCheckTags,
+			fmt.Sprintf("Unable to connect to %s: %s", c.cfg.address, err))
+		sender.Gauge("network.tcp.can_connect", 0, "", c.cfg.tags)
+		sender.Commit()
+		return nil
+	}
+	conn.Close()
+
+	sender.ServiceCheck(tcpCanConnectServiceCheck, servicecheck.ServiceCheckOK, "", c.cfg.serviceCheckTags, "")
+	sender.Gauge("network.tcp.can_connect", 1, "", c.cfg.tags)
+	if c.cfg.instance.CollectResponseTime {
+		sender.Gauge("network.tcp.response_time", elapsed.Seconds(), "", c.cfg.tags)
+	}
+	sender.Commit()
+
+	return nil
+}
+
+func tcpCheckFactory() check.Check {
+	return &TCPCheck{
+		CheckBase: core.NewCheckBase(tcpCheckName),
+	}
+}
+
+func init() {
+	core.RegisterCheck(tcpCheckName, tcpCheckFactory)
+}
@@ -0,0 +1,72 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package net
+
+import (
+	"fmt"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator/mocksender"
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+	"github.com/DataDog/datadog-agent/pkg/metrics/servicecheck"
+)
+
+func runTCPCheck(t *testing.T, config string) *mocksender.MockSender {
+	check := tcpCheckFactory().(*TCPCheck)
+	require.NoError(t, check.Configure(integration.FakeConfigHash, []byte(config), nil, "test"))
+
+	mockSender := mocksender.NewMockSender(check.ID())
+	mockSender.SetupAcceptAll()
+	require.NoError(t, check.Run())
+	return mockSender
+}
+
+func TestTCPCheck(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	port := listener.Addr().(*net.TCPAddr).Port
+
+	tags := []string{fmt.Sprintf("url:127.0.0.1:%d", port), "instance:test"}
+	serviceCheckTags := []string{"target_host:127.0.0.1", fmt.Sprintf("port:%d", port), "instance:test"}
+	config := fmt.Sprintf("name: test\nhost: 127.0.0.1\nport: %d\ncollect_response_time: true", port)
+
+	mockSender := runTCPCheck(t, config)
+	mockSender.AssertServiceCheck(t, "tcp.can_connect", servicecheck.ServiceCheckOK, "", serviceCheckTags, "")
+	mockSender.AssertMetric(t, "Gauge", "network.tcp.can_connect", 1, "", tags)
+	mockSender.AssertMetricInRange(t, "Gauge", "network.tcp.response_time", 0, 10, "", tags)
+
+	listener.Close()
+
+	mockSender = runTCPCheck(t, config)
+	mockSender.AssertCalled(t, "ServiceCheck", "tcp.can_connect", servicecheck.ServiceCheckCritical, "", serviceCheckTags, mock.AnythingOfType("string"))
+	mockSender.AssertMetric(t, "Gauge", "network.tcp.can_connect", 0, "", tags)
+	mockSender.AssertNotCalled(t, "Gauge", "network.tcp.response_time", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestTCPCheckConfigure(t *testing.T) {
+	for _, tt := range []struct {
+		config      string
+		expectedErr string
+	}{
+		{"host: localhost\nport: 80", "the name of the instance is required"},
+		{"name: test\nport: 80", "the host of the instance is required"},
+		{"name: test\nhost: localhost", "invalid port 0"},
+		{"name: test\nhost: localhost\nport: 70000", "invalid port 70000"},
+	} {
+		check := tcpCheckFactory()
+		assert.EqualError(t, check.Configure(integration.FakeConfigHash, []byte(tt.config), nil, "test"), tt.expectedErr)
+	}
+
+	var cfg tcpConfig
+	require.NoError(t, cfg.parse([]byte("name: test\nhost: ::1\nport: 22")))
+	assert.Equal(t, "[::1]:22", cfg.address)
+	assert.Equal(t, "10s", cfg.timeout.String())
+}
@@ -0,0 +1,134 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023-present Datadog, Inc.
+
+package networkconfig
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"regexp"
+	"strings"
+
+	"github.com/pmezard/go-difflib/difflib"
+
+	"github.com/DataDog/datadog-agent/pkg/persistentcache"
+)
+
+// maxDiffLength is the maximum length of the diff sent in an event, below the
+// 4000 characters limit of the text of events
+const maxDiffLength = 3500
+
+// redacted replaces the secrets in the diff of the events
+const redacted = "<redacted>"
+
+// configDiff is the difference between two versions of a configuration
+type configDiff struct {
+	unified      string
+	addedLines   int
+	removedLines int
+}
+
+// normalizeConfig returns the configuration printed by a device, without the
+// ignored lines and the trailing whitespaces
+func normalizeConfig(output string, ignoredLines []*regexp.Regexp) string {
+	var sb strings.Builder
+	for _, line := range strings.Split(strings.ReplaceAll(output, "\r\n", "\n"), "\n") {
+		line = strings.TrimRight(line, " \t\r")
+		if isIgnoredLine(line, ignoredLines) {
+			continue
+		}
+		sb.WriteString(line)
+		sb.WriteString("\n")
+	}
+	return strings.TrimSpace(sb.String()) + "\n"
+}
+
+// redactConfig replaces the secrets of the configuration, matched by the first
+// group of the secret lines, so they aren't sent in the diff of the events
+func redactConfig(config string, secretLines []*regexp.Regexp) string {
+	lines := strings.Split(config, "\n")
+	for i, line := range lines {
+		for _, pattern := range secretLines {
+			line = redactLine(line, pattern)
+		}
+		lines[i] = line
+	}
+	return strings.Join(lines, "\n")
+}
+
+func redactLine(line string, pattern *regexp.Regexp) string {
+	matches := pattern.FindAllStringSubmatchIndex(line, -1)
+	// replace from the end of the line so the indexes stay valid
+	for i := len(matches) - 1; i >= 0; i-- {
+		start, end := matches[i][2], matches[i][3]
+		if start < 0 || line[start:end] == redacted {
+			continue
+		}
+		line = line[:start] + redacted + line[end:]
+	}
+	return line
+}
+
+func isIgnoredLine(line string, ignoredLines []*regexp.Regexp) bool {
+	for _, pattern := range ignoredLines {
+		if pattern.MatchString(line) {
+			return true
+		}
+	}
+	return false
+}
+
+func diffConfigs(previous string, current string) (configDiff, error) {
+	unified, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(previous),
+		B:        difflib.SplitLines(current),
+		FromFile: "previous",
+		ToFile:   "current",
+		Context:  3,
+	})
+	if err != nil {
+		return configDiff{}, err
+	}
+
+	diff := configDiff{unified: unified}
+	for _, line := range strings.Split(unified, "\n") {
+		switch {
+		case strings.HasPrefix(line, "+++"), strings.HasPrefix(line, "---"):
+		case strings.HasPrefix(line, "+"):
+			diff.addedLines++
+		case strings.HasPrefix(line, "-"):
+			diff.removedLines++
+		}
+	}
+	return diff, nil
+}
+
+// truncateDiff truncates the diff to its lines fitting in maxLength
+func truncateDiff(diff string, maxLength int) string {
+	if len(diff) <= maxLength {
+		return diff
+	}
+	truncated := diff[:maxLength]
+	if i := strings.LastIndex(truncated, "\n"); i >= 0 {
+		truncated = truncated[:i+1]
+	}
+	return truncated + "... (truncated)\n"
+}
+
+// The last configuration of each device is stored in the persistent cache,
+// under a hash of its device ID since the cache keys are cleaned of most
+// characters, which could make the keys of different devices collide.
+func cacheKey(deviceID string) string {
+	hash := sha256.Sum256([]byte(deviceID))
+	return checkName + ":" + hex.EncodeToString(hash[:])
+}
+
+func readLastConfig(deviceID string) (string, error) {
+	return persistentcache.Read(cacheKey(deviceID))
+}
+
+func writeLastConfig(deviceID string, config string) error {
+	return persistentcache.Write(cacheKey(deviceID), config)
+}
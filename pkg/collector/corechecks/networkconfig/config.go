@@ -0,0 +1,209 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023-present Datadog, Inc.
+
+package networkconfig
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	defaultSSHPort   = 22
+	defaultTimeout   = 30 // in seconds
+	defaultNamespace = "default"
+)
+
+// The credentials can be stored in a secrets backend, using `ENC[]` handles
+// which are decrypted before the check is configured.
+type instanceConfig struct {
+	IPAddress             string `yaml:"ip_address"`
+	Port                  int    `yaml:"port"`
+	Username              string `yaml:"username"`
+	Password              string `yaml:"password"`
+	PrivateKeyFile        string `yaml:"private_key_file"`
+	PrivateKeyPassphrase  string `yaml:"private_key_passphrase"`
+	KnownHostsFile        string `yaml:"known_hosts_file"`
+	InsecureIgnoreHostKey bool   `yaml:"insecure_ignore_host_key"`
+	Vendor                string `yaml:"vendor"`
+	Command               string `yaml:"command"`
+	Namespace             string `yaml:"namespace"`
+	Timeout               int    `yaml:"timeout"`
+}
+
+type checkConfig struct {
+	instance     instanceConfig
+	address      string
+	deviceID     string
+	command      string
+	ignoredLines []*regexp.Regexp
+	secretLines  []*regexp.Regexp
+	timeout      time.Duration
+	sshConfig    *ssh.ClientConfig
+	tags         []string
+}
+
+func (c *checkConfig) parse(data []byte) error {
+	var instance instanceConfig
+	if err := yaml.Unmarshal(data, &instance); err != nil {
+		return err
+	}
+
+	if instance.IPAddress == "" {
+		return errors.New("the ip_address of the instance is required")
+	}
+	if instance.Port == 0 {
+		instance.Port = defaultSSHPort
+	}
+	if instance.Port < 0 || instance.Port > 65535 {
+		return fmt.Errorf("invalid port %d", instance.Port)
+	}
+	if instance.Timeout <= 0 {
+		instance.Timeout = defaultTimeout
+	}
+	if instance.Namespace == "" {
+		instance.Namespace = defaultNamespace
+	}
+
+	c.command = instance.Command
+	if instance.Vendor != "" {
+		profile, ok := vendorProfiles[instance.Vendor]
+		if !ok {
+			return fmt.Errorf("unknown vendor `%s`, supported vendors are: %s", instance.Vendor, strings.Join(supportedVendors(), ", "))
+		}
+		if c.command == "" {
+			c.command = profile.command
+		}
+		for _, pattern := range profile.ignoredLines {
+			c.ignoredLines = append(c.ignoredLines, regexp.MustCompile(pattern))
+		}
+	}
+	c.secretLines = compileSecretLines(instance.Vendor)
+	if c.command == "" {
+		return errors.New("either the vendor or the command of the instance is required")
+	}
+
+	c.timeout = time.Duration(instance.Timeout) * time.Second
+	sshConfig, err := buildSSHClientConfig(instance, c.timeout)
+	if err != nil {
+		return err
+	}
+	c.sshConfig = sshConfig
+
+	c.address = net.JoinHostPort(instance.IPAddress, strconv.Itoa(instance.Port))
+	c.deviceID = instance.Namespace + ":" + instance.IPAddress
+	// the tags of the instance are added by the sender
+	c.tags = []string{
+		"device_namespace:" + instance.Namespace,
+		"device_ip:" + instance.IPAddress,
+		"device_id:" + c.deviceID,
+	}
+	if instance.Vendor != "" {
+		c.tags = append(c.tags, "device_vendor:"+instance.Vendor)
+	}
+	c.instance = instance
+
+	return nil
+}
+
+func buildSSHClientConfig(instance instanceConfig, timeout time.Duration) (*ssh.ClientConfig, error) {
+	if instance.Username == "" {
+		return nil, errors.New("the username of the instance is required")
+	}
+
+	var authMethods []ssh.AuthMethod
+	if instance.PrivateKeyFile != "" {
+		key, err := os.ReadFile(instance.PrivateKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read private key: %s", err)
+		}
+		var signer ssh.Signer
+		if instance.PrivateKeyPassphrase != "" {
+			signer, err = ssh.ParsePrivateKeyWithPassphrase(key, []byte(instance.PrivateKeyPassphrase))
+		} else {
+			signer, err = ssh.ParsePrivateKey(key)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse private key: %s", err)
+		}
+		authMethods = append(authMethods, ssh.PublicKeys(signer))
+	}
+	if instance.Password != "" {
+		// many network devices only accept passwords through keyboard-interactive
+		authMethods = append(authMethods, ssh.Password(instance.Password), ssh.KeyboardInteractive(
+			func(user, instruction string, questions []string, echos []bool) ([]string, error) {
+				answers := make([]string, len(questions))
+				for i := range answers {
+					answers[i] = instance.Password
+				}
+				return answers, nil
+			}))
+	}
+	if len(authMethods) == 0 {
+		return nil, errors.New("either the password or the private_key_file of the instance is required")
+	}
+
+	var hostKeyCallback ssh.HostKeyCallback
+	if instance.KnownHostsFile != "" {
+		var err error
+		hostKeyCallback, err = knownhosts.New(instance.KnownHostsFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read known hosts: %s", err)
+		}
+	} else if instance.InsecureIgnoreHostKey {
+		hostKeyCallback = ssh.InsecureIgnoreHostKey() //nolint:gosec // explicitly requested by the configuration
+	} else {
+		return nil, errors.New("either the known_hosts_file of the instance or insecure_ignore_host_key is required")
+	}
+
+	return &ssh.ClientConfig{
+		User:            instance.Username,
+		Auth:            authMethods,
+		HostKeyCallback: hostKeyCallback,
+		Timeout:         timeout,
+	}, nil
+}
+
+// compileSecretLines compiles the secret lines of a vendor, or of every vendor
+// when it isn't set since the format of the configuration is unknown
+func compileSecretLines(vendor string) []*regexp.Regexp {
+	vendors := []string{vendor}
+	if vendor == "" {
+		vendors = supportedVendors()
+	}
+
+	var secretLines []*regexp.Regexp
+	seen := make(map[string]struct{})
+	for _, vendor := range vendors {
+		for _, pattern := range vendorProfiles[vendor].secretLines {
+			if _, ok := seen[pattern]; ok {
+				continue
+			}
+			seen[pattern] = struct{}{}
+			secretLines = append(secretLines, regexp.MustCompile(pattern))
+		}
+	}
+	return secretLines
+}
+
+func supportedVendors() []string {
+	vendors := make([]string, 0, len(vendorProfiles))
+	for vendor := range vendorProfiles {
+		vendors = append(vendors, vendor)
+	}
+	sort.Strings(vendors)
+	return vendors
+}
@@ -0,0 +1,134 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023-present Datadog, Inc.
+
+// Package networkconfig implements a check collecting the configuration of
+// network devices over SSH, to detect configuration changes.
+package networkconfig
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+	"github.com/DataDog/datadog-agent/pkg/collector/check"
+	core "github.com/DataDog/datadog-agent/pkg/collector/corechecks"
+	"github.com/DataDog/datadog-agent/pkg/metrics/event"
+	"github.com/DataDog/datadog-agent/pkg/metrics/servicecheck"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+const (
+	checkName = "network_config"
+
+	// configurations don't change often and are slow to collect
+	defaultCollectionInterval = time.Hour
+
+	canCollectServiceCheck = "network_config.can_collect"
+)
+
+var timeNow = time.Now
+
+// Check collects the configuration of a network device, and sends an event
+// with the diff when it changes
+type Check struct {
+	core.CheckBase
+	config *checkConfig
+}
+
+// Configure configures the check from the yaml
+func (c *Check) Configure(integrationConfigDigest uint64, data integration.Data, initConfig integration.Data, source string) error {
+	config := new(checkConfig)
+	if err := config.parse(data); err != nil {
+		log.Errorf("Error parsing configuration file: %s", err)
+		return err
+	}
+
+	c.BuildID(integrationConfigDigest, data, initConfig)
+	c.config = config
+
+	return c.CommonConfigure(integrationConfigDigest, initConfig, data, source)
+}
+
+// Run collects the configuration of the device and compares it to the last one
+func (c *Check) Run() error {
+	sender, err := c.GetSender()
+	if err != nil {
+		return err
+	}
+	defer sender.Commit()
+
+	output, err := runCommand(c.config.address, c.config.sshConfig, c.config.command, c.config.timeout)
+	if err == nil && strings.TrimSpace(output) == "" {
+		err = fmt.Errorf("command `%s` returned an empty configuration", c.config.command)
+	}
+	if err != nil {
+		err = fmt.Errorf("failed to collect the configuration of %s: %s", c.config.address, err)
+		sender.ServiceCheck(canCollectServiceCheck, servicecheck.ServiceCheckCritical, "", c.config.tags, err.Error())
+		return err
+	}
+	sender.ServiceCheck(canCollectServiceCheck, servicecheck.ServiceCheckOK, "", c.config.tags, "")
+
+	current := normalizeConfig(output, c.config.ignoredLines)
+	previous, err := readLastConfig(c.config.deviceID)
+	if err != nil {
+		return fmt.Errorf("failed to read the last configuration of %s: %s", c.config.deviceID, err)
+	}
+	if current == previous {
+		return nil
+	}
+
+	if previous == "" {
+		log.Infof("%s: storing the first configuration collected for %s", c.ID(), c.config.deviceID)
+	} else {
+		// the configurations are stored as collected, the secrets are only
+		// redacted from the diff sent in the event
+		diff, err := diffConfigs(redactConfig(previous, c.config.secretLines), redactConfig(current, c.config.secretLines))
+		if err != nil {
+			return fmt.Errorf("failed to diff the configuration of %s: %s", c.config.deviceID, err)
+		}
+		sender.Event(c.changeEvent(diff))
+	}
+
+	if err := writeLastConfig(c.config.deviceID, current); err != nil {
+		return fmt.Errorf("failed to store the configuration of %s: %s", c.config.deviceID, err)
+	}
+	return nil
+}
+
+func (c *Check) changeEvent(diff configDiff) event.Event {
+	var b strings.Builder
+	b.WriteString("%%% \n")
+	if diff.addedLines == 0 && diff.removedLines == 0 {
+		fmt.Fprintf(&b, "The configuration of device `%s` changed: only redacted secrets changed.\n %%%%%%", c.config.deviceID)
+	} else {
+		fmt.Fprintf(&b, "The configuration of device `%s` changed: %d lines added, %d lines removed.\n\n", c.config.deviceID, diff.addedLines, diff.removedLines)
+		b.WriteString("```diff\n")
+		b.WriteString(truncateDiff(diff.unified, maxDiffLength))
+		b.WriteString("```\n %%%")
+	}
+
+	return event.Event{
+		Title:          fmt.Sprintf("Configuration changed on %s", c.config.instance.IPAddress),
+		Text:           b.String(),
+		Ts:             timeNow().Unix(),
+		Priority:       event.EventPriorityNormal,
+		AlertType:      event.EventAlertTypeInfo,
+		AggregationKey: c.config.deviceID,
+		SourceTypeName: checkName,
+		EventType:      checkName,
+		Tags:           c.config.tags,
+	}
+}
+
+func checkFactory() check.Check {
+	return &Check{
+		CheckBase: core.NewCheckBaseWithInterval(checkName, defaultCollectionInterval),
+	}
+}
+
+func init() {
+	core.RegisterCheck(checkName, checkFactory)
+}
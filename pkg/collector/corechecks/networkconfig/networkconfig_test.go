@@ -0,0 +1,276 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023-present Datadog, Inc.
+
+package networkconfig
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh/knownhosts"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator/mocksender"
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/metrics/event"
+	"github.com/DataDog/datadog-agent/pkg/metrics/servicecheck"
+)
+
+const ciscoConfig = `Building configuration...
+
+Current configuration : 1024 bytes
+!
+! Last configuration change at 10:00:00 UTC Mon Jan 2 2023
+!
+hostname router
+!
+interface GigabitEthernet0/1
+ description uplink
+ ip address 10.0.0.1 255.255.255.0
+!
+end
+`
+
+func TestMain(m *testing.M) {
+	// the run path is set before any sender is created, since the aggregator
+	// reads the configuration concurrently
+	runPath, err := os.MkdirTemp("", "network_config")
+	if err != nil {
+		panic(err)
+	}
+	config.Datadog.Set("run_path", runPath)
+	code := m.Run()
+	os.RemoveAll(runPath)
+	os.Exit(code)
+}
+
+func runCheck(t *testing.T, instance string) (*mocksender.MockSender, error) {
+	check := checkFactory().(*Check)
+	require.NoError(t, check.Configure(integration.FakeConfigHash, []byte(instance), nil, "test"))
+
+	mockSender := mocksender.NewMockSender(check.ID())
+	mockSender.SetupAcceptAll()
+	return mockSender, check.Run()
+}
+
+func sentEvents(mockSender *mocksender.MockSender) []event.Event {
+	var events []event.Event
+	for _, call := range mockSender.Calls {
+		if call.Method == "Event" {
+			events = append(events, call.Arguments.Get(0).(event.Event))
+		}
+	}
+	return events
+}
+
+func TestCheck_Run(t *testing.T) {
+	now := time.Now()
+	timeNow = func() time.Time { return now }
+	defer func() { timeNow = time.Now }()
+
+	server := newStandInServer(t, "admin", "secret")
+	server.setOutput("show running-config", strings.ReplaceAll(ciscoConfig, "\n", "\r\n"))
+
+	knownHostsFile := filepath.Join(t.TempDir(), "known_hosts")
+	address := fmt.Sprintf("127.0.0.1:%d", server.port())
+	require.NoError(t, os.WriteFile(knownHostsFile, []byte(knownhosts.Line([]string{knownhosts.Normalize(address)}, server.hostKey)+"\n"), 0600))
+
+	instance := fmt.Sprintf(`
+ip_address: 127.0.0.1
+port: %d
+username: admin
+password: secret
+known_hosts_file: %s
+vendor: cisco_ios
+`, server.port(), knownHostsFile)
+	tags := []string{"device_namespace:default", "device_ip:127.0.0.1", "device_id:default:127.0.0.1", "device_vendor:cisco_ios"}
+
+	// the first configuration is only stored
+	require.NoError(t, writeLastConfig("default:127.0.0.1", ""))
+	mockSender, err := runCheck(t, instance)
+	require.NoError(t, err)
+	mockSender.AssertServiceCheck(t, canCollectServiceCheck, servicecheck.ServiceCheckOK, "", tags, "")
+	mockSender.AssertNotCalled(t, "Event", mock.Anything)
+
+	// the changes of the ignored lines are not configuration changes
+	server.setOutput("show running-config", strings.ReplaceAll(ciscoConfig, "10:00:00", "11:00:00"))
+	mockSender, err = runCheck(t, instance)
+	require.NoError(t, err)
+	mockSender.AssertNotCalled(t, "Event", mock.Anything)
+
+	server.setOutput("show running-config", strings.ReplaceAll(ciscoConfig, " description uplink\n", " description uplink to core\n shutdown\n"))
+	mockSender, err = runCheck(t, instance)
+	require.NoError(t, err)
+	events := sentEvents(mockSender)
+	assert.Equal(t, []event.Event{{
+		Title: "Configuration changed on 127.0.0.1",
+		Text: "%%% \nThe configuration of device `default:127.0.0.1` changed: 2 lines added, 1 lines removed.\n\n" +
+			"```diff\n" +
+			"--- previous\n" +
+			"+++ current\n" +
+			"@@ -3,7 +3,8 @@\n" +
+			" hostname router\n" +
+			" !\n" +
+			" interface GigabitEthernet0/1\n" +
+			"- description uplink\n" +
+			"+ description uplink to core\n" +
+			"+ shutdown\n" +
+			"  ip address 10.0.0.1 255.255.255.0\n" +
+			" !\n" +
+			" end\n" +
+			"```\n %%%",
+		Ts:             now.Unix(),
+		Priority:       event.EventPriorityNormal,
+		AlertType:      event.EventAlertTypeInfo,
+		AggregationKey: "default:127.0.0.1",
+		SourceTypeName: "network_config",
+		EventType:      "network_config",
+		Tags:           tags,
+	}}, events)
+
+	// the changed configuration was stored
+	mockSender, err = runCheck(t, instance)
+	require.NoError(t, err)
+	mockSender.AssertNotCalled(t, "Event", mock.Anything)
+}
+
+func TestCheck_RunErrors(t *testing.T) {
+	server := newStandInServer(t, "admin", "secret")
+	server.setOutput("show running-config", "")
+	tags := []string{"device_namespace:default", "device_ip:127.0.0.1", "device_id:default:127.0.0.1"}
+
+	for _, tt := range []struct {
+		name          string
+		password      string
+		command       string
+		expectedError string
+	}{
+		{"wrong password", "wrong", "show running-config", "unable to authenticate"},
+		{"unknown command", "secret", "show config", "command `show config` failed: Process exited with status 1: % Invalid input detected"},
+		{"empty configuration", "secret", "show running-config", "command `show running-config` returned an empty configuration"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			instance := fmt.Sprintf("ip_address: 127.0.0.1\nport: %d\nusername: admin\npassword: %s\ninsecure_ignore_host_key: true\ncommand: %s", server.port(), tt.password, tt.command)
+			mockSender, err := runCheck(t, instance)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.expectedError)
+			mockSender.AssertServiceCheck(t, canCollectServiceCheck, servicecheck.ServiceCheckCritical, "", tags, err.Error())
+			mockSender.AssertNotCalled(t, "Event", mock.Anything)
+		})
+	}
+}
+
+func TestCheck_Configure(t *testing.T) {
+	for _, tt := range []struct {
+		config        string
+		expectedError string
+	}{
+		{"username: admin\npassword: secret\nvendor: cisco_ios", "the ip_address of the instance is required"},
+		{"ip_address: 10.0.0.1\nport: 70000\nusername: admin\npassword: secret\nvendor: cisco_ios", "invalid port 70000"},
+		{"ip_address: 10.0.0.1\nusername: admin\npassword: secret\nvendor: unknown", "unknown vendor `unknown`, supported vendors are: arista_eos, cisco_asa, cisco_ios, cisco_nxos, juniper_junos, mikrotik_routeros"},
+		{"ip_address: 10.0.0.1\nusername: admin\npassword: secret", "either the vendor or the command of the instance is required"},
+		{"ip_address: 10.0.0.1\npassword: secret\nvendor: cisco_ios", "the username of the instance is required"},
+		{"ip_address: 10.0.0.1\nusername: admin\nvendor: cisco_ios", "either the password or the private_key_file of the instance is required"},
+		{"ip_address: 10.0.0.1\nusername: admin\npassword: secret\nvendor: cisco_ios", "either the known_hosts_file of the instance or insecure_ignore_host_key is required"},
+	} {
+		check := checkFactory()
+		assert.EqualError(t, check.Configure(integration.FakeConfigHash, []byte(tt.config), nil, "test"), tt.expectedError)
+	}
+
+	var cfg checkConfig
+	require.NoError(t, cfg.parse([]byte("ip_address: 10.0.0.1\nusername: admin\npassword: secret\ninsecure_ignore_host_key: true\nvendor: juniper_junos\nnamespace: dc1")))
+	assert.Equal(t, "10.0.0.1:22", cfg.address)
+	assert.Equal(t, "dc1:10.0.0.1", cfg.deviceID)
+	assert.Equal(t, "show configuration | display set | no-more", cfg.command)
+	assert.Equal(t, 30*time.Second, cfg.timeout)
+
+	check := checkFactory()
+	require.NoError(t, check.Configure(integration.FakeConfigHash, []byte("ip_address: 10.0.0.1\nusername: admin\npassword: secret\ninsecure_ignore_host_key: true\ncommand: show run"), nil, "test"))
+	assert.Equal(t, time.Hour, check.Interval())
+}
+
+func TestTruncateDiff(t *testing.T) {
+	diff := "--- previous\n+++ current\n@@ -1 +1 @@\n-a\n+b\n"
+	assert.Equal(t, diff, truncateDiff(diff, len(diff)))
+	assert.Equal(t, "--- previous\n+++ current\n... (truncated)\n", truncateDiff(diff, 30))
+}
+
+func TestCheck_RunRedactsSecrets(t *testing.T) {
+	server := newStandInServer(t, "admin", "secret")
+	secretConfig := strings.ReplaceAll(ciscoConfig, "hostname router\n", "hostname router\nenable secret 5 $1$mERr$hx5rVt7rPNoS4wqbXKX7m0\nsnmp-server community s3cr3t RO\n")
+	server.setOutput("show running-config", secretConfig)
+	instance := fmt.Sprintf("ip_address: 127.0.0.1\nport: %d\nusername: admin\npassword: secret\ninsecure_ignore_host_key: true\nvendor: cisco_ios\nnamespace: secrets", server.port())
+
+	require.NoError(t, writeLastConfig("secrets:127.0.0.1", ""))
+	_, err := runCheck(t, instance)
+	require.NoError(t, err)
+
+	changedConfig := strings.ReplaceAll(secretConfig, "enable secret 5 $1$mERr$hx5rVt7rPNoS4wqbXKX7m0", "enable secret 9 $9$nhEmQVczB7dqsO$X.HsgL6x1il0RxkOSSvyQYwucySCt7qFm4v7pqCxkKM")
+	changedConfig = strings.ReplaceAll(changedConfig, "snmp-server community s3cr3t RO", "snmp-server community n3wS3cr3t RW")
+	server.setOutput("show running-config", changedConfig)
+	mockSender, err := runCheck(t, instance)
+	require.NoError(t, err)
+	events := sentEvents(mockSender)
+	require.Len(t, events, 1)
+	text := events[0].Text
+	assert.Contains(t, text, "-enable secret 5 <redacted>\n")
+	assert.Contains(t, text, "+enable secret 9 <redacted>\n")
+	assert.Contains(t, text, "-snmp-server community <redacted> RO\n")
+	assert.Contains(t, text, "+snmp-server community <redacted> RW\n")
+	for _, secret := range []string{"$1$mERr", "$9$nhEmQ", "s3cr3t", "n3wS3cr3t"} {
+		assert.NotContains(t, text, secret)
+	}
+
+	// the configurations are stored as collected, only the secrets changed
+	server.setOutput("show running-config", strings.ReplaceAll(changedConfig, "n3wS3cr3t", "0th3rS3cr3t"))
+	mockSender, err = runCheck(t, instance)
+	require.NoError(t, err)
+	events = sentEvents(mockSender)
+	require.Len(t, events, 1)
+	assert.Equal(t, "%%% \nThe configuration of device `secrets:127.0.0.1` changed: only redacted secrets changed.\n %%%", events[0].Text)
+}
+
+func TestRedactConfig(t *testing.T) {
+	for _, tt := range []struct {
+		vendor   string
+		line     string
+		expected string
+	}{
+		{"cisco_ios", "enable secret 5 $1$abc", "enable secret 5 <redacted>"},
+		{"cisco_ios", "enable password level 15 s3cr3t", "enable password level 15 <redacted>"},
+		{"cisco_ios", "username admin privilege 15 secret 9 $9$abc", "username admin privilege 15 secret 9 <redacted>"},
+		{"cisco_ios", " password 7 0822455D0A16", " password 7 <redacted>"},
+		{"cisco_ios", " neighbor 10.0.0.2 password 7 0822455D0A16", " neighbor 10.0.0.2 password 7 <redacted>"},
+		{"cisco_ios", "snmp-server community public RO", "snmp-server community <redacted> RO"},
+		{"cisco_ios", "snmp-server host 10.0.0.2 version 2c public", "snmp-server host 10.0.0.2 version 2c <redacted>"},
+		{"cisco_ios", "tacacs-server key 7 0822455D0A16", "tacacs-server key 7 <redacted>"},
+		{"cisco_ios", " key 7 0822455D0A16", " key 7 <redacted>"},
+		{"cisco_ios", "crypto isakmp key s3cr3t address 10.0.0.2", "crypto isakmp key <redacted> address 10.0.0.2"},
+		{"cisco_ios", " pre-shared-key address 10.0.0.2 key s3cr3t", " pre-shared-key address 10.0.0.2 key <redacted>"},
+		{"cisco_ios", " ip ospf message-digest-key 1 md5 s3cr3t", " ip ospf message-digest-key 1 md5 <redacted>"},
+		{"cisco_nxos", "radius-server host 10.0.0.2 key 7 \"s3cr3t\" authentication", "radius-server host 10.0.0.2 key 7 <redacted> authentication"},
+		{"cisco_nxos", "snmp-server user admin network-admin auth md5 0xabc priv 0xdef localizedkey", "snmp-server user admin network-admin auth md5 <redacted> priv <redacted> localizedkey"},
+		{"cisco_asa", "enable password 8Ry2YjIyt7RRXU24 encrypted", "enable password <redacted> encrypted"},
+		{"cisco_asa", " ikev1 pre-shared-key s3cr3t", " ikev1 pre-shared-key <redacted>"},
+		{"arista_eos", "username admin privilege 15 role network-admin secret sha512 $6$abc", "username admin privilege 15 role network-admin secret sha512 <redacted>"},
+		{"juniper_junos", `set system root-authentication encrypted-password "$6$abc"`, "set system root-authentication encrypted-password <redacted>"},
+		{"juniper_junos", `set snmp community "my community" authorization read-only`, "set snmp community <redacted> authorization read-only"},
+		{"juniper_junos", `set security ike policy p1 pre-shared-key ascii-text "$9$abc"`, "set security ike policy p1 pre-shared-key ascii-text <redacted>"},
+		{"mikrotik_routeros", "add name=vpn password=s3cr3t service=l2tp", "add name=vpn password=<redacted> service=l2tp"},
+		{"mikrotik_routeros", "add address=10.0.0.2 secret=s3cr3t service=login", "add address=10.0.0.2 secret=<redacted> service=login"},
+		{"cisco_ios", "interface GigabitEthernet0/1", "interface GigabitEthernet0/1"},
+		// the secrets of every vendor are redacted when the vendor isn't set
+		{"", `set snmp community public`, "set snmp community <redacted>"},
+	} {
+		assert.Equal(t, tt.expected, redactConfig(tt.line, compileSecretLines(tt.vendor)), "vendor %q line %q", tt.vendor, tt.line)
+	}
+}
@@ -0,0 +1,127 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023-present Datadog, Inc.
+
+package networkconfig
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"net"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh"
+)
+
+// standInServer is a SSH server standing in for a network device, printing
+// the configured outputs of the commands run in exec sessions
+type standInServer struct {
+	listener net.Listener
+	hostKey  ssh.PublicKey
+
+	mu      sync.Mutex
+	outputs map[string]string
+}
+
+func newStandInServer(t *testing.T, username string, password string) *standInServer {
+	_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	signer, err := ssh.NewSignerFromKey(privateKey)
+	require.NoError(t, err)
+
+	serverConfig := &ssh.ServerConfig{
+		PasswordCallback: func(conn ssh.ConnMetadata, pass []byte) (*ssh.Permissions, error) {
+			if conn.User() == username && string(pass) == password {
+				return nil, nil
+			}
+			return nil, ssh.ErrNoAuth
+		},
+	}
+	serverConfig.AddHostKey(signer)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+
+	server := &standInServer{listener: listener, hostKey: signer.PublicKey(), outputs: map[string]string{}}
+	go server.serve(serverConfig)
+	return server
+}
+
+func (s *standInServer) port() int {
+	return s.listener.Addr().(*net.TCPAddr).Port
+}
+
+func (s *standInServer) setOutput(command string, output string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.outputs[command] = output
+}
+
+func (s *standInServer) output(command string) (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	output, ok := s.outputs[command]
+	return output, ok
+}
+
+func (s *standInServer) serve(config *ssh.ServerConfig) {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		go s.handleConn(conn, config)
+	}
+}
+
+func (s *standInServer) handleConn(conn net.Conn, config *ssh.ServerConfig) {
+	defer conn.Close()
+	serverConn, chans, reqs, err := ssh.NewServerConn(conn, config)
+	if err != nil {
+		return
+	}
+	defer serverConn.Close()
+	go ssh.DiscardRequests(reqs)
+
+	for newChannel := range chans {
+		if newChannel.ChannelType() != "session" {
+			newChannel.Reject(ssh.UnknownChannelType, "unsupported channel type") //nolint:errcheck
+			continue
+		}
+		channel, requests, err := newChannel.Accept()
+		if err != nil {
+			return
+		}
+		go s.handleSession(channel, requests)
+	}
+}
+
+func (s *standInServer) handleSession(channel ssh.Channel, requests <-chan *ssh.Request) {
+	defer channel.Close()
+	for req := range requests {
+		if req.Type != "exec" {
+			req.Reply(false, nil) //nolint:errcheck
+			continue
+		}
+		var payload struct{ Command string }
+		if err := ssh.Unmarshal(req.Payload, &payload); err != nil {
+			req.Reply(false, nil) //nolint:errcheck
+			return
+		}
+		req.Reply(true, nil) //nolint:errcheck
+
+		exitStatus := uint32(0)
+		if output, ok := s.output(payload.Command); ok {
+			channel.Write([]byte(output)) //nolint:errcheck
+		} else {
+			channel.Stderr().Write([]byte("% Invalid input detected\n")) //nolint:errcheck
+			exitStatus = 1
+		}
+		channel.SendRequest("exit-status", false, ssh.Marshal(struct{ Status uint32 }{exitStatus})) //nolint:errcheck
+		return
+	}
+}
@@ -0,0 +1,96 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023-present Datadog, Inc.
+
+package networkconfig
+
+// vendorProfile describes how to collect the configuration of the devices of a vendor
+type vendorProfile struct {
+	// command printing the running configuration, without paging
+	command string
+	// patterns of the lines changing without the configuration changing,
+	// like timestamps, which are left out of the stored configuration
+	ignoredLines []string
+	// patterns of the lines holding secrets, like passwords, keys or SNMP
+	// communities, whose first group is redacted from the diff of the events
+	secretLines []string
+}
+
+// ciscoSecretLines are the secret lines shared by the Cisco-like CLIs
+var ciscoSecretLines = []string{
+	`^\s*enable (?:secret|password)(?: level \d+)?(?: [0-9]| sha512)? (\S+)`,
+	`^\s*username \S+ .*\bsecret(?: [0-9]| sha512)? (\S+)`,
+	`\bpassword(?: level \d+)?(?: [0-9]| sha512)? (\S+)`,
+	`^\s*passwd (\S+)`,
+	`^\s*snmp-server community (\S+)`,
+	`^\s*snmp-server host \S+ (?:(?:traps|informs) )?(?:version (?:1|2c) )?(\S+)`,
+	`^\s*snmp-server user \S+ .*\bauth \S+ (\S+)`,
+	`^\s*snmp-server user \S+ .*\bpriv(?: aes-128| aes \d+| aes| 3des| des)? (\S+)`,
+	`^\s*(?:tacacs-server |radius-server )?key(?: [0-9])? (\S+)`,
+	`^\s*(?:tacacs-server|radius-server) host \S+ .*\bkey(?: [0-9])? (\S+)`,
+	`^\s*key-string(?: [0-9])? (\S+)`,
+	`\b(?:authentication-key|message-digest-key \d+ md5)(?: [0-9])? (\S+)`,
+	`^\s*crypto isakmp key(?: [0-9])? (\S+)`,
+	`^\s*(?:ikev1 |ikev2 )?pre-shared-key(?: local| remote)?(?: [0-9])? (\S+)$`,
+	`^\s*pre-shared-key address .*\bkey(?: [0-9])? (\S+)`,
+}
+
+var vendorProfiles = map[string]vendorProfile{
+	"cisco_ios": {
+		command: "show running-config",
+		ignoredLines: []string{
+			`^Building configuration`,
+			`^Current configuration\s*:`,
+			`^! Last configuration change at`,
+			`^! NVRAM config last updated at`,
+			`^ntp clock-period`,
+		},
+		secretLines: ciscoSecretLines,
+	},
+	"cisco_nxos": {
+		command: "show running-config",
+		ignoredLines: []string{
+			`^!Command: show running-config`,
+			`^!Running configuration last done at`,
+			`^!Time:`,
+		},
+		secretLines: ciscoSecretLines,
+	},
+	"cisco_asa": {
+		command: "show running-config",
+		ignoredLines: []string{
+			`^: Saved`,
+			`^: Written by`,
+			`^Cryptochecksum:`,
+		},
+		secretLines: ciscoSecretLines,
+	},
+	"arista_eos": {
+		command: "show running-config",
+		ignoredLines: []string{
+			`^! Command: show running-config`,
+			`^! Time:`,
+		},
+		secretLines: ciscoSecretLines,
+	},
+	"juniper_junos": {
+		command: "show configuration | display set | no-more",
+		ignoredLines: []string{
+			`^## Last commit:`,
+		},
+		secretLines: []string{
+			`\b(?:encrypted-password|secret|ascii-text|hexadecimal|authentication-key|privacy-key|simple-password) ("[^"]*"|\S+)`,
+			`^set snmp community ("[^"]*"|\S+)`,
+		},
+	},
+	"mikrotik_routeros": {
+		command: "/export",
+		ignoredLines: []string{
+			`^# .* by RouterOS`,
+		},
+		secretLines: []string{
+			`\b(?:password|secret|authentication-password|encryption-password|wpa-pre-shared-key|wpa2-pre-shared-key)=("[^"]*"|\S+)`,
+		},
+	},
+}
@@ -7,6 +7,7 @@ package corechecks
 
 import (
 	"github.com/DataDog/datadog-agent/pkg/aggregator/sender"
+	"github.com/DataDog/datadog-agent/pkg/metrics"
 	"github.com/DataDog/datadog-agent/pkg/metrics/servicecheck"
 )
 
@@ -89,6 +90,11 @@ func (ss *safeSender) HistogramBucket(metric string, value int64, lowerBound, up
 	ss.Sender.HistogramBucket(metric, value, lowerBound, upperBound, monotonic, hostname, cloneTags(tags), flushFirstValue)
 }
 
+// ExponentialHistogram implements sender.Sender#ExponentialHistogram.
+func (ss *safeSender) ExponentialHistogram(metric string, histogram *metrics.ExponentialHistogram, monotonic bool, hostname string, tags []string, flushFirstValue bool) {
+	ss.Sender.ExponentialHistogram(metric, histogram, monotonic, hostname, cloneTags(tags), flushFirstValue)
+}
+
 // SetCheckCustomTags implements sender.Sender#SetCheckCustomTags.
 func (ss *safeSender) SetCheckCustomTags(tags []string) {
 	ss.Sender.SetCheckCustomTags(cloneTags(tags))
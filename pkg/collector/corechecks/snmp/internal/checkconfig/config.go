@@ -73,6 +73,7 @@ type InitConfig struct {
 	Namespace                    string           `yaml:"namespace"`
 	DetectMetricsEnabled         Boolean          `yaml:"experimental_detect_metrics_enabled"`
 	DetectMetricsRefreshInterval int              `yaml:"experimental_detect_metrics_refresh_interval"`
+	ColumnCacheRefreshInterval   int              `yaml:"column_cache_refresh_interval"`
 }
 
 // InstanceConfig is used to deserialize integration instance config
@@ -132,6 +133,10 @@ type InstanceConfig struct {
 	DetectMetricsEnabled         *Boolean `yaml:"experimental_detect_metrics_enabled"`
 	DetectMetricsRefreshInterval int      `yaml:"experimental_detect_metrics_refresh_interval"`
 
+	// The rarely changing columns, like ifName or ifAlias, are only fetched every
+	// column_cache_refresh_interval seconds when set
+	ColumnCacheRefreshInterval int `yaml:"column_cache_refresh_interval"`
+
 	// `interface_configs` option is not supported by SNMP corecheck autodiscovery (`network_address`)
 	// it's only supported for single device instance (`ip_address`)
 	InterfaceConfigs InterfaceConfigs `yaml:"interface_configs"`
@@ -177,6 +182,8 @@ type CheckConfig struct {
 	DetectMetricsEnabled         bool
 	DetectMetricsRefreshInterval int
 
+	ColumnCacheRefreshInterval int
+
 	Network                  string
 	DiscoveryWorkers         int
 	Workers                  int
@@ -354,6 +361,12 @@ func NewCheckConfig(rawInstance integration.Data, rawInitConfig integration.Data
 		c.DetectMetricsRefreshInterval = defaultDetectMetricsRefreshInterval
 	}
 
+	if instance.ColumnCacheRefreshInterval != 0 {
+		c.ColumnCacheRefreshInterval = instance.ColumnCacheRefreshInterval
+	} else {
+		c.ColumnCacheRefreshInterval = initConfig.ColumnCacheRefreshInterval
+	}
+
 	if instance.UseDeviceIDAsHostname != nil {
 		c.UseDeviceIDAsHostname = bool(*instance.UseDeviceIDAsHostname)
 	} else {
@@ -635,6 +648,7 @@ func (c *CheckConfig) Copy() *CheckConfig {
 	newConfig.AutodetectProfile = c.AutodetectProfile
 	newConfig.DetectMetricsEnabled = c.DetectMetricsEnabled
 	newConfig.DetectMetricsRefreshInterval = c.DetectMetricsRefreshInterval
+	newConfig.ColumnCacheRefreshInterval = c.ColumnCacheRefreshInterval
 	newConfig.MinCollectionInterval = c.MinCollectionInterval
 	newConfig.InterfaceConfigs = c.InterfaceConfigs
 
@@ -654,6 +668,31 @@ func (c *CheckConfig) IsDiscovery() bool {
 	return c.Network != ""
 }
 
+// GetProfileOids returns the scalar and column oids used by the profile, not
+// including the ones used by default like the device metadata ones
+func (c *CheckConfig) GetProfileOids() ([]string, []string) {
+	if c.ProfileDef == nil {
+		return nil, nil
+	}
+	scalarOids := c.parseScalarOids(c.ProfileDef.Metrics, c.ProfileDef.MetricTags, c.ProfileDef.Metadata)
+	columnOids := c.parseColumnOids(c.ProfileDef.Metrics, c.ProfileDef.Metadata)
+	return uniqueNonEmptyOids(scalarOids), uniqueNonEmptyOids(columnOids)
+}
+
+func uniqueNonEmptyOids(oids []string) []string {
+	var uniqueOids []string
+	seen := make(map[string]bool, len(oids))
+	for _, oid := range oids {
+		if oid == "" || seen[oid] {
+			continue
+		}
+		seen[oid] = true
+		uniqueOids = append(uniqueOids, oid)
+	}
+	sort.Strings(uniqueOids)
+	return uniqueOids
+}
+
 func (c *CheckConfig) parseScalarOids(metrics []MetricsConfig, metricTags []MetricTagConfig, metadataConfigs MetadataConfig) []string {
 	var oids []string
 	for _, metric := range metrics {
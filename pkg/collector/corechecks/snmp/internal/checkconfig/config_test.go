@@ -1542,6 +1542,35 @@ experimental_detect_metrics_refresh_interval: 30
 	assert.Equal(t, 20, config.DetectMetricsRefreshInterval)
 }
 
+func Test_buildConfig_ColumnCacheRefreshInterval(t *testing.T) {
+	// language=yaml
+	rawInstanceConfig := []byte(`
+ip_address: 1.2.3.4
+community_string: "abc"
+`)
+	config, err := NewCheckConfig(rawInstanceConfig, []byte(``))
+	assert.Nil(t, err)
+	assert.Equal(t, 0, config.ColumnCacheRefreshInterval)
+
+	// language=yaml
+	rawInitConfig := []byte(`
+column_cache_refresh_interval: 3600
+`)
+	config, err = NewCheckConfig(rawInstanceConfig, rawInitConfig)
+	assert.Nil(t, err)
+	assert.Equal(t, 3600, config.ColumnCacheRefreshInterval)
+
+	// language=yaml
+	rawInstanceConfig = []byte(`
+ip_address: 1.2.3.4
+community_string: "abc"
+column_cache_refresh_interval: 600
+`)
+	config, err = NewCheckConfig(rawInstanceConfig, rawInitConfig)
+	assert.Nil(t, err)
+	assert.Equal(t, 600, config.ColumnCacheRefreshInterval)
+}
+
 func Test_buildConfig_minCollectionInterval(t *testing.T) {
 	tests := []struct {
 		name              string
@@ -146,6 +146,20 @@ func loadProfiles(pConfig profileConfigMap) (profileConfigMap, error) {
 	return profiles, nil
 }
 
+// ValidateProfileDefinitionFile reads a profile definition file and the profiles
+// it extends, returning the errors that are only logged when the profiles are loaded
+func ValidateProfileDefinitionFile(definitionFile string) error {
+	profDefinition, err := readProfileDefinition(definitionFile)
+	if err != nil {
+		return err
+	}
+	err = recursivelyExpandBaseProfiles(definitionFile, profDefinition, profDefinition.Extends, []string{})
+	if err != nil {
+		return fmt.Errorf("failed to expand profile: %s", err)
+	}
+	return nil
+}
+
 func readProfileDefinition(definitionFile string) (*profileDefinition, error) {
 	filePath := resolveProfileDefinitionPath(definitionFile)
 	buf, err := os.ReadFile(filePath)
@@ -48,6 +48,7 @@ type DeviceCheck struct {
 	config                 *checkconfig.CheckConfig
 	sender                 *report.MetricSender
 	session                session.Session
+	planner                *fetch.Planner
 	sessionCloseErrorCount *atomic.Uint64
 	savedDynamicTags       []string
 	nextAutodetectMetrics  time.Time
@@ -65,6 +66,7 @@ func NewDeviceCheck(config *checkconfig.CheckConfig, ipAddress string, sessionFa
 	return &DeviceCheck{
 		config:                 newConfig,
 		session:                sess,
+		planner:                fetch.NewPlanner(newConfig.DeviceID),
 		sessionCloseErrorCount: atomic.NewUint64(0),
 		nextAutodetectMetrics:  timeNow(),
 	}, nil
@@ -192,7 +194,7 @@ func (d *DeviceCheck) getValuesAndTags() (bool, []string, *valuestore.ResultValu
 
 	tags = append(tags, d.config.ProfileTags...)
 
-	valuesStore, err := fetch.Fetch(d.session, d.config)
+	valuesStore, err := d.planner.Fetch(d.session, d.config)
 	if log.ShouldLog(seelog.DebugLvl) {
 		log.Debugf("fetched values: %v", valuestore.ResultValueStoreAsString(valuesStore))
 	}
@@ -316,6 +316,9 @@ profiles:
 
 	assert.Equal(t, false, deviceCk.config.AutodetectProfile)
 
+	// The fetch planner gets the known rows of the small tables fetched during the first run
+	sess.On("Get", mock.Anything).Return(&gosnmp.SnmpPacket{}, nil)
+
 	// Make sure we don't auto detect and add metrics twice if we already did that previously
 	firstRunMetrics := deviceCk.config.Metrics
 	firstRunMetricsTags := deviceCk.config.MetricTags
@@ -653,6 +656,9 @@ collect_topology: false
 	expectedNextAutodetectMetricsTime := savedAutodetectMetricsTime.Add(time.Duration(deviceCk.config.DetectMetricsRefreshInterval) * time.Second)
 	assert.WithinDuration(t, expectedNextAutodetectMetricsTime, deviceCk.nextAutodetectMetrics, 3*time.Second)
 
+	// The fetch planner gets the known rows of the small tables fetched during the first run
+	sess.On("Get", mock.Anything).Return(&gosnmp.SnmpPacket{}, nil)
+
 	// Make sure we don't auto detect and add metrics twice if we already did that previously
 	firstRunMetrics := deviceCk.config.Metrics
 	firstRunMetricsTags := deviceCk.config.MetricTags
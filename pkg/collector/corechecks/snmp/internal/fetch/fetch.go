@@ -6,14 +6,7 @@
 package fetch
 
 import (
-	"fmt"
 	"strconv"
-
-	"github.com/DataDog/datadog-agent/pkg/util/log"
-
-	"github.com/DataDog/datadog-agent/pkg/collector/corechecks/snmp/internal/checkconfig"
-	"github.com/DataDog/datadog-agent/pkg/collector/corechecks/snmp/internal/session"
-	"github.com/DataDog/datadog-agent/pkg/collector/corechecks/snmp/internal/valuestore"
 )
 
 type columnFetchStrategy int
@@ -33,31 +26,3 @@ func (c columnFetchStrategy) String() string {
 		return strconv.Itoa(int(c))
 	}
 }
-
-// Fetch oid values from device
-// TODO: pass only specific configs instead of the whole CheckConfig
-func Fetch(sess session.Session, config *checkconfig.CheckConfig) (*valuestore.ResultValueStore, error) {
-	// fetch scalar values
-	scalarResults, err := fetchScalarOidsWithBatching(sess, config.OidConfig.ScalarOids, config.OidBatchSize)
-	if err != nil {
-		return nil, fmt.Errorf("failed to fetch scalar oids with batching: %v", err)
-	}
-
-	// fetch column values
-	oids := make(map[string]string, len(config.OidConfig.ColumnOids))
-	for _, value := range config.OidConfig.ColumnOids {
-		oids[value] = value
-	}
-
-	columnResults, err := fetchColumnOidsWithBatching(sess, oids, config.OidBatchSize, config.BulkMaxRepetitions, useGetBulk)
-	if err != nil {
-		log.Debugf("failed to fetch oids with GetBulk batching: %v", err)
-
-		columnResults, err = fetchColumnOidsWithBatching(sess, oids, config.OidBatchSize, config.BulkMaxRepetitions, useGetNext)
-		if err != nil {
-			return nil, fmt.Errorf("failed to fetch oids with GetNext batching: %v", err)
-		}
-	}
-
-	return &valuestore.ResultValueStore{ScalarValues: scalarResults, ColumnValues: columnResults}, nil
-}
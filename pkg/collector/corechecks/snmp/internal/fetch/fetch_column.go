@@ -20,9 +20,13 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/snmp/gosnmplib"
 )
 
-func fetchColumnOidsWithBatching(sess session.Session, oids map[string]string, oidBatchSize int, bulkMaxRepetitions uint32, fetchStrategy columnFetchStrategy) (valuestore.ColumnResultValuesType, error) {
-	retValues := make(valuestore.ColumnResultValuesType, len(oids))
+// columnBatch is a batch of column oids fetched together, with the max repetitions of their GetBulk requests
+type columnBatch struct {
+	oids               []string
+	bulkMaxRepetitions uint32
+}
 
+func fetchColumnOidsWithBatching(sess session.Session, oids map[string]string, oidBatchSize int, bulkMaxRepetitions uint32, fetchStrategy columnFetchStrategy) (valuestore.ColumnResultValuesType, error) {
 	columnOids := getOidsMapKeys(oids)
 	sort.Strings(columnOids) // sorting ColumnOids to make them deterministic for testing purpose
 	batches, err := common.CreateStringBatches(columnOids, oidBatchSize)
@@ -30,13 +34,23 @@ func fetchColumnOidsWithBatching(sess session.Session, oids map[string]string, o
 		return nil, fmt.Errorf("failed to create column oid batches: %s", err)
 	}
 
+	columnBatches := make([]columnBatch, 0, len(batches))
 	for _, batchColumnOids := range batches {
-		oidsToFetch := make(map[string]string, len(batchColumnOids))
-		for _, oid := range batchColumnOids {
+		columnBatches = append(columnBatches, columnBatch{oids: batchColumnOids, bulkMaxRepetitions: bulkMaxRepetitions})
+	}
+	return fetchColumnOidsBatches(sess, oids, columnBatches, fetchStrategy)
+}
+
+func fetchColumnOidsBatches(sess session.Session, oids map[string]string, batches []columnBatch, fetchStrategy columnFetchStrategy) (valuestore.ColumnResultValuesType, error) {
+	retValues := make(valuestore.ColumnResultValuesType, len(oids))
+
+	for _, batch := range batches {
+		oidsToFetch := make(map[string]string, len(batch.oids))
+		for _, oid := range batch.oids {
 			oidsToFetch[oid] = oids[oid]
 		}
 
-		results, err := fetchColumnOids(sess, oidsToFetch, bulkMaxRepetitions, fetchStrategy)
+		results, err := fetchColumnOids(sess, oidsToFetch, batch.bulkMaxRepetitions, fetchStrategy)
 		if err != nil {
 			return nil, fmt.Errorf("failed to fetch column oids: %s", err)
 		}
@@ -121,6 +135,55 @@ func getResults(sess session.Session, requestOids []string, bulkMaxRepetitions u
 	return results, nil
 }
 
+// fetchColumnOidsWithGet fetches the known rows of columns with Get requests,
+// `columns` mapping the column oids to their row indexes. The rows missing from
+// the device are missing from the results.
+func fetchColumnOidsWithGet(sess session.Session, columns map[string][]string, oidBatchSize int) (valuestore.ColumnResultValuesType, error) {
+	retValues := make(valuestore.ColumnResultValuesType, len(columns))
+	if len(columns) == 0 {
+		return retValues, nil
+	}
+
+	// sorting columnOids to make them deterministic for testing purpose
+	columnOids := make([]string, 0, len(columns))
+	for columnOid := range columns {
+		columnOids = append(columnOids, columnOid)
+	}
+	sort.Strings(columnOids)
+	var rowOids []string
+	for _, columnOid := range columnOids {
+		for _, index := range columns[columnOid] {
+			rowOids = append(rowOids, columnOid+"."+index)
+		}
+	}
+
+	batches, err := common.CreateStringBatches(rowOids, oidBatchSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create row oid batches: %s", err)
+	}
+	values := make(valuestore.ScalarResultValuesType, len(rowOids))
+	for _, batchOids := range batches {
+		results, err := doFetchScalarOids(sess, batchOids)
+		if err != nil {
+			return nil, err
+		}
+		for oid, value := range valuestore.ResultToScalarValues(results) {
+			values[oid] = value
+		}
+	}
+
+	for _, columnOid := range columnOids {
+		columnValues := make(map[string]valuestore.ResultValue, len(columns[columnOid]))
+		for _, index := range columns[columnOid] {
+			if value, ok := values[columnOid+"."+index]; ok {
+				columnValues[index] = value
+			}
+		}
+		retValues[columnOid] = columnValues
+	}
+	return retValues, nil
+}
+
 func updateColumnResultValues(valuesToUpdate valuestore.ColumnResultValuesType, extraValues valuestore.ColumnResultValuesType) {
 	for columnOid, columnValues := range extraValues {
 		for oid, value := range columnValues {
@@ -0,0 +1,375 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023-present Datadog, Inc.
+
+package fetch
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/gosnmp/gosnmp"
+
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+
+	"github.com/DataDog/datadog-agent/pkg/collector/corechecks/snmp/common"
+	"github.com/DataDog/datadog-agent/pkg/collector/corechecks/snmp/internal/checkconfig"
+	"github.com/DataDog/datadog-agent/pkg/collector/corechecks/snmp/internal/session"
+	"github.com/DataDog/datadog-agent/pkg/collector/corechecks/snmp/internal/valuestore"
+)
+
+// learnedLimitsTTL is how long the response limits learned from a device are
+// used before trying again with the configured ones, e.g. in case the device
+// was overloaded or its configuration changed
+const learnedLimitsTTL = time.Hour
+
+// knownIndexesTTL is how long the row indexes of the small tables are fetched
+// with Get requests before walking the tables again, to find their new rows
+const knownIndexesTTL = 10 * time.Minute
+
+// getMaxRows is the number of rows up to which the known rows of a table are
+// fetched with Get requests rather than by walking the table
+const getMaxRows = 5
+
+// define timeNow as variable to make it possible to mock it during test
+var timeNow = time.Now
+
+// cacheableColumns are the rarely changing columns that can be cached between
+// check runs when `column_cache_refresh_interval` is set
+var cacheableColumns = map[string]bool{
+	"1.3.6.1.2.1.2.2.1.2":      true, // ifDescr
+	"1.3.6.1.2.1.2.2.1.3":      true, // ifType
+	"1.3.6.1.2.1.2.2.1.6":      true, // ifPhysAddress
+	"1.3.6.1.2.1.31.1.1.1.1":   true, // ifName
+	"1.3.6.1.2.1.31.1.1.1.18":  true, // ifAlias
+	"1.3.6.1.2.1.47.1.1.1.1.2": true, // entPhysicalDescr
+	"1.3.6.1.2.1.47.1.1.1.1.7": true, // entPhysicalName
+}
+
+// Planner plans the requests fetching the values of a device from what it
+// learned during the previous check runs: the size of the tables, how many
+// variables the responses are limited to and whether GetBulk requests fail.
+// The known rows of the small tables are fetched with Get requests. It also
+// caches the rarely changing columns.
+type Planner struct {
+	deviceID string
+
+	tableSizes         map[string]int      // number of rows by column oid
+	knownIndexes       map[string][]string // row indexes by column oid
+	knownIndexesExpiry time.Time
+
+	// response limits learned from the device, 0 when unknown
+	bulkMaxRepetitions   uint32
+	maxVarbinds          int
+	getBulkDisabledUntil time.Time
+	limitsExpiry         time.Time
+
+	cachedColumns valuestore.ColumnResultValuesType
+	cacheExpiry   time.Time
+}
+
+// NewPlanner returns a planner that doesn't know anything about the device yet
+func NewPlanner(deviceID string) *Planner {
+	return &Planner{
+		deviceID:     deviceID,
+		tableSizes:   make(map[string]int),
+		knownIndexes: make(map[string][]string),
+	}
+}
+
+// Fetch oid values from device, without anything learned from the previous fetches.
+// The stats of the fetch are not reported, they would replace the ones of the
+// planner of the device.
+// TODO: pass only specific configs instead of the whole CheckConfig
+func Fetch(sess session.Session, config *checkconfig.CheckConfig) (*valuestore.ResultValueStore, error) {
+	values, _, err := NewPlanner(config.DeviceID).fetch(sess, config)
+	return values, err
+}
+
+// Fetch oid values from device, and learns from the requests for the next fetches
+func (p *Planner) Fetch(sess session.Session, config *checkconfig.CheckConfig) (*valuestore.ResultValueStore, error) {
+	start := timeNow()
+	recorder := newRecordingSession(sess)
+
+	values, strategy, err := p.fetch(recorder, config)
+
+	p.learn(recorder.requests, values)
+	p.reportStats(start, recorder.requests, config, strategy, err)
+	return values, err
+}
+
+func (p *Planner) fetch(sess session.Session, config *checkconfig.CheckConfig) (*valuestore.ResultValueStore, columnFetchStrategy, error) {
+	// fetch scalar values
+	scalarResults, err := fetchScalarOidsWithBatching(sess, config.OidConfig.ScalarOids, config.OidBatchSize)
+	if err != nil {
+		return nil, useGetBulk, fmt.Errorf("failed to fetch scalar oids with batching: %v", err)
+	}
+
+	// fetch column values, the cached ones excepted
+	useCache := p.isCacheValid(config)
+	oids := make(map[string]string, len(config.OidConfig.ColumnOids))
+	for _, value := range config.OidConfig.ColumnOids {
+		if _, cached := p.cachedColumns[value]; useCache && cached {
+			continue
+		}
+		oids[value] = value
+	}
+
+	// fetch the known rows of the small tables
+	getColumns := p.planGetColumns(oids)
+	getResults, err := fetchColumnOidsWithGet(sess, getColumns, config.OidBatchSize)
+	if err != nil {
+		return nil, useGetBulk, fmt.Errorf("failed to fetch column oids with Get batching: %v", err)
+	}
+	for columnOid := range getColumns {
+		delete(oids, columnOid)
+	}
+
+	batches, err := p.planColumnBatches(oids, config)
+	if err != nil {
+		return nil, useGetBulk, fmt.Errorf("failed to fetch oids with GetBulk batching: %v", err)
+	}
+
+	strategy := useGetBulk
+	if sess.GetVersion() == gosnmp.Version1 || timeNow().Before(p.getBulkDisabledUntil) {
+		strategy = useGetNext
+	}
+	var columnResults valuestore.ColumnResultValuesType
+	if strategy == useGetBulk {
+		columnResults, err = fetchColumnOidsBatches(sess, oids, batches, useGetBulk)
+		if err != nil {
+			log.Debugf("failed to fetch oids with GetBulk batching: %v", err)
+			strategy = useGetNext
+		}
+	}
+	if strategy == useGetNext {
+		columnResults, err = fetchColumnOidsBatches(sess, oids, batches, useGetNext)
+		if err != nil {
+			return nil, strategy, fmt.Errorf("failed to fetch oids with GetNext batching: %v", err)
+		}
+	}
+
+	for columnOid, columnValues := range getResults {
+		columnResults[columnOid] = columnValues
+		oids[columnOid] = columnOid
+	}
+
+	if config.ColumnCacheRefreshInterval > 0 {
+		if useCache {
+			for columnOid, columnValues := range p.cachedColumns {
+				if _, fetched := columnResults[columnOid]; !fetched {
+					columnResults[columnOid] = copyColumnValues(columnValues)
+				}
+			}
+		}
+		p.updateCache(oids, columnResults, useCache, config)
+	}
+
+	return &valuestore.ResultValueStore{ScalarValues: scalarResults, ColumnValues: columnResults}, strategy, nil
+}
+
+// planGetColumns returns the row indexes of the columns fetched with Get
+// requests: the tables with few rows, until their indexes must be walked again
+func (p *Planner) planGetColumns(oids map[string]string) map[string][]string {
+	getColumns := make(map[string][]string)
+	if !timeNow().Before(p.knownIndexesExpiry) {
+		return getColumns
+	}
+	for columnOid := range oids {
+		indexes, known := p.knownIndexes[columnOid]
+		if known && len(indexes) > 0 && len(indexes) <= getMaxRows {
+			getColumns[columnOid] = indexes
+		}
+	}
+	return getColumns
+}
+
+// planColumnBatches batches the columns having tables of similar sizes together,
+// so that the GetBulk requests of each batch fetch as few extra rows as possible
+func (p *Planner) planColumnBatches(oids map[string]string, config *checkconfig.CheckConfig) ([]columnBatch, error) {
+	columnOids := getOidsMapKeys(oids)
+	sort.Slice(columnOids, func(i, j int) bool {
+		sizeI, sizeJ := p.tableSizes[columnOids[i]], p.tableSizes[columnOids[j]]
+		if sizeI != sizeJ {
+			return sizeI < sizeJ
+		}
+		return columnOids[i] < columnOids[j]
+	})
+	batches, err := common.CreateStringBatches(columnOids, config.OidBatchSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create column oid batches: %s", err)
+	}
+
+	columnBatches := make([]columnBatch, 0, len(batches))
+	for _, batchColumnOids := range batches {
+		columnBatches = append(columnBatches, columnBatch{
+			oids:               batchColumnOids,
+			bulkMaxRepetitions: p.planBulkMaxRepetitions(batchColumnOids, config.BulkMaxRepetitions),
+		})
+	}
+	return columnBatches, nil
+}
+
+// planBulkMaxRepetitions returns the max repetitions of the GetBulk requests of
+// a batch: the configured ones, lowered to the response limits of the device
+// and to the size of the tables
+func (p *Planner) planBulkMaxRepetitions(columnOids []string, configured uint32) uint32 {
+	if timeNow().After(p.limitsExpiry) {
+		p.bulkMaxRepetitions = 0
+		p.maxVarbinds = 0
+	}
+
+	maxRepetitions := configured
+	if p.bulkMaxRepetitions > 0 && p.bulkMaxRepetitions < maxRepetitions {
+		maxRepetitions = p.bulkMaxRepetitions
+	}
+	if p.maxVarbinds > 0 {
+		limit := uint32(p.maxVarbinds / len(columnOids))
+		if limit < 1 {
+			limit = 1
+		}
+		if limit < maxRepetitions {
+			maxRepetitions = limit
+		}
+	}
+
+	// one more row than the largest table, to get its end in the same response
+	largestTable := 0
+	for _, oid := range columnOids {
+		size, known := p.tableSizes[oid]
+		if !known {
+			return maxRepetitions
+		}
+		if size > largestTable {
+			largestTable = size
+		}
+	}
+	if rows := uint32(largestTable + 1); rows < maxRepetitions {
+		maxRepetitions = rows
+	}
+	return maxRepetitions
+}
+
+// learn updates what the planner knows about the device from the requests
+// sent during a fetch: table sizes, response limits and GetBulk failures
+func (p *Planner) learn(requests []request, values *valuestore.ResultValueStore) {
+	now := timeNow()
+	for _, req := range requests {
+		if req.requestType != requestTypeGetBulk {
+			continue
+		}
+		if req.err != nil {
+			// GetBulk requests might time out or fail with tooBig errors when
+			// the responses are too large, retry with half the repetitions
+			if req.bulkMaxRepetitions > 1 {
+				p.bulkMaxRepetitions = req.bulkMaxRepetitions / 2
+			} else {
+				p.getBulkDisabledUntil = now.Add(learnedLimitsTTL)
+			}
+			p.limitsExpiry = now.Add(learnedLimitsTTL)
+			log.Debugf("device %s: GetBulk failed, max repetitions lowered to %d", p.deviceID, p.bulkMaxRepetitions)
+			break
+		}
+		if isTruncated(req) {
+			varbinds := len(req.result.Variables)
+			if p.maxVarbinds == 0 || varbinds < p.maxVarbinds {
+				p.maxVarbinds = varbinds
+				p.limitsExpiry = now.Add(learnedLimitsTTL)
+				log.Debugf("device %s: GetBulk responses limited to %d variables", p.deviceID, varbinds)
+			}
+		}
+	}
+
+	if values == nil {
+		return
+	}
+	for columnOid, columnValues := range values.ColumnValues {
+		p.tableSizes[columnOid] = len(columnValues)
+		indexes := make([]string, 0, len(columnValues))
+		for index := range columnValues {
+			indexes = append(indexes, index)
+		}
+		sort.Strings(indexes)
+		p.knownIndexes[columnOid] = indexes
+	}
+	// the tables were all walked, their rows are known until the next walk
+	if !now.Before(p.knownIndexesExpiry) {
+		p.knownIndexesExpiry = now.Add(knownIndexesTTL)
+	}
+}
+
+// isTruncated returns whether the device returned less variables than requested
+// by a GetBulk request, without reaching the end of the MIB view
+func isTruncated(req request) bool {
+	if req.result == nil {
+		return false
+	}
+	varbinds := len(req.result.Variables)
+	if varbinds == 0 || varbinds >= len(req.oids)*int(req.bulkMaxRepetitions) {
+		return false
+	}
+	return req.result.Variables[varbinds-1].Type != gosnmp.EndOfMibView
+}
+
+func (p *Planner) isCacheValid(config *checkconfig.CheckConfig) bool {
+	return config.ColumnCacheRefreshInterval > 0 && timeNow().Before(p.cacheExpiry)
+}
+
+// updateCache stores the fetched cacheable columns, the cache expiring after the
+// refresh interval when all of them were fetched
+func (p *Planner) updateCache(fetchedOids map[string]string, columnResults valuestore.ColumnResultValuesType, useCache bool, config *checkconfig.CheckConfig) {
+	if !useCache {
+		p.cachedColumns = make(valuestore.ColumnResultValuesType)
+		p.cacheExpiry = timeNow().Add(time.Duration(config.ColumnCacheRefreshInterval) * time.Second)
+	}
+	for columnOid := range fetchedOids {
+		if !cacheableColumns[columnOid] {
+			continue
+		}
+		p.cachedColumns[columnOid] = copyColumnValues(columnResults[columnOid])
+	}
+}
+
+func copyColumnValues(columnValues map[string]valuestore.ResultValue) map[string]valuestore.ResultValue {
+	newValues := make(map[string]valuestore.ResultValue, len(columnValues))
+	for index, value := range columnValues {
+		newValues[index] = value
+	}
+	return newValues
+}
+
+func (p *Planner) reportStats(start time.Time, requests []request, config *checkconfig.CheckConfig, strategy columnFetchStrategy, err error) {
+	if p.deviceID == "" {
+		return
+	}
+	fetchedOids := append(common.CopyStrings(config.OidConfig.ScalarOids), config.OidConfig.ColumnOids...)
+	stats := FetchStats{
+		Timestamp:          start.Unix(),
+		Duration:           timeNow().Sub(start),
+		Strategy:           requestTypeGetBulk,
+		Requests:           make(map[string]int),
+		BulkMaxRepetitions: p.bulkMaxRepetitions,
+		MaxVarbinds:        p.maxVarbinds,
+		GetBulkDisabled:    timeNow().Before(p.getBulkDisabledUntil),
+		OIDs:               buildOIDStats(requests, fetchedOids),
+	}
+	if strategy == useGetNext {
+		stats.Strategy = requestTypeGetNext
+	}
+	if err != nil {
+		stats.Error = err.Error()
+	}
+	for _, req := range requests {
+		stats.Requests[req.requestType]++
+	}
+	for _, oid := range config.OidConfig.ColumnOids {
+		oidStats := stats.OIDs[oid]
+		oidStats.Rows = p.tableSizes[oid]
+		_, oidStats.Cached = p.cachedColumns[oid]
+		oidStats.Cached = oidStats.Cached && oidStats.Requests == 0
+		stats.OIDs[oid] = oidStats
+	}
+	setFetchStats(p.deviceID, stats)
+}
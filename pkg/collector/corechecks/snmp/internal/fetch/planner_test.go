@@ -0,0 +1,277 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023-present Datadog, Inc.
+
+package fetch
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/gosnmp/gosnmp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/collector/corechecks/snmp/internal/checkconfig"
+	"github.com/DataDog/datadog-agent/pkg/collector/corechecks/snmp/internal/session"
+	"github.com/DataDog/datadog-agent/pkg/collector/corechecks/snmp/internal/valuestore"
+)
+
+func mockTimeNow(t *testing.T, now *time.Time) {
+	timeNow = func() time.Time { return *now }
+	t.Cleanup(func() { timeNow = time.Now })
+}
+
+// createTablePacket creates the response to a GetBulk request of the columns
+// of a table, the repetitions after its last row being the next OIDs of the MIB
+func createTablePacket(columns []string, rows int, repetitions int) *gosnmp.SnmpPacket {
+	var variables []gosnmp.SnmpPDU
+	for row := 1; row <= repetitions; row++ {
+		for _, column := range columns {
+			name := fmt.Sprintf("%s.%d", column, row)
+			if row > rows {
+				name = fmt.Sprintf("1.9.%d", row)
+			}
+			variables = append(variables, gosnmp.SnmpPDU{Name: name, Type: gosnmp.Integer, Value: row})
+		}
+	}
+	return &gosnmp.SnmpPacket{Variables: variables}
+}
+
+func TestPlanner_tableSizes(t *testing.T) {
+	now := time.Now()
+	mockTimeNow(t, &now)
+
+	config := &checkconfig.CheckConfig{
+		DeviceID:           "default:1.2.3.4",
+		BulkMaxRepetitions: checkconfig.DefaultBulkMaxRepetitions,
+		OidBatchSize:       2,
+		OidConfig: checkconfig.OidConfig{
+			ColumnOids: []string{"1.1.1", "1.1.2", "1.1.3"},
+		},
+	}
+
+	sess := session.CreateMockSession()
+	sess.On("GetBulk", []string{"1.1.1", "1.1.2"}, uint32(10)).Return(createTablePacket([]string{"1.1.1", "1.1.2"}, 8, 10), nil).Once()
+	sess.On("GetBulk", []string{"1.1.3"}, uint32(10)).Return(createTablePacket([]string{"1.1.3"}, 6, 10), nil).Once()
+
+	planner := NewPlanner(config.DeviceID)
+	values, err := planner.Fetch(sess, config)
+	require.NoError(t, err)
+	assert.Len(t, values.ColumnValues["1.1.1"], 8)
+	assert.Equal(t, map[string]int{"1.1.1": 8, "1.1.2": 8, "1.1.3": 6}, planner.tableSizes)
+
+	// the smallest tables are batched together, and the repetitions fetch one extra row
+	sess.On("GetBulk", []string{"1.1.1", "1.1.3"}, uint32(9)).Return(createTablePacket([]string{"1.1.1", "1.1.3"}, 8, 9), nil).Once()
+	sess.On("GetBulk", []string{"1.1.2"}, uint32(9)).Return(createTablePacket([]string{"1.1.2"}, 8, 9), nil).Once()
+
+	_, err = planner.Fetch(sess, config)
+	require.NoError(t, err)
+	sess.AssertExpectations(t)
+
+	stats := GetFetchStats()[config.DeviceID]
+	assert.Equal(t, requestTypeGetBulk, stats.Strategy)
+	assert.Equal(t, map[string]int{requestTypeGetBulk: 2}, stats.Requests)
+	assert.Equal(t, 1, stats.OIDs["1.1.2"].Requests)
+	assert.Equal(t, 8, stats.OIDs["1.1.2"].Rows)
+}
+
+func TestPlanner_get(t *testing.T) {
+	now := time.Now()
+	mockTimeNow(t, &now)
+
+	config := &checkconfig.CheckConfig{
+		BulkMaxRepetitions: checkconfig.DefaultBulkMaxRepetitions,
+		OidBatchSize:       3,
+		OidConfig: checkconfig.OidConfig{
+			ColumnOids: []string{"1.1.1", "1.1.2", "1.1.3"},
+		},
+	}
+
+	sess := session.CreateMockSession()
+	sess.On("GetBulk", []string{"1.1.1", "1.1.2", "1.1.3"}, uint32(10)).Return(createTablePacket([]string{"1.1.1", "1.1.2", "1.1.3"}, 2, 10), nil).Once()
+
+	planner := NewPlanner("")
+	_, err := planner.Fetch(sess, config)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"1", "2"}, planner.knownIndexes["1.1.1"])
+
+	// the known rows of the small tables are fetched with Get requests, the
+	// rows removed from the device are dropped
+	sess.On("Get", []string{"1.1.1.1", "1.1.1.2", "1.1.2.1"}).Return(&gosnmp.SnmpPacket{Variables: []gosnmp.SnmpPDU{
+		{Name: "1.1.1.1", Type: gosnmp.Integer, Value: 1},
+		{Name: "1.1.1.2", Type: gosnmp.NoSuchInstance},
+		{Name: "1.1.2.1", Type: gosnmp.Integer, Value: 1},
+	}}, nil).Once()
+	sess.On("Get", []string{"1.1.2.2", "1.1.3.1", "1.1.3.2"}).Return(&gosnmp.SnmpPacket{Variables: []gosnmp.SnmpPDU{
+		{Name: "1.1.2.2", Type: gosnmp.Integer, Value: 2},
+		{Name: "1.1.3.1", Type: gosnmp.Integer, Value: 1},
+		{Name: "1.1.3.2", Type: gosnmp.Integer, Value: 2},
+	}}, nil).Once()
+
+	values, err := planner.Fetch(sess, config)
+	require.NoError(t, err)
+	assert.Equal(t, valuestore.ColumnResultValuesType{
+		"1.1.1": {"1": valuestore.ResultValue{Value: float64(1)}},
+		"1.1.2": {"1": valuestore.ResultValue{Value: float64(1)}, "2": valuestore.ResultValue{Value: float64(2)}},
+		"1.1.3": {"1": valuestore.ResultValue{Value: float64(1)}, "2": valuestore.ResultValue{Value: float64(2)}},
+	}, values.ColumnValues)
+	assert.Equal(t, []string{"1"}, planner.knownIndexes["1.1.1"])
+
+	// the tables are walked again to find their new rows
+	now = now.Add(knownIndexesTTL)
+	sess.On("GetBulk", []string{"1.1.1", "1.1.2", "1.1.3"}, uint32(3)).Return(createTablePacket([]string{"1.1.1", "1.1.2", "1.1.3"}, 2, 3), nil).Once()
+	_, err = planner.Fetch(sess, config)
+	require.NoError(t, err)
+	sess.AssertExpectations(t)
+}
+
+func TestFetch_noStats(t *testing.T) {
+	config := &checkconfig.CheckConfig{
+		DeviceID:           "default:5.6.7.8",
+		BulkMaxRepetitions: checkconfig.DefaultBulkMaxRepetitions,
+		OidBatchSize:       10,
+		OidConfig: checkconfig.OidConfig{
+			ColumnOids: []string{"1.1.1"},
+		},
+	}
+
+	sess := session.CreateMockSession()
+	sess.On("GetBulk", []string{"1.1.1"}, uint32(10)).Return(createTablePacket([]string{"1.1.1"}, 2, 10), nil).Once()
+
+	// the stats of the device are left to its planner
+	_, err := Fetch(sess, config)
+	require.NoError(t, err)
+	assert.NotContains(t, GetFetchStats(), config.DeviceID)
+}
+
+func TestPlanner_maxVarbinds(t *testing.T) {
+	now := time.Now()
+	mockTimeNow(t, &now)
+
+	config := &checkconfig.CheckConfig{
+		BulkMaxRepetitions: checkconfig.DefaultBulkMaxRepetitions,
+		OidBatchSize:       10,
+		OidConfig: checkconfig.OidConfig{
+			ColumnOids: []string{"1.1.1", "1.1.2"},
+		},
+	}
+
+	// the device returns 4 variables out of the 20 requested
+	columns := []string{"1.1.1", "1.1.2"}
+	truncatedPacket := createTablePacket(columns, 2, 10)
+	truncatedPacket.Variables = truncatedPacket.Variables[:4]
+	sess := session.CreateMockSession()
+	sess.On("GetBulk", columns, uint32(10)).Return(truncatedPacket, nil).Once()
+	sess.On("GetBulk", []string{"1.1.1.2", "1.1.2.2"}, uint32(10)).Return(createTablePacket(columns, 0, 10), nil).Once()
+
+	planner := NewPlanner("")
+	_, err := planner.Fetch(sess, config)
+	require.NoError(t, err)
+	assert.Equal(t, 4, planner.maxVarbinds)
+
+	// the tables are walked with the learned limits
+	now = now.Add(knownIndexesTTL)
+	sess.On("GetBulk", columns, uint32(2)).Return(createTablePacket(columns, 2, 2), nil).Once()
+	sess.On("GetBulk", []string{"1.1.1.2", "1.1.2.2"}, uint32(2)).Return(createTablePacket(columns, 0, 2), nil).Once()
+	_, err = planner.Fetch(sess, config)
+	require.NoError(t, err)
+
+	// the learned limits expire
+	now = now.Add(learnedLimitsTTL + time.Second)
+	sess.On("GetBulk", columns, uint32(3)).Return(createTablePacket(columns, 2, 3), nil).Once()
+	_, err = planner.Fetch(sess, config)
+	require.NoError(t, err)
+	sess.AssertExpectations(t)
+}
+
+func TestPlanner_getBulkErrors(t *testing.T) {
+	now := time.Now()
+	mockTimeNow(t, &now)
+
+	config := &checkconfig.CheckConfig{
+		BulkMaxRepetitions: 2,
+		OidBatchSize:       10,
+		OidConfig: checkconfig.OidConfig{
+			ColumnOids: []string{"1.1.1"},
+		},
+	}
+	getNextPacket := session.CreateGetNextPacket("1.2.1.1", gosnmp.Integer, 1)
+
+	sess := session.CreateMockSession()
+	sess.On("GetBulk", []string{"1.1.1"}, uint32(2)).Return(&gosnmp.SnmpPacket{}, fmt.Errorf("timeout")).Once()
+	sess.On("GetNext", []string{"1.1.1"}).Return(getNextPacket, nil).Times(3)
+
+	planner := NewPlanner("")
+	_, err := planner.Fetch(sess, config)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(1), planner.bulkMaxRepetitions)
+
+	// the repetitions are halved until GetBulk is disabled
+	sess.On("GetBulk", []string{"1.1.1"}, uint32(1)).Return(&gosnmp.SnmpPacket{}, fmt.Errorf("timeout")).Once()
+	_, err = planner.Fetch(sess, config)
+	require.NoError(t, err)
+	assert.True(t, now.Before(planner.getBulkDisabledUntil))
+
+	_, err = planner.Fetch(sess, config)
+	require.NoError(t, err)
+	sess.AssertExpectations(t)
+}
+
+func TestPlanner_columnCache(t *testing.T) {
+	now := time.Now()
+	mockTimeNow(t, &now)
+
+	ifName := "1.3.6.1.2.1.31.1.1.1.1"
+	ifInOctets := "1.3.6.1.2.1.2.2.1.10"
+	config := &checkconfig.CheckConfig{
+		BulkMaxRepetitions:         checkconfig.DefaultBulkMaxRepetitions,
+		OidBatchSize:               10,
+		ColumnCacheRefreshInterval: 3600,
+		OidConfig: checkconfig.OidConfig{
+			ColumnOids: []string{ifInOctets, ifName},
+		},
+	}
+
+	sess := session.CreateMockSession()
+	sess.On("GetBulk", []string{ifInOctets, ifName}, uint32(10)).Return(createTablePacket([]string{ifInOctets, ifName}, 1, 10), nil).Once()
+	sess.On("Get", []string{ifInOctets + ".1"}).Return(session.CreateGetNextPacket(ifInOctets+".1", gosnmp.Integer, 1), nil).Once()
+
+	planner := NewPlanner("")
+	_, err := planner.Fetch(sess, config)
+	require.NoError(t, err)
+
+	values, err := planner.Fetch(sess, config)
+	require.NoError(t, err)
+	expectedValues := valuestore.ColumnResultValuesType{
+		ifInOctets: {"1": valuestore.ResultValue{Value: float64(1)}},
+		ifName:     {"1": valuestore.ResultValue{Value: float64(1)}},
+	}
+	assert.Equal(t, expectedValues, values.ColumnValues)
+
+	// the cached columns are fetched again once the cache expired
+	now = now.Add(time.Hour)
+	sess.On("GetBulk", []string{ifInOctets, ifName}, uint32(2)).Return(createTablePacket([]string{ifInOctets, ifName}, 1, 2), nil).Once()
+	_, err = planner.Fetch(sess, config)
+	require.NoError(t, err)
+	sess.AssertExpectations(t)
+}
+
+func Test_buildOIDStats(t *testing.T) {
+	requests := []request{
+		{requestType: requestTypeGet, oids: []string{"1.1.0", "1.2.0"}, duration: 10 * time.Millisecond},
+		{requestType: requestTypeGetBulk, oids: []string{"1.3.1", ".1.3.2.5"}, duration: 20 * time.Millisecond},
+		{requestType: requestTypeGetBulk, oids: []string{"1.3.2.10"}, duration: 5 * time.Millisecond},
+	}
+
+	stats := buildOIDStats(requests, []string{"1.1.0", "1.2.0", "1.3.1", "1.3.2"})
+
+	assert.Equal(t, map[string]OIDStats{
+		"1.1.0": {Requests: 1, Duration: 5 * time.Millisecond},
+		"1.2.0": {Requests: 1, Duration: 5 * time.Millisecond},
+		"1.3.1": {Requests: 1, Duration: 10 * time.Millisecond},
+		"1.3.2": {Requests: 2, Duration: 15 * time.Millisecond},
+	}, stats)
+}
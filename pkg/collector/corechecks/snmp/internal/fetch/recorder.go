@@ -0,0 +1,76 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023-present Datadog, Inc.
+
+package fetch
+
+import (
+	"time"
+
+	"github.com/gosnmp/gosnmp"
+
+	"github.com/DataDog/datadog-agent/pkg/collector/corechecks/snmp/internal/session"
+)
+
+const (
+	requestTypeGet     = "get"
+	requestTypeGetNext = "getnext"
+	requestTypeGetBulk = "getbulk"
+)
+
+// request is a request sent to a device, with its response
+type request struct {
+	requestType        string
+	oids               []string
+	bulkMaxRepetitions uint32
+	duration           time.Duration
+	result             *gosnmp.SnmpPacket
+	err                error
+}
+
+// recordingSession is a session recording the requests sent to the device, for
+// the planner to learn from them
+type recordingSession struct {
+	session.Session
+	requests []request
+}
+
+func newRecordingSession(sess session.Session) *recordingSession {
+	return &recordingSession{Session: sess}
+}
+
+// Get will send a SNMPGET command
+func (s *recordingSession) Get(oids []string) (*gosnmp.SnmpPacket, error) {
+	start := timeNow()
+	result, err := s.Session.Get(oids)
+	s.record(requestTypeGet, oids, 0, start, result, err)
+	return result, err
+}
+
+// GetBulk will send a SNMP BULKGET command
+func (s *recordingSession) GetBulk(oids []string, bulkMaxRepetitions uint32) (*gosnmp.SnmpPacket, error) {
+	start := timeNow()
+	result, err := s.Session.GetBulk(oids, bulkMaxRepetitions)
+	s.record(requestTypeGetBulk, oids, bulkMaxRepetitions, start, result, err)
+	return result, err
+}
+
+// GetNext will send a SNMP GETNEXT command
+func (s *recordingSession) GetNext(oids []string) (*gosnmp.SnmpPacket, error) {
+	start := timeNow()
+	result, err := s.Session.GetNext(oids)
+	s.record(requestTypeGetNext, oids, 0, start, result, err)
+	return result, err
+}
+
+func (s *recordingSession) record(requestType string, oids []string, bulkMaxRepetitions uint32, start time.Time, result *gosnmp.SnmpPacket, err error) {
+	s.requests = append(s.requests, request{
+		requestType:        requestType,
+		oids:               append([]string(nil), oids...),
+		bulkMaxRepetitions: bulkMaxRepetitions,
+		duration:           timeNow().Sub(start),
+		result:             result,
+		err:                err,
+	})
+}
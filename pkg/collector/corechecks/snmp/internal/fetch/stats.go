@@ -0,0 +1,113 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023-present Datadog, Inc.
+
+package fetch
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+// fetchStatsTTL is how long the stats of a device are kept without being
+// updated, e.g. after the device is no longer discovered
+const fetchStatsTTL = time.Hour
+
+// FetchStats contains the statistics of the last fetch of a device, served by
+// the agent API to the `agent snmp fetch-stats` command
+type FetchStats struct {
+	Timestamp int64          `json:"timestamp"`
+	Duration  time.Duration  `json:"duration"`
+	Error     string         `json:"error,omitempty"`
+	Strategy  string         `json:"strategy"`
+	Requests  map[string]int `json:"requests"` // by request type
+
+	// What the planner learned about the device
+	BulkMaxRepetitions uint32 `json:"bulk_max_repetitions"`
+	MaxVarbinds        int    `json:"max_varbinds,omitempty"`
+	GetBulkDisabled    bool   `json:"getbulk_disabled,omitempty"`
+
+	OIDs map[string]OIDStats `json:"oids"`
+}
+
+// OIDStats contains the statistics of the last fetch of a scalar or column OID
+type OIDStats struct {
+	Requests int `json:"requests"`
+	// Duration is the share of the duration of the requests fetching the OID
+	Duration time.Duration `json:"duration"`
+	Rows     int           `json:"rows,omitempty"` // columns only
+	Cached   bool          `json:"cached,omitempty"`
+}
+
+var (
+	fetchStatsMu sync.RWMutex
+	fetchStats   = make(map[string]FetchStats) // by device ID
+)
+
+func setFetchStats(deviceID string, stats FetchStats) {
+	fetchStatsMu.Lock()
+	defer fetchStatsMu.Unlock()
+
+	expired := timeNow().Add(-fetchStatsTTL).Unix()
+	for id, deviceStats := range fetchStats {
+		if deviceStats.Timestamp < expired {
+			delete(fetchStats, id)
+		}
+	}
+	fetchStats[deviceID] = stats
+}
+
+// GetFetchStats returns the statistics of the last fetch of each device, by
+// device ID
+func GetFetchStats() map[string]FetchStats {
+	fetchStatsMu.RLock()
+	defer fetchStatsMu.RUnlock()
+
+	stats := make(map[string]FetchStats, len(fetchStats))
+	for id, deviceStats := range fetchStats {
+		stats[id] = deviceStats
+	}
+	return stats
+}
+
+// buildOIDStats attributes the duration of the requests to the fetched OIDs,
+// each request OID being a scalar OID, or a column OID or one of its rows
+func buildOIDStats(requests []request, fetchedOids []string) map[string]OIDStats {
+	oidStats := make(map[string]OIDStats, len(fetchedOids))
+	for _, oid := range fetchedOids {
+		oidStats[oid] = OIDStats{}
+	}
+	for _, req := range requests {
+		if len(req.oids) == 0 {
+			continue
+		}
+		share := req.duration / time.Duration(len(req.oids))
+		for _, requestOid := range req.oids {
+			oid, found := findFetchedOid(oidStats, requestOid)
+			if !found {
+				continue
+			}
+			stats := oidStats[oid]
+			stats.Requests++
+			stats.Duration += share
+			oidStats[oid] = stats
+		}
+	}
+	return oidStats
+}
+
+func findFetchedOid(oidStats map[string]OIDStats, requestOid string) (string, bool) {
+	oid := strings.TrimLeft(requestOid, ".")
+	for {
+		if _, ok := oidStats[oid]; ok {
+			return oid, true
+		}
+		lastDot := strings.LastIndex(oid, ".")
+		if lastDot < 0 {
+			return "", false
+		}
+		oid = oid[:lastDot]
+	}
+}
@@ -18,6 +18,7 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/util/log"
 
 	devicemetadata "github.com/DataDog/datadog-agent/pkg/networkdevice/metadata"
+	"github.com/DataDog/datadog-agent/pkg/networkdevice/topology"
 
 	"github.com/DataDog/datadog-agent/pkg/collector/corechecks/snmp/common"
 	"github.com/DataDog/datadog-agent/pkg/collector/corechecks/snmp/internal/checkconfig"
@@ -45,6 +46,12 @@ func (ms *MetricSender) ReportNetworkDeviceMetadata(config *checkconfig.CheckCon
 	ipAddresses := buildNetworkIPAddressesMetadata(config.DeviceID, metadataStore)
 	topologyLinks := buildNetworkTopologyMetadata(config.DeviceID, metadataStore, interfaces)
 
+	if store != nil {
+		// keep the metadata previously shared while the device is unreachable
+		ms.updateDeviceCache(config, store, devices[0], interfaces, ipAddresses)
+		updateTopologyStore(config, metadataStore, devices[0], interfaces, ipAddresses)
+	}
+
 	metadataPayloads := devicemetadata.BatchPayloads(config.Namespace, config.ResolvedSubnetName, collectTime, devicemetadata.PayloadMetadataBatchSize, devices, interfaces, ipAddresses, topologyLinks, nil)
 
 	for _, payload := range metadataPayloads {
@@ -85,6 +92,54 @@ func (ms *MetricSender) ReportNetworkDeviceMetadata(config *checkconfig.CheckCon
 	}
 }
 
+// updateDeviceCache shares the device and interfaces metadata with the other
+// features of the Agent, like NetFlow that uses them to enrich the flows
+func (ms *MetricSender) updateDeviceCache(config *checkconfig.CheckConfig, store *valuestore.ResultValueStore, device devicemetadata.DeviceMetadata, interfaces []devicemetadata.InterfaceMetadata, ipAddresses []devicemetadata.IPAddressMetadata) {
+	deviceInfo := devicemetadata.DeviceInfo{
+		Name:       device.Name,
+		Tags:       device.Tags,
+		Interfaces: make(map[uint32]devicemetadata.InterfaceInfo, len(interfaces)),
+	}
+	for _, networkInterface := range interfaces {
+		// the speed is left empty when ifHighSpeed is not collected
+		speed, _ := ms.getIfHighSpeed(strconv.Itoa(int(networkInterface.Index)), store)
+		deviceInfo.Interfaces[uint32(networkInterface.Index)] = devicemetadata.InterfaceInfo{
+			Name:  networkInterface.Name,
+			Alias: networkInterface.Alias,
+			Speed: speed,
+		}
+	}
+
+	deviceIPAddresses := []string{config.IPAddress}
+	for _, ipAddress := range ipAddresses {
+		deviceIPAddresses = append(deviceIPAddresses, ipAddress.IPAddress)
+	}
+	devicemetadata.GetDeviceCache().Set(config.Namespace, deviceIPAddresses, deviceInfo)
+}
+
+// updateTopologyStore shares the LLDP and CDP neighbors of the device with the
+// topology graph of the Agent. Unlike the metadata payload, which only falls back
+// on CDP when there are no LLDP neighbors, both are used to build the graph.
+func updateTopologyStore(config *checkconfig.CheckConfig, store *metadata.Store, device devicemetadata.DeviceMetadata, interfaces []devicemetadata.InterfaceMetadata, ipAddresses []devicemetadata.IPAddressMetadata) {
+	topologyDevice := topology.Device{
+		ID:          config.DeviceID,
+		Name:        device.Name,
+		IPAddress:   config.IPAddress,
+		IPAddresses: make([]string, 0, len(ipAddresses)),
+		Interfaces:  make(map[string]string, len(interfaces)),
+	}
+	for _, ipAddress := range ipAddresses {
+		topologyDevice.IPAddresses = append(topologyDevice.IPAddresses, ipAddress.IPAddress)
+	}
+	for _, networkInterface := range interfaces {
+		topologyDevice.Interfaces[config.DeviceID+":"+strconv.Itoa(int(networkInterface.Index))] = networkInterface.Name
+	}
+
+	links := buildNetworkTopologyMetadataWithLLDP(config.DeviceID, store, interfaces)
+	links = append(links, buildNetworkTopologyMetadataWithCDP(config.DeviceID, store, interfaces)...)
+	topology.GetStore().Set(topologyDevice, links)
+}
+
 func computeInterfaceStatus(adminStatus common.IfAdminStatus, operStatus common.IfOperStatus) common.InterfaceStatus {
 	if adminStatus == common.AdminStatus_Up {
 		switch {
@@ -20,6 +20,7 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/util/log"
 
 	"github.com/DataDog/datadog-agent/pkg/networkdevice/metadata"
+	"github.com/DataDog/datadog-agent/pkg/networkdevice/topology"
 
 	"github.com/DataDog/datadog-agent/pkg/aggregator/mocksender"
 	"github.com/DataDog/datadog-agent/pkg/collector/corechecks/snmp/common"
@@ -381,6 +382,120 @@ func Test_metricSender_reportNetworkDeviceMetadata_withInterfaces(t *testing.T)
 	sender.AssertEventPlatformEvent(t, compactEvent.Bytes(), "network-devices-metadata")
 }
 
+func Test_metricSender_reportNetworkDeviceMetadata_deviceCache(t *testing.T) {
+	var store = &valuestore.ResultValueStore{
+		ScalarValues: valuestore.ScalarResultValuesType{
+			"1.3.6.1.2.1.1.5.0": valuestore.ResultValue{Value: "my-sys-name"},
+		},
+		ColumnValues: valuestore.ColumnResultValuesType{
+			"1.3.6.1.2.1.31.1.1.1.1": {
+				"1": valuestore.ResultValue{Value: "eth0"},
+				"2": valuestore.ResultValue{Value: "eth1"},
+			},
+			"1.3.6.1.2.1.31.1.1.1.18": {
+				"1": valuestore.ResultValue{Value: "uplink"},
+			},
+			"1.3.6.1.2.1.31.1.1.1.15": {
+				"1": valuestore.ResultValue{Value: float64(1000)},
+			},
+			"1.3.6.1.2.1.4.20.1.2": {
+				"10.0.0.1": valuestore.ResultValue{Value: float64(1)},
+			},
+		},
+	}
+	sender := mocksender.NewMockSender("testID") // required to initiate aggregator
+	sender.On("EventPlatformEvent", mock.Anything, mock.Anything).Return()
+	sender.On("Gauge", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return()
+	ms := &MetricSender{
+		sender: sender,
+	}
+
+	config := &checkconfig.CheckConfig{
+		IPAddress: "1.2.3.4",
+		DeviceID:  "device-cache-ns:1.2.3.4",
+		Namespace: "device-cache-ns",
+		Metadata:  checkconfig.LegacyMetadataConfig,
+	}
+	ms.ReportNetworkDeviceMetadata(config, store, []string{"tag2", "tag1"}, common.MockTimeNow(), metadata.DeviceStatusReachable)
+
+	expectedDevice := metadata.DeviceInfo{
+		Name: "my-sys-name",
+		Tags: []string{"tag1", "tag2"},
+		Interfaces: map[uint32]metadata.InterfaceInfo{
+			1: {Name: "eth0", Alias: "uplink", Speed: 1000000000},
+			2: {Name: "eth1"},
+		},
+	}
+	for _, ipAddress := range []string{"1.2.3.4", "10.0.0.1"} {
+		device, found := metadata.GetDeviceCache().Get("device-cache-ns", ipAddress)
+		assert.True(t, found)
+		assert.Equal(t, expectedDevice, device)
+	}
+
+	// the device metadata is kept while the device is unreachable
+	ms.ReportNetworkDeviceMetadata(config, nil, []string{"tag1", "tag2"}, common.MockTimeNow(), metadata.DeviceStatusUnreachable)
+	device, found := metadata.GetDeviceCache().Get("device-cache-ns", "1.2.3.4")
+	assert.True(t, found)
+	assert.Equal(t, expectedDevice, device)
+}
+
+func Test_metricSender_reportNetworkDeviceMetadata_topologyStore(t *testing.T) {
+	var store = &valuestore.ResultValueStore{
+		ScalarValues: valuestore.ScalarResultValuesType{
+			"1.3.6.1.2.1.1.5.0": valuestore.ResultValue{Value: "my-sys-name"},
+		},
+		ColumnValues: valuestore.ColumnResultValuesType{
+			"1.3.6.1.2.1.31.1.1.1.1": {
+				"1": valuestore.ResultValue{Value: "eth0"},
+			},
+			// cdpCacheDeviceId
+			"1.3.6.1.4.1.9.9.23.1.2.1.1.6": {
+				"1.3": valuestore.ResultValue{Value: "switch.example.com"},
+			},
+			// cdpCacheDevicePort
+			"1.3.6.1.4.1.9.9.23.1.2.1.1.7": {
+				"1.3": valuestore.ResultValue{Value: "Gi0/1"},
+			},
+			// cdpCacheSysName
+			"1.3.6.1.4.1.9.9.23.1.2.1.1.17": {
+				"1.3": valuestore.ResultValue{Value: "switch"},
+			},
+		},
+	}
+	sender := mocksender.NewMockSender("testID") // required to initiate aggregator
+	sender.On("EventPlatformEvent", mock.Anything, mock.Anything).Return()
+	sender.On("Gauge", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return()
+	ms := &MetricSender{
+		sender: sender,
+	}
+
+	metadataConfig := checkconfig.MetadataConfig{}
+	for resource, resourceConfig := range checkconfig.LegacyMetadataConfig {
+		metadataConfig[resource] = resourceConfig
+	}
+	for resource, resourceConfig := range checkconfig.TopologyMetadataConfig {
+		metadataConfig[resource] = resourceConfig
+	}
+	config := &checkconfig.CheckConfig{
+		IPAddress: "1.2.3.4",
+		DeviceID:  "topology-ns:1.2.3.4",
+		Namespace: "topology-ns",
+		Metadata:  metadataConfig,
+	}
+	ms.ReportNetworkDeviceMetadata(config, store, []string{"tag1"}, common.MockTimeNow(), metadata.DeviceStatusReachable)
+
+	graph := topology.GetStore().Graph()
+	assert.Contains(t, graph.Nodes, topology.Node{ID: "topology-ns:1.2.3.4", Name: "my-sys-name", IPAddress: "1.2.3.4", Monitored: true})
+	assert.Contains(t, graph.Nodes, topology.Node{ID: "neighbor:switch", Name: "switch"})
+	assert.Contains(t, graph.Edges, topology.Edge{
+		Source:          "neighbor:switch",
+		SourceInterface: "Gi0/1",
+		Target:          "topology-ns:1.2.3.4",
+		TargetInterface: "eth0",
+		SourceTypes:     []string{"cdp"},
+	})
+}
+
 func Test_metricSender_reportNetworkDeviceMetadata_fallbackOnFieldValue(t *testing.T) {
 	var emptyMetadataStore = &valuestore.ResultValueStore{
 		ColumnValues: valuestore.ColumnResultValuesType{},
@@ -0,0 +1,161 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023-present Datadog, Inc.
+
+package session
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gosnmp/gosnmp"
+)
+
+// WalkSession is a session serving the variables of a recorded walk, like an
+// agent of a device would, to run the check without the device
+type WalkSession struct {
+	variables []walkVariable // sorted by OID
+	version   gosnmp.SnmpVersion
+}
+
+type walkVariable struct {
+	oid []int
+	pdu gosnmp.SnmpPDU
+}
+
+// NewWalkSession creates a session serving the given variables
+func NewWalkSession(pdus []gosnmp.SnmpPDU) (*WalkSession, error) {
+	variables := make([]walkVariable, 0, len(pdus))
+	for _, pdu := range pdus {
+		oid, err := parseOID(pdu.Name)
+		if err != nil {
+			return nil, err
+		}
+		pdu.Name = "." + strings.TrimLeft(pdu.Name, ".")
+		variables = append(variables, walkVariable{oid: oid, pdu: pdu})
+	}
+	sort.SliceStable(variables, func(i, j int) bool {
+		return compareOIDs(variables[i].oid, variables[j].oid) < 0
+	})
+	return &WalkSession{variables: variables, version: gosnmp.Version2c}, nil
+}
+
+// Connect is used to create a new connection
+func (s *WalkSession) Connect() error {
+	return nil
+}
+
+// Close is used to close the connection
+func (s *WalkSession) Close() error {
+	return nil
+}
+
+// Get will send a SNMPGET command
+func (s *WalkSession) Get(oids []string) (*gosnmp.SnmpPacket, error) {
+	packet := &gosnmp.SnmpPacket{Variables: make([]gosnmp.SnmpPDU, 0, len(oids))}
+	for _, oid := range oids {
+		parsedOID, err := parseOID(oid)
+		if err != nil {
+			return nil, err
+		}
+		i := s.search(parsedOID)
+		if i < len(s.variables) && compareOIDs(s.variables[i].oid, parsedOID) == 0 {
+			packet.Variables = append(packet.Variables, s.variables[i].pdu)
+		} else {
+			packet.Variables = append(packet.Variables, gosnmp.SnmpPDU{Name: "." + strings.TrimLeft(oid, "."), Type: gosnmp.NoSuchObject})
+		}
+	}
+	return packet, nil
+}
+
+// GetBulk will send a SNMP BULKGET command
+func (s *WalkSession) GetBulk(oids []string, bulkMaxRepetitions uint32) (*gosnmp.SnmpPacket, error) {
+	packet := &gosnmp.SnmpPacket{Variables: make([]gosnmp.SnmpPDU, 0, len(oids)*int(bulkMaxRepetitions))}
+	nextIndexes := make([]int, len(oids))
+	for i, oid := range oids {
+		parsedOID, err := parseOID(oid)
+		if err != nil {
+			return nil, err
+		}
+		nextIndexes[i] = s.searchNext(parsedOID)
+	}
+	// the variables are interleaved, as sent by devices: one row of each requested oid per repetition
+	for repetition := uint32(0); repetition < bulkMaxRepetitions; repetition++ {
+		for i, oid := range oids {
+			packet.Variables = append(packet.Variables, s.variableAt(nextIndexes[i], oid))
+			nextIndexes[i]++
+		}
+	}
+	return packet, nil
+}
+
+// GetNext will send a SNMP GETNEXT command
+func (s *WalkSession) GetNext(oids []string) (*gosnmp.SnmpPacket, error) {
+	packet := &gosnmp.SnmpPacket{Variables: make([]gosnmp.SnmpPDU, 0, len(oids))}
+	for _, oid := range oids {
+		parsedOID, err := parseOID(oid)
+		if err != nil {
+			return nil, err
+		}
+		packet.Variables = append(packet.Variables, s.variableAt(s.searchNext(parsedOID), oid))
+	}
+	return packet, nil
+}
+
+// GetVersion returns the snmp version used
+func (s *WalkSession) GetVersion() gosnmp.SnmpVersion {
+	return s.version
+}
+
+// search returns the index of the first variable whose OID is greater or equal to the given one
+func (s *WalkSession) search(oid []int) int {
+	return sort.Search(len(s.variables), func(i int) bool {
+		return compareOIDs(s.variables[i].oid, oid) >= 0
+	})
+}
+
+// searchNext returns the index of the first variable whose OID is greater than the given one
+func (s *WalkSession) searchNext(oid []int) int {
+	return sort.Search(len(s.variables), func(i int) bool {
+		return compareOIDs(s.variables[i].oid, oid) > 0
+	})
+}
+
+func (s *WalkSession) variableAt(index int, requestedOid string) gosnmp.SnmpPDU {
+	if index >= len(s.variables) {
+		return gosnmp.SnmpPDU{Name: "." + strings.TrimLeft(requestedOid, "."), Type: gosnmp.EndOfMibView}
+	}
+	return s.variables[index].pdu
+}
+
+func parseOID(oid string) ([]int, error) {
+	oid = strings.TrimLeft(oid, ".")
+	if oid == "" {
+		return nil, fmt.Errorf("invalid empty oid")
+	}
+	parts := strings.Split(oid, ".")
+	parsed := make([]int, 0, len(parts))
+	for _, part := range parts {
+		value, err := strconv.Atoi(part)
+		if err != nil {
+			return nil, fmt.Errorf("invalid oid `%s`: %s", oid, err)
+		}
+		parsed = append(parsed, value)
+	}
+	return parsed, nil
+}
+
+func compareOIDs(a []int, b []int) int {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if a[i] != b[i] {
+			if a[i] < b[i] {
+				return -1
+			}
+			return 1
+		}
+	}
+	return len(a) - len(b)
+}
@@ -0,0 +1,55 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023-present Datadog, Inc.
+
+package session
+
+import (
+	"testing"
+
+	"github.com/gosnmp/gosnmp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWalkSession(t *testing.T) {
+	// not sorted, and sorted by string the OIDs would be in a different order
+	sess, err := NewWalkSession([]gosnmp.SnmpPDU{
+		{Name: "1.3.6.1.2.1.2.2.1.14.10", Type: gosnmp.Counter32, Value: uint(10)},
+		{Name: "1.3.6.1.2.1.1.5.0", Type: gosnmp.OctetString, Value: []byte("router")},
+		{Name: "1.3.6.1.2.1.2.2.1.14.2", Type: gosnmp.Counter32, Value: uint(2)},
+		{Name: "1.3.6.1.2.1.2.2.1.13.2", Type: gosnmp.Counter32, Value: uint(22)},
+	})
+	require.NoError(t, err)
+
+	result, err := sess.Get([]string{"1.3.6.1.2.1.1.5.0", "1.3.6.1.2.1.1.6.0"})
+	require.NoError(t, err)
+	assert.Equal(t, []gosnmp.SnmpPDU{
+		{Name: ".1.3.6.1.2.1.1.5.0", Type: gosnmp.OctetString, Value: []byte("router")},
+		{Name: ".1.3.6.1.2.1.1.6.0", Type: gosnmp.NoSuchObject},
+	}, result.Variables)
+
+	result, err = sess.GetNext([]string{"1.3.6.1.2.1.1.5.0", "1.3.6.1.2.1.2.2.1.14"})
+	require.NoError(t, err)
+	assert.Equal(t, []gosnmp.SnmpPDU{
+		{Name: ".1.3.6.1.2.1.2.2.1.13.2", Type: gosnmp.Counter32, Value: uint(22)},
+		{Name: ".1.3.6.1.2.1.2.2.1.14.2", Type: gosnmp.Counter32, Value: uint(2)},
+	}, result.Variables)
+
+	result, err = sess.GetBulk([]string{"1.3.6.1.2.1.2.2.1.13", "1.3.6.1.2.1.2.2.1.14"}, 2)
+	require.NoError(t, err)
+	assert.Equal(t, []gosnmp.SnmpPDU{
+		{Name: ".1.3.6.1.2.1.2.2.1.13.2", Type: gosnmp.Counter32, Value: uint(22)},
+		{Name: ".1.3.6.1.2.1.2.2.1.14.2", Type: gosnmp.Counter32, Value: uint(2)},
+		{Name: ".1.3.6.1.2.1.2.2.1.14.2", Type: gosnmp.Counter32, Value: uint(2)},
+		{Name: ".1.3.6.1.2.1.2.2.1.14.10", Type: gosnmp.Counter32, Value: uint(10)},
+	}, result.Variables)
+
+	result, err = sess.GetNext([]string{"1.3.6.1.2.1.2.2.1.14.10"})
+	require.NoError(t, err)
+	assert.Equal(t, []gosnmp.SnmpPDU{{Name: ".1.3.6.1.2.1.2.2.1.14.10", Type: gosnmp.EndOfMibView}}, result.Variables)
+
+	_, err = sess.Get([]string{"1.3.abc"})
+	assert.Error(t, err)
+}
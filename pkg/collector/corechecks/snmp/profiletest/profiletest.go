@@ -0,0 +1,178 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023-present Datadog, Inc.
+
+// Package profiletest runs the SNMP check against a recorded walk, to test
+// profiles without the devices they are written for.
+package profiletest
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gosnmp/gosnmp"
+	"gopkg.in/yaml.v2"
+
+	"github.com/DataDog/datadog-agent/pkg/snmp/gosnmplib"
+
+	"github.com/DataDog/datadog-agent/pkg/collector/corechecks/snmp/internal/checkconfig"
+	"github.com/DataDog/datadog-agent/pkg/collector/corechecks/snmp/internal/devicecheck"
+	"github.com/DataDog/datadog-agent/pkg/collector/corechecks/snmp/internal/report"
+	"github.com/DataDog/datadog-agent/pkg/collector/corechecks/snmp/internal/session"
+)
+
+// replayIPAddress is the IP address of the device whose walk is replayed, used
+// in the tags and device ID
+const replayIPAddress = "127.0.0.1"
+
+// the telemetry metrics of the check, which don't depend on the profile
+const telemetryMetricsPrefix = "datadog.snmp."
+
+// Result contains what the check produces with a profile for a recorded walk
+type Result struct {
+	Metrics       []Metric          `json:"metrics"`
+	ServiceChecks []ServiceCheck    `json:"service_checks"`
+	Metadata      []json.RawMessage `json:"metadata"`
+	Errors        []string          `json:"errors"`
+}
+
+// Metric is a metric submitted by the check
+type Metric struct {
+	Name  string   `json:"name"`
+	Type  string   `json:"type"`
+	Value float64  `json:"value"`
+	Tags  []string `json:"tags"`
+}
+
+// ServiceCheck is a service check submitted by the check
+type ServiceCheck struct {
+	Name    string   `json:"name"`
+	Status  string   `json:"status"`
+	Tags    []string `json:"tags"`
+	Message string   `json:"message,omitempty"`
+}
+
+// Run runs the check once with the profile defined in `profileFile`, against
+// the walk recorded in the snmprec file `walkFile`. Invalid profiles are
+// returned as errors, while the check errors and the profile OIDs missing
+// from the walk are reported in the result.
+func Run(profileFile string, walkFile string) (*Result, error) {
+	profileFile, err := filepath.Abs(profileFile)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkconfig.ValidateProfileDefinitionFile(profileFile); err != nil {
+		return nil, fmt.Errorf("invalid profile `%s`: %s", profileFile, err)
+	}
+
+	pdus, err := readWalkFile(walkFile)
+	if err != nil {
+		return nil, err
+	}
+	walkSession, err := session.NewWalkSession(pdus)
+	if err != nil {
+		return nil, fmt.Errorf("invalid walk file `%s`: %s", walkFile, err)
+	}
+
+	config, err := buildConfig(profileFile)
+	if err != nil {
+		return nil, err
+	}
+	deviceCk, err := devicecheck.NewDeviceCheck(config, replayIPAddress, func(*checkconfig.CheckConfig) (session.Session, error) {
+		return walkSession, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sender := &recordingSender{}
+	deviceCk.SetSender(report.NewMetricSender(sender, "", config.InterfaceConfigs))
+
+	result := &Result{}
+	if err := deviceCk.Run(time.Now()); err != nil {
+		result.Errors = append(result.Errors, err.Error())
+	}
+	result.Errors = append(result.Errors, findUnmatchedOids(config, walkSession)...)
+
+	for _, metric := range sender.metrics {
+		if !strings.HasPrefix(metric.Name, telemetryMetricsPrefix) {
+			result.Metrics = append(result.Metrics, metric)
+		}
+	}
+	sort.SliceStable(result.Metrics, func(i, j int) bool {
+		if result.Metrics[i].Name != result.Metrics[j].Name {
+			return result.Metrics[i].Name < result.Metrics[j].Name
+		}
+		return strings.Join(result.Metrics[i].Tags, ",") < strings.Join(result.Metrics[j].Tags, ",")
+	})
+	result.ServiceChecks = sender.serviceChecks
+	result.Metadata = sender.metadata
+	return result, nil
+}
+
+func readWalkFile(walkFile string) ([]gosnmp.SnmpPDU, error) {
+	f, err := os.Open(walkFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open walk file: %s", err)
+	}
+	defer f.Close()
+
+	pdus, err := gosnmplib.ReadSnmprec(f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read walk file `%s`: %s", walkFile, err)
+	}
+	return pdus, nil
+}
+
+// buildConfig builds the config of an instance using the profile, the same
+// way as the check does for instances configured in `snmp.d/conf.yaml`
+func buildConfig(profileFile string) (*checkconfig.CheckConfig, error) {
+	profileName := strings.TrimSuffix(filepath.Base(profileFile), filepath.Ext(profileFile))
+	rawInstance, err := yaml.Marshal(map[string]interface{}{
+		"ip_address":       replayIPAddress,
+		"community_string": "public",
+		"profile":          profileName,
+	})
+	if err != nil {
+		return nil, err
+	}
+	rawInitConfig, err := yaml.Marshal(map[string]interface{}{
+		"profiles": map[string]interface{}{
+			profileName: map[string]string{"definition_file": profileFile},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	config, err := checkconfig.NewCheckConfig(rawInstance, rawInitConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build config with profile `%s`: %s", profileName, err)
+	}
+	return config, nil
+}
+
+// findUnmatchedOids returns an error for each OID of the profile that has no
+// value in the walk
+func findUnmatchedOids(config *checkconfig.CheckConfig, walkSession *session.WalkSession) []string {
+	var errors []string
+	scalarOids, columnOids := config.GetProfileOids()
+	for _, oid := range scalarOids {
+		result, err := walkSession.Get([]string{oid})
+		if err != nil || result.Variables[0].Type == gosnmp.NoSuchObject {
+			errors = append(errors, fmt.Sprintf("scalar oid `%s` not found in the walk", oid))
+		}
+	}
+	for _, oid := range columnOids {
+		result, err := walkSession.GetNext([]string{oid})
+		if err != nil || !strings.HasPrefix(strings.TrimLeft(result.Variables[0].Name, "."), oid+".") {
+			errors = append(errors, fmt.Sprintf("column oid `%s` not found in the walk", oid))
+		}
+	}
+	return errors
+}
@@ -0,0 +1,75 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023-present Datadog, Inc.
+
+package profiletest
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+)
+
+func setConfdPath(t *testing.T) string {
+	confdPath, err := filepath.Abs(filepath.Join("testdata", "conf.d"))
+	require.NoError(t, err)
+	config.Datadog.Set("confd_path", confdPath)
+	return filepath.Join(confdPath, "snmp.d", "profiles")
+}
+
+func TestRun(t *testing.T) {
+	profilesPath := setConfdPath(t)
+
+	result, err := Run(filepath.Join(profilesPath, "my-device.yaml"), filepath.Join("testdata", "my-device.snmprec"))
+	require.NoError(t, err)
+
+	tags := []string{
+		"device_namespace:default",
+		"snmp_device:127.0.0.1",
+		"snmp_profile:my-device",
+		"device_vendor:acme",
+		"snmp_host:router-1",
+	}
+	assert.Contains(t, result.Metrics, Metric{Name: "snmp.cpuUsage", Type: "gauge", Value: 42, Tags: tags})
+	assert.Contains(t, result.Metrics, Metric{Name: "snmp.ifInErrors", Type: "rate", Value: 3, Tags: append(append([]string(nil), tags...), "interface:eth0")})
+	assert.Contains(t, result.Metrics, Metric{Name: "snmp.ifInErrors", Type: "rate", Value: 7, Tags: append(append([]string(nil), tags...), "interface:eth1")})
+	for _, metric := range result.Metrics {
+		assert.NotEqual(t, "snmp.memoryUsage", metric.Name)
+		assert.NotContains(t, metric.Name, "datadog.snmp.")
+	}
+
+	assert.Equal(t, []ServiceCheck{{Name: "snmp.can_check", Status: "OK", Tags: tags}}, result.ServiceChecks)
+
+	require.Len(t, result.Metadata, 1)
+	var payload struct {
+		Devices []struct {
+			Name   string `json:"name"`
+			Vendor string `json:"vendor"`
+		} `json:"devices"`
+	}
+	require.NoError(t, json.Unmarshal(result.Metadata[0], &payload))
+	require.Len(t, payload.Devices, 1)
+	assert.Equal(t, "router-1", payload.Devices[0].Name)
+	assert.Equal(t, "acme", payload.Devices[0].Vendor)
+
+	assert.Equal(t, []string{"scalar oid `1.3.6.1.4.1.99999.1.2.0` not found in the walk"}, result.Errors)
+}
+
+func TestRun_invalidProfile(t *testing.T) {
+	profilesPath := setConfdPath(t)
+
+	_, err := Run(filepath.Join(profilesPath, "bad-extends.yaml"), filepath.Join("testdata", "my-device.snmprec"))
+	assert.ErrorContains(t, err, "_missing.yaml")
+
+	_, err = Run(filepath.Join(profilesPath, "unknown.yaml"), filepath.Join("testdata", "my-device.snmprec"))
+	assert.ErrorContains(t, err, "invalid profile")
+
+	_, err = Run(filepath.Join(profilesPath, "my-device.yaml"), filepath.Join("testdata", "unknown.snmprec"))
+	assert.ErrorContains(t, err, "failed to open walk file")
+}
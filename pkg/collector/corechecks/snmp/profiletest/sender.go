@@ -0,0 +1,105 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023-present Datadog, Inc.
+
+package profiletest
+
+import (
+	"encoding/json"
+
+	"github.com/DataDog/datadog-agent/pkg/collector/check/stats"
+	"github.com/DataDog/datadog-agent/pkg/epforwarder"
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+	"github.com/DataDog/datadog-agent/pkg/metrics/event"
+	"github.com/DataDog/datadog-agent/pkg/metrics/servicecheck"
+	"github.com/DataDog/datadog-agent/pkg/serializer/types"
+)
+
+// recordingSender is a sender keeping what the check submits instead of
+// sending it, only for the kinds of data submitted by the SNMP check
+type recordingSender struct {
+	metrics       []Metric
+	serviceChecks []ServiceCheck
+	metadata      []json.RawMessage
+}
+
+func (s *recordingSender) addMetric(metricType string, metric string, value float64, tags []string) {
+	s.metrics = append(s.metrics, Metric{Name: metric, Type: metricType, Value: value, Tags: append([]string(nil), tags...)})
+}
+
+func (s *recordingSender) Commit() {}
+
+func (s *recordingSender) Gauge(metric string, value float64, hostname string, tags []string) {
+	s.addMetric("gauge", metric, value, tags)
+}
+
+func (s *recordingSender) GaugeNoIndex(metric string, value float64, hostname string, tags []string) {
+	s.addMetric("gauge", metric, value, tags)
+}
+
+func (s *recordingSender) Rate(metric string, value float64, hostname string, tags []string) {
+	s.addMetric("rate", metric, value, tags)
+}
+
+func (s *recordingSender) Count(metric string, value float64, hostname string, tags []string) {
+	s.addMetric("count", metric, value, tags)
+}
+
+func (s *recordingSender) MonotonicCount(metric string, value float64, hostname string, tags []string) {
+	s.addMetric("monotonic_count", metric, value, tags)
+}
+
+func (s *recordingSender) MonotonicCountWithFlushFirstValue(metric string, value float64, hostname string, tags []string, flushFirstValue bool) {
+	s.addMetric("monotonic_count", metric, value, tags)
+}
+
+func (s *recordingSender) Counter(metric string, value float64, hostname string, tags []string) {
+	s.addMetric("counter", metric, value, tags)
+}
+
+func (s *recordingSender) Histogram(metric string, value float64, hostname string, tags []string) {
+	s.addMetric("histogram", metric, value, tags)
+}
+
+func (s *recordingSender) Historate(metric string, value float64, hostname string, tags []string) {
+	s.addMetric("historate", metric, value, tags)
+}
+
+func (s *recordingSender) ServiceCheck(checkName string, status servicecheck.ServiceCheckStatus, hostname string, tags []string, message string) {
+	s.serviceChecks = append(s.serviceChecks, ServiceCheck{Name: checkName, Status: status.String(), Tags: append([]string(nil), tags...), Message: message})
+}
+
+func (s *recordingSender) HistogramBucket(metric string, value int64, lowerBound, upperBound float64, monotonic bool, hostname string, tags []string, flushFirstValue bool) {
+}
+
+func (s *recordingSender) ExponentialHistogram(metric string, histogram *metrics.ExponentialHistogram, monotonic bool, hostname string, tags []string, flushFirstValue bool) {
+}
+
+func (s *recordingSender) Event(e event.Event) {}
+
+func (s *recordingSender) EventPlatformEvent(rawEvent []byte, eventType string) {
+	if eventType == epforwarder.EventTypeNetworkDevicesMetadata {
+		s.metadata = append(s.metadata, json.RawMessage(rawEvent))
+	}
+}
+
+func (s *recordingSender) GetSenderStats() stats.SenderStats {
+	return stats.NewSenderStats()
+}
+
+func (s *recordingSender) DisableDefaultHostname(disable bool) {}
+
+func (s *recordingSender) SetCheckCustomTags(tags []string) {}
+
+func (s *recordingSender) SetCheckService(service string) {}
+
+func (s *recordingSender) SetNoIndex(noIndex bool) {}
+
+func (s *recordingSender) FinalizeCheckServiceTag() {}
+
+func (s *recordingSender) OrchestratorMetadata(msgs []types.ProcessMessageBody, clusterID string, nodeType int) {
+}
+
+func (s *recordingSender) OrchestratorManifest(msgs []types.ProcessMessageBody, clusterID string) {
+}
@@ -21,6 +21,7 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/collector/corechecks/snmp/internal/checkconfig"
 	"github.com/DataDog/datadog-agent/pkg/collector/corechecks/snmp/internal/devicecheck"
 	"github.com/DataDog/datadog-agent/pkg/collector/corechecks/snmp/internal/discovery"
+	"github.com/DataDog/datadog-agent/pkg/collector/corechecks/snmp/internal/fetch"
 	"github.com/DataDog/datadog-agent/pkg/collector/corechecks/snmp/internal/report"
 	"github.com/DataDog/datadog-agent/pkg/collector/corechecks/snmp/internal/session"
 )
@@ -170,6 +171,12 @@ func (c *Check) Interval() time.Duration {
 	return c.config.MinCollectionInterval
 }
 
+// GetFetchStats returns the statistics of the last fetch of each device
+// monitored by the check, by device ID
+func GetFetchStats() map[string]fetch.FetchStats {
+	return fetch.GetFetchStats()
+}
+
 func snmpFactory() check.Check {
 	return &Check{
 		CheckBase:                  core.NewCheckBase(common.SnmpIntegrationName),
@@ -0,0 +1,88 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build linux
+
+package kernel
+
+import (
+	"github.com/DataDog/datadog-agent/pkg/aggregator/sender"
+	"github.com/DataDog/datadog-agent/pkg/tagger"
+	"github.com/DataDog/datadog-agent/pkg/util/cgroups"
+	"github.com/DataDog/datadog-agent/pkg/util/containers"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// cgroupPressureCollector reports the pressure stall information of the
+// containers, only available with cgroup v2
+type cgroupPressureCollector struct {
+	reader *cgroups.Reader
+}
+
+// newCgroupPressureCollector returns a collector reading the cgroups of the
+// containers, or nil if the cgroups don't expose the pressure
+func newCgroupPressureCollector(hostPrefix, procPath string) *cgroupPressureCollector {
+	reader, err := cgroups.NewReader(
+		cgroups.WithHostPrefix(hostPrefix),
+		cgroups.WithProcPath(procPath),
+		cgroups.WithReaderFilter(cgroups.ContainerFilter),
+	)
+	if err != nil {
+		log.Infof("Unable to read the cgroups, the pressure of the containers won't be collected: %s", err)
+		return nil
+	}
+	if reader.CgroupVersion() != 2 {
+		log.Debugf("The pressure of the containers is only available with cgroup v2, found cgroup v%d", reader.CgroupVersion())
+		return nil
+	}
+	return &cgroupPressureCollector{reader: reader}
+}
+
+func (c *cgroupPressureCollector) collect(sender sender.Sender) {
+	if err := c.reader.RefreshCgroups(0); err != nil {
+		log.Debugf("Unable to refresh the cgroups: %s", err)
+		return
+	}
+
+	for _, cg := range c.reader.ListCgroups() {
+		containerID := cg.Identifier()
+		tags, err := tagger.Tag(containers.BuildTaggerEntityName(containerID), tagger.ChecksCardinality)
+		if err != nil {
+			log.Debugf("Unable to get the tags of container %s: %s", containerID, err)
+		}
+		if len(tags) == 0 {
+			// not a container known by the tagger, like a container being created
+			continue
+		}
+
+		var cpuStats cgroups.CPUStats
+		if err := cg.GetCPUStats(&cpuStats); err == nil {
+			sendCgroupPressure(sender, "container.pressure.cpu.some", cpuStats.PSISome, tags)
+		}
+		var memoryStats cgroups.MemoryStats
+		if err := cg.GetMemoryStats(&memoryStats); err == nil {
+			sendCgroupPressure(sender, "container.pressure.memory.some", memoryStats.PSISome, tags)
+			sendCgroupPressure(sender, "container.pressure.memory.full", memoryStats.PSIFull, tags)
+		}
+		var ioStats cgroups.IOStats
+		if err := cg.GetIOStats(&ioStats); err == nil {
+			sendCgroupPressure(sender, "container.pressure.io.some", ioStats.PSISome, tags)
+			sendCgroupPressure(sender, "container.pressure.io.full", ioStats.PSIFull, tags)
+		}
+	}
+}
+
+func sendCgroupPressure(sender sender.Sender, prefix string, stats cgroups.PSIStats, tags []string) {
+	// the pressure files are missing when the kernel is built without PSI
+	if stats.Avg10 == nil || stats.Avg60 == nil || stats.Avg300 == nil || stats.Total == nil {
+		return
+	}
+	sendPressure(sender, prefix, pressure{
+		avg10:  *stats.Avg10,
+		avg60:  *stats.Avg60,
+		avg300: *stats.Avg300,
+		total:  *stats.Total,
+	}, tags)
+}
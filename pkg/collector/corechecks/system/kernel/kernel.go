@@ -0,0 +1,290 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build linux
+
+// Package kernel implements the linux_kernel check, reporting the pressure
+// stall information and the virtual memory, softirq and softnet statistics of
+// the Linux kernel.
+package kernel
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator/sender"
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+	"github.com/DataDog/datadog-agent/pkg/collector/check"
+	core "github.com/DataDog/datadog-agent/pkg/collector/corechecks"
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+const checkName = "linux_kernel"
+
+// pressureResources are the resources of /proc/pressure
+var pressureResources = []string{"cpu", "memory", "io"}
+
+// defaultVmstatFields are the counters of /proc/vmstat reported by default,
+// mostly about the memory reclaim. The counters split by zone on some kernels,
+// like allocstall, are summed.
+var defaultVmstatFields = []string{
+	"pgfault",
+	"pgmajfault",
+	"pgpgin",
+	"pgpgout",
+	"pswpin",
+	"pswpout",
+	"pgscan_kswapd",
+	"pgscan_direct",
+	"pgsteal_kswapd",
+	"pgsteal_direct",
+	"allocstall",
+	"compact_stall",
+	"oom_kill",
+	"thp_fault_alloc",
+	"thp_collapse_alloc",
+}
+
+// vmstatZoneSuffixes are the suffixes of the counters split by zone, summed
+// when their field is missing.
+var vmstatZoneSuffixes = []string{"_dma", "_dma32", "_normal", "_movable"}
+
+// vmstatGauges are the fields of /proc/vmstat that are not cumulative counters,
+// on top of the nr_* ones.
+var vmstatGauges = map[string]struct{}{
+	"workingset_nodes": {},
+}
+
+// isVmstatGauge returns whether a field of /proc/vmstat reports a current
+// amount, like the number of free pages, rather than a count of events.
+func isVmstatGauge(field string) bool {
+	if strings.HasPrefix(field, "nr_") {
+		return true
+	}
+	_, found := vmstatGauges[field]
+	return found
+}
+
+// Check reports the kernel statistics of /proc
+type Check struct {
+	core.CheckBase
+	config    checkConfig
+	procPath  string
+	cgroupPSI *cgroupPressureCollector
+}
+
+type checkConfig struct {
+	CollectPressure          bool     `yaml:"collect_pressure"`
+	CollectContainerPressure bool     `yaml:"collect_container_pressure"`
+	CollectVmstat            bool     `yaml:"collect_vmstat"`
+	VmstatFields             []string `yaml:"vmstat_fields"`
+	CollectSoftirqs          bool     `yaml:"collect_softirqs"`
+	CollectSoftnet           bool     `yaml:"collect_softnet"`
+	PerCPU                   bool     `yaml:"per_cpu"`
+}
+
+func (c *checkConfig) parse(data []byte) error {
+	*c = checkConfig{
+		CollectPressure:          true,
+		CollectContainerPressure: true,
+		CollectVmstat:            true,
+		CollectSoftirqs:          true,
+		CollectSoftnet:           true,
+	}
+	if err := yaml.Unmarshal(data, c); err != nil {
+		return err
+	}
+
+	// the fields of the configuration come on top of the default ones
+	fields := make([]string, 0, len(defaultVmstatFields)+len(c.VmstatFields))
+	seen := make(map[string]struct{})
+	for _, field := range append(append([]string{}, defaultVmstatFields...), c.VmstatFields...) {
+		if _, found := seen[field]; !found {
+			seen[field] = struct{}{}
+			fields = append(fields, field)
+		}
+	}
+	c.VmstatFields = fields
+
+	return nil
+}
+
+// Configure parses the check configuration and init the check
+func (c *Check) Configure(integrationConfigDigest uint64, data integration.Data, initConfig integration.Data, source string) error {
+	if err := c.CommonConfigure(integrationConfigDigest, initConfig, data, source); err != nil {
+		return err
+	}
+	if err := c.config.parse(data); err != nil {
+		return fmt.Errorf("cannot parse the configuration: %s", err)
+	}
+
+	c.procPath = "/proc"
+	if config.Datadog.IsSet("procfs_path") {
+		c.procPath = config.Datadog.GetString("procfs_path")
+	}
+
+	if c.config.CollectContainerPressure {
+		hostPrefix := ""
+		if strings.HasPrefix(c.procPath, "/host") {
+			hostPrefix = "/host"
+		}
+		c.cgroupPSI = newCgroupPressureCollector(hostPrefix, c.procPath)
+	}
+
+	return nil
+}
+
+// Run executes the check
+func (c *Check) Run() error {
+	sender, err := c.GetSender()
+	if err != nil {
+		return err
+	}
+
+	// the files may be missing, depending on the kernel version and configuration
+	if c.config.CollectPressure {
+		for _, resource := range pressureResources {
+			if err := c.collectPressure(sender, resource); err != nil {
+				log.Debugf("%s: cannot collect the %s pressure: %s", c.ID(), resource, err)
+			}
+		}
+	}
+	if c.config.CollectContainerPressure && c.cgroupPSI != nil {
+		c.cgroupPSI.collect(sender)
+	}
+	if c.config.CollectVmstat {
+		if err := c.collectVmstat(sender); err != nil {
+			log.Debugf("%s: cannot collect the vmstat counters: %s", c.ID(), err)
+		}
+	}
+	if c.config.CollectSoftirqs {
+		if err := c.collectSoftirqs(sender); err != nil {
+			log.Debugf("%s: cannot collect the softirqs: %s", c.ID(), err)
+		}
+	}
+	if c.config.CollectSoftnet {
+		if err := c.collectSoftnet(sender); err != nil {
+			log.Debugf("%s: cannot collect the softnet statistics: %s", c.ID(), err)
+		}
+	}
+
+	sender.Commit()
+	return nil
+}
+
+func (c *Check) collectPressure(sender sender.Sender, resource string) error {
+	pressures, err := readPressure(filepath.Join(c.procPath, "pressure", resource))
+	if err != nil {
+		return err
+	}
+	for kind, p := range pressures {
+		sendPressure(sender, "system.pressure."+resource+"."+kind, p, nil)
+	}
+	return nil
+}
+
+func sendPressure(sender sender.Sender, prefix string, p pressure, tags []string) {
+	sender.Gauge(prefix+".avg10", p.avg10, "", tags)
+	sender.Gauge(prefix+".avg60", p.avg60, "", tags)
+	sender.Gauge(prefix+".avg300", p.avg300, "", tags)
+	sender.MonotonicCount(prefix+".total", float64(p.total), "", tags)
+}
+
+func (c *Check) collectVmstat(sender sender.Sender) error {
+	vmstat, err := readVmstat(filepath.Join(c.procPath, "vmstat"))
+	if err != nil {
+		return err
+	}
+
+	for _, field := range c.config.VmstatFields {
+		value, found := vmstat[field]
+		if !found {
+			// sum the counters split by zone, like allocstall_normal,
+			// but not the other counters sharing the prefix, like
+			// pgscan_direct_throttle
+			for _, suffix := range vmstatZoneSuffixes {
+				if v, ok := vmstat[field+suffix]; ok {
+					value += v
+					found = true
+				}
+			}
+		}
+		if !found {
+			continue
+		}
+		if isVmstatGauge(field) {
+			sender.Gauge("system.vmstat."+field, float64(value), "", nil)
+		} else {
+			sender.MonotonicCount("system.vmstat."+field, float64(value), "", nil)
+		}
+	}
+	return nil
+}
+
+func (c *Check) collectSoftirqs(sender sender.Sender) error {
+	softirqs, err := readSoftirqs(filepath.Join(c.procPath, "softirqs"))
+	if err != nil {
+		return err
+	}
+
+	for softirq, counts := range softirqs {
+		tags := []string{"softirq:" + softirq}
+		if c.config.PerCPU {
+			for cpu, count := range counts {
+				sender.MonotonicCount("system.softirqs", float64(count), "", append(tags, "cpu:"+strconv.Itoa(cpu)))
+			}
+			continue
+		}
+		var total uint64
+		for _, count := range counts {
+			total += count
+		}
+		sender.MonotonicCount("system.softirqs", float64(total), "", tags)
+	}
+	return nil
+}
+
+func (c *Check) collectSoftnet(sender sender.Sender) error {
+	stats, err := readSoftnetStat(filepath.Join(c.procPath, "net", "softnet_stat"))
+	if err != nil {
+		return err
+	}
+
+	if c.config.PerCPU {
+		for _, stat := range stats {
+			sendSoftnetStat(sender, stat, []string{"cpu:" + strconv.Itoa(stat.cpu)})
+		}
+		return nil
+	}
+	var total softnetStat
+	for _, stat := range stats {
+		total.processed += stat.processed
+		total.dropped += stat.dropped
+		total.timeSqueeze += stat.timeSqueeze
+	}
+	sendSoftnetStat(sender, total, nil)
+	return nil
+}
+
+func sendSoftnetStat(sender sender.Sender, stat softnetStat, tags []string) {
+	sender.MonotonicCount("system.net.softnet.processed", float64(stat.processed), "", tags)
+	sender.MonotonicCount("system.net.softnet.dropped", float64(stat.dropped), "", tags)
+	sender.MonotonicCount("system.net.softnet.time_squeeze", float64(stat.timeSqueeze), "", tags)
+}
+
+func kernelFactory() check.Check {
+	return &Check{
+		CheckBase: core.NewCheckBase(checkName),
+	}
+}
+
+func init() {
+	core.RegisterCheck(checkName, kernelFactory)
+}
@@ -0,0 +1,140 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build linux
+
+package kernel
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator/mocksender"
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/tagger"
+	"github.com/DataDog/datadog-agent/pkg/tagger/local"
+	"github.com/DataDog/datadog-agent/pkg/util/containers"
+)
+
+func runKernelCheck(t *testing.T, instance string) *mocksender.MockSender {
+	config.Mock(t).Set("procfs_path", "./testdata/proc")
+
+	check := kernelFactory().(*Check)
+	require.NoError(t, check.Configure(integration.FakeConfigHash, []byte(instance), nil, "test"))
+
+	mockSender := mocksender.NewMockSender(check.ID())
+	mockSender.SetupAcceptAll()
+	require.NoError(t, check.Run())
+	return mockSender
+}
+
+func TestKernelCheck(t *testing.T) {
+	mockSender := runKernelCheck(t, "collect_container_pressure: false")
+
+	mockSender.AssertMetric(t, "Gauge", "system.pressure.cpu.some.avg10", 1.5, "", nil)
+	mockSender.AssertMetric(t, "Gauge", "system.pressure.cpu.some.avg60", 0.75, "", nil)
+	mockSender.AssertMetric(t, "Gauge", "system.pressure.cpu.some.avg300", 0.25, "", nil)
+	mockSender.AssertMetric(t, "MonotonicCount", "system.pressure.cpu.some.total", 123456, "", nil)
+	mockSender.AssertMetric(t, "Gauge", "system.pressure.memory.full.avg10", 1, "", nil)
+	mockSender.AssertMetric(t, "MonotonicCount", "system.pressure.memory.full.total", 1000, "", nil)
+	// the io pressure is missing from the fixtures
+	mockSender.AssertNotCalled(t, "Gauge", "system.pressure.io.some.avg10", mock.Anything, mock.Anything, mock.Anything)
+
+	mockSender.AssertMetric(t, "MonotonicCount", "system.vmstat.pgfault", 30000, "", nil)
+	mockSender.AssertMetric(t, "MonotonicCount", "system.vmstat.oom_kill", 5, "", nil)
+	mockSender.AssertMetric(t, "MonotonicCount", "system.vmstat.allocstall", 6, "", nil)
+	// pgscan_direct_throttle isn't a zone of pgscan_direct
+	mockSender.AssertMetric(t, "MonotonicCount", "system.vmstat.pgscan_direct", 10, "", nil)
+	mockSender.AssertNotCalled(t, "Gauge", "system.vmstat.nr_free_pages", mock.Anything, mock.Anything, mock.Anything)
+	mockSender.AssertNotCalled(t, "MonotonicCount", "system.vmstat.pswpin", mock.Anything, mock.Anything, mock.Anything)
+
+	mockSender.AssertMetric(t, "MonotonicCount", "system.softirqs", 3, "", []string{"softirq:hi"})
+	mockSender.AssertMetric(t, "MonotonicCount", "system.softirqs", 300, "", []string{"softirq:timer"})
+	mockSender.AssertMetric(t, "MonotonicCount", "system.softirqs", 30, "", []string{"softirq:net_rx"})
+
+	mockSender.AssertMetric(t, "MonotonicCount", "system.net.softnet.processed", 30, "", nil)
+	mockSender.AssertMetric(t, "MonotonicCount", "system.net.softnet.dropped", 3, "", nil)
+	mockSender.AssertMetric(t, "MonotonicCount", "system.net.softnet.time_squeeze", 5, "", nil)
+}
+
+func TestKernelCheckPerCPU(t *testing.T) {
+	mockSender := runKernelCheck(t, "collect_container_pressure: false\ncollect_pressure: false\nper_cpu: true\nvmstat_fields: [nr_free_pages]")
+
+	mockSender.AssertNotCalled(t, "Gauge", "system.pressure.cpu.some.avg10", mock.Anything, mock.Anything, mock.Anything)
+	// the nr_* fields are current amounts, not counters
+	mockSender.AssertMetric(t, "Gauge", "system.vmstat.nr_free_pages", 123456, "", nil)
+	mockSender.AssertNotCalled(t, "MonotonicCount", "system.vmstat.nr_free_pages", mock.Anything, mock.Anything, mock.Anything)
+
+	mockSender.AssertMetric(t, "MonotonicCount", "system.softirqs", 100, "", []string{"softirq:timer", "cpu:0"})
+	mockSender.AssertMetric(t, "MonotonicCount", "system.softirqs", 200, "", []string{"softirq:timer", "cpu:1"})
+
+	mockSender.AssertMetric(t, "MonotonicCount", "system.net.softnet.processed", 10, "", []string{"cpu:0"})
+	mockSender.AssertMetric(t, "MonotonicCount", "system.net.softnet.processed", 20, "", []string{"cpu:1"})
+	mockSender.AssertMetric(t, "MonotonicCount", "system.net.softnet.dropped", 2, "", []string{"cpu:1"})
+}
+
+func TestCheckConfigParse(t *testing.T) {
+	var c checkConfig
+	require.NoError(t, c.parse([]byte("collect_softnet: false\nvmstat_fields: [pgfault, numa_hit]")))
+
+	assert.True(t, c.CollectPressure)
+	assert.True(t, c.CollectVmstat)
+	assert.False(t, c.CollectSoftnet)
+	assert.Equal(t, append(append([]string{}, defaultVmstatFields...), "numa_hit"), c.VmstatFields)
+}
+
+func TestReadSoftnetStatWithoutCPU(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "softnet_stat")
+	require.NoError(t, os.WriteFile(path, []byte("00000001 00000000 00000000\n00000002 00000001 00000000\n"), 0o640))
+
+	stats, err := readSoftnetStat(path)
+	require.NoError(t, err)
+	assert.Equal(t, []softnetStat{
+		{cpu: 0, processed: 1},
+		{cpu: 1, processed: 2, dropped: 1},
+	}, stats)
+}
+
+func TestCgroupPressure(t *testing.T) {
+	const containerID = "2327a2aec169e25cf05f2a901486b7463fdb513ae097fc0ae6a3ca94381ddc40"
+
+	root := t.TempDir()
+	procPath := filepath.Join(root, "proc")
+	cgroupRoot := filepath.Join(root, "sys", "fs", "cgroup")
+	containerPath := filepath.Join(cgroupRoot, "system.slice", "docker-"+containerID+".scope")
+	require.NoError(t, os.MkdirAll(procPath, 0o750))
+	require.NoError(t, os.MkdirAll(containerPath, 0o750))
+	require.NoError(t, os.WriteFile(filepath.Join(procPath, "mounts"), []byte(fmt.Sprintf("cgroup2 %s cgroup2 rw,nosuid,nodev,noexec,relatime 0 0\n", cgroupRoot)), 0o640))
+	require.NoError(t, os.WriteFile(filepath.Join(cgroupRoot, "cgroup.controllers"), []byte("cpu io memory"), 0o640))
+	require.NoError(t, os.WriteFile(filepath.Join(containerPath, "cpu.pressure"), []byte("some avg10=5.00 avg60=4.00 avg300=3.00 total=42\nfull avg10=0.00 avg60=0.00 avg300=0.00 total=0\n"), 0o640))
+	require.NoError(t, os.WriteFile(filepath.Join(containerPath, "memory.pressure"), []byte("some avg10=1.00 avg60=1.00 avg300=1.00 total=10\nfull avg10=0.50 avg60=0.50 avg300=0.50 total=5\n"), 0o640))
+
+	defaultTagger := tagger.GetDefaultTagger()
+	fakeTagger := local.NewFakeTagger()
+	fakeTagger.SetTags(containers.BuildTaggerEntityName(containerID), "foo", []string{"container_name:foo"}, nil, nil, nil)
+	tagger.SetDefaultTagger(fakeTagger)
+	defer tagger.SetDefaultTagger(defaultTagger)
+
+	collector := newCgroupPressureCollector("", procPath)
+	require.NotNil(t, collector)
+
+	mockSender := mocksender.NewMockSender("linux_kernel")
+	mockSender.SetupAcceptAll()
+	collector.collect(mockSender)
+
+	tags := []string{"container_name:foo"}
+	mockSender.AssertMetric(t, "Gauge", "container.pressure.cpu.some.avg10", 5, "", tags)
+	mockSender.AssertMetric(t, "MonotonicCount", "container.pressure.cpu.some.total", 42, "", tags)
+	mockSender.AssertMetric(t, "Gauge", "container.pressure.memory.full.avg60", 0.5, "", tags)
+	mockSender.AssertMetric(t, "MonotonicCount", "container.pressure.memory.some.total", 10, "", tags)
+	mockSender.AssertNotCalled(t, "Gauge", "container.pressure.io.some.avg10", mock.Anything, mock.Anything, mock.Anything)
+}
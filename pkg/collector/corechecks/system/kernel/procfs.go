@@ -0,0 +1,173 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build linux
+
+package kernel
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// pressure holds a line of a /proc/pressure file
+type pressure struct {
+	avg10  float64 // in percent
+	avg60  float64 // in percent
+	avg300 float64 // in percent
+	total  uint64  // in microseconds
+}
+
+// readPressure reads a /proc/pressure file, whose format is
+//
+//	some avg10=0.00 avg60=0.00 avg300=0.00 total=0
+//	full avg10=0.00 avg60=0.00 avg300=0.00 total=0
+//
+// and returns the pressure by kind (some or full)
+func readPressure(path string) (map[string]pressure, error) {
+	res := make(map[string]pressure)
+	err := scanFile(path, func(fields []string) error {
+		if len(fields) != 5 {
+			return fmt.Errorf("unexpected line %q", strings.Join(fields, " "))
+		}
+
+		var p pressure
+		for _, field := range fields[1:] {
+			key, value, found := strings.Cut(field, "=")
+			if !found {
+				return fmt.Errorf("unexpected field %q", field)
+			}
+			var err error
+			switch key {
+			case "avg10":
+				p.avg10, err = strconv.ParseFloat(value, 64)
+			case "avg60":
+				p.avg60, err = strconv.ParseFloat(value, 64)
+			case "avg300":
+				p.avg300, err = strconv.ParseFloat(value, 64)
+			case "total":
+				p.total, err = strconv.ParseUint(value, 10, 64)
+			}
+			if err != nil {
+				return fmt.Errorf("unexpected field %q: %s", field, err)
+			}
+		}
+		res[fields[0]] = p
+		return nil
+	})
+	return res, err
+}
+
+// readVmstat reads /proc/vmstat, made of `key value` lines
+func readVmstat(path string) (map[string]uint64, error) {
+	res := make(map[string]uint64)
+	err := scanFile(path, func(fields []string) error {
+		if len(fields) != 2 {
+			return fmt.Errorf("unexpected line %q", strings.Join(fields, " "))
+		}
+		value, err := strconv.ParseUint(fields[1], 10, 64)
+		if err != nil {
+			return fmt.Errorf("unexpected value for %s: %s", fields[0], err)
+		}
+		res[fields[0]] = value
+		return nil
+	})
+	return res, err
+}
+
+// readSoftirqs reads /proc/softirqs, a table of the softirqs handled by type
+// and by CPU, and returns the counts by lowercased type, indexed by CPU
+func readSoftirqs(path string) (map[string][]uint64, error) {
+	res := make(map[string][]uint64)
+	header := true
+	err := scanFile(path, func(fields []string) error {
+		// the first line lists the CPUs
+		if header {
+			header = false
+			return nil
+		}
+		if len(fields) < 2 || !strings.HasSuffix(fields[0], ":") {
+			return fmt.Errorf("unexpected line %q", strings.Join(fields, " "))
+		}
+
+		counts := make([]uint64, 0, len(fields)-1)
+		for _, field := range fields[1:] {
+			count, err := strconv.ParseUint(field, 10, 64)
+			if err != nil {
+				return fmt.Errorf("unexpected count for %s: %s", fields[0], err)
+			}
+			counts = append(counts, count)
+		}
+		res[strings.ToLower(strings.TrimSuffix(fields[0], ":"))] = counts
+		return nil
+	})
+	return res, err
+}
+
+// softnetStat holds a line of /proc/net/softnet_stat
+type softnetStat struct {
+	cpu         int
+	processed   uint64
+	dropped     uint64
+	timeSqueeze uint64
+}
+
+// readSoftnetStat reads /proc/net/softnet_stat, made of a line of hexadecimal
+// counters per CPU. The CPU is the 13th column on the kernels 5.10 and
+// later, and the index of the line before.
+func readSoftnetStat(path string) ([]softnetStat, error) {
+	var res []softnetStat
+	err := scanFile(path, func(fields []string) error {
+		if len(fields) < 3 {
+			return fmt.Errorf("unexpected line %q", strings.Join(fields, " "))
+		}
+
+		values := make([]uint64, 0, len(fields))
+		for _, field := range fields {
+			value, err := strconv.ParseUint(field, 16, 64)
+			if err != nil {
+				return fmt.Errorf("unexpected counter %q: %s", field, err)
+			}
+			values = append(values, value)
+		}
+
+		stat := softnetStat{
+			cpu:         len(res),
+			processed:   values[0],
+			dropped:     values[1],
+			timeSqueeze: values[2],
+		}
+		if len(values) >= 13 {
+			stat.cpu = int(values[12])
+		}
+		res = append(res, stat)
+		return nil
+	})
+	return res, err
+}
+
+// scanFile calls parse on the fields of each non-empty line of a file
+func scanFile(path string, parse func(fields []string) error) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		if err := parse(fields); err != nil {
+			return fmt.Errorf("cannot parse %s: %s", path, err)
+		}
+	}
+	return scanner.Err()
+}
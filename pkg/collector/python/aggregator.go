@@ -12,6 +12,7 @@ import (
 
 	"github.com/DataDog/datadog-agent/pkg/aggregator"
 	checkid "github.com/DataDog/datadog-agent/pkg/collector/check/id"
+	"github.com/DataDog/datadog-agent/pkg/metrics"
 	metricsevent "github.com/DataDog/datadog-agent/pkg/metrics/event"
 	"github.com/DataDog/datadog-agent/pkg/metrics/servicecheck"
 	"github.com/DataDog/datadog-agent/pkg/util/log"
@@ -141,6 +142,45 @@ func SubmitHistogramBucket(checkID *C.char, metricName *C.char, value C.longlong
 	sender.HistogramBucket(_name, _value, _lowerBound, _upperBound, _monotonic, _hostname, _tags, _flushFirstValue)
 }
 
+// SubmitExponentialHistogram is the method exposed to Python scripts to submit exponential histograms
+//
+//export SubmitExponentialHistogram
+func SubmitExponentialHistogram(checkID *C.char, metricName *C.char, histogram *C.exponential_histogram_t, monotonic C.int, hostname *C.char, tags **C.char, flushFirstValue C.bool) {
+	goCheckID := C.GoString(checkID)
+	sender, err := aggregator.GetSender(checkid.ID(goCheckID))
+	if err != nil || sender == nil {
+		log.Errorf("Error submitting exponential histogram to the Sender: %v", err)
+		return
+	}
+
+	_name := C.GoString(metricName)
+	_histogram := &metrics.ExponentialHistogram{
+		Scale:     int32(histogram.scale),
+		Sum:       float64(histogram.sum),
+		ZeroCount: uint64(histogram.zero_count),
+		Positive:  cExponentialBuckets(histogram.positive_offset, histogram.positive_counts, histogram.positive_len),
+		Negative:  cExponentialBuckets(histogram.negative_offset, histogram.negative_counts, histogram.negative_len),
+	}
+	_monotonic := (monotonic != 0)
+	_hostname := C.GoString(hostname)
+	_tags := cStringArrayToSlice(tags)
+	_flushFirstValue := bool(flushFirstValue)
+
+	sender.ExponentialHistogram(_name, _histogram, _monotonic, _hostname, _tags, _flushFirstValue)
+}
+
+// cExponentialBuckets copies the bucket counts allocated by rtloader into Go memory
+func cExponentialBuckets(offset C.int, counts *C.ulonglong, length C.int) metrics.ExponentialBuckets {
+	buckets := metrics.ExponentialBuckets{
+		Offset: int32(offset),
+		Counts: make([]uint64, 0, int(length)),
+	}
+	for _, count := range unsafe.Slice(counts, int(length)) {
+		buckets.Counts = append(buckets.Counts, uint64(count))
+	}
+	return buckets
+}
+
 // SubmitEventPlatformEvent is the method exposed to Python scripts to submit event platform events
 //
 //export SubmitEventPlatformEvent
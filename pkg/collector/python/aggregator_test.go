@@ -41,6 +41,10 @@ func TestSubmitHistogramBucket(t *testing.T) {
 	testSubmitHistogramBucket(t)
 }
 
+func TestSubmitExponentialHistogram(t *testing.T) {
+	testSubmitExponentialHistogram(t)
+}
+
 func TestSubmitEventPlatformEvent(t *testing.T) {
 	testSubmitEventPlatformEvent(t)
 }
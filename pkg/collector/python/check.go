@@ -44,6 +44,7 @@ type PythonCheck struct {
 	class          *C.rtloader_pyobject_t
 	ModuleName     string
 	interval       time.Duration
+	runTimeout     time.Duration
 	lastWarnings   []error
 	source         string
 	telemetry      bool // whether or not the telemetry is enabled for this check
@@ -123,6 +124,23 @@ func (c *PythonCheck) RunSimple() error {
 // Stop does nothing
 func (c *PythonCheck) Stop() {}
 
+// Interrupt interrupts the check if it's running, by raising an exception in
+// the thread running it. The check is interrupted once the thread runs Python
+// code again: a check blocked in a C extension isn't. It's only called when a
+// run exceeds its timeout, not when the check is unscheduled.
+func (c *PythonCheck) Interrupt() {
+	gstate, err := newStickyLock()
+	if err != nil {
+		log.Warnf("failed to interrupt check %s: %s", c.id, err)
+		return
+	}
+	defer gstate.unlock()
+
+	if C.interrupt_check(rtloader, c.instance) != 0 {
+		log.Infof("Interrupted python check %s", c.id)
+	}
+}
+
 // Cancel signals to a python check that he can free all internal resources and
 // deregisters the sender
 func (c *PythonCheck) Cancel() {
@@ -238,6 +256,11 @@ func (c *PythonCheck) Configure(integrationConfigDigest uint64, data integration
 		c.interval = time.Duration(commonOptions.MinCollectionInterval) * time.Second
 	}
 
+	// See if a run timeout was specified
+	if commonOptions.CheckTimeout > 0 {
+		c.runTimeout = time.Duration(commonOptions.CheckTimeout) * time.Second
+	}
+
 	// Disable default hostname if specified
 	if commonOptions.EmptyDefaultHostname {
 		s, err := aggregator.GetSender(c.id)
@@ -327,6 +350,12 @@ func (c *PythonCheck) Interval() time.Duration {
 	return c.interval
 }
 
+// RunTimeout returns the timeout of the runs configured for the check, or 0
+// to use the default one.
+func (c *PythonCheck) RunTimeout() time.Duration {
+	return c.runTimeout
+}
+
 // ID returns the ID of the check
 func (c *PythonCheck) ID() checkid.ID {
 	return c.id
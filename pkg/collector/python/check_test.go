@@ -31,6 +31,14 @@ func TestCheckCancel(t *testing.T) {
 	testCheckCancel(t)
 }
 
+func TestCheckStop(t *testing.T) {
+	testCheckStop(t)
+}
+
+func TestCheckInterrupt(t *testing.T) {
+	testCheckInterrupt(t)
+}
+
 func TestCheckCancelWhenRuntimeUnloaded(t *testing.T) {
 	testCheckCancelWhenRuntimeUnloaded(t)
 }
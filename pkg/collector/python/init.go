@@ -117,6 +117,7 @@ void SubmitMetric(char *, metric_type_t, char *, double, char **, char *, bool);
 void SubmitServiceCheck(char *, char *, int, char **, char *, char *);
 void SubmitEvent(char *, event_t *);
 void SubmitHistogramBucket(char *, char *, long long, float, float, int, char *, char **, bool);
+void SubmitExponentialHistogram(char *, char *, exponential_histogram_t *, int, char *, char **, bool);
 void SubmitEventPlatformEvent(char *, char *, int, char *);
 
 void initAggregatorModule(rtloader_t *rtloader) {
@@ -124,6 +125,7 @@ void initAggregatorModule(rtloader_t *rtloader) {
 	set_submit_service_check_cb(rtloader, SubmitServiceCheck);
 	set_submit_event_cb(rtloader, SubmitEvent);
 	set_submit_histogram_bucket_cb(rtloader, SubmitHistogramBucket);
+	set_submit_exponential_histogram_cb(rtloader, SubmitExponentialHistogram);
 	set_submit_event_platform_event_cb(rtloader, SubmitEventPlatformEvent);
 }
 
@@ -12,6 +12,7 @@ import (
 
 	"github.com/DataDog/datadog-agent/pkg/aggregator/mocksender"
 	checkid "github.com/DataDog/datadog-agent/pkg/collector/check/id"
+	"github.com/DataDog/datadog-agent/pkg/metrics"
 	"github.com/DataDog/datadog-agent/pkg/metrics/event"
 	"github.com/DataDog/datadog-agent/pkg/metrics/servicecheck"
 )
@@ -221,6 +222,40 @@ func testSubmitHistogramBucket(t *testing.T) {
 	sender.AssertHistogramBucket(t, "HistogramBucket", "test_histogram", 42, 1.0, 2.0, true, "my_hostname", []string{"tag1", "tag2"}, true)
 }
 
+func testSubmitExponentialHistogram(t *testing.T) {
+	sender := mocksender.NewMockSender(checkid.ID("testID"))
+	sender.SetupAcceptAll()
+
+	cTags := []*C.char{C.CString("tag1"), C.CString("tag2"), nil}
+	positiveCounts := []C.ulonglong{1, 0, 4}
+	histogram := C.exponential_histogram_t{
+		scale:           3,
+		sum:             21.5,
+		zero_count:      2,
+		positive_offset: -1,
+		positive_counts: &positiveCounts[0],
+		positive_len:    C.int(len(positiveCounts)),
+	}
+	SubmitExponentialHistogram(
+		C.CString("testID"),
+		C.CString("test_histogram"),
+		&histogram,
+		C.int(1),
+		C.CString("my_hostname"),
+		&cTags[0],
+		true,
+	)
+
+	expected := &metrics.ExponentialHistogram{
+		Scale:     3,
+		Sum:       21.5,
+		ZeroCount: 2,
+		Positive:  metrics.ExponentialBuckets{Offset: -1, Counts: []uint64{1, 0, 4}},
+		Negative:  metrics.ExponentialBuckets{Counts: []uint64{}},
+	}
+	sender.AssertCalled(t, "ExponentialHistogram", "test_histogram", expected, true, "my_hostname", []string{"tag1", "tag2"}, true)
+}
+
 func testSubmitEventPlatformEvent(t *testing.T) {
 	sender := mocksender.NewMockSender("testID")
 	sender.SetupAcceptAll()
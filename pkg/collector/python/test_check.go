@@ -85,6 +85,15 @@ char *run_check(rtloader_t *s, rtloader_pyobject_t *check) {
 	return run_check_return;
 }
 
+int interrupt_check_calls = 0;
+int interrupt_check_return = 0;
+rtloader_pyobject_t *interrupt_check_instance = NULL;
+int interrupt_check(rtloader_t *s, rtloader_pyobject_t *check) {
+	interrupt_check_instance = check;
+	interrupt_check_calls++;
+	return interrupt_check_return;
+}
+
 int cancel_check_calls = 0;
 rtloader_pyobject_t *cancel_check_instance = NULL;
 void cancel_check(rtloader_t *s, rtloader_pyobject_t *check) {
@@ -173,6 +182,9 @@ void reset_check_mock() {
 	get_check_check = NULL;
 	cancel_check_calls = 0;
 	cancel_check_instance = NULL;
+	interrupt_check_calls = 0;
+	interrupt_check_return = 0;
+	interrupt_check_instance = NULL;
 
 	get_check_deprecated_calls = 0;
 	get_check_deprecated_return = 0;
@@ -302,6 +314,49 @@ func testCheckCancel(t *testing.T) {
 	assert.Equal(t, check.instance, C.cancel_check_instance)
 }
 
+func testCheckStop(t *testing.T) {
+	rtloader = newMockRtLoaderPtr()
+	defer func() { rtloader = nil }()
+
+	check, err := NewPythonFakeCheck()
+	if !assert.Nil(t, err) {
+		return
+	}
+
+	C.reset_check_mock()
+	check.instance = newMockPyObjectPtr()
+
+	// Stop is called when the check is unscheduled, it must not interrupt a run
+	check.Stop()
+
+	assert.Equal(t, C.int(0), C.gil_locked_calls)
+	assert.Equal(t, C.int(0), C.interrupt_check_calls)
+}
+
+func testCheckInterrupt(t *testing.T) {
+	rtloader = newMockRtLoaderPtr()
+	defer func() { rtloader = nil }()
+
+	check, err := NewPythonFakeCheck()
+	if !assert.Nil(t, err) {
+		return
+	}
+
+	C.reset_check_mock()
+	check.instance = newMockPyObjectPtr()
+	C.interrupt_check_return = 1
+
+	check.Interrupt()
+
+	// Check that the lock was acquired
+	assert.Equal(t, C.int(1), C.gil_locked_calls)
+	assert.Equal(t, C.int(1), C.gil_unlocked_calls)
+
+	// Check that the call was passed to C
+	assert.Equal(t, C.int(1), C.interrupt_check_calls)
+	assert.Equal(t, check.instance, C.interrupt_check_instance)
+}
+
 func testCheckCancelWhenRuntimeUnloaded(t *testing.T) {
 	rtloader = newMockRtLoaderPtr()
 	defer func() { rtloader = nil }()
@@ -26,6 +26,7 @@ const (
 	runningChecksExpvarKey = "RunningChecks"
 	runsExpvarKey          = "Runs"
 	runningExpvarKey       = "Running"
+	timeoutsExpvarKey      = "Timeouts"
 	warningsExpvarKey      = "Warnings"
 )
 
@@ -81,6 +82,7 @@ func Reset() {
 		errorsExpvarKey,
 		runsExpvarKey,
 		runningChecksExpvarKey,
+		timeoutsExpvarKey,
 		warningsExpvarKey,
 	} {
 		runnerStats.Delete(key)
@@ -264,3 +266,17 @@ func GetErrorsCount() int64 {
 	}
 	return count.(*expvar.Int).Value()
 }
+
+// AddTimeoutsCount is used to increment the 'Timeouts' expvar
+func AddTimeoutsCount(amount int) {
+	runnerStats.Add(timeoutsExpvarKey, int64(amount))
+}
+
+// GetTimeoutsCount is used to get the value of 'Timeouts' expvar
+func GetTimeoutsCount() int64 {
+	count := runnerStats.Get(timeoutsExpvarKey)
+	if count == nil {
+		return 0
+	}
+	return count.(*expvar.Int).Value()
+}
@@ -177,6 +177,7 @@ func TestExpvarsReset(t *testing.T) {
 	AddRunsCount(2)
 	AddRunningCheckCount(3)
 	AddWarningsCount(4)
+	AddTimeoutsCount(5)
 
 	assert.Equal(t, numCheckNames, len(GetCheckStats()))
 	assert.Equal(t, numCheckNames, len(getCheckStatsExpvarMap(t)))
@@ -184,6 +185,7 @@ func TestExpvarsReset(t *testing.T) {
 	assert.NotNil(t, getRunnerExpvarMap(t).Get(runsExpvarKey))
 	assert.NotNil(t, getRunnerExpvarMap(t).Get(runningChecksExpvarKey))
 	assert.NotNil(t, getRunnerExpvarMap(t).Get(warningsExpvarKey))
+	assert.NotNil(t, getRunnerExpvarMap(t).Get(timeoutsExpvarKey))
 	assert.NotNil(t, getRunnerExpvarMap(t).Get(workersExpvarKey))
 
 	Reset()
@@ -195,6 +197,7 @@ func TestExpvarsReset(t *testing.T) {
 	assert.Nil(t, getRunnerExpvarMap(t).Get(runsExpvarKey))
 	assert.Nil(t, getRunnerExpvarMap(t).Get(runningChecksExpvarKey))
 	assert.Nil(t, getRunnerExpvarMap(t).Get(warningsExpvarKey))
+	assert.Nil(t, getRunnerExpvarMap(t).Get(timeoutsExpvarKey))
 	assert.NotNil(t, getRunnerExpvarMap(t).Get(workersExpvarKey))
 }
 
@@ -379,6 +382,7 @@ func TestExpvarsToplevelKeys(t *testing.T) {
 		"Errors":        GetErrorsCount,
 		"Runs":          GetRunsCount,
 		"RunningChecks": GetRunningCheckCount,
+		"Timeouts":      GetTimeoutsCount,
 		"Warnings":      GetWarningsCount,
 	}
 
@@ -386,6 +390,7 @@ func TestExpvarsToplevelKeys(t *testing.T) {
 		"Errors":        AddErrorsCount,
 		"Runs":          AddRunsCount,
 		"RunningChecks": AddRunningCheckCount,
+		"Timeouts":      AddTimeoutsCount,
 		"Warnings":      AddWarningsCount,
 	} {
 
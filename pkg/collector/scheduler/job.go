@@ -7,11 +7,15 @@ package scheduler
 
 import (
 	"fmt"
+	"hash/fnv"
+	"math/rand"
+	"sort"
 	"sync"
 	"time"
 
 	"github.com/DataDog/datadog-agent/pkg/collector/check"
 	checkid "github.com/DataDog/datadog-agent/pkg/collector/check/id"
+	"github.com/DataDog/datadog-agent/pkg/config"
 	"github.com/DataDog/datadog-agent/pkg/status/health"
 	"github.com/DataDog/datadog-agent/pkg/util/log"
 )
@@ -55,6 +59,22 @@ func (jb *jobBucket) removeJob(id checkid.ID) bool {
 	return false
 }
 
+func (jb *jobBucket) setJobs(jobs []check.Check) {
+	jb.mu.Lock()
+	defer jb.mu.Unlock()
+
+	jb.jobs = jobs
+}
+
+func (jb *jobBucket) allJobs() []check.Check {
+	jb.mu.RLock()
+	defer jb.mu.RUnlock()
+
+	jobs := make([]check.Check, len(jb.jobs))
+	copy(jobs, jb.jobs)
+	return jobs
+}
+
 // jobQueue contains a list of checks (called jobs) that need to be
 // scheduled at a certain interval.
 type jobQueue struct {
@@ -69,7 +89,20 @@ type jobQueue struct {
 	schedulingBucketIdx uint
 	running             bool
 	health              *health.Handle
-	mu                  sync.RWMutex // to protect critical sections in struct's fields
+	spread              bool                         // place the checks at offsets derived from their ID
+	jitter              time.Duration                // maximum random delay added to the runs of spread checks
+	offsets             map[checkid.ID]time.Duration // offset of the checks in the interval
+	unbalanced          bool                         // checks were added or removed since the last rebalance
+	delayed             chan delayedJobs             // checks waiting for their offset in the second of their bucket tick
+	mu                  sync.RWMutex                 // to protect critical sections in struct's fields
+}
+
+// delayedJobs are the checks of a bucket tick, sent to the execution pipeline
+// once their dispatch delay has elapsed
+type delayedJobs struct {
+	tick   time.Time
+	jobs   []check.Check
+	delays map[checkid.ID]time.Duration
 }
 
 // newJobQueue creates a new jobQueue instance
@@ -80,8 +113,17 @@ func newJobQueue(interval time.Duration) *jobQueue {
 		stopped:      make(chan bool),
 		health:       health.RegisterLiveness(fmt.Sprintf("collector-queue-%vs", interval.Seconds())),
 		bucketTicker: time.NewTicker(time.Second),
+		spread:       config.Datadog.GetBool("check_scheduling_spread"),
+		offsets:      make(map[checkid.ID]time.Duration),
 	}
 
+	jitter := time.Duration(config.Datadog.GetInt("check_scheduling_jitter")) * time.Millisecond
+	if jitter > time.Second {
+		log.Warnf("check_scheduling_jitter can't be greater than 1000ms, using 1000ms")
+		jitter = time.Second
+	}
+	jq.jitter = jitter
+
 	var nb int
 	if interval <= time.Second {
 		nb = 1
@@ -119,8 +161,19 @@ func (jq *jobQueue) addJob(c check.Check) {
 	jq.mu.Lock()
 	defer jq.mu.Unlock()
 
+	if jq.spread {
+		// the check runs at the offset derived from its ID until the queue is
+		// rebalanced, once at the next tick however many checks are added
+		offset := idOffset(c.ID(), jq.interval)
+		jq.buckets[int(offset/time.Second)%len(jq.buckets)].addJob(c)
+		jq.offsets[c.ID()] = offset
+		jq.unbalanced = true
+		return
+	}
+
 	// Checks scheduled to buckets scheduled with sparse round-robin
 	jq.buckets[jq.schedulingBucketIdx].addJob(c)
+	jq.offsets[c.ID()] = time.Duration(jq.schedulingBucketIdx) * time.Second
 	jq.schedulingBucketIdx = (jq.schedulingBucketIdx + jq.sparseStep) % uint(len(jq.buckets))
 }
 
@@ -130,6 +183,8 @@ func (jq *jobQueue) removeJob(id checkid.ID) error {
 
 	for _, bucket := range jq.buckets {
 		if found := bucket.removeJob(id); found {
+			delete(jq.offsets, id)
+			jq.unbalanced = jq.spread
 			return nil
 		}
 	}
@@ -137,6 +192,116 @@ func (jq *jobQueue) removeJob(id checkid.ID) error {
 	return fmt.Errorf("check with id %s is not in this Job Queue", id)
 }
 
+// jobs returns the checks of all the buckets
+func (jq *jobQueue) jobs() []check.Check {
+	var jobs []check.Check
+	for _, bucket := range jq.buckets {
+		jobs = append(jobs, bucket.allJobs()...)
+	}
+	return jobs
+}
+
+// balance rebalances the queue if checks were added or removed since the last rebalance
+func (jq *jobQueue) balance() {
+	jq.mu.Lock()
+	defer jq.mu.Unlock()
+
+	if jq.unbalanced {
+		jq.rebalance(jq.jobs())
+		jq.unbalanced = false
+	}
+}
+
+// rebalance places the checks in the buckets matching the offsets derived from
+// their ID. A bucket holds at most its share of the checks, the checks in excess
+// are moved to the following buckets: the load is spread evenly across the interval
+// and the placement only depends on the set of checks in the queue.
+// Must be called with jq.mu held.
+func (jq *jobQueue) rebalance(jobs []check.Check) {
+	idOffsets := make(map[checkid.ID]time.Duration, len(jobs))
+	for _, c := range jobs {
+		idOffsets[c.ID()] = idOffset(c.ID(), jq.interval)
+	}
+	sort.SliceStable(jobs, func(i, j int) bool {
+		oi, oj := idOffsets[jobs[i].ID()], idOffsets[jobs[j].ID()]
+		if oi != oj {
+			return oi < oj
+		}
+		return jobs[i].ID() < jobs[j].ID()
+	})
+
+	nb := len(jq.buckets)
+	share := (len(jobs) + nb - 1) / nb
+	placed := make([][]check.Check, nb)
+	offsets := make(map[checkid.ID]time.Duration, len(jobs))
+	for _, c := range jobs {
+		offset := idOffsets[c.ID()]
+		idx := int(offset / time.Second)
+		if idx >= nb {
+			idx = nb - 1
+		}
+		for len(placed[idx]) >= share {
+			idx = (idx + 1) % nb
+		}
+		placed[idx] = append(placed[idx], c)
+
+		offsets[c.ID()] = time.Duration(idx)*time.Second + offset%time.Second
+		if previous, found := jq.offsets[c.ID()]; found && previous != offsets[c.ID()] {
+			log.Debugf("Check %s moved from the offset %v to %v of the %v queue", c.ID(), previous, offsets[c.ID()], jq.interval)
+		}
+	}
+
+	for i, bucket := range jq.buckets {
+		bucket.setJobs(placed[i])
+	}
+	jq.offsets = offsets
+}
+
+// idOffset returns the offset of a check in the interval, derived from its ID
+func idOffset(id checkid.ID, interval time.Duration) time.Duration {
+	ms := uint64(interval / time.Millisecond)
+	if ms == 0 {
+		return 0
+	}
+	h := fnv.New64a()
+	h.Write([]byte(id)) //nolint:errcheck
+	return time.Duration(h.Sum64()%ms) * time.Millisecond
+}
+
+// dispatchDelays returns how long after the tick of their bucket the checks are
+// sent to the execution pipeline: spread checks are delayed by the sub-second
+// part of their offset, plus the random jitter wrapped within the second.
+func (jq *jobQueue) dispatchDelays(jobs []check.Check) map[checkid.ID]time.Duration {
+	jq.mu.RLock()
+	defer jq.mu.RUnlock()
+
+	if !jq.spread {
+		return nil
+	}
+
+	delays := make(map[checkid.ID]time.Duration, len(jobs))
+	for _, c := range jobs {
+		delay := jq.offsets[c.ID()] % time.Second
+		if jq.jitter > 0 {
+			delay = (delay + time.Duration(rand.Int63n(int64(jq.jitter)))) % time.Second
+		}
+		delays[c.ID()] = delay
+	}
+	return delays
+}
+
+// checkOffsets returns the offset of the checks in the interval
+func (jq *jobQueue) checkOffsets() map[checkid.ID]string {
+	jq.mu.RLock()
+	defer jq.mu.RUnlock()
+
+	offsets := make(map[checkid.ID]string, len(jq.offsets))
+	for id, offset := range jq.offsets {
+		offsets[id] = offset.String()
+	}
+	return offsets
+}
+
 func (jq *jobQueue) stats() map[string]interface{} {
 	jq.mu.RLock()
 	defer jq.mu.RUnlock()
@@ -159,12 +324,29 @@ func (jq *jobQueue) stats() map[string]interface{} {
 // execution pipeline.
 // Not blocking, runs in a new goroutine.
 func (jq *jobQueue) run(s *Scheduler) {
+	// spread checks are dispatched by a separate goroutine so that waiting for
+	// their offset doesn't delay the following ticks. It buffers at most an
+	// interval worth of ticks.
+	var stopDispatch, dispatchStopped chan struct{}
+	if jq.spread {
+		jq.delayed = make(chan delayedJobs, len(jq.buckets))
+		stopDispatch = make(chan struct{})
+		dispatchStopped = make(chan struct{})
+		go func() {
+			jq.dispatch(s, stopDispatch)
+			close(dispatchStopped)
+		}()
+	}
 
 	go func() {
 		log.Debugf("Job queue is running...")
 		for jq.process(s) {
 			// empty
 		}
+		if stopDispatch != nil {
+			close(stopDispatch)
+			<-dispatchStopped
+		}
 		jq.stopped <- true
 	}()
 }
@@ -179,6 +361,7 @@ func (jq *jobQueue) process(s *Scheduler) bool {
 		return false
 	case t := <-jq.bucketTicker.C:
 		log.Tracef("Bucket ticked... current index: %v", jq.currentBucketIdx)
+		jq.balance()
 		jq.mu.Lock()
 		if !jq.lastTick.Equal(time.Time{}) && t.After(jq.lastTick.Add(2*time.Second)) {
 			log.Debugf("Previous bucket took over %v to schedule. Next checks will be running behind the schedule.", t.Sub(jq.lastTick))
@@ -196,24 +379,43 @@ func (jq *jobQueue) process(s *Scheduler) bool {
 
 		log.Tracef("Jobs in bucket: %v", jobs)
 
-		for _, check := range jobs {
-			if !s.IsCheckScheduled(check.ID()) {
-				continue
-			}
+		if delays := jq.dispatchDelays(jobs); delays != nil {
+			sort.SliceStable(jobs, func(i, j int) bool {
+				return delays[jobs[i].ID()] < delays[jobs[j].ID()]
+			})
 
 			select {
-			// blocking, we'll be here as long as it takes
-			case s.checksPipe <- check:
+			// only blocks if the dispatcher is an interval behind
+			case jq.delayed <- delayedJobs{tick: t, jobs: jobs, delays: delays}:
 			case <-jq.stop:
 				jq.health.Deregister() //nolint:errcheck
 				return false
 			}
 
 			select {
-			// we were able to schedule a check so we're not stuck, therefore poll the health chan
 			case <-jq.health.C:
 			default:
 			}
+		} else {
+			for _, check := range jobs {
+				if !s.IsCheckScheduled(check.ID()) {
+					continue
+				}
+
+				select {
+				// blocking, we'll be here as long as it takes
+				case s.checksPipe <- check:
+				case <-jq.stop:
+					jq.health.Deregister() //nolint:errcheck
+					return false
+				}
+
+				select {
+				// we were able to schedule a check so we're not stuck, therefore poll the health chan
+				case <-jq.health.C:
+				default:
+				}
+			}
 		}
 		jq.mu.Lock()
 		jq.currentBucketIdx = (jq.currentBucketIdx + 1) % uint(len(jq.buckets))
@@ -224,3 +426,39 @@ func (jq *jobQueue) process(s *Scheduler) bool {
 
 	return true
 }
+
+// dispatch sends the delayed checks to the execution pipeline once their
+// dispatch delay has elapsed, until stop is closed
+func (jq *jobQueue) dispatch(s *Scheduler, stop <-chan struct{}) {
+	for {
+		var delayed delayedJobs
+		select {
+		case delayed = <-jq.delayed:
+		case <-stop:
+			return
+		}
+
+		for _, check := range delayed.jobs {
+			if wait := time.Until(delayed.tick.Add(delayed.delays[check.ID()])); wait > 0 {
+				timer := time.NewTimer(wait)
+				select {
+				case <-timer.C:
+				case <-stop:
+					timer.Stop()
+					return
+				}
+			}
+
+			if !s.IsCheckScheduled(check.ID()) {
+				continue
+			}
+
+			select {
+			// blocking, we'll be here as long as it takes
+			case s.checksPipe <- check:
+			case <-stop:
+				return
+			}
+		}
+	}
+}
@@ -6,13 +6,17 @@
 package scheduler
 
 import (
+	"fmt"
 	"runtime"
 	"testing"
 	"time"
 
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/DataDog/datadog-agent/pkg/collector/check"
 	checkid "github.com/DataDog/datadog-agent/pkg/collector/check/id"
+	"github.com/DataDog/datadog-agent/pkg/config"
 	"github.com/DataDog/datadog-agent/pkg/util/testutil"
 )
 
@@ -66,3 +70,136 @@ func TestBucket_RemoveJob(t *testing.T) {
 	// use the bucket, just to keep it alive during the earlier GC run
 	bucket.addJob(&TestJobCheck{id: "here so the GC doesn't GC the entire bucket"})
 }
+
+func TestJobQueue_Spread(t *testing.T) {
+	config.Datadog.Set("check_scheduling_spread", true)
+	defer config.Datadog.Set("check_scheduling_spread", false)
+
+	jq := newJobQueue(10 * time.Second)
+	defer jq.health.Deregister() //nolint:errcheck
+
+	var checks []*TestJobCheck
+	for i := 0; i < 45; i++ {
+		c := &TestJobCheck{id: fmt.Sprintf("check:%d", i)}
+		checks = append(checks, c)
+		jq.addJob(c)
+	}
+	// the queue is rebalanced once for all the checks added
+	assert.True(t, jq.unbalanced)
+	jq.balance()
+	assert.False(t, jq.unbalanced)
+
+	// every bucket holds at most its share of the checks
+	for _, bucket := range jq.buckets {
+		assert.LessOrEqual(t, bucket.size(), 5)
+	}
+	for i, bucket := range jq.buckets {
+		for _, c := range bucket.jobs {
+			offset := jq.offsets[c.ID()]
+			assert.Equal(t, time.Duration(i), offset/time.Second)
+			// the sub-second part of the offset is derived from the ID
+			assert.Equal(t, idOffset(c.ID(), jq.interval)%time.Second, offset%time.Second)
+		}
+	}
+
+	// the placement only depends on the set of checks
+	other := newJobQueue(10 * time.Second)
+	defer other.health.Deregister() //nolint:errcheck
+	for i := len(checks) - 1; i >= 0; i-- {
+		other.addJob(checks[i])
+	}
+	other.balance()
+	assert.Equal(t, jq.offsets, other.offsets)
+
+	// removing checks rebalances the queue
+	for _, c := range checks[:25] {
+		require.NoError(t, jq.removeJob(c.ID()))
+	}
+	jq.balance()
+	assert.Len(t, jq.offsets, 20)
+	for _, bucket := range jq.buckets {
+		assert.LessOrEqual(t, bucket.size(), 2)
+	}
+	assert.Error(t, jq.removeJob(checks[0].ID()))
+}
+
+func TestJobQueue_DispatchDelays(t *testing.T) {
+	jq := newJobQueue(10 * time.Second)
+	defer jq.health.Deregister() //nolint:errcheck
+	c := &TestJobCheck{id: "check:1"}
+	jq.addJob(c)
+	assert.Nil(t, jq.dispatchDelays([]check.Check{c}))
+	assert.Equal(t, map[checkid.ID]string{"check:1": "0s"}, jq.checkOffsets())
+
+	config.Datadog.Set("check_scheduling_spread", true)
+	config.Datadog.Set("check_scheduling_jitter", 200)
+	defer config.Datadog.Set("check_scheduling_spread", false)
+	defer config.Datadog.Set("check_scheduling_jitter", 0)
+
+	jq = newJobQueue(10 * time.Second)
+	defer jq.health.Deregister() //nolint:errcheck
+	jq.addJob(c)
+
+	subSecond := idOffset(c.ID(), jq.interval) % time.Second
+	for i := 0; i < 100; i++ {
+		delay := jq.dispatchDelays([]check.Check{c})[c.ID()]
+		// the jitter is wrapped within the second
+		if subSecond+200*time.Millisecond < time.Second {
+			assert.GreaterOrEqual(t, delay, subSecond)
+			assert.Less(t, delay, subSecond+200*time.Millisecond)
+		} else {
+			assert.Less(t, delay, time.Second)
+		}
+	}
+}
+
+func TestJobQueue_DelayedDispatchDoesntBlockTicks(t *testing.T) {
+	config.Datadog.Set("check_scheduling_spread", true)
+	defer config.Datadog.Set("check_scheduling_spread", false)
+
+	// a check in the first bucket, dispatched late in the second of its tick
+	var c *TestJobCheck
+	for i := 0; c == nil; i++ {
+		id := checkid.ID(fmt.Sprintf("check:%d", i))
+		if offset := idOffset(id, 10*time.Second); offset >= 500*time.Millisecond && offset < 900*time.Millisecond {
+			c = &TestJobCheck{id: string(id)}
+		}
+	}
+
+	pipe := make(chan check.Check, 1)
+	s := NewScheduler(pipe)
+	ticks := make(chan time.Time)
+	jq := newJobQueue(10 * time.Second)
+	jq.bucketTicker.Stop()
+	jq.bucketTicker = &time.Ticker{C: ticks}
+	jq.addJob(c)
+	s.checkToQueue[c.ID()] = jq
+
+	jq.run(s)
+	start := time.Now()
+	ticks <- start
+
+	// the following tick is processed while the check waits for its offset
+	select {
+	case ticks <- time.Now():
+	case <-time.After(400 * time.Millisecond):
+		t.Fatal("the tick was blocked by the delayed check")
+	}
+
+	select {
+	case dispatched := <-pipe:
+		assert.Equal(t, c.ID(), dispatched.ID())
+		assert.GreaterOrEqual(t, time.Since(start), jq.offsets[c.ID()]%time.Second)
+	case <-time.After(2 * time.Second):
+		t.Fatal("the delayed check wasn't dispatched")
+	}
+
+	jq.stop <- true
+	<-jq.stopped
+}
+
+func TestIDOffset(t *testing.T) {
+	assert.Equal(t, idOffset("check:1", time.Minute), idOffset("check:1", time.Minute))
+	assert.Less(t, idOffset("check:1", time.Minute), time.Minute)
+	assert.Equal(t, time.Duration(0), idOffset("check:1", time.Microsecond))
+}
@@ -118,6 +118,7 @@ func (s *Scheduler) Enter(check check.Check) error {
 		tlmChecksEntered.Inc(checkName)
 	}
 	schedulerExpvars.Set("Queues", expvar.Func(expQueues(s)))
+	schedulerExpvars.Set("CheckOffsets", expvar.Func(expCheckOffsets(s)))
 	return nil
 }
 
@@ -148,6 +149,7 @@ func (s *Scheduler) Cancel(id checkid.ID) error {
 		tlmChecksEntered.Dec(checkName)
 	}
 	schedulerExpvars.Set("Queues", expvar.Func(expQueues(s)))
+	schedulerExpvars.Set("CheckOffsets", expvar.Func(expCheckOffsets(s)))
 	return nil
 }
 
@@ -287,3 +289,17 @@ func expQueues(s *Scheduler) func() interface{} {
 		return queues
 	}
 }
+
+// expCheckOffsets return a function to get the offsets of the checks in their interval
+func expCheckOffsets(s *Scheduler) func() interface{} {
+	return func() interface{} {
+		offsets := make(map[checkid.ID]string)
+
+		for _, queue := range s.jobQueues {
+			for id, offset := range queue.checkOffsets() {
+				offsets[id] = offset
+			}
+		}
+		return offsets
+	}
+}
@@ -0,0 +1,287 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package wasm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/tetratelabs/wazero"
+	"github.com/tetratelabs/wazero/api"
+	"github.com/tetratelabs/wazero/imports/wasi_snapshot_preview1"
+	"gopkg.in/yaml.v2"
+
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+	core "github.com/DataDog/datadog-agent/pkg/collector/corechecks"
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+const (
+	// runFunction is the function modules must export, called at each run of the check.
+	// A non-zero result means the run failed.
+	runFunction = "run"
+	// initializeFunction is the function called, if exported, when the module is instantiated
+	initializeFunction = "_initialize"
+
+	// wasmPageSize is the size of a WebAssembly memory page
+	wasmPageSize = 64 * 1024
+)
+
+// limitsConfig are the instance options limiting the resources used by a module
+type limitsConfig struct {
+	MemoryLimitMB int `yaml:"wasm_memory_limit_mb"`
+	RunTimeout    int `yaml:"wasm_run_timeout"`
+}
+
+// WasmCheck runs a check compiled to WebAssembly
+type WasmCheck struct {
+	core.CheckBase
+	code  []byte
+	cache wazero.CompilationCache
+
+	instance   integration.Data
+	initConfig integration.Data
+
+	memoryLimitMB int
+	runTimeout    time.Duration
+
+	// m protects the fields below, as Stop and Cancel may be called while the check runs
+	m         sync.Mutex
+	runtime   wazero.Runtime
+	compiled  wazero.CompiledModule
+	module    api.Module
+	cancelRun context.CancelFunc
+	// runError is the error message set by the module during the current run
+	runError string
+}
+
+func newWasmCheck(name string, code []byte, cache wazero.CompilationCache) *WasmCheck {
+	return &WasmCheck{
+		CheckBase: core.NewCheckBase(name),
+		code:      code,
+		cache:     cache,
+	}
+}
+
+// Configure configures the check and instantiates its module
+func (c *WasmCheck) Configure(integrationConfigDigest uint64, data integration.Data, initConfig integration.Data, source string) error {
+	c.BuildID(integrationConfigDigest, data, initConfig)
+	if err := c.CheckBase.Configure(integrationConfigDigest, data, initConfig, source); err != nil {
+		return err
+	}
+	c.instance = data
+	c.initConfig = initConfig
+
+	limits := limitsConfig{
+		MemoryLimitMB: config.Datadog.GetInt("wasm_check_memory_limit_mb"),
+		RunTimeout:    config.Datadog.GetInt("wasm_check_run_timeout"),
+	}
+	if err := yaml.Unmarshal(data, &limits); err != nil {
+		return err
+	}
+	if limits.MemoryLimitMB <= 0 {
+		return fmt.Errorf("wasm_memory_limit_mb must be greater than 0")
+	}
+	if limits.RunTimeout <= 0 {
+		return fmt.Errorf("wasm_run_timeout must be greater than 0")
+	}
+	c.memoryLimitMB = limits.MemoryLimitMB
+	c.runTimeout = time.Duration(limits.RunTimeout) * time.Second
+
+	ctx := context.Background()
+	runtimeConfig := wazero.NewRuntimeConfig().
+		WithCompilationCache(c.cache).
+		WithMemoryLimitPages(uint32(c.memoryLimitMB * 1024 * 1024 / wasmPageSize)).
+		WithCloseOnContextDone(true)
+	runtime := wazero.NewRuntimeWithConfig(ctx, runtimeConfig)
+	c.m.Lock()
+	c.runtime = runtime
+	c.m.Unlock()
+
+	if err := c.instantiateHostModules(ctx, runtime); err != nil {
+		c.closeRuntime()
+		return err
+	}
+
+	compiled, err := runtime.CompileModule(ctx, c.code)
+	if err != nil {
+		c.closeRuntime()
+		return fmt.Errorf("could not compile wasm module: %v", err)
+	}
+	if _, found := compiled.ExportedFunctions()[runFunction]; !found {
+		c.closeRuntime()
+		return fmt.Errorf("wasm module doesn't export a %q function", runFunction)
+	}
+	c.m.Lock()
+	c.compiled = compiled
+	c.m.Unlock()
+
+	if err := c.instantiate(ctx); err != nil {
+		c.closeRuntime()
+		return err
+	}
+	return nil
+}
+
+func (c *WasmCheck) instantiateHostModules(ctx context.Context, runtime wazero.Runtime) error {
+	// WASI lets modules built by the usual toolchains run, without giving them
+	// access to the filesystem, the network or the environment.
+	if _, err := wasi_snapshot_preview1.Instantiate(ctx, runtime); err != nil {
+		return fmt.Errorf("could not instantiate wasi: %v", err)
+	}
+	if _, err := newHostModule(c, runtime).Instantiate(ctx); err != nil {
+		return fmt.Errorf("could not instantiate the %s host module: %v", hostModuleName, err)
+	}
+	return nil
+}
+
+// instantiate creates a new instance of the module, with a fresh memory
+func (c *WasmCheck) instantiate(ctx context.Context) error {
+	c.m.Lock()
+	runtime, compiled := c.runtime, c.compiled
+	c.m.Unlock()
+
+	if runtime == nil {
+		return errors.New("the check was cancelled")
+	}
+	moduleConfig := wazero.NewModuleConfig().
+		WithName(string(c.ID())).
+		WithStartFunctions(initializeFunction)
+	module, err := runtime.InstantiateModule(ctx, compiled, moduleConfig)
+	if err != nil {
+		return fmt.Errorf("could not instantiate wasm module: %v", err)
+	}
+
+	c.m.Lock()
+	defer c.m.Unlock()
+	// the check may have been cancelled in the meantime
+	if c.runtime == nil {
+		if err := module.Close(context.Background()); err != nil {
+			log.Debugf("wasm check %s: could not close module: %v", c.ID(), err)
+		}
+		return errors.New("the check was cancelled")
+	}
+	c.module = module
+	return nil
+}
+
+// Run calls the `run` function of the module
+func (c *WasmCheck) Run() error {
+	return c.RunContext(context.Background())
+}
+
+// RunContext calls the `run` function of the module, interrupting it once ctx
+// is cancelled
+func (c *WasmCheck) RunContext(parent context.Context) error {
+	sender, err := c.GetSender()
+	if err != nil {
+		return err
+	}
+
+	c.m.Lock()
+	module := c.module
+	c.m.Unlock()
+
+	// the module is closed when a run fails, start again from a fresh instance
+	if module == nil {
+		if err := c.instantiate(context.Background()); err != nil {
+			return err
+		}
+		c.m.Lock()
+		module = c.module
+		c.m.Unlock()
+	}
+
+	ctx, cancel := context.WithTimeout(parent, c.runTimeout)
+	defer cancel()
+
+	c.m.Lock()
+	c.cancelRun = cancel
+	c.runError = ""
+	c.m.Unlock()
+
+	results, err := module.ExportedFunction(runFunction).Call(ctx)
+	sender.Commit()
+
+	c.m.Lock()
+	defer c.m.Unlock()
+	c.cancelRun = nil
+
+	if err != nil {
+		c.closeModule()
+		switch {
+		case parent.Err() != nil:
+			return fmt.Errorf("run was interrupted: %v", parent.Err())
+		case ctx.Err() == context.DeadlineExceeded:
+			addExpvarTimeout(c.String())
+			return fmt.Errorf("run exceeded the time limit of %s", c.runTimeout)
+		case ctx.Err() == context.Canceled:
+			return errors.New("run was stopped")
+		}
+		return fmt.Errorf("run failed: %v", err)
+	}
+
+	if len(results) > 0 && results[0] != 0 {
+		if c.runError != "" {
+			return errors.New(c.runError)
+		}
+		return fmt.Errorf("run returned %d", api.DecodeI32(results[0]))
+	}
+	return nil
+}
+
+// Stop interrupts the current run
+func (c *WasmCheck) Stop() {
+	c.m.Lock()
+	defer c.m.Unlock()
+
+	if c.cancelRun != nil {
+		c.cancelRun()
+	}
+}
+
+// Cancel releases the runtime of the check
+func (c *WasmCheck) Cancel() {
+	c.Stop()
+	c.closeRuntime()
+	c.CheckBase.Cancel()
+}
+
+func (c *WasmCheck) setRunError(msg string) {
+	c.m.Lock()
+	defer c.m.Unlock()
+
+	c.runError = msg
+}
+
+// closeModule closes the module instance. It must be called with c.m held.
+func (c *WasmCheck) closeModule() {
+	if c.module == nil {
+		return
+	}
+	if err := c.module.Close(context.Background()); err != nil {
+		log.Debugf("wasm check %s: could not close module: %v", c.ID(), err)
+	}
+	c.module = nil
+}
+
+func (c *WasmCheck) closeRuntime() {
+	c.m.Lock()
+	defer c.m.Unlock()
+
+	c.module = nil
+	if c.runtime == nil {
+		return
+	}
+	if err := c.runtime.Close(context.Background()); err != nil {
+		log.Debugf("wasm check %s: could not close runtime: %v", c.ID(), err)
+	}
+	c.runtime = nil
+}
@@ -0,0 +1,218 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package wasm
+
+import (
+	"encoding/binary"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tetratelabs/wazero"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator/mocksender"
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+	checkid "github.com/DataDog/datadog-agent/pkg/collector/check/id"
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/metrics/servicecheck"
+)
+
+// The test modules import, in order, submit_metric, submit_service_check and
+// set_error, and export their memory and a run function. Their data is:
+//
+//	0: "foo.bar", 16: "a:b\nc:d", 32: "my.sc", 48: "msg", 56: "boom"
+var testData = map[byte]string{0: "foo.bar", 16: "a:b\nc:d", 32: "my.sc", 48: "msg", 56: "boom"}
+
+// submitBody submits a gauge and a service check, and returns 0
+func submitBody() []byte {
+	value := make([]byte, 8)
+	binary.LittleEndian.PutUint64(value, math.Float64bits(1.5))
+
+	body := []byte{
+		0x41, 0, // i32.const 0: gauge
+		0x41, 0, 0x41, 7, // name
+		0x44, // f64.const 1.5
+	}
+	body = append(body, value...)
+	body = append(body,
+		0x41, 16, 0x41, 7, // tags
+		0x41, 0, 0x41, 0, // hostname
+		0x10, 0, // call submit_metric
+		0x41, 32, 0x41, 5, // name
+		0x41, 1, // status
+		0x41, 16, 0x41, 7, // tags
+		0x41, 0, 0x41, 0, // hostname
+		0x41, 48, 0x41, 3, // message
+		0x10, 1, // call submit_service_check
+		0x41, 0, // return 0
+	)
+	return body
+}
+
+// errorBody sets the "boom" error and returns 1
+func errorBody() []byte {
+	return []byte{0x41, 56, 0x41, 4, 0x10, 2, 0x41, 1}
+}
+
+// loopBody never returns
+func loopBody() []byte {
+	return []byte{0x03, 0x40, 0x0c, 0, 0x0b, 0x41, 0}
+}
+
+func buildModule(runBody []byte, memoryPages byte) []byte {
+	i32, f64 := byte(0x7f), byte(0x7c)
+	types := vec(
+		append([]byte{0x60}, append(vec([]byte{i32}, []byte{i32}, []byte{i32}, []byte{f64}, []byte{i32}, []byte{i32}, []byte{i32}, []byte{i32}), vec()...)...),
+		append([]byte{0x60}, append(vec([]byte{i32}, []byte{i32}, []byte{i32}, []byte{i32}, []byte{i32}, []byte{i32}, []byte{i32}, []byte{i32}, []byte{i32}), vec()...)...),
+		append([]byte{0x60}, append(vec([]byte{i32}, []byte{i32}), vec()...)...),
+		append([]byte{0x60}, append(vec(), vec([]byte{i32})...)...),
+	)
+	imports := vec(
+		append(append(name(hostModuleName), name("submit_metric")...), 0x00, 0),
+		append(append(name(hostModuleName), name("submit_service_check")...), 0x00, 1),
+		append(append(name(hostModuleName), name("set_error")...), 0x00, 2),
+	)
+	functions := vec([]byte{3})
+	memory := vec([]byte{0x00, memoryPages})
+	exports := vec(
+		append(name("memory"), 0x02, 0),
+		append(name(runFunction), 0x00, 3),
+	)
+	body := append(append([]byte{0}, runBody...), 0x0b)
+	code := vec(append(uleb(uint32(len(body))), body...))
+	var segments [][]byte
+	for offset, s := range testData {
+		segments = append(segments, append([]byte{0x00, 0x41, offset, 0x0b}, append(uleb(uint32(len(s))), s...)...))
+	}
+	data := vec(segments...)
+
+	module := []byte{0x00, 'a', 's', 'm', 0x01, 0x00, 0x00, 0x00}
+	module = append(module, section(1, types)...)
+	module = append(module, section(2, imports)...)
+	module = append(module, section(3, functions)...)
+	module = append(module, section(5, memory)...)
+	module = append(module, section(7, exports)...)
+	module = append(module, section(10, code)...)
+	module = append(module, section(11, data)...)
+	return module
+}
+
+func uleb(v uint32) []byte {
+	var b []byte
+	for {
+		c := byte(v & 0x7f)
+		v >>= 7
+		if v != 0 {
+			b = append(b, c|0x80)
+		} else {
+			return append(b, c)
+		}
+	}
+}
+
+func vec(items ...[]byte) []byte {
+	b := uleb(uint32(len(items)))
+	for _, item := range items {
+		b = append(b, item...)
+	}
+	return b
+}
+
+func name(s string) []byte {
+	return append(uleb(uint32(len(s))), s...)
+}
+
+func section(id byte, content []byte) []byte {
+	return append(append([]byte{id}, uleb(uint32(len(content)))...), content...)
+}
+
+func newTestCheck(t *testing.T, code []byte, instance integration.Data) (*WasmCheck, *mocksender.MockSender) {
+	mockSender := mocksender.NewMockSender(checkid.BuildID("test", integration.FakeConfigHash, instance, nil))
+	mockSender.SetupAcceptAll()
+
+	c := newWasmCheck("test", code, wazero.NewCompilationCache())
+	err := c.Configure(integration.FakeConfigHash, instance, nil, "test")
+	require.NoError(t, err)
+	t.Cleanup(c.Cancel)
+	return c, mockSender
+}
+
+func TestRun(t *testing.T) {
+	c, mockSender := newTestCheck(t, buildModule(submitBody(), 1), nil)
+
+	require.NoError(t, c.Run())
+	mockSender.AssertCalled(t, "Gauge", "foo.bar", 1.5, "", []string{"a:b", "c:d"})
+	mockSender.AssertCalled(t, "ServiceCheck", "my.sc", servicecheck.ServiceCheckWarning, "", []string{"a:b", "c:d"}, "msg")
+	mockSender.AssertNumberOfCalls(t, "Commit", 1)
+
+	// the module instance is kept between runs
+	require.NoError(t, c.Run())
+	mockSender.AssertNumberOfCalls(t, "Gauge", 2)
+}
+
+func TestRunError(t *testing.T) {
+	c, _ := newTestCheck(t, buildModule(errorBody(), 1), nil)
+	assert.EqualError(t, c.Run(), "boom")
+}
+
+func TestRunTimeout(t *testing.T) {
+	c, _ := newTestCheck(t, buildModule(loopBody(), 1), integration.Data("wasm_run_timeout: 1"))
+
+	assert.EqualError(t, c.Run(), "run exceeded the time limit of 1s")
+	assert.Equal(t, 1, expvarTimeouts().(map[string]int)["test"])
+
+	// the module is instantiated again at the next run
+	assert.EqualError(t, c.Run(), "run exceeded the time limit of 1s")
+}
+
+func TestRunCancel(t *testing.T) {
+	c, _ := newTestCheck(t, buildModule(errorBody(), 1), nil)
+
+	// the runs following the cancellation instantiate the module again, which
+	// must not race with the release of the runtime
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 100; i++ {
+			c.Run()
+		}
+	}()
+	c.Cancel()
+	<-done
+
+	assert.EqualError(t, c.Run(), "the check was cancelled")
+}
+
+func TestMemoryLimit(t *testing.T) {
+	code := buildModule(submitBody(), 32) // 2MB
+	instance := integration.Data("wasm_memory_limit_mb: 1")
+	mocksender.NewMockSender(checkid.BuildID("test", integration.FakeConfigHash, instance, nil)).SetupAcceptAll()
+
+	c := newWasmCheck("test", code, wazero.NewCompilationCache())
+	assert.Error(t, c.Configure(integration.FakeConfigHash, instance, nil, "test"))
+}
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "test.wasm"), buildModule(submitBody(), 1), 0644))
+	config.Datadog.Set("additional_checksd", dir)
+	defer config.Datadog.Set("additional_checksd", nil)
+
+	loader, err := NewWasmCheckLoader()
+	require.NoError(t, err)
+
+	cfg := integration.Config{Name: "test"}
+	mocksender.NewMockSender(checkid.BuildID("test", cfg.FastDigest(), nil, nil)).SetupAcceptAll()
+	c, err := loader.Load(cfg, nil)
+	require.NoError(t, err)
+	c.Cancel()
+
+	_, err = loader.Load(integration.Config{Name: "other"}, nil)
+	assert.Error(t, err)
+}
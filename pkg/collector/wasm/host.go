@@ -0,0 +1,206 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package wasm
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+
+	"github.com/tetratelabs/wazero"
+	"github.com/tetratelabs/wazero/api"
+
+	"github.com/DataDog/datadog-agent/pkg/metrics/event"
+	"github.com/DataDog/datadog-agent/pkg/metrics/servicecheck"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// hostModuleName is the name of the module the WASM checks import the host API from.
+//
+// Strings are passed as a pointer and a length in the memory of the module.
+// Tags are passed as a single string, separated by new lines.
+const hostModuleName = "datadog"
+
+// Metric types, matching the ones of the Python checks
+const (
+	metricTypeGauge uint32 = iota
+	metricTypeRate
+	metricTypeCount
+	metricTypeMonotonicCount
+	metricTypeCounter
+	metricTypeHistogram
+	metricTypeHistorate
+)
+
+// Log levels
+const (
+	logLevelDebug uint32 = iota
+	logLevelInfo
+	logLevelWarn
+	logLevelError
+)
+
+// newHostModule builds the host API of a check:
+//
+//	instance_config(buf_ptr, buf_len) -> size
+//	init_config(buf_ptr, buf_len) -> size
+//	submit_metric(type, name_ptr, name_len, value f64, tags_ptr, tags_len, hostname_ptr, hostname_len)
+//	submit_service_check(name_ptr, name_len, status, tags_ptr, tags_len, hostname_ptr, hostname_len, message_ptr, message_len)
+//	submit_event(event_ptr, event_len)
+//	warning(message_ptr, message_len)
+//	set_error(message_ptr, message_len)
+//	log(level, message_ptr, message_len)
+//
+// instance_config and init_config copy the YAML configuration into the buffer,
+// truncated to its length, and return the size of the whole configuration.
+// submit_event takes the event encoded in JSON, with the fields of the events
+// sent to the intake.
+func newHostModule(c *WasmCheck, runtime wazero.Runtime) wazero.HostModuleBuilder {
+	h := &host{check: c}
+	return runtime.NewHostModuleBuilder(hostModuleName).
+		NewFunctionBuilder().WithFunc(h.instanceConfig).Export("instance_config").
+		NewFunctionBuilder().WithFunc(h.initConfig).Export("init_config").
+		NewFunctionBuilder().WithFunc(h.submitMetric).Export("submit_metric").
+		NewFunctionBuilder().WithFunc(h.submitServiceCheck).Export("submit_service_check").
+		NewFunctionBuilder().WithFunc(h.submitEvent).Export("submit_event").
+		NewFunctionBuilder().WithFunc(h.warning).Export("warning").
+		NewFunctionBuilder().WithFunc(h.setError).Export("set_error").
+		NewFunctionBuilder().WithFunc(h.log).Export("log")
+}
+
+type host struct {
+	check *WasmCheck
+}
+
+func (h *host) instanceConfig(_ context.Context, m api.Module, bufPtr, bufLen uint32) uint32 {
+	return writeBuffer(m, bufPtr, bufLen, h.check.instance)
+}
+
+func (h *host) initConfig(_ context.Context, m api.Module, bufPtr, bufLen uint32) uint32 {
+	return writeBuffer(m, bufPtr, bufLen, h.check.initConfig)
+}
+
+func (h *host) submitMetric(_ context.Context, m api.Module, metricType, namePtr, nameLen uint32, value float64, tagsPtr, tagsLen, hostnamePtr, hostnameLen uint32) {
+	sender, err := h.check.GetSender()
+	if err != nil {
+		log.Errorf("Error submitting metric to the Sender: %v", err)
+		return
+	}
+
+	name := readString(m, namePtr, nameLen)
+	tags := readTags(m, tagsPtr, tagsLen)
+	hostname := readString(m, hostnamePtr, hostnameLen)
+
+	switch metricType {
+	case metricTypeGauge:
+		sender.Gauge(name, value, hostname, tags)
+	case metricTypeRate:
+		sender.Rate(name, value, hostname, tags)
+	case metricTypeCount:
+		sender.Count(name, value, hostname, tags)
+	case metricTypeMonotonicCount:
+		sender.MonotonicCount(name, value, hostname, tags)
+	case metricTypeCounter:
+		sender.Counter(name, value, hostname, tags)
+	case metricTypeHistogram:
+		sender.Histogram(name, value, hostname, tags)
+	case metricTypeHistorate:
+		sender.Historate(name, value, hostname, tags)
+	default:
+		log.Debugf("wasm check %s: ignoring metric %s of unknown type %d", h.check.ID(), name, metricType)
+	}
+}
+
+func (h *host) submitServiceCheck(_ context.Context, m api.Module, namePtr, nameLen, status, tagsPtr, tagsLen, hostnamePtr, hostnameLen, messagePtr, messageLen uint32) {
+	sender, err := h.check.GetSender()
+	if err != nil {
+		log.Errorf("Error submitting service check to the Sender: %v", err)
+		return
+	}
+
+	sender.ServiceCheck(
+		readString(m, namePtr, nameLen),
+		servicecheck.ServiceCheckStatus(status),
+		readString(m, hostnamePtr, hostnameLen),
+		readTags(m, tagsPtr, tagsLen),
+		readString(m, messagePtr, messageLen),
+	)
+}
+
+func (h *host) submitEvent(_ context.Context, m api.Module, eventPtr, eventLen uint32) {
+	sender, err := h.check.GetSender()
+	if err != nil {
+		log.Errorf("Error submitting event to the Sender: %v", err)
+		return
+	}
+
+	var e event.Event
+	if err := json.Unmarshal(readBytes(m, eventPtr, eventLen), &e); err != nil {
+		log.Debugf("wasm check %s: ignoring invalid event: %v", h.check.ID(), err)
+		return
+	}
+	sender.Event(e)
+}
+
+func (h *host) warning(_ context.Context, m api.Module, messagePtr, messageLen uint32) {
+	h.check.Warn(readString(m, messagePtr, messageLen)) //nolint:errcheck
+}
+
+func (h *host) setError(_ context.Context, m api.Module, messagePtr, messageLen uint32) {
+	h.check.setRunError(readString(m, messagePtr, messageLen))
+}
+
+func (h *host) log(_ context.Context, m api.Module, level, messagePtr, messageLen uint32) {
+	message := readString(m, messagePtr, messageLen)
+	switch level {
+	case logLevelDebug:
+		log.Debugf("wasm check %s: %s", h.check.ID(), message)
+	case logLevelInfo:
+		log.Infof("wasm check %s: %s", h.check.ID(), message)
+	case logLevelWarn:
+		log.Warnf("wasm check %s: %s", h.check.ID(), message)
+	default:
+		log.Errorf("wasm check %s: %s", h.check.ID(), message)
+	}
+}
+
+// readBytes returns a copy of a slice of the module memory, or nil if it is out of range
+func readBytes(m api.Module, ptr, length uint32) []byte {
+	if length == 0 {
+		return nil
+	}
+	b, ok := m.Memory().Read(ptr, length)
+	if !ok {
+		log.Debugf("wasm module %s: read out of memory range (%d, %d)", m.Name(), ptr, length)
+		return nil
+	}
+	return append([]byte{}, b...)
+}
+
+func readString(m api.Module, ptr, length uint32) string {
+	return string(readBytes(m, ptr, length))
+}
+
+func readTags(m api.Module, ptr, length uint32) []string {
+	raw := readString(m, ptr, length)
+	if raw == "" {
+		return nil
+	}
+	return strings.Split(raw, "\n")
+}
+
+// writeBuffer copies data into the module memory, truncated to the buffer
+// length, and returns the size of data.
+func writeBuffer(m api.Module, bufPtr, bufLen uint32, data []byte) uint32 {
+	n := uint32(len(data))
+	if n > bufLen {
+		n = bufLen
+	}
+	if n > 0 && !m.Memory().Write(bufPtr, data[:n]) {
+		log.Debugf("wasm module %s: write out of memory range (%d, %d)", m.Name(), bufPtr, n)
+	}
+	return uint32(len(data))
+}
@@ -0,0 +1,141 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+// Package wasm implements a loader running checks compiled to WebAssembly in a
+// sandbox: the modules can only submit data through the host API mirroring
+// `sender.Sender` and have no access to the host filesystem, network or environment.
+package wasm
+
+import (
+	"expvar"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/tetratelabs/wazero"
+
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+	"github.com/DataDog/datadog-agent/pkg/collector/check"
+	"github.com/DataDog/datadog-agent/pkg/collector/loaders"
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+const moduleExtension = ".wasm"
+
+var (
+	wasmLoaderStats *expvar.Map
+	configureErrors map[string][]string
+	timeouts        map[string]int
+	statsLock       sync.RWMutex
+)
+
+func init() {
+	factory := func() (check.Loader, error) {
+		return NewWasmCheckLoader()
+	}
+	loaders.RegisterLoader(40, factory)
+
+	configureErrors = map[string][]string{}
+	timeouts = map[string]int{}
+	wasmLoaderStats = expvar.NewMap("wasmLoader")
+	wasmLoaderStats.Set("ConfigureErrors", expvar.Func(expvarConfigureErrors))
+	wasmLoaderStats.Set("Timeouts", expvar.Func(expvarTimeouts))
+}
+
+// WasmCheckLoader is a specific loader for checks compiled to WebAssembly
+type WasmCheckLoader struct {
+	// cache shares the compiled modules between the runtimes of the check instances
+	cache wazero.CompilationCache
+}
+
+// NewWasmCheckLoader creates an instance of the WASM checks loader
+func NewWasmCheckLoader() (*WasmCheckLoader, error) {
+	return &WasmCheckLoader{cache: wazero.NewCompilationCache()}, nil
+}
+
+// Name returns WASM loader name
+func (wl *WasmCheckLoader) Name() string {
+	return "wasm"
+}
+
+// Load looks for a `<check name>.wasm` module in the `additional_checksd`
+// directory and returns the corresponding Check
+func (wl *WasmCheckLoader) Load(config integration.Config, instance integration.Data) (check.Check, error) {
+	modulePath, err := findModule(config.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	code, err := os.ReadFile(modulePath)
+	if err != nil {
+		return nil, fmt.Errorf("could not read wasm module %s: %v", modulePath, err)
+	}
+
+	c := newWasmCheck(config.Name, code, wl.cache)
+	if err := c.Configure(config.FastDigest(), instance, config.InitConfig, config.Source); err != nil {
+		addExpvarConfigureError(config.Name, err.Error())
+		return c, fmt.Errorf("could not configure check instance for wasm check %s: %s", config.Name, err)
+	}
+
+	log.Debugf("wasm loader: done loading check %s from %s", config.Name, modulePath)
+	return c, nil
+}
+
+func (wl *WasmCheckLoader) String() string {
+	return "WASM Check Loader"
+}
+
+func findModule(name string) (string, error) {
+	dir := config.Datadog.GetString("additional_checksd")
+	if dir == "" {
+		return "", fmt.Errorf("no wasm module found for check %s: additional_checksd is not set", name)
+	}
+
+	modulePath := filepath.Join(dir, name+moduleExtension)
+	if _, err := os.Stat(modulePath); err != nil {
+		return "", fmt.Errorf("no wasm module found for check %s: %v", name, err)
+	}
+	return modulePath, nil
+}
+
+func expvarConfigureErrors() interface{} {
+	statsLock.RLock()
+	defer statsLock.RUnlock()
+
+	configureErrorsCopy := map[string][]string{}
+	for k, v := range configureErrors {
+		configureErrorsCopy[k] = append([]string{}, v...)
+	}
+	return configureErrorsCopy
+}
+
+func addExpvarConfigureError(check string, errMsg string) {
+	log.Errorf("wasm.loader: could not configure check '%s': %s", check, errMsg)
+
+	statsLock.Lock()
+	defer statsLock.Unlock()
+
+	configureErrors[check] = append(configureErrors[check], errMsg)
+}
+
+func expvarTimeouts() interface{} {
+	statsLock.RLock()
+	defer statsLock.RUnlock()
+
+	timeoutsCopy := make(map[string]int, len(timeouts))
+	for k, v := range timeouts {
+		timeoutsCopy[k] = v
+	}
+	return timeoutsCopy
+}
+
+func addExpvarTimeout(check string) {
+	statsLock.Lock()
+	defer statsLock.Unlock()
+
+	timeouts[check]++
+}
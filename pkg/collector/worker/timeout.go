@@ -0,0 +1,114 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package worker
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/collector/check"
+	"github.com/DataDog/datadog-agent/pkg/collector/runner/expvars"
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/telemetry"
+)
+
+var (
+	// stuckRunGracePeriod is how long an interrupted run is waited for before
+	// the worker gives up on it
+	stuckRunGracePeriod = 1 * time.Second
+
+	tlmCheckTimeouts = telemetry.NewCounter("runner", "check_timeouts",
+		[]string{"check_name"}, "Count of check runs interrupted because they exceeded their timeout")
+	tlmStuckChecks = telemetry.NewGauge("runner", "stuck_checks",
+		[]string{"check_name"}, "Number of check runs still running after being interrupted")
+)
+
+// timeoutError is the error of the runs exceeding their timeout
+type timeoutError struct {
+	timeout time.Duration
+}
+
+func (e *timeoutError) Error() string {
+	return fmt.Sprintf("check run exceeded the timeout of %s and was interrupted", e.timeout)
+}
+
+// runTimeout returns the timeout of the runs of a check: the one configured by
+// its instance, or the global `check_timeout`. Long-running checks have none.
+func runTimeout(c check.Check) time.Duration {
+	if c.Interval() == 0 {
+		return 0
+	}
+	if tc, ok := c.(check.TimeoutCheck); ok && tc.RunTimeout() > 0 {
+		return tc.RunTimeout()
+	}
+	return time.Duration(config.Datadog.GetInt("check_timeout")) * time.Second
+}
+
+// runCheck runs a check, interrupting it if it exceeds the timeout: the context
+// of checks implementing check.ContextCheck is cancelled, the checks implementing
+// check.InterruptibleCheck are interrupted and the others are stopped.
+//
+// If the run doesn't return within stuckRunGracePeriod once interrupted, runCheck
+// returns without waiting for it, along with a channel closed when it returns.
+func runCheck(c check.Check, timeout time.Duration) (<-chan struct{}, error) {
+	if timeout <= 0 {
+		return nil, run(context.Background(), c)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	done := make(chan struct{})
+	var err error
+	go func() {
+		defer close(done)
+		defer cancel()
+		err = run(ctx, c)
+	}()
+
+	select {
+	case <-done:
+		return nil, err
+	case <-ctx.Done():
+		if ctx.Err() != context.DeadlineExceeded {
+			// the run returned
+			<-done
+			return nil, err
+		}
+	}
+
+	expvars.AddTimeoutsCount(1)
+	tlmCheckTimeouts.Inc(c.String())
+	timeoutErr := &timeoutError{timeout: timeout}
+
+	// interrupting the run may block, like the run itself
+	if ic, ok := c.(check.InterruptibleCheck); ok {
+		go ic.Interrupt()
+	} else {
+		go c.Stop()
+	}
+
+	select {
+	case <-done:
+		return nil, timeoutErr
+	case <-time.After(stuckRunGracePeriod):
+	}
+
+	tlmStuckChecks.Inc(c.String())
+	stuck := make(chan struct{})
+	go func() {
+		<-done
+		tlmStuckChecks.Dec(c.String())
+		close(stuck)
+	}()
+	return stuck, timeoutErr
+}
+
+func run(ctx context.Context, c check.Check) error {
+	if cc, ok := c.(check.ContextCheck); ok {
+		return cc.RunContext(ctx)
+	}
+	return c.Run()
+}
@@ -0,0 +1,199 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package worker
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/atomic"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator/mocksender"
+	"github.com/DataDog/datadog-agent/pkg/aggregator/sender"
+	"github.com/DataDog/datadog-agent/pkg/collector/check"
+	checkid "github.com/DataDog/datadog-agent/pkg/collector/check/id"
+	"github.com/DataDog/datadog-agent/pkg/collector/runner/expvars"
+	"github.com/DataDog/datadog-agent/pkg/collector/runner/tracker"
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/metrics/servicecheck"
+)
+
+// contextCheck returns when the context of its run is cancelled
+type contextCheck struct {
+	*testCheck
+	timeout time.Duration
+}
+
+func (c *contextCheck) RunTimeout() time.Duration { return c.timeout }
+
+func (c *contextCheck) RunContext(ctx context.Context) error {
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+// stuckCheck ignores the cancellation of its runs, and returns once released
+type stuckCheck struct {
+	*testCheck
+	timeout time.Duration
+	release chan struct{}
+	stopped *atomic.Bool
+}
+
+func (c *stuckCheck) RunTimeout() time.Duration { return c.timeout }
+
+func (c *stuckCheck) Run() error {
+	<-c.release
+	return nil
+}
+
+func (c *stuckCheck) Stop() { c.stopped.Store(true) }
+
+// interruptibleCheck returns once interrupted
+type interruptibleCheck struct {
+	*testCheck
+	timeout     time.Duration
+	interrupted chan struct{}
+	stopped     *atomic.Bool
+}
+
+func (c *interruptibleCheck) RunTimeout() time.Duration { return c.timeout }
+
+func (c *interruptibleCheck) Run() error {
+	<-c.interrupted
+	return nil
+}
+
+func (c *interruptibleCheck) Interrupt() { close(c.interrupted) }
+
+func (c *interruptibleCheck) Stop() { c.stopped.Store(true) }
+
+func TestRunTimeout(t *testing.T) {
+	defer config.Datadog.Set("check_timeout", 0)
+
+	c := &contextCheck{testCheck: newCheck(t, "check:123", false, nil)}
+	assert.Equal(t, time.Duration(0), runTimeout(c))
+
+	config.Datadog.Set("check_timeout", 30)
+	assert.Equal(t, 30*time.Second, runTimeout(c))
+
+	c.timeout = 10 * time.Second
+	assert.Equal(t, 10*time.Second, runTimeout(c))
+
+	longRunning := &contextCheck{testCheck: newCheck(t, "check:456", false, nil), timeout: time.Second}
+	longRunning.longRunning = true
+	assert.Equal(t, time.Duration(0), runTimeout(longRunning))
+}
+
+func TestWorkerTimeout(t *testing.T) {
+	expvars.Reset()
+	config.Datadog.Set("hostname", "myhost")
+	config.Datadog.Set("integration_check_status_enabled", "true")
+	defer config.Datadog.Set("integration_check_status_enabled", nil)
+
+	checksTracker := tracker.NewRunningChecksTracker()
+	pendingChecksChan := make(chan check.Check, 10)
+	mockShouldAddStatsFunc := func(id checkid.ID) bool { return true }
+
+	c := &contextCheck{testCheck: newCheck(t, "timeout_check:123", false, nil), timeout: 50 * time.Millisecond}
+	pendingChecksChan <- c
+	close(pendingChecksChan)
+
+	mockSender := mocksender.NewMockSender("")
+	mockSender.On("Commit").Return().Times(1)
+	mockSender.On(
+		"ServiceCheck",
+		serviceCheckStatusKey,
+		servicecheck.ServiceCheckCritical,
+		"myhost",
+		[]string{"check:timeout_check", "dd_enable_check_intake:true"},
+		"check run exceeded the timeout of 50ms and was interrupted",
+	).Return().Times(1)
+
+	worker, err := newWorkerWithOptions(
+		100,
+		200,
+		pendingChecksChan,
+		checksTracker,
+		mockShouldAddStatsFunc,
+		func() (sender.Sender, error) {
+			return mockSender, nil
+		},
+		pollingInterval,
+	)
+	require.Nil(t, err)
+
+	worker.Run()
+
+	mockSender.AssertExpectations(t)
+	assertErrorCount(t, c, 1)
+	assert.Equal(t, 1, int(expvars.GetTimeoutsCount()))
+	assert.Equal(t, 0, int(expvars.GetRunningCheckCount()))
+	assert.Equal(t, 0, len(checksTracker.RunningChecks()))
+}
+
+func TestRunCheckInterrupt(t *testing.T) {
+	c := &interruptibleCheck{
+		testCheck:   newCheck(t, "interruptible_check:123", false, nil),
+		timeout:     50 * time.Millisecond,
+		interrupted: make(chan struct{}),
+		stopped:     atomic.NewBool(false),
+	}
+
+	// the run is interrupted, the check isn't stopped as if it was unscheduled
+	stuck, err := runCheck(c, c.timeout)
+	assert.Nil(t, stuck)
+	assert.IsType(t, &timeoutError{}, err)
+	assert.False(t, c.stopped.Load())
+}
+
+func TestWorkerStuckCheck(t *testing.T) {
+	expvars.Reset()
+	config.Datadog.Set("hostname", "myhost")
+
+	defer func(d time.Duration) { stuckRunGracePeriod = d }(stuckRunGracePeriod)
+	stuckRunGracePeriod = 10 * time.Millisecond
+
+	checksTracker := tracker.NewRunningChecksTracker()
+	pendingChecksChan := make(chan check.Check, 10)
+	mockShouldAddStatsFunc := func(id checkid.ID) bool { return true }
+
+	stuck := &stuckCheck{
+		testCheck: newCheck(t, "stuck_check:123", false, nil),
+		timeout:   50 * time.Millisecond,
+		release:   make(chan struct{}),
+		stopped:   atomic.NewBool(false),
+	}
+	goodCheck := newCheck(t, "goodcheck:123", false, nil)
+
+	pendingChecksChan <- stuck
+	pendingChecksChan <- goodCheck
+	close(pendingChecksChan)
+
+	worker, err := NewWorker(100, 200, pendingChecksChan, checksTracker, mockShouldAddStatsFunc)
+	require.Nil(t, err)
+
+	// the worker runs the next check while the stuck one still runs
+	worker.Run()
+
+	assert.Equal(t, 1, goodCheck.RunCount())
+	assert.True(t, stuck.stopped.Load())
+	assertErrorCount(t, stuck, 1)
+	assert.Equal(t, 1, int(expvars.GetTimeoutsCount()))
+	assert.Equal(t, 1, int(expvars.GetRunningCheckCount()))
+	_, running := checksTracker.Check(stuck.ID())
+	assert.True(t, running)
+
+	// the check is removed from the running checks once its run returns
+	close(stuck.release)
+	assert.Eventually(t, func() bool {
+		_, running := checksTracker.Check(stuck.ID())
+		return !running
+	}, time.Second, 10*time.Millisecond)
+	assert.Equal(t, 0, int(expvars.GetRunningCheckCount()))
+}
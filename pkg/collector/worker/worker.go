@@ -137,12 +137,13 @@ func (w *Worker) Run() {
 		utilizationTracker.CheckStarted()
 
 		// Run the check
-		var checkErr error
-		checkErr = check.Run()
+		stuck, checkErr := runCheck(check, runTimeout(check))
 
 		utilizationTracker.CheckFinished()
 
-		expvars.DeleteRunningStats(check.ID())
+		if stuck == nil {
+			expvars.DeleteRunningStats(check.ID())
+		}
 
 		checkWarnings := check.GetWarnings()
 
@@ -167,9 +168,15 @@ func (w *Worker) Run() {
 			serviceCheckStatus = servicecheck.ServiceCheckCritical
 		}
 
+		// Only timeouts are reported in the message, the other errors are in the check status
+		serviceCheckMessage := ""
+		if _, ok := checkErr.(*timeoutError); ok {
+			serviceCheckMessage = checkErr.Error()
+		}
+
 		if sender != nil && !longRunning {
 			if config.Datadog.GetBool("integration_check_status_enabled") {
-				sender.ServiceCheck(serviceCheckStatusKey, serviceCheckStatus, hname, serviceCheckTags, "")
+				sender.ServiceCheck(serviceCheckStatusKey, serviceCheckStatus, hname, serviceCheckTags, serviceCheckMessage)
 			}
 			// FIXME(remy): this `Commit()` should be part of the `if` above, we keep
 			// it here for now to make sure it's not breaking any historical behavior
@@ -177,11 +184,18 @@ func (w *Worker) Run() {
 			sender.Commit()
 		}
 
-		// Remove the check from the running list
-		w.checksTracker.DeleteCheck(check.ID())
+		if stuck == nil {
+			// Remove the check from the running list
+			w.checksTracker.DeleteCheck(check.ID())
+			expvars.AddRunningCheckCount(-1)
+		} else {
+			// The check stays in the running list until its run returns, so that
+			// it isn't run again meanwhile, but no longer occupies the worker.
+			log.Warnf("Check %s did not return once interrupted, releasing its worker", check.ID())
+			go w.waitStuckCheck(check, stuck)
+		}
 
 		// Publish statistics about this run
-		expvars.AddRunningCheckCount(-1)
 		expvars.AddRunsCount(1)
 
 		if !longRunning || len(checkWarnings) != 0 || checkErr != nil {
@@ -199,6 +213,16 @@ func (w *Worker) Run() {
 	log.Debugf("Runner %d, worker %d: Finished processing checks.", w.runnerID, w.ID)
 }
 
+// waitStuckCheck removes a check from the running list once its stuck run returns
+func (w *Worker) waitStuckCheck(c check.Check, stuck <-chan struct{}) {
+	<-stuck
+	log.Infof("Check %s: stuck run returned", c.ID())
+
+	expvars.DeleteRunningStats(c.ID())
+	w.checksTracker.DeleteCheck(c.ID())
+	expvars.AddRunningCheckCount(-1)
+}
+
 func startExpvarUpdater(name string, ut *UtilizationTracker) {
 	expvars.SetWorkerStats(name, &expvars.WorkerStats{
 		Utilization: 0.0,
@@ -269,6 +269,9 @@ func InitConfig(config Config) {
 	config.BindEnvAndSetDefault("enable_metadata_collection", true)
 	config.BindEnvAndSetDefault("enable_gohai", true)
 	config.BindEnvAndSetDefault("check_runners", int64(4))
+	config.BindEnvAndSetDefault("check_timeout", 0) // value in seconds, 0 disables it
+	config.BindEnvAndSetDefault("check_scheduling_spread", false)
+	config.BindEnvAndSetDefault("check_scheduling_jitter", 0) // value in milliseconds
 	config.BindEnvAndSetDefault("auth_token_file_path", "")
 	config.BindEnv("bind_host")
 	config.BindEnvAndSetDefault("ipc_address", "localhost")
@@ -349,6 +352,11 @@ func InitConfig(config Config) {
 	// library support will not work reliably in those environments)
 	config.BindEnvAndSetDefault("allow_python_path_heuristics_failure", false)
 
+	// WASM checks: default limits of each check instance, overridden by the
+	// `wasm_memory_limit_mb` and `wasm_run_timeout` instance options.
+	config.BindEnvAndSetDefault("wasm_check_memory_limit_mb", 64)
+	config.BindEnvAndSetDefault("wasm_check_run_timeout", 15)
+
 	// if/when the default is changed to true, make the default platform
 	// dependent; default should remain false on Windows to maintain backward
 	// compatibility with Agent5 behavior/win
@@ -449,6 +457,9 @@ func InitConfig(config Config) {
 	config.BindEnvAndSetDefault("enable_events_stream_payload_serialization", true)
 	config.BindEnvAndSetDefault("enable_sketch_stream_payload_serialization", true)
 	config.BindEnvAndSetDefault("enable_json_stream_shared_compressor_buffers", true)
+	// Compression of the series, sketches and service checks payloads: "zlib" or "zstd"
+	config.BindEnvAndSetDefault("serializer_compressor_kind", "zlib")
+	config.BindEnvAndSetDefault("serializer_zstd_compressor_level", 1)
 
 	// Warning: do not change the following values. Your payloads will get dropped by Datadog's intake.
 	config.BindEnvAndSetDefault("serializer_max_payload_size", 2*megaByte+megaByte/2)
@@ -473,6 +484,15 @@ func InitConfig(config Config) {
 	config.BindEnvAndSetDefault("forwarder_connection_reset_interval", 0)                                // in seconds, 0 means disabled
 	config.BindEnvAndSetDefault("forwarder_apikey_validation_interval", DefaultAPIKeyValidationInterval) // in minutes
 	config.BindEnvAndSetDefault("forwarder_num_workers", 1)
+	config.BindEnv("forwarder_routing_rules")
+	config.SetEnvKeyTransformer("forwarder_routing_rules", func(in string) interface{} {
+		var rules []map[string]interface{}
+		if err := json.Unmarshal([]byte(in), &rules); err != nil {
+			log.Errorf(`"forwarder_routing_rules" can not be parsed: %v`, err)
+		}
+		return rules
+	})
+	config.BindEnvAndSetDefault("forwarder_routing_default_domains", []string{})
 	config.BindEnvAndSetDefault("forwarder_stop_timeout", 2)
 	// Forwarder retry settings
 	config.BindEnvAndSetDefault("forwarder_backoff_factor", 2)
@@ -488,6 +508,14 @@ func InitConfig(config Config) {
 	config.BindEnvAndSetDefault("forwarder_storage_max_size_in_bytes", 0)                // 0 means disabled. This is a BETA feature.
 	config.BindEnvAndSetDefault("forwarder_storage_max_disk_ratio", 0.80)                // Do not store transactions on disk when the disk usage exceeds 80% of the disk capacity. Use 80% as some applications do not behave well when the disk space is very small.
 	config.BindEnvAndSetDefault("forwarder_retry_queue_capacity_time_interval_sec", 900) // 15 mins
+	config.BindEnv("forwarder_retry_queue_budgets")
+	config.SetEnvKeyTransformer("forwarder_retry_queue_budgets", func(in string) interface{} {
+		var budgets map[string]interface{}
+		if err := json.Unmarshal([]byte(in), &budgets); err != nil {
+			log.Errorf(`"forwarder_retry_queue_budgets" can not be parsed: %v`, err)
+		}
+		return budgets
+	})
 
 	// Forwarder channels buffer size
 	config.BindEnvAndSetDefault("forwarder_high_prio_buffer_size", 100)
@@ -580,6 +608,15 @@ func InitConfig(config Config) {
 		return mappings
 	})
 
+	config.BindEnv("metric_rollups")
+	config.SetEnvKeyTransformer("metric_rollups", func(in string) interface{} {
+		var rollups []map[string]interface{}
+		if err := json.Unmarshal([]byte(in), &rollups); err != nil {
+			log.Errorf(`"metric_rollups" can not be parsed: %v`, err)
+		}
+		return rollups
+	})
+
 	config.BindEnvAndSetDefault("statsd_forward_host", "")
 	config.BindEnvAndSetDefault("statsd_forward_port", 0)
 	config.BindEnvAndSetDefault("statsd_metric_namespace", "")
@@ -591,6 +628,7 @@ func InitConfig(config Config) {
 	config.BindEnvAndSetDefault("autoconf_template_dir", "/datadog/check_configs")
 	config.BindEnvAndSetDefault("autoconf_config_files_poll", false)
 	config.BindEnvAndSetDefault("autoconf_config_files_poll_interval", 60)
+	config.BindEnvAndSetDefault("autoconf_config_files_watch", false)
 	config.BindEnvAndSetDefault("exclude_pause_container", true)
 	config.BindEnvAndSetDefault("ac_include", []string{})
 	config.BindEnvAndSetDefault("ac_exclude", []string{})
@@ -667,6 +705,8 @@ func InitConfig(config Config) {
 	config.BindEnvAndSetDefault("kubernetes_map_services_on_ip", false) // temporary opt-out of the new mapping logic
 	config.BindEnvAndSetDefault("kubernetes_apiserver_use_protobuf", false)
 	config.BindEnvAndSetDefault("kubernetes_ad_tags_disabled", []string{})
+	config.BindEnvAndSetDefault("kube_configmaps_provider.label_selector", "ad.datadoghq.com/checks=true")
+	config.BindEnvAndSetDefault("kube_configmaps_provider.namespace", "") // empty to watch the agent namespace, "*" to watch all the namespaces
 
 	config.BindEnvAndSetDefault("prometheus_scrape.enabled", false)           // Enables the prometheus config provider
 	config.BindEnvAndSetDefault("prometheus_scrape.service_endpoints", false) // Enables Service Endpoints checks in the prometheus config provider
@@ -697,6 +737,10 @@ func InitConfig(config Config) {
 	config.BindEnvAndSetDefault("network_devices.snmp_traps.bind_host", "0.0.0.0")
 	config.BindEnvAndSetDefault("network_devices.snmp_traps.stop_timeout", 5) // in seconds
 	config.SetKnown("network_devices.snmp_traps.users")
+	config.BindEnvAndSetDefault("network_devices.snmp_traps.deduplication.enabled", false)
+	config.BindEnvAndSetDefault("network_devices.snmp_traps.deduplication.window", 60) // in seconds
+	config.SetKnown("network_devices.snmp_traps.metrics")
+	config.SetKnown("network_devices.snmp_traps.relays")
 
 	// NetFlow
 	config.SetKnown("network_devices.netflow.listeners")
@@ -706,6 +750,8 @@ func InitConfig(config Config) {
 	config.SetKnown("network_devices.netflow.aggregator_flow_context_ttl")
 	config.SetKnown("network_devices.netflow.aggregator_port_rollup_threshold")
 	config.SetKnown("network_devices.netflow.aggregator_rollup_tracker_refresh_interval")
+	config.SetKnown("network_devices.netflow.aggregator_top_n")
+	config.SetKnown("network_devices.netflow.enrichment")
 	config.BindEnvAndSetDefault("network_devices.netflow.enabled", "false")
 	bindEnvAndSetLogsConfigKeys(config, "network_devices.netflow.forwarder.")
 
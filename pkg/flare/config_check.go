@@ -6,14 +6,18 @@
 package flare
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
+	"sort"
+	"time"
 
 	"github.com/fatih/color"
 
 	"github.com/DataDog/datadog-agent/cmd/agent/api/response"
 	"github.com/DataDog/datadog-agent/pkg/api/util"
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/configresolver"
 	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
 	checkid "github.com/DataDog/datadog-agent/pkg/collector/check/id"
 	"github.com/DataDog/datadog-agent/pkg/config"
@@ -29,30 +33,7 @@ func GetConfigCheck(w io.Writer, withDebug bool) error {
 		color.NoColor = true
 	}
 
-	c := util.GetClient(false) // FIX: get certificates right then make this true
-
-	// Set session token
-	err := util.SetAuthToken()
-	if err != nil {
-		return err
-	}
-	ipcAddress, err := config.GetIPCAddress()
-	if err != nil {
-		return err
-	}
-	if configCheckURL == "" {
-		configCheckURL = fmt.Sprintf("https://%v:%v/agent/config-check", ipcAddress, config.Datadog.GetInt("cmd_port"))
-	}
-	r, err := util.DoGet(c, configCheckURL, util.LeaveConnectionOpen)
-	if err != nil {
-		if r != nil && string(r) != "" {
-			return fmt.Errorf("the agent ran into an error while checking config: %s", string(r))
-		}
-		return fmt.Errorf("failed to query the agent (running?): %s", err)
-	}
-
-	cr := response.ConfigCheckResponse{}
-	err = json.Unmarshal(r, &cr)
+	cr, err := getConfigCheckResponse()
 	if err != nil {
 		return err
 	}
@@ -64,6 +45,8 @@ func GetConfigCheck(w io.Writer, withDebug bool) error {
 		}
 	}
 
+	printTemplateVariableErrors(w, cr.Unresolved)
+
 	for _, c := range cr.Configs {
 		PrintConfig(w, c, "")
 	}
@@ -93,12 +76,178 @@ func GetConfigCheck(w io.Writer, withDebug bool) error {
 	return nil
 }
 
+// WatchConfigCheck dumps all loaded configurations to the writer, then the
+// configurations scheduled and unscheduled by the agent and the configuration
+// errors found, polled at the given interval until the context is done
+func WatchConfigCheck(ctx context.Context, w io.Writer, interval time.Duration) error {
+	if w != color.Output {
+		color.NoColor = true
+	}
+
+	previous, err := getConfigCheckResponse()
+	if err != nil {
+		return err
+	}
+	for _, c := range previous.Configs {
+		PrintConfig(w, c, "")
+	}
+	fmt.Fprintln(w, fmt.Sprintf("\nWatching the configurations every %s, press Ctrl+C to stop", interval))
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+
+		current, err := getConfigCheckResponse()
+		if err != nil {
+			// the agent may be restarting, keep watching
+			fmt.Fprintln(w, fmt.Sprintf("\n%s", color.RedString(err.Error())))
+			continue
+		}
+		printConfigCheckChanges(w, time.Now(), previous, current)
+		previous = current
+	}
+}
+
+// printConfigCheckChanges prints the configurations and the configuration
+// errors that differ between two responses
+func printConfigCheckChanges(w io.Writer, now time.Time, previous, current response.ConfigCheckResponse) {
+	previousConfigs := make(map[string]integration.Config, len(previous.Configs))
+	for _, c := range previous.Configs {
+		previousConfigs[c.Digest()] = c
+	}
+	currentConfigs := make(map[string]integration.Config, len(current.Configs))
+	for _, c := range current.Configs {
+		currentConfigs[c.Digest()] = c
+	}
+
+	var unscheduled, scheduled []integration.Config
+	for _, c := range previous.Configs {
+		if _, found := currentConfigs[c.Digest()]; !found {
+			unscheduled = append(unscheduled, c)
+		}
+	}
+	for _, c := range current.Configs {
+		if _, found := previousConfigs[c.Digest()]; !found {
+			scheduled = append(scheduled, c)
+		}
+	}
+
+	var newErrors, fixedErrors []string
+	for check, e := range current.ConfigErrors {
+		if previous.ConfigErrors[check] != e {
+			newErrors = append(newErrors, check)
+		}
+	}
+	for check := range previous.ConfigErrors {
+		if _, found := current.ConfigErrors[check]; !found {
+			fixedErrors = append(fixedErrors, check)
+		}
+	}
+	sort.Strings(newErrors)
+	sort.Strings(fixedErrors)
+
+	if len(unscheduled) == 0 && len(scheduled) == 0 && len(newErrors) == 0 && len(fixedErrors) == 0 {
+		return
+	}
+
+	fmt.Fprintln(w, fmt.Sprintf("\n--- %s ---", now.Format(time.RFC3339)))
+	for _, check := range newErrors {
+		fmt.Fprintln(w, fmt.Sprintf("\n%s: %s", color.RedString(check), current.ConfigErrors[check]))
+	}
+	for _, check := range fixedErrors {
+		fmt.Fprintln(w, fmt.Sprintf("\n%s: configuration error fixed", color.GreenString(check)))
+	}
+	for _, c := range unscheduled {
+		printUnscheduledConfig(w, c)
+	}
+	for _, c := range scheduled {
+		PrintConfig(w, c, "")
+	}
+}
+
+// printUnscheduledConfig prints the source and the instance IDs of a
+// configuration that is not scheduled anymore
+func printUnscheduledConfig(w io.Writer, c integration.Config) {
+	configDigest := c.FastDigest()
+	fmt.Fprintln(w, fmt.Sprintf("\n=== %s check %s ===", color.GreenString(c.Name), color.RedString("unscheduled")))
+	if c.Source != "" {
+		fmt.Fprintln(w, fmt.Sprintf("%s: %s", color.BlueString("Configuration source"), color.CyanString(c.Source)))
+	}
+	for _, inst := range c.Instances {
+		ID := string(checkid.BuildID(c.Name, configDigest, inst, c.InitConfig))
+		fmt.Fprintln(w, fmt.Sprintf("%s: %s", color.BlueString("Instance ID"), color.CyanString(ID)))
+	}
+	fmt.Fprintln(w, "===")
+}
+
+// printTemplateVariableErrors prints the template variables of the templates
+// that cannot be resolved whatever the service they are matched against
+func printTemplateVariableErrors(w io.Writer, templates map[string][]integration.Config) {
+	ids := make([]string, 0, len(templates))
+	for id := range templates {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	headerPrinted := false
+	for _, id := range ids {
+		for _, tpl := range templates[id] {
+			errs := configresolver.ValidateTemplateVariables(tpl)
+			if len(errs) == 0 {
+				continue
+			}
+			if !headerPrinted {
+				fmt.Fprintln(w, fmt.Sprintf("=== Template variable %s ===", color.RedString("errors")))
+				headerPrinted = true
+			}
+			fmt.Fprintln(w, fmt.Sprintf("\n%s (%s: %s)", color.RedString(tpl.Name), color.BlueString("Auto-discovery IDs"), color.YellowString(id)))
+			for _, err := range errs {
+				fmt.Fprintln(w, fmt.Sprintf("* %s", err))
+			}
+		}
+	}
+}
+
 // GetClusterAgentConfigCheck proxies GetConfigCheck overidding the URL
 func GetClusterAgentConfigCheck(w io.Writer, withDebug bool) error {
 	configCheckURL = fmt.Sprintf("https://localhost:%v/config-check", config.Datadog.GetInt("cluster_agent.cmd_port"))
 	return GetConfigCheck(w, withDebug)
 }
 
+// getConfigCheckResponse queries the loaded configurations of the running agent
+func getConfigCheckResponse() (response.ConfigCheckResponse, error) {
+	cr := response.ConfigCheckResponse{}
+	c := util.GetClient(false) // FIX: get certificates right then make this true
+
+	// Set session token
+	err := util.SetAuthToken()
+	if err != nil {
+		return cr, err
+	}
+	ipcAddress, err := config.GetIPCAddress()
+	if err != nil {
+		return cr, err
+	}
+	if configCheckURL == "" {
+		configCheckURL = fmt.Sprintf("https://%v:%v/agent/config-check", ipcAddress, config.Datadog.GetInt("cmd_port"))
+	}
+	r, err := util.DoGet(c, configCheckURL, util.LeaveConnectionOpen)
+	if err != nil {
+		if r != nil && string(r) != "" {
+			return cr, fmt.Errorf("the agent ran into an error while checking config: %s", string(r))
+		}
+		return cr, fmt.Errorf("failed to query the agent (running?): %s", err)
+	}
+
+	err = json.Unmarshal(r, &cr)
+	return cr, err
+}
+
 func printYaml(w io.Writer, data []byte) {
 	scrubbed, err := scrubber.ScrubYaml(data)
 	if err == nil {
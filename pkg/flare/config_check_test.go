@@ -9,9 +9,11 @@ import (
 	"bytes"
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 
+	"github.com/DataDog/datadog-agent/cmd/agent/api/response"
 	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
 )
 
@@ -97,3 +99,63 @@ func newConfig(configType configType, excluded bool) integration.Config {
 
 	return config
 }
+
+func TestPrintTemplateVariableErrors(t *testing.T) {
+	templates := map[string][]integration.Config{
+		"redis": {
+			{
+				Name:          "redisdb",
+				ADIdentifiers: []string{"redis"},
+				Instances:     []integration.Data{integration.Data("host: %%host%%\nimage: %%container_image_digest%%")},
+			},
+		},
+		"nginx": {
+			{
+				Name:          "nginx",
+				ADIdentifiers: []string{"nginx"},
+				Instances:     []integration.Data{integration.Data("app: %%kube_pod_label_app%%")},
+			},
+		},
+	}
+
+	var result bytes.Buffer
+	printTemplateVariableErrors(&result, templates)
+	assert.Contains(t, result.String(), "=== Template variable errors ===")
+	assert.Contains(t, result.String(), "redisdb (Auto-discovery IDs: redis)")
+	assert.Contains(t, result.String(), `* invalid %%container_image_digest%% tag: unsupported container variable "image_digest"`)
+	assert.NotContains(t, result.String(), "nginx")
+
+	result.Reset()
+	printTemplateVariableErrors(&result, map[string][]integration.Config{"nginx": templates["nginx"]})
+	assert.Empty(t, result.String())
+}
+
+func TestPrintConfigCheckChanges(t *testing.T) {
+	foo := integration.Config{Name: "foo", Source: "file:/etc/datadog-agent/conf.d/foo.yaml", Instances: []integration.Data{integration.Data("a: 1")}}
+	bar := integration.Config{Name: "bar", Source: "file:/etc/datadog-agent/conf.d/bar.yaml", Instances: []integration.Data{integration.Data("b: 1")}}
+	baz := integration.Config{Name: "baz", Source: "file:/etc/datadog-agent/conf.d/baz.yaml", Instances: []integration.Data{integration.Data("c: 1")}}
+	now := time.Date(2023, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	previous := response.ConfigCheckResponse{
+		Configs:      []integration.Config{foo, bar},
+		ConfigErrors: map[string]string{"qux": "yaml: line 1: did not find expected key"},
+	}
+
+	var b bytes.Buffer
+	printConfigCheckChanges(&b, now, previous, previous)
+	assert.Empty(t, b.String())
+
+	current := response.ConfigCheckResponse{
+		Configs:      []integration.Config{foo, baz},
+		ConfigErrors: map[string]string{"quux": "Configuration file contains no valid instances"},
+	}
+	printConfigCheckChanges(&b, now, previous, current)
+
+	output := b.String()
+	assert.Contains(t, output, "--- 2023-06-01T12:00:00Z ---")
+	assert.Contains(t, output, "quux: Configuration file contains no valid instances")
+	assert.Contains(t, output, "qux: configuration error fixed")
+	assert.Contains(t, output, "=== bar check unscheduled ===\nConfiguration source: file:/etc/datadog-agent/conf.d/bar.yaml\nInstance ID: bar:")
+	assert.Contains(t, output, "=== baz check ===")
+	assert.NotContains(t, output, "=== foo check")
+}
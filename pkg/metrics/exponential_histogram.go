@@ -0,0 +1,268 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package metrics
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/DataDog/opentelemetry-mapping-go/pkg/quantile"
+)
+
+const (
+	// MinExponentialHistogramScale is the lowest scale supported for exponential histograms
+	MinExponentialHistogramScale = -9
+	// MaxExponentialHistogramScale is the highest scale supported for exponential histograms
+	MaxExponentialHistogramScale = 20
+)
+
+// The bins of the sketches, with their default 1/128 relative accuracy: the
+// bin m covers the [gamma^(m-0.5), gamma^(m+0.5)) range, the values below the
+// bin of sketchMinValue are counted in the zero bin and the highest bin is
+// sketchMaxBins above it.
+const (
+	sketchGamma    = 1 + 2.0/128
+	sketchMinValue = 1e-9
+	sketchMaxBins  = math.MaxInt16 - 2
+)
+
+var (
+	sketchLogGamma = math.Log1p(sketchGamma - 1)
+	sketchMinBin   = math.Floor(math.Log(sketchMinValue) / sketchLogGamma)
+	sketchMaxBin   = sketchMinBin + sketchMaxBins
+)
+
+// ExponentialBuckets holds a contiguous range of buckets of an exponential histogram.
+// The bucket at position i in Counts has index Offset+i.
+type ExponentialBuckets struct {
+	Offset int32
+	Counts []uint64
+}
+
+func (b ExponentialBuckets) count() uint64 {
+	var c uint64
+	for _, n := range b.Counts {
+		c += n
+	}
+	return c
+}
+
+// downscale returns the buckets merged into a scale that is `by` levels coarser.
+// At each level, two adjacent buckets are merged into one.
+func (b ExponentialBuckets) downscale(by int32) ExponentialBuckets {
+	if by == 0 || len(b.Counts) == 0 {
+		return b
+	}
+
+	first := b.Offset >> by
+	last := (b.Offset + int32(len(b.Counts)) - 1) >> by
+	counts := make([]uint64, last-first+1)
+	for i, n := range b.Counts {
+		counts[((b.Offset+int32(i))>>by)-first] += n
+	}
+	return ExponentialBuckets{Offset: first, Counts: counts}
+}
+
+// sub returns b minus prev, bucket by bucket. Both must use the same scale.
+// It returns false if any bucket count decreased.
+func (b ExponentialBuckets) sub(prev ExponentialBuckets) (ExponentialBuckets, bool) {
+	counts := make([]uint64, len(b.Counts))
+	copy(counts, b.Counts)
+
+	for i, n := range prev.Counts {
+		if n == 0 {
+			continue
+		}
+		idx := int(prev.Offset) + i - int(b.Offset)
+		if idx < 0 || idx >= len(counts) || counts[idx] < n {
+			return ExponentialBuckets{}, false
+		}
+		counts[idx] -= n
+	}
+	return ExponentialBuckets{Offset: b.Offset, Counts: counts}, true
+}
+
+// addToBins spreads the count of each bucket over the sketch bins it overlaps,
+// in proportion of the overlap on a logarithmic scale. The counts falling below
+// the lowest bin of the sketches are added to zeroCount.
+func (b ExponentialBuckets) addToBins(scale int32, bins map[float64]float64, zeroCount *float64) {
+	logBase := math.Ldexp(math.Ln2, int(-scale))
+	for i, n := range b.Counts {
+		if n == 0 {
+			continue
+		}
+		// the bucket covers the (base^index, base^(index+1)] range
+		index := float64(int(b.Offset) + i)
+		low, high := index*logBase/sketchLogGamma, (index+1)*logBase/sketchLogGamma
+		width := high - low
+
+		if high <= sketchMinBin {
+			*zeroCount += float64(n)
+			continue
+		}
+		if low < sketchMinBin {
+			*zeroCount += float64(n) * (sketchMinBin - low) / width
+			low = sketchMinBin
+		}
+		if low >= sketchMaxBin {
+			bins[sketchMaxBin] += float64(n)
+			continue
+		}
+		if high > sketchMaxBin {
+			bins[sketchMaxBin] += float64(n) * (high - sketchMaxBin) / width
+			high = sketchMaxBin
+		}
+
+		for bin := math.Floor(low + 0.5); bin-0.5 < high; bin++ {
+			overlap := math.Min(high, bin+0.5) - math.Max(low, bin-0.5)
+			if overlap > 0 {
+				bins[bin] += float64(n) * overlap / width
+			}
+		}
+	}
+}
+
+// insertBins inserts the counts of the bins into the sketch, rounded so that
+// their sum is kept. The values are inserted at the center of their bin.
+func insertBins(agent *quantile.Agent, bins map[float64]float64, sign float64) {
+	keys := make([]float64, 0, len(bins))
+	for bin := range bins {
+		keys = append(keys, bin)
+	}
+	sort.Float64s(keys)
+
+	var total float64
+	var inserted uint64
+	for _, bin := range keys {
+		total += bins[bin]
+		count := uint64(math.Round(total)) - inserted
+		if count == 0 {
+			continue
+		}
+		value := sign * math.Exp(bin*sketchLogGamma)
+		agent.InsertInterpolate(value, value, uint(count))
+		inserted += count
+	}
+}
+
+// ExponentialHistogram represents a base-2 exponential bucket histogram, as
+// used by OpenTelemetry exponential histograms and Prometheus native histograms.
+//
+// The bucket of index i covers the (base^i, base^(i+1)] range, where
+// base = 2^(2^-Scale). Values whose absolute value falls below the lowest
+// bucket are counted in ZeroCount.
+type ExponentialHistogram struct {
+	Scale     int32
+	ZeroCount uint64
+	Positive  ExponentialBuckets
+	Negative  ExponentialBuckets
+	Sum       float64
+}
+
+// Count returns the total number of values in the histogram
+func (h *ExponentialHistogram) Count() uint64 {
+	return h.ZeroCount + h.Positive.count() + h.Negative.count()
+}
+
+// Validate returns an error if the histogram can't be converted into a sketch
+func (h *ExponentialHistogram) Validate() error {
+	if h.Scale < MinExponentialHistogramScale || h.Scale > MaxExponentialHistogramScale {
+		return fmt.Errorf("scale %d is out of the supported [%d, %d] range", h.Scale, MinExponentialHistogramScale, MaxExponentialHistogramScale)
+	}
+	if math.IsInf(h.Sum, 0) || math.IsNaN(h.Sum) {
+		return fmt.Errorf("invalid sum %f", h.Sum)
+	}
+	return nil
+}
+
+// Downscale returns a copy of the histogram using the given, coarser, scale
+func (h *ExponentialHistogram) Downscale(scale int32) *ExponentialHistogram {
+	if scale >= h.Scale {
+		return h
+	}
+	return &ExponentialHistogram{
+		Scale:     scale,
+		ZeroCount: h.ZeroCount,
+		Positive:  h.Positive.downscale(h.Scale - scale),
+		Negative:  h.Negative.downscale(h.Scale - scale),
+		Sum:       h.Sum,
+	}
+}
+
+// Sub returns the histogram of values observed since prev, for histograms
+// holding cumulative counts. Both histograms are brought to the coarser of their
+// two scales before being subtracted. It returns false if any count
+// decreased, which means the underlying counters were reset.
+func (h *ExponentialHistogram) Sub(prev *ExponentialHistogram) (*ExponentialHistogram, bool) {
+	scale := h.Scale
+	if prev.Scale < scale {
+		scale = prev.Scale
+	}
+	cur, prev := h.Downscale(scale), prev.Downscale(scale)
+
+	if cur.ZeroCount < prev.ZeroCount {
+		return nil, false
+	}
+	positive, ok := cur.Positive.sub(prev.Positive)
+	if !ok {
+		return nil, false
+	}
+	negative, ok := cur.Negative.sub(prev.Negative)
+	if !ok {
+		return nil, false
+	}
+
+	return &ExponentialHistogram{
+		Scale:     scale,
+		ZeroCount: cur.ZeroCount - prev.ZeroCount,
+		Positive:  positive,
+		Negative:  negative,
+		Sum:       cur.Sum - prev.Sum,
+	}, true
+}
+
+// ToSketch converts the histogram into a sketch. The buckets are mapped
+// directly onto the sketch bins they overlap, their count being spread over
+// the bins on a logarithmic scale, so the buckets finer than the bins are
+// kept with the sketch's own relative accuracy.
+func (h *ExponentialHistogram) ToSketch() (*quantile.Sketch, error) {
+	if err := h.Validate(); err != nil {
+		return nil, err
+	}
+
+	count := h.Count()
+	if count == 0 {
+		return nil, errors.New("exponential histogram is empty")
+	}
+
+	zeroCount := float64(h.ZeroCount)
+	positive := make(map[float64]float64)
+	h.Positive.addToBins(h.Scale, positive, &zeroCount)
+	negative := make(map[float64]float64)
+	h.Negative.addToBins(h.Scale, negative, &zeroCount)
+
+	var agent quantile.Agent
+	insertBins(&agent, negative, -1)
+	if zeros := uint64(math.Round(zeroCount)); zeros > 0 {
+		agent.InsertInterpolate(0, 0, uint(zeros))
+	}
+	insertBins(&agent, positive, 1)
+
+	sketch := agent.Finish()
+	if sketch == nil {
+		return nil, errors.New("exponential histogram is empty")
+	}
+
+	// The sketch summary is estimated from the bins, override it with the exact
+	// values we know.
+	sketch.Basic.Cnt = int64(count)
+	sketch.Basic.Sum = h.Sum
+	sketch.Basic.Avg = h.Sum / float64(count)
+
+	return sketch, nil
+}
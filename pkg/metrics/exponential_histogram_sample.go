@@ -0,0 +1,54 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package metrics
+
+import "github.com/DataDog/datadog-agent/pkg/tagset"
+
+// ExponentialHistogramSample represents an exponential histogram submitted by a check
+type ExponentialHistogramSample struct {
+	Name            string
+	Histogram       *ExponentialHistogram
+	Monotonic       bool
+	Tags            []string
+	Host            string
+	Timestamp       float64
+	FlushFirstValue bool
+	Source          MetricSource
+}
+
+// Implement the MetricSampleContext interface
+
+// GetName returns the histogram name
+func (m *ExponentialHistogramSample) GetName() string {
+	return m.Name
+}
+
+// GetHost returns the histogram host
+func (m *ExponentialHistogramSample) GetHost() string {
+	return m.Host
+}
+
+// GetTags returns the histogram tags.
+func (m *ExponentialHistogramSample) GetTags(taggerBuffer, metricBuffer tagset.TagsAccumulator) {
+	// Like HistogramBucket, exponential histograms only come from checks so
+	// there is no origin detection to run.
+	metricBuffer.Append(m.Tags...)
+}
+
+// GetMetricType implements MetricSampleContext#GetMetricType.
+func (m *ExponentialHistogramSample) GetMetricType() MetricType {
+	return DistributionType
+}
+
+// IsNoIndex returns if the metric must not be indexed.
+func (m *ExponentialHistogramSample) IsNoIndex() bool {
+	return false
+}
+
+// GetSource returns the currently set MetricSource
+func (m *ExponentialHistogramSample) GetSource() MetricSource {
+	return m.Source
+}
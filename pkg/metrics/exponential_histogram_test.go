@@ -0,0 +1,150 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package metrics
+
+import (
+	"math"
+	"testing"
+
+	"github.com/DataDog/opentelemetry-mapping-go/pkg/quantile"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExponentialHistogramToSketch(t *testing.T) {
+	// scale 0: bucket i covers (2^i, 2^(i+1)]
+	h := &ExponentialHistogram{
+		Scale:     0,
+		ZeroCount: 2,
+		Positive:  ExponentialBuckets{Offset: 1, Counts: []uint64{10, 0, 5}},
+		Negative:  ExponentialBuckets{Offset: 0, Counts: []uint64{3}},
+		Sum:       42,
+	}
+	require.NoError(t, h.Validate())
+	assert.EqualValues(t, 20, h.Count())
+
+	sketch, err := h.ToSketch()
+	require.NoError(t, err)
+
+	assert.EqualValues(t, 20, sketch.Basic.Cnt)
+	assert.EqualValues(t, 42, sketch.Basic.Sum)
+	assert.InDelta(t, 2.1, sketch.Basic.Avg, 1e-9)
+
+	// the sketch has a ~1% relative accuracy
+	within := func(v, low, high float64) bool {
+		return v >= low*0.99 && v <= high*1.01
+	}
+	c := quantile.Default()
+	// 3 negative values in [-2, -1), 2 zeros, 10 values in (2, 4] and 5 in (8, 16]
+	assert.Less(t, sketch.Quantile(c, 0.1), 0.0)
+	assert.Equal(t, 0.0, sketch.Quantile(c, 0.2))
+	median := sketch.Quantile(c, 0.5)
+	assert.True(t, within(median, 2, 4), "unexpected median %f", median)
+	p99 := sketch.Quantile(c, 0.99)
+	assert.True(t, within(p99, 8, 16), "unexpected p99 %f", p99)
+}
+
+// exponentialHistogramQuantile returns the quantile of a positive histogram,
+// the values being spread uniformly on a logarithmic scale in each bucket.
+func exponentialHistogramQuantile(h *ExponentialHistogram, q float64) float64 {
+	rank := q * float64(h.Count()-1)
+	logBase := math.Ldexp(math.Ln2, int(-h.Scale))
+	var seen float64
+	for i, n := range h.Positive.Counts {
+		if n == 0 {
+			continue
+		}
+		if seen+float64(n) > rank {
+			index := float64(int(h.Positive.Offset) + i)
+			return math.Exp((index + (rank-seen+0.5)/float64(n)) * logBase)
+		}
+		seen += float64(n)
+	}
+	return math.NaN()
+}
+
+func TestExponentialHistogramToSketchAccuracy(t *testing.T) {
+	for _, scale := range []int32{-2, 0, 3, 6, 8, 12} {
+		// buckets covering the (2^-8, 2^24] range, with about the same count
+		// for each power of two whatever the scale
+		perPowerOfTwo := math.Ldexp(1, int(scale))
+		counts := make([]uint64, int(32*perPowerOfTwo))
+		for i := range counts {
+			counts[i] = uint64(math.Ceil(float64(4000+(i*37)%2000) / perPowerOfTwo))
+		}
+		h := &ExponentialHistogram{
+			Scale:    scale,
+			Positive: ExponentialBuckets{Offset: int32(-8 * perPowerOfTwo), Counts: counts},
+			Sum:      1,
+		}
+
+		sketch, err := h.ToSketch()
+		require.NoError(t, err)
+		assert.EqualValues(t, h.Count(), sketch.Basic.Cnt)
+
+		c := quantile.Default()
+		for _, q := range []float64{0.01, 0.1, 0.25, 0.5, 0.75, 0.9, 0.99} {
+			expected := exponentialHistogramQuantile(h, q)
+			actual := sketch.Quantile(c, q)
+			// the relative accuracy of the sketch bins, the source histogram
+			// being finer or coarser
+			assert.InEpsilon(t, expected, actual, 0.01, "scale %d, quantile %g", scale, q)
+		}
+	}
+}
+
+func TestExponentialHistogramToSketchErrors(t *testing.T) {
+	_, err := (&ExponentialHistogram{}).ToSketch()
+	assert.Error(t, err)
+
+	_, err = (&ExponentialHistogram{Scale: 21, ZeroCount: 1}).ToSketch()
+	assert.Error(t, err)
+
+	_, err = (&ExponentialHistogram{ZeroCount: 1, Sum: math.NaN()}).ToSketch()
+	assert.Error(t, err)
+}
+
+func TestExponentialHistogramDownscale(t *testing.T) {
+	h := &ExponentialHistogram{
+		Scale:    2,
+		Positive: ExponentialBuckets{Offset: -3, Counts: []uint64{1, 2, 3, 4, 5, 6}},
+		Sum:      10,
+	}
+
+	d := h.Downscale(1)
+	assert.EqualValues(t, 1, d.Scale)
+	// indexes -3..2 map to -2, -1, -1, 0, 0, 1
+	assert.Equal(t, ExponentialBuckets{Offset: -2, Counts: []uint64{1, 5, 9, 6}}, d.Positive)
+	assert.Equal(t, h.Count(), d.Count())
+
+	assert.Same(t, h, h.Downscale(3))
+}
+
+func TestExponentialHistogramSub(t *testing.T) {
+	prev := &ExponentialHistogram{
+		Scale:     1,
+		ZeroCount: 1,
+		Positive:  ExponentialBuckets{Offset: 0, Counts: []uint64{1, 1}},
+		Sum:       5,
+	}
+	cur := &ExponentialHistogram{
+		Scale:     2,
+		ZeroCount: 3,
+		Positive:  ExponentialBuckets{Offset: 0, Counts: []uint64{2, 1, 4}},
+		Sum:       20,
+	}
+
+	delta, ok := cur.Sub(prev)
+	require.True(t, ok)
+	assert.EqualValues(t, 1, delta.Scale)
+	assert.EqualValues(t, 2, delta.ZeroCount)
+	assert.Equal(t, ExponentialBuckets{Offset: 0, Counts: []uint64{2, 3}}, delta.Positive)
+	assert.EqualValues(t, 15, delta.Sum)
+
+	// counters were reset
+	_, ok = prev.Sub(cur)
+	assert.False(t, ok)
+}
@@ -100,6 +100,9 @@ type MetricSample struct {
 	Cardinality      string
 	NoIndex          bool
 	Source           MetricSource
+	// ExponentialHistogram holds the buckets of a DistributionType sample
+	// submitted as a whole histogram rather than as a single Value.
+	ExponentialHistogram *ExponentialHistogram
 }
 
 // Implement the MetricSampleContext interface
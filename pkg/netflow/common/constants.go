@@ -21,9 +21,27 @@ const (
 	// DefaultAggregatorRollupTrackerRefreshInterval is the default aggregator rollup tracker refresh interval
 	DefaultAggregatorRollupTrackerRefreshInterval = 300 // 5min
 
+	// DefaultAggregatorTopN is the default number of top talkers and applications reported as metrics
+	DefaultAggregatorTopN = 10
+
+	// MaxMappedFields is the maximum number of custom fields a listener can map
+	MaxMappedFields = 5
+
 	// DefaultBindHost is the default bind host used for flow listeners
 	DefaultBindHost = "0.0.0.0"
 
 	// DefaultPrometheusListenerAddress is the default goflow prometheus listener address
 	DefaultPrometheusListenerAddress = "localhost:9090"
+
+	// DefaultReverseDNSCacheSize is the default maximum number of hostnames cached by the reverse DNS enrichment
+	DefaultReverseDNSCacheSize = 10000
+
+	// DefaultReverseDNSCacheTTL is the default duration in seconds the hostnames are cached by the reverse DNS enrichment
+	DefaultReverseDNSCacheTTL = 3600 // 1h
+
+	// DefaultReverseDNSTimeout is the default timeout in milliseconds of a reverse DNS lookup
+	DefaultReverseDNSTimeout = 2000
+
+	// DefaultReverseDNSWorkers is the default number of concurrent reverse DNS lookups
+	DefaultReverseDNSWorkers = 4
 )
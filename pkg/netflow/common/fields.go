@@ -0,0 +1,77 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2022-present Datadog, Inc.
+
+package common
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash"
+)
+
+// FieldType is the type a custom NetFlow v9/IPFIX field is decoded as
+type FieldType string
+
+// Field Types
+const (
+	FieldTypeString  FieldType = "string"
+	FieldTypeInteger FieldType = "integer"
+	FieldTypeIP      FieldType = "ip"
+	FieldTypeMac     FieldType = "mac"
+	FieldTypeHex     FieldType = "hex"
+)
+
+// IsValid returns whether the field type is supported
+func (t FieldType) IsValid() bool {
+	switch t {
+	case FieldTypeString, FieldTypeInteger, FieldTypeIP, FieldTypeMac, FieldTypeHex:
+		return true
+	}
+	return false
+}
+
+// AdditionalField contains the value of a custom NetFlow v9/IPFIX field
+// mapped by the listener configuration
+type AdditionalField struct {
+	Name           string
+	Value          interface{} // string, or uint64 for the integer fields
+	Tag            bool        // also reported as a `name:value` tag
+	AggregationKey bool        // flows with different values are not aggregated together
+}
+
+func (f AdditionalField) writeHash(h hash.Hash64) {
+	h.Write([]byte(f.Name)) //nolint:errcheck
+	switch value := f.Value.(type) {
+	case uint64:
+		binary.Write(h, binary.LittleEndian, value) //nolint:errcheck
+	default:
+		h.Write([]byte(fmt.Sprint(value))) //nolint:errcheck
+	}
+}
+
+// isEqualAggregationKeys check if the additional fields used as aggregation keys are equal
+func isEqualAggregationKeys(a []AdditionalField, b []AdditionalField) bool {
+	aKeys := aggregationKeys(a)
+	bKeys := aggregationKeys(b)
+	if len(aKeys) != len(bKeys) {
+		return false
+	}
+	for i := range aKeys {
+		if aKeys[i].Name != bKeys[i].Name || aKeys[i].Value != bKeys[i].Value {
+			return false
+		}
+	}
+	return true
+}
+
+func aggregationKeys(fields []AdditionalField) []AdditionalField {
+	var keys []AdditionalField
+	for _, field := range fields {
+		if field.AggregationKey {
+			keys = append(keys, field)
+		}
+	}
+	return keys
+}
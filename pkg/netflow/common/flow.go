@@ -65,6 +65,9 @@ type Flow struct {
 	Tos uint32 // FLOW KEY
 
 	NextHop []byte // FLOW KEY
+
+	// Custom fields mapped by the listener, FLOW KEY for the ones configured as aggregation keys
+	AdditionalFields []AdditionalField
 }
 
 // AggregationHash return a hash used as aggregation key
@@ -79,6 +82,11 @@ func (f *Flow) AggregationHash() uint64 {
 	binary.Write(h, binary.LittleEndian, f.IPProtocol)     //nolint:errcheck
 	binary.Write(h, binary.LittleEndian, f.Tos)            //nolint:errcheck
 	binary.Write(h, binary.LittleEndian, f.InputInterface) //nolint:errcheck
+	for _, field := range f.AdditionalFields {
+		if field.AggregationKey {
+			field.writeHash(h)
+		}
+	}
 	return h.Sum64()
 }
 
@@ -93,7 +101,8 @@ func IsEqualFlowContext(a Flow, b Flow) bool {
 		a.DstPort == b.DstPort &&
 		a.IPProtocol == b.IPProtocol &&
 		a.Tos == b.Tos &&
-		a.InputInterface == b.InputInterface {
+		a.InputInterface == b.InputInterface &&
+		isEqualAggregationKeys(a.AdditionalFields, b.AdditionalFields) {
 		return true
 	}
 	return false
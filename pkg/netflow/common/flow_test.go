@@ -85,8 +85,26 @@ func TestFlow_AggregationHash(t *testing.T) {
 	assert.Equal(t, origHash, flow.AggregationHash())
 	allHash[flow.AggregationHash()] = true
 
+	flow = origFlow
+	flow.AdditionalFields = []AdditionalField{{Name: "application", Value: "ssl", AggregationKey: true}}
+	assert.NotEqual(t, origHash, flow.AggregationHash())
+	allHash[flow.AggregationHash()] = true
+
+	flow.AdditionalFields = []AdditionalField{{Name: "application", Value: "dns", AggregationKey: true}}
+	assert.NotEqual(t, origHash, flow.AggregationHash())
+	allHash[flow.AggregationHash()] = true
+
+	flow.AdditionalFields = []AdditionalField{{Name: "user_id", Value: uint64(1234), AggregationKey: true}}
+	assert.NotEqual(t, origHash, flow.AggregationHash())
+	allHash[flow.AggregationHash()] = true
+
+	// additional fields are not key fields unless configured as aggregation keys
+	flow.AdditionalFields = []AdditionalField{{Name: "nat_source_ip", Value: "1.1.1.1"}}
+	assert.Equal(t, origHash, flow.AggregationHash())
+	allHash[flow.AggregationHash()] = true
+
 	// Should contain expected number of different hashes
-	assert.Equal(t, 10, len(allHash))
+	assert.Equal(t, 13, len(allHash))
 }
 
 func TestFlow_IsEqualFlowContext(t *testing.T) {
@@ -154,6 +172,15 @@ func TestFlow_IsEqualFlowContext(t *testing.T) {
 	flow.Tos = 1
 	assert.False(t, IsEqualFlowContext(origFlow, flow))
 
+	flow = origFlow
+	flow.AdditionalFields = []AdditionalField{{Name: "application", Value: "ssl", AggregationKey: true}}
+	assert.False(t, IsEqualFlowContext(origFlow, flow))
+	otherFlow = flow
+	otherFlow.AdditionalFields = []AdditionalField{{Name: "application", Value: "dns", AggregationKey: true}}
+	assert.False(t, IsEqualFlowContext(flow, otherFlow))
+	otherFlow.AdditionalFields = []AdditionalField{{Name: "application", Value: "ssl", AggregationKey: true}, {Name: "nat_source_ip", Value: "1.1.1.1"}}
+	assert.True(t, IsEqualFlowContext(flow, otherFlow))
+
 	flow = origFlow
 	flow.Bytes = 999
 	assert.True(t, IsEqualFlowContext(origFlow, flow))
@@ -24,12 +24,32 @@ type NetflowConfig struct {
 	AggregatorFlowContextTTL      int              `mapstructure:"aggregator_flow_context_ttl"`
 	AggregatorPortRollupThreshold int              `mapstructure:"aggregator_port_rollup_threshold"`
 	AggregatorPortRollupDisabled  bool             `mapstructure:"aggregator_port_rollup_disabled"`
+	AggregatorTopN                int              `mapstructure:"aggregator_top_n"` // top talkers and applications reported as metrics, -1 to disable
 
 	// AggregatorRollupTrackerRefreshInterval is useful to speed up testing to avoid wait for 1h default
 	AggregatorRollupTrackerRefreshInterval uint `mapstructure:"aggregator_rollup_tracker_refresh_interval"`
 
 	PrometheusListenerAddress string `mapstructure:"prometheus_listener_address"` // Example `localhost:9090`
 	PrometheusListenerEnabled bool   `mapstructure:"prometheus_listener_enabled"`
+
+	Enrichment EnrichmentConfig `mapstructure:"enrichment"`
+}
+
+// EnrichmentConfig contains configuration for the enrichment of the flows
+// with the autonomous systems, the locations and the hostnames of the endpoints
+type EnrichmentConfig struct {
+	ASNDatabasePath   string           `mapstructure:"asn_database_path"`   // MaxMind-format (MMDB) ASN database, like GeoLite2-ASN
+	GeoIPDatabasePath string           `mapstructure:"geoip_database_path"` // MaxMind-format (MMDB) City or Country database, like GeoLite2-City
+	ReverseDNS        ReverseDNSConfig `mapstructure:"reverse_dns"`
+}
+
+// ReverseDNSConfig contains configuration for the reverse DNS resolution of the endpoints
+type ReverseDNSConfig struct {
+	Enabled   bool `mapstructure:"enabled"`
+	CacheSize int  `mapstructure:"cache_size"`
+	CacheTTL  int  `mapstructure:"cache_ttl"` // in seconds
+	Timeout   int  `mapstructure:"timeout"`   // in milliseconds
+	Workers   int  `mapstructure:"workers"`
 }
 
 // ListenerConfig contains configuration for a single flow listener
@@ -39,6 +59,18 @@ type ListenerConfig struct {
 	BindHost  string          `mapstructure:"bind_host"`
 	Workers   int             `mapstructure:"workers"`
 	Namespace string          `mapstructure:"namespace"`
+	Mapping   []Mapping       `mapstructure:"mapping"`
+}
+
+// Mapping contains configuration for a custom NetFlow v9/IPFIX field, like the
+// vendor specific ones, mapped into the flows
+type Mapping struct {
+	Field          uint16           `mapstructure:"field"`      // Information Element ID
+	Enterprise     uint32           `mapstructure:"enterprise"` // Private Enterprise Number, 0 for the standard fields
+	Type           common.FieldType `mapstructure:"type"`
+	Destination    string           `mapstructure:"destination"` // name of the field in the flows
+	Tag            bool             `mapstructure:"tag"`
+	AggregationKey bool             `mapstructure:"aggregation_key"`
 }
 
 // ReadConfig builds and returns configuration from Agent configuration.
@@ -77,6 +109,10 @@ func ReadConfig() (*NetflowConfig, error) {
 			return nil, fmt.Errorf("invalid namespace `%s` error: %s", listenerConfig.Namespace, err)
 		}
 		listenerConfig.Namespace = normalizedNamespace
+
+		if err := validateMapping(listenerConfig.FlowType, listenerConfig.Mapping); err != nil {
+			return nil, err
+		}
 	}
 
 	if mainConfig.StopTimeout == 0 {
@@ -96,6 +132,9 @@ func ReadConfig() (*NetflowConfig, error) {
 	if mainConfig.AggregatorPortRollupThreshold == 0 {
 		mainConfig.AggregatorPortRollupThreshold = common.DefaultAggregatorPortRollupThreshold
 	}
+	if mainConfig.AggregatorTopN == 0 {
+		mainConfig.AggregatorTopN = common.DefaultAggregatorTopN
+	}
 	if mainConfig.AggregatorRollupTrackerRefreshInterval == 0 {
 		mainConfig.AggregatorRollupTrackerRefreshInterval = common.DefaultAggregatorRollupTrackerRefreshInterval
 	}
@@ -104,6 +143,26 @@ func ReadConfig() (*NetflowConfig, error) {
 		mainConfig.PrometheusListenerAddress = common.DefaultPrometheusListenerAddress
 	}
 
+	reverseDNS := &mainConfig.Enrichment.ReverseDNS
+	if reverseDNS.CacheSize == 0 {
+		reverseDNS.CacheSize = common.DefaultReverseDNSCacheSize
+	}
+	if reverseDNS.CacheTTL == 0 {
+		reverseDNS.CacheTTL = common.DefaultReverseDNSCacheTTL
+	}
+	if reverseDNS.Timeout == 0 {
+		reverseDNS.Timeout = common.DefaultReverseDNSTimeout
+	}
+	if reverseDNS.Workers == 0 {
+		reverseDNS.Workers = common.DefaultReverseDNSWorkers
+	}
+	if reverseDNS.CacheSize < 0 {
+		return nil, fmt.Errorf("the reverse DNS cache size must be positive, got %d", reverseDNS.CacheSize)
+	}
+	if reverseDNS.Workers < 0 {
+		return nil, fmt.Errorf("the number of reverse DNS workers must be positive, got %d", reverseDNS.Workers)
+	}
+
 	return &mainConfig, nil
 }
 
@@ -111,3 +170,31 @@ func ReadConfig() (*NetflowConfig, error) {
 func (c *ListenerConfig) Addr() string {
 	return fmt.Sprintf("%s:%d", c.BindHost, c.Port)
 }
+
+func validateMapping(flowType common.FlowType, mapping []Mapping) error {
+	if len(mapping) == 0 {
+		return nil
+	}
+	if flowType != common.TypeNetFlow9 && flowType != common.TypeIPFIX {
+		return fmt.Errorf("custom field mapping is only supported for %s and %s flows, not `%s`", common.TypeNetFlow9, common.TypeIPFIX, flowType)
+	}
+	if len(mapping) > common.MaxMappedFields {
+		return fmt.Errorf("at most %d custom fields can be mapped, got %d", common.MaxMappedFields, len(mapping))
+	}
+	destinations := make(map[string]bool)
+	for _, field := range mapping {
+		if field.Destination == "" {
+			return fmt.Errorf("the destination of the custom field %d (enterprise %d) is missing", field.Field, field.Enterprise)
+		}
+		if destinations[field.Destination] {
+			return fmt.Errorf("the destination `%s` is used by several custom fields", field.Destination)
+		}
+		destinations[field.Destination] = true
+
+		if !field.Type.IsValid() {
+			return fmt.Errorf("the type `%s` of the custom field `%s` is not valid (valid types: %s, %s, %s, %s, %s)", field.Type, field.Destination,
+				common.FieldTypeString, common.FieldTypeInteger, common.FieldTypeIP, common.FieldTypeMac, common.FieldTypeHex)
+		}
+	}
+	return nil
+}
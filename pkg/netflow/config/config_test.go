@@ -37,8 +37,16 @@ network_devices:
     aggregator_rollup_tracker_refresh_interval: 60
     log_payloads: true
     aggregator_port_rollup_disabled: true
+    aggregator_top_n: 5
     prometheus_listener_enabled: true
     prometheus_listener_address: 127.0.0.1:9099
+    enrichment:
+      asn_database_path: /opt/GeoLite2-ASN.mmdb
+      geoip_database_path: /opt/GeoLite2-City.mmdb
+      reverse_dns:
+        enabled: true
+        cache_size: 100
+        timeout: 500
     listeners:
       - flow_type: netflow9
         bind_host: 127.0.0.1
@@ -61,8 +69,20 @@ network_devices:
 				AggregatorPortRollupThreshold:          20,
 				AggregatorRollupTrackerRefreshInterval: 60,
 				AggregatorPortRollupDisabled:           true,
+				AggregatorTopN:                         5,
 				PrometheusListenerEnabled:              true,
 				PrometheusListenerAddress:              "127.0.0.1:9099",
+				Enrichment: EnrichmentConfig{
+					ASNDatabasePath:   "/opt/GeoLite2-ASN.mmdb",
+					GeoIPDatabasePath: "/opt/GeoLite2-City.mmdb",
+					ReverseDNS: ReverseDNSConfig{
+						Enabled:   true,
+						CacheSize: 100,
+						CacheTTL:  3600,
+						Timeout:   500,
+						Workers:   4,
+					},
+				},
 				Listeners: []ListenerConfig{
 					{
 						FlowType:  common.TypeNetFlow9,
@@ -96,8 +116,17 @@ network_devices:
 				AggregatorFlushInterval:                300,
 				AggregatorFlowContextTTL:               300,
 				AggregatorPortRollupThreshold:          10,
+				AggregatorTopN:                         10,
 				AggregatorRollupTrackerRefreshInterval: 300,
 				PrometheusListenerAddress:              "localhost:9090",
+				Enrichment: EnrichmentConfig{
+					ReverseDNS: ReverseDNSConfig{
+						CacheSize: 10000,
+						CacheTTL:  3600,
+						Timeout:   2000,
+						Workers:   4,
+					},
+				},
 				Listeners: []ListenerConfig{
 					{
 						FlowType:  common.TypeNetFlow9,
@@ -125,8 +154,17 @@ network_devices:
 				AggregatorFlushInterval:                50,
 				AggregatorFlowContextTTL:               50,
 				AggregatorPortRollupThreshold:          10,
+				AggregatorTopN:                         10,
 				AggregatorRollupTrackerRefreshInterval: 300,
 				PrometheusListenerAddress:              "localhost:9090",
+				Enrichment: EnrichmentConfig{
+					ReverseDNS: ReverseDNSConfig{
+						CacheSize: 10000,
+						CacheTTL:  3600,
+						Timeout:   2000,
+						Workers:   4,
+					},
+				},
 				Listeners: []ListenerConfig{
 					{
 						FlowType:  common.TypeNetFlow9,
@@ -138,6 +176,134 @@ network_devices:
 				},
 			},
 		},
+		{
+			name: "custom field mapping",
+			configYaml: `
+network_devices:
+  netflow:
+    enabled: true
+    listeners:
+      - flow_type: ipfix
+        mapping:
+          - field: 56701
+            enterprise: 25461
+            type: string
+            destination: application
+            tag: true
+            aggregation_key: true
+          - field: 225
+            type: ip
+            destination: nat_source_ip
+`,
+			expectedConfig: NetflowConfig{
+				StopTimeout:                            5,
+				AggregatorBufferSize:                   10000,
+				AggregatorFlushInterval:                300,
+				AggregatorFlowContextTTL:               300,
+				AggregatorPortRollupThreshold:          10,
+				AggregatorTopN:                         10,
+				AggregatorRollupTrackerRefreshInterval: 300,
+				PrometheusListenerAddress:              "localhost:9090",
+				Enrichment: EnrichmentConfig{
+					ReverseDNS: ReverseDNSConfig{
+						CacheSize: 10000,
+						CacheTTL:  3600,
+						Timeout:   2000,
+						Workers:   4,
+					},
+				},
+				Listeners: []ListenerConfig{
+					{
+						FlowType:  common.TypeIPFIX,
+						BindHost:  "0.0.0.0",
+						Port:      uint16(4739),
+						Workers:   1,
+						Namespace: "default",
+						Mapping: []Mapping{
+							{
+								Field:          56701,
+								Enterprise:     25461,
+								Type:           common.FieldTypeString,
+								Destination:    "application",
+								Tag:            true,
+								AggregationKey: true,
+							},
+							{
+								Field:       225,
+								Type:        common.FieldTypeIP,
+								Destination: "nat_source_ip",
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "custom field mapping not supported by the flow type",
+			configYaml: `
+network_devices:
+  netflow:
+    enabled: true
+    listeners:
+      - flow_type: sflow5
+        mapping:
+          - field: 225
+            type: ip
+            destination: nat_source_ip
+`,
+			expectedError: "custom field mapping is only supported for netflow9 and ipfix flows, not `sflow5`",
+		},
+		{
+			name: "custom field mapping with invalid type",
+			configYaml: `
+network_devices:
+  netflow:
+    enabled: true
+    listeners:
+      - flow_type: netflow9
+        mapping:
+          - field: 225
+            type: float
+            destination: nat_source_ip
+`,
+			expectedError: "the type `float` of the custom field `nat_source_ip` is not valid",
+		},
+		{
+			name: "custom field mapping with duplicated destination",
+			configYaml: `
+network_devices:
+  netflow:
+    enabled: true
+    listeners:
+      - flow_type: netflow9
+        mapping:
+          - field: 225
+            type: ip
+            destination: nat_ip
+          - field: 226
+            type: ip
+            destination: nat_ip
+`,
+			expectedError: "the destination `nat_ip` is used by several custom fields",
+		},
+		{
+			name: "too many custom fields",
+			configYaml: `
+network_devices:
+  netflow:
+    enabled: true
+    listeners:
+      - flow_type: netflow9
+        mapping:
+          - {field: 1001, type: integer, destination: field1}
+          - {field: 1002, type: integer, destination: field2}
+          - {field: 1003, type: integer, destination: field3}
+          - {field: 1004, type: integer, destination: field4}
+          - {field: 1005, type: ip, destination: field5}
+          - {field: 1006, type: string, destination: field6}
+`,
+			expectedError: "at most 5 custom fields can be mapped, got 6",
+		},
 		{
 			name: "invalid flow type",
 			configYaml: `
@@ -149,6 +315,36 @@ network_devices:
 `,
 			expectedError: "the provided flow type `invalidType` is not valid",
 		},
+		{
+			name: "negative reverse DNS cache size",
+			configYaml: `
+network_devices:
+  netflow:
+    enabled: true
+    enrichment:
+      reverse_dns:
+        enabled: true
+        cache_size: -1
+    listeners:
+      - flow_type: netflow9
+`,
+			expectedError: "the reverse DNS cache size must be positive, got -1",
+		},
+		{
+			name: "negative reverse DNS workers",
+			configYaml: `
+network_devices:
+  netflow:
+    enabled: true
+    enrichment:
+      reverse_dns:
+        enabled: true
+        workers: -2
+    listeners:
+      - flow_type: netflow9
+`,
+			expectedError: "the number of reverse DNS workers must be positive, got -2",
+		},
 		{
 			name: "invalid namespace with >100 chars",
 			configYaml: `
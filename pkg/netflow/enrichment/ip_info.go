@@ -0,0 +1,99 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2022-present Datadog, Inc.
+
+package enrichment
+
+import (
+	"net"
+
+	"github.com/oschwald/maxminddb-golang"
+)
+
+// IPInfo contains the autonomous system and the location of an IP address
+type IPInfo struct {
+	ASNumber       uint32
+	ASOrganization string
+	CountryISOCode string
+	City           string
+}
+
+// asnRecord holds the fields of the GeoLite2-ASN and GeoIP2-ISP databases used
+type asnRecord struct {
+	AutonomousSystemNumber       uint32 `maxminddb:"autonomous_system_number"`
+	AutonomousSystemOrganization string `maxminddb:"autonomous_system_organization"`
+}
+
+// geoRecord holds the fields of the GeoLite2/GeoIP2 City and Country databases used
+type geoRecord struct {
+	Country struct {
+		ISOCode string `maxminddb:"iso_code"`
+	} `maxminddb:"country"`
+	City struct {
+		Names map[string]string `maxminddb:"names"`
+	} `maxminddb:"city"`
+}
+
+// IPInfoReader looks up IP addresses in local MaxMind-format (MMDB) databases
+type IPInfoReader struct {
+	asnDB *maxminddb.Reader
+	geoDB *maxminddb.Reader
+}
+
+// NewIPInfoReader opens the ASN and GeoIP databases, any of them can be left empty
+func NewIPInfoReader(asnDatabasePath string, geoIPDatabasePath string) (*IPInfoReader, error) {
+	reader := &IPInfoReader{}
+	if asnDatabasePath != "" {
+		db, err := maxminddb.Open(asnDatabasePath)
+		if err != nil {
+			return nil, err
+		}
+		reader.asnDB = db
+	}
+	if geoIPDatabasePath != "" {
+		db, err := maxminddb.Open(geoIPDatabasePath)
+		if err != nil {
+			reader.Close()
+			return nil, err
+		}
+		reader.geoDB = db
+	}
+	return reader, nil
+}
+
+// Lookup returns the info found about an IP address, the zero value is
+// returned for the addresses missing from the databases like private ones
+func (r *IPInfoReader) Lookup(ipAddr []byte) IPInfo {
+	var info IPInfo
+	ip := net.IP(ipAddr)
+	if len(ip) != net.IPv4len && len(ip) != net.IPv6len {
+		return info
+	}
+
+	if r.asnDB != nil {
+		var record asnRecord
+		if err := r.asnDB.Lookup(ip, &record); err == nil {
+			info.ASNumber = record.AutonomousSystemNumber
+			info.ASOrganization = record.AutonomousSystemOrganization
+		}
+	}
+	if r.geoDB != nil {
+		var record geoRecord
+		if err := r.geoDB.Lookup(ip, &record); err == nil {
+			info.CountryISOCode = record.Country.ISOCode
+			info.City = record.City.Names["en"]
+		}
+	}
+	return info
+}
+
+// Close closes the databases
+func (r *IPInfoReader) Close() {
+	if r.asnDB != nil {
+		r.asnDB.Close()
+	}
+	if r.geoDB != nil {
+		r.geoDB.Close()
+	}
+}
@@ -0,0 +1,57 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2022-present Datadog, Inc.
+
+//go:build test
+
+package enrichment
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/netflow/testutil"
+)
+
+func TestIPInfoReader(t *testing.T) {
+	asnDB := testutil.WriteMMDB(t, "GeoLite2-ASN", map[string]map[string]interface{}{
+		"8.8.8.0/24": {"autonomous_system_number": uint32(15169), "autonomous_system_organization": "GOOGLE"},
+		"1.1.1.0/24": {"autonomous_system_number": uint32(13335), "autonomous_system_organization": "CLOUDFLARENET"},
+	})
+	geoDB := testutil.WriteMMDB(t, "GeoLite2-City", map[string]map[string]interface{}{
+		"8.8.0.0/16": {
+			"country": map[string]interface{}{"iso_code": "US"},
+			"city":    map[string]interface{}{"names": map[string]interface{}{"en": "Mountain View", "fr": "Mountain View"}},
+		},
+		"1.1.1.0/24": {"country": map[string]interface{}{"iso_code": "AU"}},
+	})
+
+	reader, err := NewIPInfoReader(asnDB, geoDB)
+	require.NoError(t, err)
+	defer reader.Close()
+
+	assert.Equal(t, IPInfo{ASNumber: 15169, ASOrganization: "GOOGLE", CountryISOCode: "US", City: "Mountain View"}, reader.Lookup(net.ParseIP("8.8.8.8")))
+	assert.Equal(t, IPInfo{ASNumber: 13335, ASOrganization: "CLOUDFLARENET", CountryISOCode: "AU"}, reader.Lookup(net.ParseIP("1.1.1.1").To4()))
+	assert.Equal(t, IPInfo{CountryISOCode: "US", City: "Mountain View"}, reader.Lookup(net.ParseIP("8.8.4.4")))
+	assert.Equal(t, IPInfo{}, reader.Lookup(net.ParseIP("10.0.0.1")))
+	assert.Equal(t, IPInfo{}, reader.Lookup(net.ParseIP("2001:db8::1")))
+	assert.Equal(t, IPInfo{}, reader.Lookup(nil))
+}
+
+func TestIPInfoReaderSingleDatabase(t *testing.T) {
+	asnDB := testutil.WriteMMDB(t, "GeoLite2-ASN", map[string]map[string]interface{}{
+		"8.8.8.0/24": {"autonomous_system_number": uint32(15169)},
+	})
+
+	reader, err := NewIPInfoReader(asnDB, "")
+	require.NoError(t, err)
+	defer reader.Close()
+	assert.Equal(t, IPInfo{ASNumber: 15169}, reader.Lookup(net.ParseIP("8.8.8.8")))
+
+	_, err = NewIPInfoReader(asnDB, "/does/not/exist.mmdb")
+	assert.Error(t, err)
+}
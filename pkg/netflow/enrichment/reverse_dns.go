@@ -0,0 +1,146 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2022-present Datadog, Inc.
+
+package enrichment
+
+import (
+	"context"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+type reverseDNSEntry struct {
+	hostname string
+	expiry   time.Time
+}
+
+// ReverseDNSCache resolves the hostnames of IP addresses asynchronously and
+// caches them. The cache is bounded: the addresses are not resolved anymore
+// when it is full, until its entries expire.
+type ReverseDNSCache struct {
+	mu      sync.Mutex
+	entries map[string]reverseDNSEntry
+	pending map[string]struct{}
+
+	size    int
+	ttl     time.Duration
+	timeout time.Duration
+	queue   chan string
+	stop    chan struct{}
+	wg      sync.WaitGroup
+
+	lookupAddr func(ctx context.Context, addr string) ([]string, error) // Allows to mock the resolver in tests
+	timeNow    func() time.Time
+}
+
+// NewReverseDNSCache returns a cache of up to `size` hostnames, resolved by
+// `workers` goroutines and kept for `ttl`, failed lookups included
+func NewReverseDNSCache(size int, ttl time.Duration, timeout time.Duration, workers int) *ReverseDNSCache {
+	return newReverseDNSCache(size, ttl, timeout, workers, net.DefaultResolver.LookupAddr)
+}
+
+func newReverseDNSCache(size int, ttl time.Duration, timeout time.Duration, workers int, lookupAddr func(ctx context.Context, addr string) ([]string, error)) *ReverseDNSCache {
+	c := &ReverseDNSCache{
+		entries:    make(map[string]reverseDNSEntry),
+		pending:    make(map[string]struct{}),
+		size:       size,
+		ttl:        ttl,
+		timeout:    timeout,
+		queue:      make(chan string, size),
+		stop:       make(chan struct{}),
+		lookupAddr: lookupAddr,
+		timeNow:    time.Now,
+	}
+	for i := 0; i < workers; i++ {
+		c.wg.Add(1)
+		go c.worker()
+	}
+	return c
+}
+
+// Get returns the cached hostname of an IP address. If it is missing, the
+// address is queued to be resolved and an empty string is returned.
+func (c *ReverseDNSCache) Get(ipAddr []byte) string {
+	ip := net.IP(ipAddr)
+	if (len(ip) != net.IPv4len && len(ip) != net.IPv6len) || ip.IsUnspecified() {
+		return ""
+	}
+	addr := ip.String()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.timeNow()
+	if entry, found := c.entries[addr]; found {
+		if now.Before(entry.expiry) {
+			return entry.hostname
+		}
+		delete(c.entries, addr)
+	}
+	if _, found := c.pending[addr]; found {
+		return ""
+	}
+
+	if len(c.entries)+len(c.pending) >= c.size {
+		c.evictExpired(now)
+		if len(c.entries)+len(c.pending) >= c.size {
+			return ""
+		}
+	}
+
+	select {
+	case c.queue <- addr:
+		c.pending[addr] = struct{}{}
+	default:
+	}
+	return ""
+}
+
+func (c *ReverseDNSCache) evictExpired(now time.Time) {
+	for addr, entry := range c.entries {
+		if !now.Before(entry.expiry) {
+			delete(c.entries, addr)
+		}
+	}
+}
+
+func (c *ReverseDNSCache) worker() {
+	defer c.wg.Done()
+	for {
+		select {
+		case <-c.stop:
+			return
+		case addr := <-c.queue:
+			hostname := c.resolve(addr)
+
+			c.mu.Lock()
+			delete(c.pending, addr)
+			c.entries[addr] = reverseDNSEntry{hostname: hostname, expiry: c.timeNow().Add(c.ttl)}
+			c.mu.Unlock()
+		}
+	}
+}
+
+func (c *ReverseDNSCache) resolve(addr string) string {
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+
+	names, err := c.lookupAddr(ctx, addr)
+	if err != nil || len(names) == 0 {
+		log.Tracef("No reverse DNS hostname found for %s: %v", addr, err)
+		return ""
+	}
+	return strings.TrimSuffix(names[0], ".")
+}
+
+// Stop stops the goroutines resolving the addresses
+func (c *ReverseDNSCache) Stop() {
+	close(c.stop)
+	c.wg.Wait()
+}
@@ -0,0 +1,64 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2022-present Datadog, Inc.
+
+package enrichment
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/atomic"
+)
+
+func TestReverseDNSCache(t *testing.T) {
+	lookups := atomic.NewInt32(0)
+	lookupAddr := func(ctx context.Context, addr string) ([]string, error) {
+		lookups.Inc()
+		switch addr {
+		case "10.0.0.1":
+			return []string{"host-a.example.com."}, nil
+		case "10.0.0.2":
+			return []string{"host-b.example.com."}, nil
+		}
+		return nil, errors.New("no such host")
+	}
+	cache := newReverseDNSCache(2, time.Minute, time.Second, 1, lookupAddr)
+	defer cache.Stop()
+	now := time.Now()
+	cache.timeNow = func() time.Time { return now }
+
+	ipA := net.ParseIP("10.0.0.1").To4()
+	ipB := net.ParseIP("10.0.0.2").To4()
+	ipC := net.ParseIP("10.0.0.3").To4()
+
+	// resolved asynchronously
+	assert.Equal(t, "", cache.Get(ipA))
+	require.Eventually(t, func() bool { return cache.Get(ipA) == "host-a.example.com" }, 5*time.Second, 10*time.Millisecond)
+
+	// failed lookups are cached too
+	assert.Equal(t, "", cache.Get(net.ParseIP("10.0.0.4")))
+	require.Eventually(t, func() bool { return lookups.Load() == 2 }, 5*time.Second, 10*time.Millisecond)
+
+	// the cache is full
+	assert.Equal(t, "", cache.Get(ipB))
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, int32(2), lookups.Load())
+	assert.Equal(t, "", cache.Get(ipB))
+
+	// the entries expire
+	now = now.Add(2 * time.Minute)
+	assert.Equal(t, "", cache.Get(ipB))
+	require.Eventually(t, func() bool { return cache.Get(ipB) == "host-b.example.com" }, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, "", cache.Get(ipC))
+
+	// invalid addresses are not resolved
+	assert.Equal(t, "", cache.Get(nil))
+	assert.Equal(t, "", cache.Get(net.IPv4zero.To4()))
+}
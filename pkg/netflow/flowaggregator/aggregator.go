@@ -46,6 +46,10 @@ type FlowAggregator struct {
 	flushedFlowCount             *atomic.Uint64
 	hostname                     string
 	goflowPrometheusGatherer     prometheus.Gatherer
+	enricher                     *flowEnricher
+	deviceCache                  *metadata.DeviceCache
+	prometheusListenerEnabled    bool
+	topN                         int
 	TimeNowFunction              func() time.Time // Allows to mock time in tests
 
 	lastSequencePerExporter   map[SequenceDeltaKey]uint32
@@ -91,6 +95,10 @@ func NewFlowAggregator(sender sender.Sender, epForwarder epforwarder.EventPlatfo
 		flushedFlowCount:             atomic.NewUint64(0),
 		hostname:                     hostname,
 		goflowPrometheusGatherer:     prometheus.DefaultGatherer,
+		enricher:                     newFlowEnricher(config.Enrichment),
+		deviceCache:                  metadata.GetDeviceCache(),
+		prometheusListenerEnabled:    config.PrometheusListenerEnabled,
+		topN:                         config.AggregatorTopN,
 		TimeNowFunction:              time.Now,
 		lastSequencePerExporter:      make(map[SequenceDeltaKey]uint32),
 	}
@@ -108,6 +116,9 @@ func (agg *FlowAggregator) Stop() {
 	close(agg.stopChan)
 	<-agg.flushLoopDone
 	<-agg.runDone
+	if agg.enricher != nil {
+		agg.enricher.stop()
+	}
 }
 
 // GetFlowInChan returns flow input chan
@@ -132,6 +143,13 @@ func (agg *FlowAggregator) run() {
 func (agg *FlowAggregator) sendFlows(flows []*common.Flow, flushTime time.Time) {
 	for _, flow := range flows {
 		flowPayload := buildPayload(flow, agg.hostname, flushTime)
+		enrichWithDeviceMetadata(&flowPayload, agg.deviceCache)
+		if agg.enricher != nil {
+			agg.enricher.enrich(&flowPayload, flow)
+		}
+		if agg.prometheusListenerEnabled {
+			observeInterfaceMetrics(&flowPayload)
+		}
 		payloadBytes, err := json.Marshal(flowPayload)
 		if err != nil {
 			log.Errorf("Error marshalling device metadata: %s", err)
@@ -259,6 +277,7 @@ func (agg *FlowAggregator) flush() int {
 	// TODO: Add flush stats to agent telemetry e.g. aggregator newFlushCountStats()
 	if len(flowsToFlush) > 0 {
 		agg.sendFlows(flowsToFlush, flushTime)
+		agg.sendFlowMetrics(flowsToFlush)
 	}
 	agg.sendExporterMetadata(flowsToFlush, flushTime)
 
@@ -6,6 +6,7 @@
 package flowaggregator
 
 import (
+	"fmt"
 	"time"
 
 	"github.com/DataDog/datadog-agent/pkg/netflow/common"
@@ -15,6 +16,7 @@ import (
 )
 
 func buildPayload(aggFlow *common.Flow, hostname string, flushTime time.Time) payload.FlowPayload {
+	additionalFields, tags := buildAdditionalFields(aggFlow.AdditionalFields)
 	return payload.FlowPayload{
 		// TODO: Implement Tos
 		FlushTimestamp: flushTime.UnixMilli(),
@@ -60,5 +62,22 @@ func buildPayload(aggFlow *common.Flow, hostname string, flushTime time.Time) pa
 		NextHop: payload.NextHop{
 			IP: common.IPBytesToString(aggFlow.NextHop),
 		},
+		AdditionalFields: additionalFields,
+		Tags:             tags,
 	}
 }
+
+func buildAdditionalFields(fields []common.AdditionalField) (map[string]interface{}, []string) {
+	if len(fields) == 0 {
+		return nil, nil
+	}
+	additionalFields := make(map[string]interface{}, len(fields))
+	var tags []string
+	for _, field := range fields {
+		additionalFields[field.Name] = field.Value
+		if field.Tag {
+			tags = append(tags, fmt.Sprintf("%s:%v", field.Name, field.Value))
+		}
+	}
+	return additionalFields, tags
+}
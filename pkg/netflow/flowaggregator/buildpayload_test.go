@@ -225,3 +225,26 @@ func Test_buildPayload(t *testing.T) {
 		})
 	}
 }
+
+func Test_buildPayload_additionalFields(t *testing.T) {
+	flow := &common.Flow{
+		FlowType: common.TypeIPFIX,
+		AdditionalFields: []common.AdditionalField{
+			{Name: "application", Value: "ssl", Tag: true, AggregationKey: true},
+			{Name: "user_id", Value: uint64(1234), Tag: true},
+			{Name: "nat_source_ip", Value: "1.1.1.1"},
+		},
+	}
+	flowPayload := buildPayload(flow, "my-hostname", time.Now())
+
+	assert.Equal(t, map[string]interface{}{
+		"application":   "ssl",
+		"user_id":       uint64(1234),
+		"nat_source_ip": "1.1.1.1",
+	}, flowPayload.AdditionalFields)
+	assert.Equal(t, []string{"application:ssl", "user_id:1234"}, flowPayload.Tags)
+
+	flowPayload = buildPayload(&common.Flow{FlowType: common.TypeIPFIX}, "my-hostname", time.Now())
+	assert.Nil(t, flowPayload.AdditionalFields)
+	assert.Nil(t, flowPayload.Tags)
+}
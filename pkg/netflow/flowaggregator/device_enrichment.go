@@ -0,0 +1,36 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2022-present Datadog, Inc.
+
+package flowaggregator
+
+import (
+	"github.com/DataDog/datadog-agent/pkg/networkdevice/metadata"
+
+	"github.com/DataDog/datadog-agent/pkg/netflow/payload"
+)
+
+// enrichWithDeviceMetadata adds to the flow payload the name and the tags of the
+// exporter, and the names, aliases and speeds of its interfaces, when the
+// exporter is also monitored by the SNMP check
+func enrichWithDeviceMetadata(flowPayload *payload.FlowPayload, deviceCache *metadata.DeviceCache) {
+	device, found := deviceCache.Get(flowPayload.Device.Namespace, flowPayload.Exporter.IP)
+	if !found {
+		return
+	}
+	flowPayload.Device.Name = device.Name
+	flowPayload.Device.Tags = device.Tags
+	enrichInterface(&flowPayload.Ingress.Interface, device)
+	enrichInterface(&flowPayload.Egress.Interface, device)
+}
+
+func enrichInterface(flowInterface *payload.Interface, device metadata.DeviceInfo) {
+	interfaceInfo, found := device.Interfaces[flowInterface.Index]
+	if !found {
+		return
+	}
+	flowInterface.Name = interfaceInfo.Name
+	flowInterface.Alias = interfaceInfo.Alias
+	flowInterface.Speed = interfaceInfo.Speed
+}
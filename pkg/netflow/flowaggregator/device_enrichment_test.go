@@ -0,0 +1,147 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2022-present Datadog, Inc.
+
+package flowaggregator
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	promtestutil "github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/DataDog/datadog-agent/pkg/networkdevice/metadata"
+
+	"github.com/DataDog/datadog-agent/pkg/netflow/common"
+	"github.com/DataDog/datadog-agent/pkg/netflow/payload"
+)
+
+func newTestDeviceCache(namespace string) *metadata.DeviceCache {
+	deviceCache := metadata.NewDeviceCache(time.Hour)
+	deviceCache.Set(namespace, []string{"127.0.0.1"}, metadata.DeviceInfo{
+		Name: "router",
+		Tags: []string{"snmp_profile:cisco", "device_vendor:cisco"},
+		Interfaces: map[uint32]metadata.InterfaceInfo{
+			1: {Name: "eth0", Alias: "uplink", Speed: 1000000000},
+		},
+	})
+	return deviceCache
+}
+
+func TestEnrichWithDeviceMetadata(t *testing.T) {
+	flow := &common.Flow{
+		Namespace:       "my-ns",
+		FlowType:        common.TypeNetFlow9,
+		ExporterAddr:    []byte{127, 0, 0, 1},
+		InputInterface:  1,
+		OutputInterface: 2,
+		Bytes:           100,
+		Packets:         10,
+	}
+	flowPayload := buildPayload(flow, "my-hostname", time.Now())
+	enrichWithDeviceMetadata(&flowPayload, newTestDeviceCache("my-ns"))
+
+	assert.Equal(t, payload.Device{
+		Namespace: "my-ns",
+		Name:      "router",
+		Tags:      []string{"snmp_profile:cisco", "device_vendor:cisco"},
+	}, flowPayload.Device)
+	assert.Equal(t, payload.Interface{Index: 1, Name: "eth0", Alias: "uplink", Speed: 1000000000}, flowPayload.Ingress.Interface)
+	// unknown interfaces are left as is
+	assert.Equal(t, payload.Interface{Index: 2}, flowPayload.Egress.Interface)
+
+	// the flows from other exporters are left as is
+	flow.ExporterAddr = []byte{127, 0, 0, 2}
+	flowPayload = buildPayload(flow, "my-hostname", time.Now())
+	enrichWithDeviceMetadata(&flowPayload, newTestDeviceCache("my-ns"))
+	assert.Equal(t, payload.Device{Namespace: "my-ns"}, flowPayload.Device)
+	assert.Equal(t, payload.Interface{Index: 1}, flowPayload.Ingress.Interface)
+}
+
+func TestObserveInterfaceMetrics(t *testing.T) {
+	flow := &common.Flow{
+		Namespace:       "metrics-ns",
+		FlowType:        common.TypeNetFlow9,
+		ExporterAddr:    []byte{127, 0, 0, 1},
+		InputInterface:  1,
+		OutputInterface: 2,
+		Bytes:           100,
+		Packets:         10,
+	}
+	// the metrics are global, use a namespace of its own
+	deviceCache := newTestDeviceCache("metrics-ns")
+	for i := 0; i < 2; i++ {
+		flowPayload := buildPayload(flow, "my-hostname", time.Now())
+		enrichWithDeviceMetadata(&flowPayload, deviceCache)
+		observeInterfaceMetrics(&flowPayload)
+	}
+
+	ingressLabels := prometheus.Labels{
+		"device_namespace": "metrics-ns",
+		"exporter_ip":      "127.0.0.1",
+		"device_name":      "router",
+		"device_tags":      "device_vendor:cisco,snmp_profile:cisco",
+		"direction":        "ingress",
+		"interface_index":  "1",
+		"interface_name":   "eth0",
+		"interface_alias":  "uplink",
+		"interface_speed":  "1000000000",
+	}
+	assert.Equal(t, float64(200), promtestutil.ToFloat64(interfaceBytes.With(ingressLabels)))
+	assert.Equal(t, float64(20), promtestutil.ToFloat64(interfacePackets.With(ingressLabels)))
+
+	egressLabels := prometheus.Labels{
+		"device_namespace": "metrics-ns",
+		"exporter_ip":      "127.0.0.1",
+		"device_name":      "router",
+		"device_tags":      "device_vendor:cisco,snmp_profile:cisco",
+		"direction":        "egress",
+		"interface_index":  "2",
+		"interface_name":   "",
+		"interface_alias":  "",
+		"interface_speed":  "",
+	}
+	assert.Equal(t, float64(200), promtestutil.ToFloat64(interfaceBytes.With(egressLabels)))
+}
+
+func TestObserveInterfaceMetricsSeries(t *testing.T) {
+	flowPayload := payload.FlowPayload{
+		Device: payload.Device{
+			Namespace: "series-ns",
+			Name:      "router",
+			Tags:      []string{"snmp_profile:cisco", "env:prod", "device_vendor:cisco", "snmp_device:127.0.0.1"},
+		},
+		Exporter: payload.Exporter{IP: "127.0.0.1"},
+		Ingress:  payload.ObservationPoint{Interface: payload.Interface{Index: 1, Name: "eth0", Alias: "uplink"}},
+		Egress:   payload.ObservationPoint{Interface: payload.Interface{Index: 2}},
+		Bytes:    100,
+		Packets:  10,
+	}
+	observeInterfaceMetrics(&flowPayload)
+
+	// only the allowed device tags are set
+	labels := prometheus.Labels{
+		"device_namespace": "series-ns",
+		"exporter_ip":      "127.0.0.1",
+		"device_name":      "router",
+		"device_tags":      "device_vendor:cisco,snmp_device:127.0.0.1,snmp_profile:cisco",
+		"direction":        "ingress",
+		"interface_index":  "1",
+		"interface_name":   "eth0",
+		"interface_alias":  "uplink",
+		"interface_speed":  "",
+	}
+	assert.Equal(t, float64(100), promtestutil.ToFloat64(interfaceBytes.With(labels)))
+
+	// the series of an interface are replaced when its alias changes
+	flowPayload.Ingress.Interface.Alias = "downlink"
+	observeInterfaceMetrics(&flowPayload)
+	assert.False(t, interfaceBytes.Delete(labels))
+	assert.False(t, interfacePackets.Delete(labels))
+
+	labels["interface_alias"] = "downlink"
+	assert.Equal(t, float64(100), promtestutil.ToFloat64(interfaceBytes.With(labels)))
+}
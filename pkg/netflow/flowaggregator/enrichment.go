@@ -0,0 +1,88 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2022-present Datadog, Inc.
+
+package flowaggregator
+
+import (
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+
+	"github.com/DataDog/datadog-agent/pkg/netflow/common"
+	"github.com/DataDog/datadog-agent/pkg/netflow/config"
+	"github.com/DataDog/datadog-agent/pkg/netflow/enrichment"
+	"github.com/DataDog/datadog-agent/pkg/netflow/payload"
+)
+
+// flowEnricher adds to the flow payloads the autonomous systems, the locations
+// and the hostnames of the endpoints, read from local databases and resolved
+// through reverse DNS
+type flowEnricher struct {
+	ipInfo     *enrichment.IPInfoReader
+	reverseDNS *enrichment.ReverseDNSCache
+}
+
+// newFlowEnricher returns nil if no enrichment is configured
+func newFlowEnricher(conf config.EnrichmentConfig) *flowEnricher {
+	enricher := &flowEnricher{}
+
+	if conf.ASNDatabasePath != "" || conf.GeoIPDatabasePath != "" {
+		ipInfo, err := enrichment.NewIPInfoReader(conf.ASNDatabasePath, conf.GeoIPDatabasePath)
+		if err != nil {
+			log.Errorf("Error opening the ASN and GeoIP databases, flows won't be enriched with them: %s", err)
+		} else {
+			enricher.ipInfo = ipInfo
+		}
+	}
+
+	if conf.ReverseDNS.Enabled {
+		enricher.reverseDNS = enrichment.NewReverseDNSCache(
+			conf.ReverseDNS.CacheSize,
+			time.Duration(conf.ReverseDNS.CacheTTL)*time.Second,
+			time.Duration(conf.ReverseDNS.Timeout)*time.Millisecond,
+			conf.ReverseDNS.Workers,
+		)
+	}
+
+	if enricher.ipInfo == nil && enricher.reverseDNS == nil {
+		return nil
+	}
+	return enricher
+}
+
+func (e *flowEnricher) enrich(flowPayload *payload.FlowPayload, flow *common.Flow) {
+	e.enrichEndpoint(&flowPayload.Source, flow.SrcAddr)
+	e.enrichEndpoint(&flowPayload.Destination, flow.DstAddr)
+}
+
+func (e *flowEnricher) enrichEndpoint(endpoint *payload.Endpoint, ipAddr []byte) {
+	if e.ipInfo != nil {
+		info := e.ipInfo.Lookup(ipAddr)
+		if info.ASNumber != 0 {
+			endpoint.AS = &payload.AutonomousSystem{
+				Number:       info.ASNumber,
+				Organization: info.ASOrganization,
+			}
+		}
+		if info.CountryISOCode != "" || info.City != "" {
+			endpoint.Geo = &payload.Geo{
+				CountryISOCode: info.CountryISOCode,
+				City:           info.City,
+			}
+		}
+	}
+	if e.reverseDNS != nil {
+		endpoint.ReverseDNSHostname = e.reverseDNS.Get(ipAddr)
+	}
+}
+
+func (e *flowEnricher) stop() {
+	if e.ipInfo != nil {
+		e.ipInfo.Close()
+	}
+	if e.reverseDNS != nil {
+		e.reverseDNS.Stop()
+	}
+}
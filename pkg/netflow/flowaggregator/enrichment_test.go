@@ -0,0 +1,69 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2022-present Datadog, Inc.
+
+//go:build test
+
+package flowaggregator
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/netflow/common"
+	"github.com/DataDog/datadog-agent/pkg/netflow/config"
+	"github.com/DataDog/datadog-agent/pkg/netflow/payload"
+	"github.com/DataDog/datadog-agent/pkg/netflow/testutil"
+)
+
+func TestNewFlowEnricher(t *testing.T) {
+	assert.Nil(t, newFlowEnricher(config.EnrichmentConfig{}))
+	// the enrichment is skipped when the databases cannot be opened
+	assert.Nil(t, newFlowEnricher(config.EnrichmentConfig{ASNDatabasePath: "/does/not/exist.mmdb"}))
+
+	enricher := newFlowEnricher(config.EnrichmentConfig{
+		ReverseDNS: config.ReverseDNSConfig{Enabled: true, CacheSize: 10, CacheTTL: 60, Timeout: 100, Workers: 1},
+	})
+	require.NotNil(t, enricher)
+	assert.Nil(t, enricher.ipInfo)
+	assert.NotNil(t, enricher.reverseDNS)
+	enricher.stop()
+}
+
+func TestFlowEnricher(t *testing.T) {
+	asnDB := testutil.WriteMMDB(t, "GeoLite2-ASN", map[string]map[string]interface{}{
+		"8.8.8.0/24": {"autonomous_system_number": uint32(15169), "autonomous_system_organization": "GOOGLE"},
+	})
+	geoDB := testutil.WriteMMDB(t, "GeoLite2-City", map[string]map[string]interface{}{
+		"8.8.8.0/24": {
+			"country": map[string]interface{}{"iso_code": "US"},
+			"city":    map[string]interface{}{"names": map[string]interface{}{"en": "Mountain View"}},
+		},
+	})
+	enricher := newFlowEnricher(config.EnrichmentConfig{ASNDatabasePath: asnDB, GeoIPDatabasePath: geoDB})
+	require.NotNil(t, enricher)
+	defer enricher.stop()
+
+	flow := &common.Flow{
+		FlowType: common.TypeNetFlow9,
+		SrcAddr:  []byte{10, 10, 10, 10},
+		DstAddr:  []byte{8, 8, 8, 8},
+		SrcPort:  2000,
+		DstPort:  53,
+	}
+	flowPayload := buildPayload(flow, "my-hostname", time.Now())
+	enricher.enrich(&flowPayload, flow)
+
+	assert.Equal(t, payload.Endpoint{IP: "10.10.10.10", Port: "2000", Mac: "00:00:00:00:00:00", Mask: "0.0.0.0/0"}, flowPayload.Source)
+	assert.Equal(t, &payload.AutonomousSystem{Number: 15169, Organization: "GOOGLE"}, flowPayload.Destination.AS)
+	assert.Equal(t, &payload.Geo{CountryISOCode: "US", City: "Mountain View"}, flowPayload.Destination.Geo)
+
+	destination, err := json.Marshal(flowPayload.Destination)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"ip":"8.8.8.8","port":"53","mac":"00:00:00:00:00:00","mask":"0.0.0.0/0","as":{"number":15169,"organization":"GOOGLE"},"geo":{"country_iso_code":"US","city":"Mountain View"}}`, string(destination))
+}
@@ -101,6 +101,42 @@ func Test_flowAccumulator_add(t *testing.T) {
 	assert.Equal(t, []byte{10, 10, 10, 30}, wrappedFlowB.flow.DstAddr)
 }
 
+func Test_flowAccumulator_add_additionalFields(t *testing.T) {
+	newFlow := func(application string, natSourceIP string) *common.Flow {
+		return &common.Flow{
+			FlowType:     common.TypeIPFIX,
+			ExporterAddr: []byte{127, 0, 0, 1},
+			Bytes:        10,
+			Packets:      1,
+			SrcAddr:      []byte{10, 10, 10, 10},
+			DstAddr:      []byte{10, 10, 10, 20},
+			IPProtocol:   uint32(6),
+			SrcPort:      2000,
+			DstPort:      443,
+			AdditionalFields: []common.AdditionalField{
+				{Name: "application", Value: application, AggregationKey: true},
+				{Name: "nat_source_ip", Value: natSourceIP},
+			},
+		}
+	}
+	flowA1 := newFlow("ssl", "1.1.1.1")
+	flowA2 := newFlow("ssl", "2.2.2.2")
+	flowB1 := newFlow("web-browsing", "1.1.1.1")
+
+	acc := newFlowAccumulator(common.DefaultAggregatorFlushInterval, common.DefaultAggregatorFlushInterval, common.DefaultAggregatorPortRollupThreshold, true)
+	acc.add(flowA1)
+	acc.add(flowA2)
+	acc.add(flowB1)
+
+	// flows are only split by the additional fields configured as aggregation keys
+	assert.Equal(t, 2, len(acc.flows))
+	wrappedFlowA := acc.flows[flowA1.AggregationHash()]
+	assert.Equal(t, uint64(20), wrappedFlowA.flow.Bytes)
+	assert.Equal(t, flowA1.AdditionalFields, wrappedFlowA.flow.AdditionalFields)
+	wrappedFlowB := acc.flows[flowB1.AggregationHash()]
+	assert.Equal(t, uint64(10), wrappedFlowB.flow.Bytes)
+}
+
 func Test_flowAccumulator_portRollUp(t *testing.T) {
 	synFlag := uint32(2)
 	ackFlag := uint32(16)
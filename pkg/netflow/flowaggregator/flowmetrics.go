@@ -0,0 +1,157 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2022-present Datadog, Inc.
+
+package flowaggregator
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/DataDog/datadog-agent/pkg/netflow/common"
+	"github.com/DataDog/datadog-agent/pkg/netflow/enrichment"
+)
+
+const flowMetricPrefix = "netflow."
+
+// trafficEntry holds the traffic of the flows sharing the same tags
+type trafficEntry struct {
+	tags    []string
+	bytes   uint64
+	packets uint64
+}
+
+// trafficByNamespace structure: map[NAMESPACE]map[TAGS_KEY]*trafficEntry
+type trafficByNamespace map[string]map[string]*trafficEntry
+
+func (t trafficByNamespace) add(namespace string, tags []string, flow *common.Flow) {
+	if _, ok := t[namespace]; !ok {
+		t[namespace] = make(map[string]*trafficEntry)
+	}
+	key := strings.Join(tags, ",")
+	entry, ok := t[namespace][key]
+	if !ok {
+		entry = &trafficEntry{tags: tags}
+		t[namespace][key] = entry
+	}
+	entry.bytes += flow.Bytes
+	entry.packets += flow.Packets
+}
+
+// top returns the n entries of each namespace with the most bytes
+func (t trafficByNamespace) top(n int) []*trafficEntry {
+	var topEntries []*trafficEntry
+	for _, entriesByKey := range t {
+		entries := make([]*trafficEntry, 0, len(entriesByKey))
+		for _, entry := range entriesByKey {
+			entries = append(entries, entry)
+		}
+		sort.Slice(entries, func(i, j int) bool {
+			if entries[i].bytes != entries[j].bytes {
+				return entries[i].bytes > entries[j].bytes
+			}
+			// predictable order for the entries with the same traffic
+			return strings.Join(entries[i].tags, ",") < strings.Join(entries[j].tags, ",")
+		})
+		if len(entries) > n {
+			entries = entries[:n]
+		}
+		topEntries = append(topEntries, entries...)
+	}
+	return topEntries
+}
+
+func (t trafficByNamespace) all() []*trafficEntry {
+	var entries []*trafficEntry
+	for _, entriesByKey := range t {
+		for _, entry := range entriesByKey {
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
+// sendFlowMetrics submits the traffic of the flushed flows as metrics: per
+// exporter interface and direction, and for the top talkers and applications
+func (agg *FlowAggregator) sendFlowMetrics(flows []*common.Flow) {
+	interfaceTraffic := make(trafficByNamespace)
+	talkersTraffic := make(trafficByNamespace)
+	applicationsTraffic := make(trafficByNamespace)
+
+	for _, flow := range flows {
+		namespaceTag := "device_namespace:" + flow.Namespace
+		exporterIP := common.IPBytesToString(flow.ExporterAddr)
+
+		interfaceTraffic.add(flow.Namespace, agg.interfaceTags(flow.Namespace, exporterIP, "ingress", flow.InputInterface), flow)
+		interfaceTraffic.add(flow.Namespace, agg.interfaceTags(flow.Namespace, exporterIP, "egress", flow.OutputInterface), flow)
+
+		if agg.topN > 0 {
+			talkersTraffic.add(flow.Namespace, []string{
+				namespaceTag,
+				"source_ip:" + common.IPBytesToString(flow.SrcAddr),
+				"destination_ip:" + common.IPBytesToString(flow.DstAddr),
+			}, flow)
+			if port := applicationPort(flow); port >= 0 {
+				applicationsTraffic.add(flow.Namespace, []string{
+					namespaceTag,
+					"ip_protocol:" + enrichment.MapIPProtocol(flow.IPProtocol),
+					"port:" + strconv.Itoa(int(port)),
+				}, flow)
+			}
+		}
+	}
+
+	agg.sendTrafficMetrics("interface", interfaceTraffic.all())
+	if agg.topN > 0 {
+		agg.sendTrafficMetrics("top_talkers", talkersTraffic.top(agg.topN))
+		agg.sendTrafficMetrics("top_applications", applicationsTraffic.top(agg.topN))
+	}
+}
+
+func (agg *FlowAggregator) sendTrafficMetrics(name string, entries []*trafficEntry) {
+	for _, entry := range entries {
+		agg.sender.Count(flowMetricPrefix+name+".bytes", float64(entry.bytes), "", entry.tags)
+		agg.sender.Count(flowMetricPrefix+name+".packets", float64(entry.packets), "", entry.tags)
+	}
+}
+
+// interfaceTags returns the tags of an exporter interface, named like the
+// SNMP interface metrics ones when the exporter is monitored by the SNMP check
+func (agg *FlowAggregator) interfaceTags(namespace string, exporterIP string, direction string, index uint32) []string {
+	tags := []string{
+		"device_namespace:" + namespace,
+		"exporter_ip:" + exporterIP,
+		"direction:" + direction,
+		"interface_index:" + strconv.FormatUint(uint64(index), 10),
+	}
+	device, found := agg.deviceCache.Get(namespace, exporterIP)
+	if !found {
+		return tags
+	}
+	if interfaceInfo, found := device.Interfaces[index]; found {
+		if interfaceInfo.Name != "" {
+			tags = append(tags, "interface:"+interfaceInfo.Name)
+		}
+		if interfaceInfo.Alias != "" {
+			tags = append(tags, "interface_alias:"+interfaceInfo.Alias)
+		}
+	}
+	return tags
+}
+
+// applicationPort returns the port of the service a flow belongs to: the
+// ephemeral port of the client is either rolled up to -1 or the highest one
+func applicationPort(flow *common.Flow) int32 {
+	switch {
+	case flow.SrcPort < 0:
+		return flow.DstPort
+	case flow.DstPort < 0:
+		return flow.SrcPort
+	case flow.SrcPort < flow.DstPort:
+		return flow.SrcPort
+	default:
+		return flow.DstPort
+	}
+}
@@ -0,0 +1,90 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2022-present Datadog, Inc.
+
+package flowaggregator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator/mocksender"
+
+	"github.com/DataDog/datadog-agent/pkg/netflow/common"
+)
+
+func newMetricsTestFlow(srcIP byte, dstIP byte, srcPort int32, dstPort int32, bytes uint64) *common.Flow {
+	return &common.Flow{
+		Namespace:       "my-ns",
+		FlowType:        common.TypeNetFlow9,
+		ExporterAddr:    []byte{127, 0, 0, 1},
+		SrcAddr:         []byte{10, 0, 0, srcIP},
+		DstAddr:         []byte{10, 0, 0, dstIP},
+		SrcPort:         srcPort,
+		DstPort:         dstPort,
+		IPProtocol:      6,
+		InputInterface:  1,
+		OutputInterface: 2,
+		Bytes:           bytes,
+		Packets:         bytes / 10,
+	}
+}
+
+func TestFlowAggregator_sendFlowMetrics(t *testing.T) {
+	sender := mocksender.NewMockSender("")
+	sender.On("Count", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return()
+
+	agg := &FlowAggregator{
+		sender:      sender,
+		deviceCache: newTestDeviceCache("my-ns"),
+		topN:        2,
+	}
+	agg.sendFlowMetrics([]*common.Flow{
+		newMetricsTestFlow(1, 2, -1, 443, 1000),
+		newMetricsTestFlow(1, 2, -1, 80, 500),
+		newMetricsTestFlow(3, 4, 53, 40000, 300),
+		newMetricsTestFlow(5, 6, -1, -1, 200),
+	})
+
+	ingressTags := []string{"device_namespace:my-ns", "exporter_ip:127.0.0.1", "direction:ingress", "interface_index:1", "interface:eth0", "interface_alias:uplink"}
+	egressTags := []string{"device_namespace:my-ns", "exporter_ip:127.0.0.1", "direction:egress", "interface_index:2"}
+	sender.AssertMetric(t, "Count", "netflow.interface.bytes", 2000, "", ingressTags)
+	sender.AssertMetric(t, "Count", "netflow.interface.packets", 200, "", ingressTags)
+	sender.AssertMetric(t, "Count", "netflow.interface.bytes", 2000, "", egressTags)
+
+	sender.AssertMetric(t, "Count", "netflow.top_talkers.bytes", 1500, "", []string{"device_namespace:my-ns", "source_ip:10.0.0.1", "destination_ip:10.0.0.2"})
+	sender.AssertMetric(t, "Count", "netflow.top_talkers.packets", 150, "", []string{"device_namespace:my-ns", "source_ip:10.0.0.1", "destination_ip:10.0.0.2"})
+	sender.AssertMetric(t, "Count", "netflow.top_talkers.bytes", 300, "", []string{"device_namespace:my-ns", "source_ip:10.0.0.3", "destination_ip:10.0.0.4"})
+	sender.AssertNotCalled(t, "Count", "netflow.top_talkers.bytes", float64(200), "", mock.Anything)
+
+	sender.AssertMetric(t, "Count", "netflow.top_applications.bytes", 1000, "", []string{"device_namespace:my-ns", "ip_protocol:TCP", "port:443"})
+	sender.AssertMetric(t, "Count", "netflow.top_applications.bytes", 500, "", []string{"device_namespace:my-ns", "ip_protocol:TCP", "port:80"})
+	sender.AssertNotCalled(t, "Count", "netflow.top_applications.bytes", float64(300), "", []string{"device_namespace:my-ns", "ip_protocol:TCP", "port:53"})
+	sender.AssertNumberOfCalls(t, "Count", 2*2+2*2+2*2)
+}
+
+func TestFlowAggregator_sendFlowMetrics_topNDisabled(t *testing.T) {
+	sender := mocksender.NewMockSender("")
+	sender.On("Count", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return()
+
+	agg := &FlowAggregator{
+		sender:      sender,
+		deviceCache: newTestDeviceCache("my-ns"),
+		topN:        -1,
+	}
+	agg.sendFlowMetrics([]*common.Flow{newMetricsTestFlow(1, 2, -1, 443, 1000)})
+
+	sender.AssertMetric(t, "Count", "netflow.interface.bytes", 1000, "", []string{"device_namespace:my-ns", "exporter_ip:127.0.0.1", "direction:egress", "interface_index:2"})
+	sender.AssertNotCalled(t, "Count", "netflow.top_talkers.bytes", mock.Anything, mock.Anything, mock.Anything)
+	sender.AssertNotCalled(t, "Count", "netflow.top_applications.bytes", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func Test_applicationPort(t *testing.T) {
+	assert.Equal(t, int32(443), applicationPort(&common.Flow{SrcPort: -1, DstPort: 443}))
+	assert.Equal(t, int32(53), applicationPort(&common.Flow{SrcPort: 53, DstPort: -1}))
+	assert.Equal(t, int32(22), applicationPort(&common.Flow{SrcPort: 51000, DstPort: 22}))
+	assert.Equal(t, int32(-1), applicationPort(&common.Flow{SrcPort: -1, DstPort: -1}))
+}
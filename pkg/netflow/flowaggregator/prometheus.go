@@ -0,0 +1,131 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2022-present Datadog, Inc.
+
+package flowaggregator
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/DataDog/datadog-agent/pkg/netflow/payload"
+)
+
+// interfaceMetricLabels are the labels of the traffic metrics exposed by the
+// Prometheus listener, the interface and device details are only set when the
+// exporter is monitored by the SNMP check
+var interfaceMetricLabels = []string{
+	"device_namespace",
+	"exporter_ip",
+	"device_name",
+	"device_tags",
+	"direction",
+	"interface_index",
+	"interface_name",
+	"interface_alias",
+	"interface_speed",
+}
+
+// interfaceMetricDeviceTagKeys are the keys of the device tags set in the
+// device_tags label, the other tags are free-form and left out
+var interfaceMetricDeviceTagKeys = map[string]struct{}{
+	"device_vendor": {},
+	"snmp_profile":  {},
+	"snmp_device":   {},
+}
+
+var (
+	interfaceBytes = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "flow_aggregator_interface_bytes",
+		Help: "Bytes of the flows flushed by the aggregator, by exporter interface",
+	}, interfaceMetricLabels)
+	interfacePackets = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "flow_aggregator_interface_packets",
+		Help: "Packets of the flows flushed by the aggregator, by exporter interface",
+	}, interfaceMetricLabels)
+)
+
+func init() {
+	prometheus.MustRegister(interfaceBytes, interfacePackets)
+}
+
+// interfaceSeries holds the labels of the series of each interface, the
+// series of an interface are replaced when its details change, so that their
+// number stays bounded by the number of interfaces
+var interfaceSeries = struct {
+	labels map[string]prometheus.Labels
+	sync.Mutex
+}{labels: make(map[string]prometheus.Labels)}
+
+// observeInterfaceMetrics counts the traffic of a flow on its ingress and
+// egress interfaces
+func observeInterfaceMetrics(flowPayload *payload.FlowPayload) {
+	deviceTags := interfaceMetricDeviceTags(flowPayload.Device.Tags)
+
+	for direction, observationPoint := range map[string]payload.ObservationPoint{"ingress": flowPayload.Ingress, "egress": flowPayload.Egress} {
+		var speed string
+		if observationPoint.Interface.Speed != 0 {
+			speed = strconv.FormatUint(observationPoint.Interface.Speed, 10)
+		}
+		labels := prometheus.Labels{
+			"device_namespace": flowPayload.Device.Namespace,
+			"exporter_ip":      flowPayload.Exporter.IP,
+			"device_name":      flowPayload.Device.Name,
+			"device_tags":      deviceTags,
+			"direction":        direction,
+			"interface_index":  strconv.FormatUint(uint64(observationPoint.Interface.Index), 10),
+			"interface_name":   observationPoint.Interface.Name,
+			"interface_alias":  observationPoint.Interface.Alias,
+			"interface_speed":  speed,
+		}
+		setInterfaceSeries(labels)
+		interfaceBytes.With(labels).Add(float64(flowPayload.Bytes))
+		interfacePackets.With(labels).Add(float64(flowPayload.Packets))
+	}
+}
+
+// interfaceMetricDeviceTags returns the sorted device tags with an allowed key
+func interfaceMetricDeviceTags(tags []string) string {
+	var allowedTags []string
+	for _, tag := range tags {
+		key, _, _ := strings.Cut(tag, ":")
+		if _, ok := interfaceMetricDeviceTagKeys[key]; ok {
+			allowedTags = append(allowedTags, tag)
+		}
+	}
+	sort.Strings(allowedTags)
+	return strings.Join(allowedTags, ",")
+}
+
+// setInterfaceSeries deletes the previous series of the interface when its
+// labels changed
+func setInterfaceSeries(labels prometheus.Labels) {
+	key := strings.Join([]string{labels["device_namespace"], labels["exporter_ip"], labels["direction"], labels["interface_index"]}, "|")
+
+	interfaceSeries.Lock()
+	defer interfaceSeries.Unlock()
+
+	previous, ok := interfaceSeries.labels[key]
+	if ok && !labelsEqual(previous, labels) {
+		interfaceBytes.Delete(previous)
+		interfacePackets.Delete(previous)
+	}
+	interfaceSeries.labels[key] = labels
+}
+
+func labelsEqual(a, b prometheus.Labels) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for name, value := range a {
+		if b[name] != value {
+			return false
+		}
+	}
+	return true
+}
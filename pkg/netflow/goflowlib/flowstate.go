@@ -16,6 +16,7 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/util/log"
 
 	"github.com/DataDog/datadog-agent/pkg/netflow/common"
+	"github.com/DataDog/datadog-agent/pkg/netflow/config"
 )
 
 // setting reusePort to false since not expected to be useful
@@ -39,10 +40,11 @@ type FlowRunnableState interface {
 }
 
 // StartFlowRoutine starts one of the goflow flow routine depending on the flow type
-func StartFlowRoutine(flowType common.FlowType, hostname string, port uint16, workers int, namespace string, flowInChan chan *common.Flow) (*FlowStateWrapper, error) {
+func StartFlowRoutine(flowType common.FlowType, hostname string, port uint16, workers int, namespace string, mapping []config.Mapping, flowInChan chan *common.Flow) (*FlowStateWrapper, error) {
 	var flowState FlowRunnableState
 
-	formatDriver := NewAggregatorFormatDriver(flowInChan, namespace)
+	producerConfig, fieldMappings := newFieldMappings(mapping)
+	formatDriver := NewAggregatorFormatDriver(flowInChan, namespace, fieldMappings)
 	logger := GetLogrusLevel()
 	ctx := context.Background()
 
@@ -58,6 +60,7 @@ func StartFlowRoutine(flowType common.FlowType, hostname string, port uint16, wo
 		state.Format = formatDriver
 		state.Logger = logger
 		state.TemplateSystem = templateSystem
+		state.Config = producerConfig
 		flowState = state
 	case common.TypeSFlow5:
 		state := utils.NewStateSFlow()
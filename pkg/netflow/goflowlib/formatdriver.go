@@ -16,15 +16,17 @@ import (
 
 // AggregatorFormatDriver is used as goflow formatter to forward flow data to aggregator/EP Forwarder
 type AggregatorFormatDriver struct {
-	namespace string
-	flowAggIn chan *common.Flow
+	namespace     string
+	flowAggIn     chan *common.Flow
+	fieldMappings []fieldMapping
 }
 
 // NewAggregatorFormatDriver returns a new AggregatorFormatDriver
-func NewAggregatorFormatDriver(flowAgg chan *common.Flow, namespace string) *AggregatorFormatDriver {
+func NewAggregatorFormatDriver(flowAgg chan *common.Flow, namespace string, fieldMappings []fieldMapping) *AggregatorFormatDriver {
 	return &AggregatorFormatDriver{
-		namespace: namespace,
-		flowAggIn: flowAgg,
+		namespace:     namespace,
+		flowAggIn:     flowAgg,
+		fieldMappings: fieldMappings,
 	}
 }
 
@@ -44,6 +46,10 @@ func (d *AggregatorFormatDriver) Format(data interface{}) ([]byte, []byte, error
 	if !ok {
 		return nil, nil, fmt.Errorf("message is not flowpb.FlowMessage")
 	}
-	d.flowAggIn <- ConvertFlow(flow, d.namespace)
+	convertedFlow := ConvertFlow(flow, d.namespace)
+	if len(d.fieldMappings) > 0 {
+		convertedFlow.AdditionalFields = convertAdditionalFields(flow, d.fieldMappings)
+	}
+	d.flowAggIn <- convertedFlow
 	return nil, nil, nil
 }
@@ -0,0 +1,121 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2022-present Datadog, Inc.
+
+package goflowlib
+
+import (
+	"bytes"
+	"encoding/hex"
+	"net"
+	"strconv"
+
+	flowpb "github.com/netsampler/goflow2/pb"
+	"github.com/netsampler/goflow2/producer"
+
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+
+	"github.com/DataDog/datadog-agent/pkg/netflow/common"
+	"github.com/DataDog/datadog-agent/pkg/netflow/config"
+)
+
+// fieldMapping is a custom field decoded by goflow into one of the generic
+// custom fields of its flow messages, CustomBytes_N. The integer fields are
+// decoded as bytes too, as a CustomInteger_N field missing from the template
+// of a flow can't be told apart from a zero.
+type fieldMapping struct {
+	config.Mapping
+	slot int // from 1 to 5
+}
+
+// newFieldMappings returns the goflow configuration decoding the custom fields
+// and where they are decoded to. The number of fields is checked by the config.
+func newFieldMappings(mapping []config.Mapping) (*producer.ProducerConfig, []fieldMapping) {
+	var goflowMapping []producer.NetFlowMapField
+	var fieldMappings []fieldMapping
+	for i, field := range mapping {
+		slot := i + 1
+		goflowMapping = append(goflowMapping, producer.NetFlowMapField{
+			PenProvided: field.Enterprise != 0,
+			Type:        field.Field,
+			Pen:         field.Enterprise,
+			Destination: "CustomBytes_" + strconv.Itoa(slot),
+			Endian:      producer.BigEndian,
+		})
+		fieldMappings = append(fieldMappings, fieldMapping{Mapping: field, slot: slot})
+	}
+	return &producer.ProducerConfig{
+		IPFIX:     producer.IPFIXProducerConfig{Mapping: goflowMapping},
+		NetFlowV9: producer.NetFlowV9ProducerConfig{Mapping: goflowMapping},
+	}, fieldMappings
+}
+
+// convertAdditionalFields returns the custom fields found in a flow, in the
+// order of the configuration
+func convertAdditionalFields(srcFlow *flowpb.FlowMessage, fieldMappings []fieldMapping) []common.AdditionalField {
+	var fields []common.AdditionalField
+	for _, mapping := range fieldMappings {
+		rawValue := customBytes(srcFlow, mapping.slot)
+		if len(rawValue) == 0 {
+			// the field is missing from the template of this flow
+			continue
+		}
+		var value interface{}
+		if mapping.Type == common.FieldTypeInteger {
+			if len(rawValue) > 8 {
+				log.Debugf("The custom field `%s` is too long to be decoded as an integer: %d bytes", mapping.Destination, len(rawValue))
+				continue
+			}
+			value = decodeIntegerField(rawValue)
+		} else {
+			value = formatBytesField(mapping.Type, rawValue)
+		}
+		fields = append(fields, common.AdditionalField{
+			Name:           mapping.Destination,
+			Value:          value,
+			Tag:            mapping.Tag,
+			AggregationKey: mapping.AggregationKey,
+		})
+	}
+	return fields
+}
+
+// decodeIntegerField decodes a big endian unsigned integer of up to 8 bytes
+func decodeIntegerField(value []byte) uint64 {
+	var res uint64
+	for _, b := range value {
+		res = res<<8 | uint64(b)
+	}
+	return res
+}
+
+func formatBytesField(fieldType common.FieldType, value []byte) string {
+	switch fieldType {
+	case common.FieldTypeIP:
+		return common.IPBytesToString(value)
+	case common.FieldTypeMac:
+		return net.HardwareAddr(value).String()
+	case common.FieldTypeHex:
+		return hex.EncodeToString(value)
+	default:
+		// fixed length strings are padded with null bytes
+		return string(bytes.TrimRight(value, "\x00"))
+	}
+}
+
+func customBytes(srcFlow *flowpb.FlowMessage, slot int) []byte {
+	switch slot {
+	case 1:
+		return srcFlow.CustomBytes_1
+	case 2:
+		return srcFlow.CustomBytes_2
+	case 3:
+		return srcFlow.CustomBytes_3
+	case 4:
+		return srcFlow.CustomBytes_4
+	case 5:
+		return srcFlow.CustomBytes_5
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2022-present Datadog, Inc.
+
+package goflowlib
+
+import (
+	"testing"
+
+	"github.com/netsampler/goflow2/decoders/netflow"
+	"github.com/netsampler/goflow2/producer"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/DataDog/datadog-agent/pkg/netflow/common"
+	"github.com/DataDog/datadog-agent/pkg/netflow/config"
+)
+
+func TestConvertAdditionalFields(t *testing.T) {
+	producerConfig, fieldMappings := newFieldMappings([]config.Mapping{
+		{Field: 56701, Enterprise: 25461, Type: common.FieldTypeString, Destination: "application", Tag: true, AggregationKey: true},
+		{Field: 225, Type: common.FieldTypeIP, Destination: "nat_source_ip"},
+		{Field: 56702, Enterprise: 25461, Type: common.FieldTypeInteger, Destination: "user_id"},
+		{Field: 81, Type: common.FieldTypeMac, Destination: "post_source_mac"},
+		{Field: 95, Type: common.FieldTypeHex, Destination: "application_id"},
+	})
+	assert.Equal(t, []fieldMapping{
+		{Mapping: config.Mapping{Field: 56701, Enterprise: 25461, Type: common.FieldTypeString, Destination: "application", Tag: true, AggregationKey: true}, slot: 1},
+		{Mapping: config.Mapping{Field: 225, Type: common.FieldTypeIP, Destination: "nat_source_ip"}, slot: 2},
+		{Mapping: config.Mapping{Field: 56702, Enterprise: 25461, Type: common.FieldTypeInteger, Destination: "user_id"}, slot: 3},
+		{Mapping: config.Mapping{Field: 81, Type: common.FieldTypeMac, Destination: "post_source_mac"}, slot: 4},
+		{Mapping: config.Mapping{Field: 95, Type: common.FieldTypeHex, Destination: "application_id"}, slot: 5},
+	}, fieldMappings)
+
+	// decode an IPFIX record with goflow, the same way as the listeners
+	record := []netflow.DataField{
+		{Type: netflow.NFV9_FIELD_IN_BYTES, Value: []byte{0, 0, 0, 100}},
+		{Type: 56701, PenProvided: true, Pen: 25461, Value: []byte("ssl\x00\x00\x00")},
+		{Type: 225, Value: []byte{192, 168, 1, 1}},
+		{Type: 56702, PenProvided: true, Pen: 25461, Value: []byte{0, 0, 4, 210}},
+		{Type: 81, Value: []byte{0x00, 0x1b, 0x21, 0x3c, 0x4d, 0x5e}},
+		{Type: 95, Value: []byte{0x03, 0x00, 0x00, 0x50}},
+		// same field ID without the enterprise number, not mapped
+		{Type: 56701, Value: []byte("other")},
+	}
+	srcFlow := producer.ConvertNetFlowDataSet(10, 0, 0, record, producer.NewProducerConfigMapped(producerConfig).IPFIX, nil)
+
+	assert.Equal(t, uint64(100), srcFlow.Bytes)
+	assert.Equal(t, []common.AdditionalField{
+		{Name: "application", Value: "ssl", Tag: true, AggregationKey: true},
+		{Name: "nat_source_ip", Value: "192.168.1.1"},
+		{Name: "user_id", Value: uint64(1234)},
+		{Name: "post_source_mac", Value: "00:1b:21:3c:4d:5e"},
+		{Name: "application_id", Value: "03000050"},
+	}, convertAdditionalFields(srcFlow, fieldMappings))
+}
+
+func TestConvertAdditionalFieldsMissing(t *testing.T) {
+	producerConfig, fieldMappings := newFieldMappings([]config.Mapping{
+		{Field: 225, Type: common.FieldTypeIP, Destination: "nat_source_ip"},
+		{Field: 56702, Enterprise: 25461, Type: common.FieldTypeInteger, Destination: "user_id"},
+		{Field: 56703, Enterprise: 25461, Type: common.FieldTypeInteger, Destination: "group_id"},
+	})
+
+	// the fields missing from the record are omitted, a zero is kept
+	record := []netflow.DataField{
+		{Type: netflow.NFV9_FIELD_IN_BYTES, Value: []byte{0, 0, 0, 100}},
+		{Type: 56703, PenProvided: true, Pen: 25461, Value: []byte{0, 0}},
+	}
+	srcFlow := producer.ConvertNetFlowDataSet(10, 0, 0, record, producer.NewProducerConfigMapped(producerConfig).IPFIX, nil)
+
+	assert.Equal(t, []common.AdditionalField{
+		{Name: "group_id", Value: uint64(0)},
+	}, convertAdditionalFields(srcFlow, fieldMappings))
+}
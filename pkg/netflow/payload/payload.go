@@ -7,7 +7,9 @@ package payload
 
 // Device contains device details (device sending NetFlow flows)
 type Device struct {
-	Namespace string `json:"namespace"`
+	Namespace string   `json:"namespace"`
+	Name      string   `json:"name,omitempty"`
+	Tags      []string `json:"tags,omitempty"`
 }
 
 // Exporter contains NetFlow exporter details
@@ -15,12 +17,27 @@ type Exporter struct {
 	IP string `json:"ip"`
 }
 
+// AutonomousSystem contains autonomous system details
+type AutonomousSystem struct {
+	Number       uint32 `json:"number"`
+	Organization string `json:"organization,omitempty"`
+}
+
+// Geo contains location details
+type Geo struct {
+	CountryISOCode string `json:"country_iso_code,omitempty"`
+	City           string `json:"city,omitempty"`
+}
+
 // Endpoint contains source or destination endpoint details
 type Endpoint struct {
-	IP   string `json:"ip"`
-	Port string `json:"port"` // Port number can be zero/positive or `*` (ephemeral port)
-	Mac  string `json:"mac"`
-	Mask string `json:"mask"`
+	IP                 string            `json:"ip"`
+	Port               string            `json:"port"` // Port number can be zero/positive or `*` (ephemeral port)
+	Mac                string            `json:"mac"`
+	Mask               string            `json:"mask"`
+	AS                 *AutonomousSystem `json:"as,omitempty"`
+	Geo                *Geo              `json:"geo,omitempty"`
+	ReverseDNSHostname string            `json:"reverse_dns_hostname,omitempty"`
 }
 
 // NextHop contains next hop details
@@ -31,6 +48,9 @@ type NextHop struct {
 // Interface contains interface details
 type Interface struct {
 	Index uint32 `json:"index"`
+	Name  string `json:"name,omitempty"`
+	Alias string `json:"alias,omitempty"`
+	Speed uint64 `json:"speed,omitempty"` // in bits per second
 }
 
 // ObservationPoint contains ingress or egress observation point
@@ -59,4 +79,8 @@ type FlowPayload struct {
 	Host           string           `json:"host"`
 	TCPFlags       []string         `json:"tcp_flags,omitempty"`
 	NextHop        NextHop          `json:"next_hop,omitempty"`
+
+	// AdditionalFields contains the custom fields mapped by the listener
+	AdditionalFields map[string]interface{} `json:"additional_fields,omitempty"`
+	Tags             []string               `json:"tags,omitempty"`
 }
@@ -0,0 +1,175 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023-present Datadog, Inc.
+
+//go:build test
+
+package testutil
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math/bits"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// mmdbNode is a node of the search tree of a MaxMind DB file
+type mmdbNode struct {
+	children [2]*mmdbNode
+	leaf     bool
+	offset   uint32 // offset of the record in the data section, for the leaves
+	id       uint32
+}
+
+// WriteMMDB writes an IPv4 MaxMind DB file holding the given records by network
+// (like `1.2.3.0/24`), and returns its path. The records are maps whose values
+// are strings, uint32, or maps of the same kind.
+func WriteMMDB(t *testing.T, databaseType string, records map[string]map[string]interface{}) string {
+	root := &mmdbNode{}
+	var data []byte
+
+	networks := make([]string, 0, len(records))
+	for network := range records {
+		networks = append(networks, network)
+	}
+	sort.Strings(networks)
+	for _, network := range networks {
+		_, ipNet, err := net.ParseCIDR(network)
+		require.NoError(t, err)
+		ip := ipNet.IP.To4()
+		require.NotNil(t, ip, "only IPv4 networks are supported")
+		prefixLen, _ := ipNet.Mask.Size()
+
+		node := root
+		for i := 0; i < prefixLen; i++ {
+			bit := (ip[i/8] >> (7 - uint(i%8))) & 1
+			if node.children[bit] == nil {
+				node.children[bit] = &mmdbNode{}
+			}
+			node = node.children[bit]
+		}
+		node.leaf = true
+		node.offset = uint32(len(data))
+		data = encodeMMDBValue(t, data, records[network])
+	}
+
+	// number the inner nodes, the leaves and the missing nodes are records
+	var nodes []*mmdbNode
+	var number func(node *mmdbNode)
+	number = func(node *mmdbNode) {
+		if node == nil || node.leaf {
+			return
+		}
+		node.id = uint32(len(nodes))
+		nodes = append(nodes, node)
+		number(node.children[0])
+		number(node.children[1])
+	}
+	number(root)
+	nodeCount := uint32(len(nodes))
+
+	var out []byte
+	for _, node := range nodes {
+		for _, child := range node.children {
+			var record uint32
+			switch {
+			case child == nil:
+				record = nodeCount
+			case child.leaf:
+				record = nodeCount + 16 + child.offset
+			default:
+				record = child.id
+			}
+			out = append(out, byte(record>>16), byte(record>>8), byte(record))
+		}
+	}
+	out = append(out, make([]byte, 16)...)
+	out = append(out, data...)
+	out = append(out, []byte("\xAB\xCD\xEFMaxMind.com")...)
+	out = encodeMMDBValue(t, out, map[string]interface{}{
+		"binary_format_major_version": uint16(2),
+		"binary_format_minor_version": uint16(0),
+		"build_epoch":                 uint64(1672531200),
+		"database_type":               databaseType,
+		"description":                 map[string]interface{}{"en": "Test database"},
+		"ip_version":                  uint16(4),
+		"languages":                   []interface{}{"en"},
+		"node_count":                  nodeCount,
+		"record_size":                 uint16(24),
+	})
+
+	path := filepath.Join(t.TempDir(), databaseType+".mmdb")
+	require.NoError(t, os.WriteFile(path, out, 0o644))
+	return path
+}
+
+func encodeMMDBValue(t *testing.T, out []byte, value interface{}) []byte {
+	switch v := value.(type) {
+	case string:
+		out = appendMMDBControl(out, 2, len(v))
+		return append(out, v...)
+	case uint16:
+		return appendMMDBUint(out, 5, uint64(v))
+	case uint32:
+		return appendMMDBUint(out, 6, uint64(v))
+	case uint64:
+		return appendMMDBUint(out, 9, v)
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		out = appendMMDBControl(out, 7, len(v))
+		for _, key := range keys {
+			out = encodeMMDBValue(t, out, key)
+			out = encodeMMDBValue(t, out, v[key])
+		}
+		return out
+	case []interface{}:
+		out = appendMMDBControl(out, 11, len(v))
+		for _, item := range v {
+			out = encodeMMDBValue(t, out, item)
+		}
+		return out
+	default:
+		require.FailNow(t, fmt.Sprintf("unsupported MMDB value %T", value))
+		return nil
+	}
+}
+
+func appendMMDBUint(out []byte, dataType byte, value uint64) []byte {
+	size := (bits.Len64(value) + 7) / 8
+	out = appendMMDBControl(out, dataType, size)
+	buf := make([]byte, 8)
+	binary.BigEndian.PutUint64(buf, value)
+	return append(out, buf[8-size:]...)
+}
+
+// appendMMDBControl appends the control byte of a field, sizes up to 284 are supported
+func appendMMDBControl(out []byte, dataType byte, size int) []byte {
+	var control byte
+	if dataType <= 7 {
+		control = dataType << 5
+	}
+	if size < 29 {
+		control |= byte(size)
+	} else {
+		control |= 29
+	}
+	out = append(out, control)
+	if dataType > 7 {
+		out = append(out, dataType-7)
+	}
+	if size >= 29 {
+		out = append(out, byte(size-29))
+	}
+	return out
+}
@@ -0,0 +1,98 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023-present Datadog, Inc.
+
+package metadata
+
+import (
+	"sync"
+	"time"
+)
+
+// DeviceCacheTTL is how long the devices are kept in the cache without being
+// reported again, e.g. after their check has been unscheduled
+const DeviceCacheTTL = time.Hour
+
+// DeviceInfo contains the metadata of a device shared with the other network
+// devices features running in the Agent, like NetFlow
+type DeviceInfo struct {
+	Name       string
+	Tags       []string
+	Interfaces map[uint32]InterfaceInfo // by ifIndex
+}
+
+// InterfaceInfo contains the metadata of an interface shared with the other
+// network devices features running in the Agent
+type InterfaceInfo struct {
+	Name  string
+	Alias string
+	Speed uint64 // in bits per second
+}
+
+type deviceCacheKey struct {
+	namespace string
+	ipAddress string
+}
+
+type deviceCacheEntry struct {
+	info   DeviceInfo
+	expiry time.Time
+}
+
+// DeviceCache holds the metadata of the devices monitored by the Agent, by
+// namespace and IP address
+type DeviceCache struct {
+	mu      sync.RWMutex
+	devices map[deviceCacheKey]deviceCacheEntry
+	ttl     time.Duration
+	timeNow func() time.Time
+}
+
+var defaultDeviceCache = NewDeviceCache(DeviceCacheTTL)
+
+// GetDeviceCache returns the cache shared by the features of the Agent
+func GetDeviceCache() *DeviceCache {
+	return defaultDeviceCache
+}
+
+// NewDeviceCache returns an empty cache whose devices expire after `ttl`
+func NewDeviceCache(ttl time.Duration) *DeviceCache {
+	return &DeviceCache{
+		devices: make(map[deviceCacheKey]deviceCacheEntry),
+		ttl:     ttl,
+		timeNow: time.Now,
+	}
+}
+
+// Set stores the metadata of a device, under all its IP addresses as its
+// data can be sent from any of them
+func (c *DeviceCache) Set(namespace string, ipAddresses []string, info DeviceInfo) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.timeNow()
+	for key, entry := range c.devices {
+		if !now.Before(entry.expiry) {
+			delete(c.devices, key)
+		}
+	}
+	for _, ipAddress := range ipAddresses {
+		if ipAddress == "" {
+			continue
+		}
+		c.devices[deviceCacheKey{namespace: namespace, ipAddress: ipAddress}] = deviceCacheEntry{info: info, expiry: now.Add(c.ttl)}
+	}
+}
+
+// Get returns the metadata of the device with the given IP address
+func (c *DeviceCache) Get(namespace string, ipAddress string) (DeviceInfo, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	entry, found := c.devices[deviceCacheKey{namespace: namespace, ipAddress: ipAddress}]
+	if !found || !c.timeNow().Before(entry.expiry) {
+		return DeviceInfo{}, false
+	}
+	return entry.info, true
+}
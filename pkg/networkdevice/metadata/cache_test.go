@@ -0,0 +1,54 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023-present Datadog, Inc.
+
+package metadata
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeviceCache(t *testing.T) {
+	now := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	cache := NewDeviceCache(time.Minute)
+	cache.timeNow = func() time.Time { return now }
+
+	info := DeviceInfo{
+		Name: "router",
+		Tags: []string{"snmp_device:10.0.0.1"},
+		Interfaces: map[uint32]InterfaceInfo{
+			1: {Name: "eth0", Alias: "uplink", Speed: 1e9},
+		},
+	}
+	cache.Set("default", []string{"10.0.0.1", "192.168.1.1", ""}, info)
+
+	device, found := cache.Get("default", "10.0.0.1")
+	assert.True(t, found)
+	assert.Equal(t, info, device)
+	device, found = cache.Get("default", "192.168.1.1")
+	assert.True(t, found)
+	assert.Equal(t, info, device)
+
+	_, found = cache.Get("other", "10.0.0.1")
+	assert.False(t, found)
+	_, found = cache.Get("default", "")
+	assert.False(t, found)
+
+	// the entries expire unless the device is reported again
+	now = now.Add(30 * time.Second)
+	cache.Set("default", []string{"10.0.0.2"}, DeviceInfo{Name: "switch"})
+	now = now.Add(45 * time.Second)
+	_, found = cache.Get("default", "10.0.0.1")
+	assert.False(t, found)
+	device, found = cache.Get("default", "10.0.0.2")
+	assert.True(t, found)
+	assert.Equal(t, "switch", device.Name)
+
+	// the expired entries are removed when the cache is updated
+	cache.Set("default", []string{"10.0.0.2"}, DeviceInfo{Name: "switch"})
+	assert.Len(t, cache.devices, 1)
+}
@@ -0,0 +1,50 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023-present Datadog, Inc.
+
+package topology
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+var dotEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// WriteDOT writes the graph in the GraphViz DOT format, the neighbors that are
+// not monitored by the Agent being dashed
+func (g Graph) WriteDOT(w io.Writer) error {
+	var sb strings.Builder
+	sb.WriteString("graph topology {\n")
+	sb.WriteString("  node [shape=box];\n")
+	for _, node := range g.Nodes {
+		label := node.ID
+		if node.Name != "" {
+			label = node.Name
+		}
+		if node.IPAddress != "" {
+			label += "\n" + node.IPAddress
+		}
+		style := ""
+		if !node.Monitored {
+			style = ", style=dashed"
+		}
+		fmt.Fprintf(&sb, "  %s [label=%s%s];\n", dotQuote(node.ID), dotQuote(label), style)
+	}
+	for _, edge := range g.Edges {
+		fmt.Fprintf(&sb, "  %s -- %s [taillabel=%s, headlabel=%s, label=%s];\n",
+			dotQuote(edge.Source), dotQuote(edge.Target),
+			dotQuote(edge.SourceInterface), dotQuote(edge.TargetInterface),
+			dotQuote(strings.Join(edge.SourceTypes, ",")))
+	}
+	sb.WriteString("}\n")
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+func dotQuote(s string) string {
+	return `"` + dotEscaper.Replace(s) + `"`
+}
@@ -0,0 +1,184 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023-present Datadog, Inc.
+
+package topology
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/DataDog/datadog-agent/pkg/networkdevice/metadata"
+)
+
+// Graph is the adjacency graph of the devices monitored by the Agent and of
+// their neighbors
+type Graph struct {
+	Nodes []Node `json:"nodes"`
+	Edges []Edge `json:"edges"`
+}
+
+// Node is a device of the graph, either monitored by the Agent or only known
+// as the neighbor of a monitored device
+type Node struct {
+	ID        string `json:"id"`
+	Name      string `json:"name,omitempty"`
+	IPAddress string `json:"ip_address,omitempty"`
+	Monitored bool   `json:"monitored"`
+}
+
+// Edge is a link between the interfaces of two nodes, reported by one or both
+// of them
+type Edge struct {
+	Source          string   `json:"source"`
+	SourceInterface string   `json:"source_interface,omitempty"`
+	Target          string   `json:"target"`
+	TargetInterface string   `json:"target_interface,omitempty"`
+	SourceTypes     []string `json:"source_types"` // lldp and/or cdp
+}
+
+type graphBuilder struct {
+	nodes   map[string]*Node
+	aliases map[string]string // node ID by IP address, name or chassis ID
+	edges   map[string]*Edge
+}
+
+// buildGraph merges the links reported by the devices: the monitored devices
+// are recognized when reported as neighbors, and a link reported from both
+// ends, or over both LLDP and CDP, is a single edge
+func buildGraph(entries []storeEntry) Graph {
+	// sorting the devices to make the graph deterministic
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].device.ID < entries[j].device.ID
+	})
+
+	b := &graphBuilder{
+		nodes:   make(map[string]*Node),
+		aliases: make(map[string]string),
+		edges:   make(map[string]*Edge),
+	}
+	for _, entry := range entries {
+		device := entry.device
+		b.nodes[device.ID] = &Node{ID: device.ID, Name: device.Name, IPAddress: device.IPAddress, Monitored: true}
+		b.addAliases(device.ID, append([]string{device.IPAddress, device.Name}, device.IPAddresses...))
+	}
+	for _, entry := range entries {
+		for _, link := range entry.links {
+			b.addLink(entry.device, link)
+		}
+	}
+	return b.graph()
+}
+
+func (b *graphBuilder) addAliases(nodeID string, aliases []string) {
+	for _, alias := range aliases {
+		alias = normalizeAlias(alias)
+		if alias == "" {
+			continue
+		}
+		if _, exists := b.aliases[alias]; !exists {
+			b.aliases[alias] = nodeID
+		}
+	}
+}
+
+func (b *graphBuilder) addLink(device Device, link metadata.TopologyLinkMetadata) {
+	if link.Remote == nil || link.Remote.Device == nil {
+		return
+	}
+	remoteNodeID := b.resolveRemoteNode(link.Remote.Device)
+	if remoteNodeID == "" || remoteNodeID == device.ID {
+		return
+	}
+
+	var localInterface, remoteInterface string
+	if link.Local != nil && link.Local.Interface != nil {
+		localInterface = device.Interfaces[link.Local.Interface.DDID]
+		if localInterface == "" {
+			localInterface = link.Local.Interface.ID
+		}
+	}
+	if link.Remote.Interface != nil {
+		remoteInterface = link.Remote.Interface.ID
+	}
+
+	source, sourceInterface, target, targetInterface := device.ID, localInterface, remoteNodeID, remoteInterface
+	if target < source {
+		source, sourceInterface, target, targetInterface = target, targetInterface, source, sourceInterface
+	}
+	key := strings.Join([]string{source, sourceInterface, target, targetInterface}, "|")
+	edge, exists := b.edges[key]
+	if !exists {
+		edge = &Edge{Source: source, SourceInterface: sourceInterface, Target: target, TargetInterface: targetInterface}
+		b.edges[key] = edge
+	}
+	if !containsString(edge.SourceTypes, link.SourceType) {
+		edge.SourceTypes = append(edge.SourceTypes, link.SourceType)
+		sort.Strings(edge.SourceTypes)
+	}
+}
+
+// resolveRemoteNode returns the ID of the node of a neighbor, matching it to a
+// known node by its IP address, name or chassis ID, or adding a new node
+func (b *graphBuilder) resolveRemoteNode(remote *metadata.TopologyLinkDevice) string {
+	aliases := []string{remote.IPAddress, remote.Name, remote.ID}
+	for _, alias := range aliases {
+		if nodeID, found := b.aliases[normalizeAlias(alias)]; found {
+			b.addAliases(nodeID, aliases)
+			return nodeID
+		}
+	}
+
+	var nodeID string
+	for _, alias := range aliases {
+		if alias != "" {
+			nodeID = "neighbor:" + alias
+			break
+		}
+	}
+	if nodeID == "" {
+		return ""
+	}
+	b.nodes[nodeID] = &Node{ID: nodeID, Name: remote.Name, IPAddress: remote.IPAddress}
+	b.addAliases(nodeID, aliases)
+	return nodeID
+}
+
+func (b *graphBuilder) graph() Graph {
+	graph := Graph{
+		Nodes: make([]Node, 0, len(b.nodes)),
+		Edges: make([]Edge, 0, len(b.edges)),
+	}
+	for _, node := range b.nodes {
+		graph.Nodes = append(graph.Nodes, *node)
+	}
+	sort.Slice(graph.Nodes, func(i, j int) bool {
+		return graph.Nodes[i].ID < graph.Nodes[j].ID
+	})
+
+	keys := make([]string, 0, len(b.edges))
+	for key := range b.edges {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		graph.Edges = append(graph.Edges, *b.edges[key])
+	}
+	return graph
+}
+
+// normalizeAlias makes the names reported by LLDP and CDP comparable, as
+// devices don't always report them in the same case
+func normalizeAlias(alias string) string {
+	return strings.ToLower(strings.TrimSpace(alias))
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
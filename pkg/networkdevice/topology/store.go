@@ -0,0 +1,89 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023-present Datadog, Inc.
+
+// Package topology builds the L2 topology graph of the network devices
+// monitored by the Agent, from the LLDP and CDP neighbors they report.
+package topology
+
+import (
+	"sync"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/networkdevice/metadata"
+)
+
+// DeviceTTL is how long the links of a device are kept without being
+// reported again, e.g. after its check has been unscheduled
+const DeviceTTL = time.Hour
+
+// Device is a network device monitored by the Agent
+type Device struct {
+	ID          string
+	Name        string
+	IPAddress   string
+	IPAddresses []string          // all the IP addresses of the device, used to recognize it as a neighbor
+	Interfaces  map[string]string // interface names by interface ID (`<device_id>:<if_index>`)
+}
+
+type storeEntry struct {
+	device Device
+	links  []metadata.TopologyLinkMetadata
+	expiry time.Time
+}
+
+// Store holds the topology links reported by the devices monitored by the
+// Agent, by device ID
+type Store struct {
+	mu      sync.RWMutex
+	devices map[string]storeEntry
+	ttl     time.Duration
+	timeNow func() time.Time
+}
+
+var defaultStore = NewStore(DeviceTTL)
+
+// GetStore returns the store shared by the features of the Agent
+func GetStore() *Store {
+	return defaultStore
+}
+
+// NewStore returns an empty store whose devices expire after `ttl`
+func NewStore(ttl time.Duration) *Store {
+	return &Store{
+		devices: make(map[string]storeEntry),
+		ttl:     ttl,
+		timeNow: time.Now,
+	}
+}
+
+// Set stores the LLDP and CDP links reported by a device, replacing the
+// previously reported ones
+func (s *Store) Set(device Device, links []metadata.TopologyLinkMetadata) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.timeNow()
+	for id, entry := range s.devices {
+		if !now.Before(entry.expiry) {
+			delete(s.devices, id)
+		}
+	}
+	s.devices[device.ID] = storeEntry{device: device, links: links, expiry: now.Add(s.ttl)}
+}
+
+// Graph returns the adjacency graph merging the links of all the devices
+func (s *Store) Graph() Graph {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	now := s.timeNow()
+	entries := make([]storeEntry, 0, len(s.devices))
+	for _, entry := range s.devices {
+		if now.Before(entry.expiry) {
+			entries = append(entries, entry)
+		}
+	}
+	return buildGraph(entries)
+}
@@ -0,0 +1,104 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023-present Datadog, Inc.
+
+package topology
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/DataDog/datadog-agent/pkg/networkdevice/metadata"
+)
+
+func newLink(sourceType string, localInterfaceDDID string, remote metadata.TopologyLinkDevice, remoteInterface string) metadata.TopologyLinkMetadata {
+	return metadata.TopologyLinkMetadata{
+		SourceType: sourceType,
+		Local: &metadata.TopologyLinkSide{
+			Interface: &metadata.TopologyLinkInterface{DDID: localInterfaceDDID},
+		},
+		Remote: &metadata.TopologyLinkSide{
+			Device:    &remote,
+			Interface: &metadata.TopologyLinkInterface{ID: remoteInterface},
+		},
+	}
+}
+
+func TestStore_Graph(t *testing.T) {
+	now := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	store := NewStore(time.Minute)
+	store.timeNow = func() time.Time { return now }
+
+	router := Device{
+		ID:          "default:10.0.0.1",
+		Name:        "router",
+		IPAddress:   "10.0.0.1",
+		IPAddresses: []string{"192.168.1.1"},
+		Interfaces:  map[string]string{"default:10.0.0.1:1": "ge-0/0/1", "default:10.0.0.1:2": "ge-0/0/2"},
+	}
+	switchDevice := Device{
+		ID:         "default:10.0.0.2",
+		Name:       "switch",
+		IPAddress:  "10.0.0.2",
+		Interfaces: map[string]string{"default:10.0.0.2:10": "Gi0/1"},
+	}
+	store.Set(router, []metadata.TopologyLinkMetadata{
+		// the switch, seen from the router over LLDP and CDP
+		newLink("lldp", "default:10.0.0.1:1", metadata.TopologyLinkDevice{Name: "switch", ID: "00:11:22:33:44:55", IDType: "mac_address"}, "Gi0/1"),
+		newLink("cdp", "default:10.0.0.1:1", metadata.TopologyLinkDevice{Name: "SWITCH", IPAddress: "10.0.0.2"}, "Gi0/1"),
+		// a neighbor that isn't monitored
+		newLink("lldp", "default:10.0.0.1:2", metadata.TopologyLinkDevice{Name: "server", ID: "aa:bb:cc:dd:ee:ff"}, "eth0"),
+	})
+	store.Set(switchDevice, []metadata.TopologyLinkMetadata{
+		// the router, seen from the switch by one of its other addresses
+		newLink("lldp", "default:10.0.0.2:10", metadata.TopologyLinkDevice{IPAddress: "192.168.1.1"}, "ge-0/0/1"),
+	})
+
+	expectedGraph := Graph{
+		Nodes: []Node{
+			{ID: "default:10.0.0.1", Name: "router", IPAddress: "10.0.0.1", Monitored: true},
+			{ID: "default:10.0.0.2", Name: "switch", IPAddress: "10.0.0.2", Monitored: true},
+			{ID: "neighbor:server", Name: "server"},
+		},
+		Edges: []Edge{
+			{Source: "default:10.0.0.1", SourceInterface: "ge-0/0/1", Target: "default:10.0.0.2", TargetInterface: "Gi0/1", SourceTypes: []string{"cdp", "lldp"}},
+			{Source: "default:10.0.0.1", SourceInterface: "ge-0/0/2", Target: "neighbor:server", TargetInterface: "eth0", SourceTypes: []string{"lldp"}},
+		},
+	}
+	assert.Equal(t, expectedGraph, store.Graph())
+
+	// the devices expire unless they are reported again
+	now = now.Add(30 * time.Second)
+	store.Set(switchDevice, nil)
+	now = now.Add(45 * time.Second)
+	assert.Equal(t, Graph{
+		Nodes: []Node{{ID: "default:10.0.0.2", Name: "switch", IPAddress: "10.0.0.2", Monitored: true}},
+		Edges: []Edge{},
+	}, store.Graph())
+}
+
+func TestGraph_WriteDOT(t *testing.T) {
+	graph := Graph{
+		Nodes: []Node{
+			{ID: "default:10.0.0.1", Name: "router", IPAddress: "10.0.0.1", Monitored: true},
+			{ID: "neighbor:server", Name: `my "server"`},
+		},
+		Edges: []Edge{
+			{Source: "default:10.0.0.1", SourceInterface: "ge-0/0/2", Target: "neighbor:server", TargetInterface: "eth0", SourceTypes: []string{"cdp", "lldp"}},
+		},
+	}
+
+	var sb strings.Builder
+	assert.NoError(t, graph.WriteDOT(&sb))
+	assert.Equal(t, `graph topology {
+  node [shape=box];
+  "default:10.0.0.1" [label="router\n10.0.0.1"];
+  "neighbor:server" [label="my \"server\"", style=dashed];
+  "default:10.0.0.1" -- "neighbor:server" [taillabel="ge-0/0/2", headlabel="eth0", label="cdp,lldp"];
+}
+`, sb.String())
+}
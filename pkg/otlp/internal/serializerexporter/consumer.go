@@ -11,6 +11,8 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"strconv"
+	"strings"
 	"time"
 
 	"go.uber.org/multierr"
@@ -36,6 +38,10 @@ type serializerConsumer struct {
 	series      metrics.Series
 	sketches    metrics.SketchSeriesList
 	apmstats    []io.Reader
+
+	// expHistogramSketches replace the sketches of the translator for the
+	// points marked with expHistogramPointAttribute
+	expHistogramSketches []*quantile.Sketch
 }
 
 // enrichedTags of a given dimension.
@@ -62,6 +68,24 @@ func (c *serializerConsumer) enrichedTags(dimensions *otlpmetrics.Dimensions) []
 	return enrichedTags
 }
 
+// expHistogramSketch removes the expHistogramPointAttribute tag from tags and
+// returns the sketch it refers to, nil if there is none.
+func (c *serializerConsumer) expHistogramSketch(tags []string) ([]string, *quantile.Sketch) {
+	prefix := expHistogramPointAttribute + ":"
+	for i, tag := range tags {
+		if !strings.HasPrefix(tag, prefix) {
+			continue
+		}
+		tags = append(tags[:i], tags[i+1:]...)
+		index, err := strconv.Atoi(strings.TrimPrefix(tag, prefix))
+		if err != nil || index < 0 || index >= len(c.expHistogramSketches) {
+			return tags, nil
+		}
+		return tags, c.expHistogramSketches[index]
+	}
+	return tags, nil
+}
+
 func (c *serializerConsumer) ConsumeAPMStats(ss pb.ClientStatsPayload) {
 	log.Tracef("Serializing %d client stats buckets.", len(ss.Stats))
 	ss.Tags = append(ss.Tags, c.extraTags...)
@@ -74,9 +98,13 @@ func (c *serializerConsumer) ConsumeAPMStats(ss pb.ClientStatsPayload) {
 }
 
 func (c *serializerConsumer) ConsumeSketch(_ context.Context, dimensions *otlpmetrics.Dimensions, ts uint64, qsketch *quantile.Sketch) {
+	tags, sketch := c.expHistogramSketch(c.enrichedTags(dimensions))
+	if sketch != nil {
+		qsketch = sketch
+	}
 	c.sketches = append(c.sketches, &metrics.SketchSeries{
 		Name:     dimensions.Name(),
-		Tags:     tagset.CompositeTagsFromSlice(c.enrichedTags(dimensions)),
+		Tags:     tagset.CompositeTagsFromSlice(tags),
 		Host:     dimensions.Host(),
 		Interval: 0, // OTLP metrics do not have an interval.
 		Points: []metrics.SketchPoint{{
@@ -97,11 +125,13 @@ func apiTypeFromTranslatorType(typ otlpmetrics.DataType) metrics.APIMetricType {
 }
 
 func (c *serializerConsumer) ConsumeTimeSeries(ctx context.Context, dimensions *otlpmetrics.Dimensions, typ otlpmetrics.DataType, ts uint64, value float64) {
+	// the aggregations of the exponential histograms carry the marker too
+	tags, _ := c.expHistogramSketch(c.enrichedTags(dimensions))
 	c.series = append(c.series,
 		&metrics.Serie{
 			Name:     dimensions.Name(),
 			Points:   []metrics.Point{{Ts: float64(ts / 1e9), Value: value}},
-			Tags:     tagset.CompositeTagsFromSlice(c.enrichedTags(dimensions)),
+			Tags:     tagset.CompositeTagsFromSlice(tags),
 			Host:     dimensions.Host(),
 			MType:    apiTypeFromTranslatorType(typ),
 			Interval: 0, // OTLP metrics do not have an interval.
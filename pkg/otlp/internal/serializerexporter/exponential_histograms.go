@@ -0,0 +1,241 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package serializerexporter
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/DataDog/opentelemetry-mapping-go/pkg/quantile"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+)
+
+// expHistogramPointAttribute marks the exponential histogram points whose sketch
+// is computed by the exporter, its value is the index of the sketch.
+const expHistogramPointAttribute = "_dd.exp_histogram_point"
+
+// expHistogramPoint is the last cumulative point seen for an exponential histogram stream
+type expHistogramPoint struct {
+	startTs   pcommon.Timestamp
+	ts        pcommon.Timestamp
+	histogram *metrics.ExponentialHistogram
+	lastSeen  time.Time
+}
+
+// expHistogramDeltaConverter converts cumulative exponential histograms into delta
+// exponential histograms. The translator only maps delta exponential histograms
+// into sketches and drops the cumulative ones.
+type expHistogramDeltaConverter struct {
+	mu     sync.Mutex
+	ttl    time.Duration
+	points map[string]expHistogramPoint
+}
+
+func newExpHistogramDeltaConverter(ttl time.Duration) *expHistogramDeltaConverter {
+	return &expHistogramDeltaConverter{
+		ttl:    ttl,
+		points: make(map[string]expHistogramPoint),
+	}
+}
+
+// convert replaces, in place, the cumulative exponential histograms of md by their
+// deltas since the previous point of the same stream. The first point of each
+// stream is removed since it has nothing to be compared with.
+func (c *expHistogramDeltaConverter) convert(md pmetric.Metrics) {
+	now := time.Now()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	rms := md.ResourceMetrics()
+	for i := 0; i < rms.Len(); i++ {
+		rm := rms.At(i)
+		sms := rm.ScopeMetrics()
+		for j := 0; j < sms.Len(); j++ {
+			sm := sms.At(j)
+			ms := sm.Metrics()
+			for k := 0; k < ms.Len(); k++ {
+				m := ms.At(k)
+				if m.Type() != pmetric.MetricTypeExponentialHistogram {
+					continue
+				}
+				eh := m.ExponentialHistogram()
+				if eh.AggregationTemporality() != pmetric.AggregationTemporalityCumulative {
+					continue
+				}
+
+				prefix := streamPrefix(rm.Resource(), sm.Scope(), m)
+				eh.DataPoints().RemoveIf(func(p pmetric.ExponentialHistogramDataPoint) bool {
+					return !c.toDelta(prefix+attributesKey(p.Attributes()), p, now)
+				})
+				eh.SetAggregationTemporality(pmetric.AggregationTemporalityDelta)
+			}
+		}
+	}
+
+	for key, point := range c.points {
+		if now.Sub(point.lastSeen) > c.ttl {
+			delete(c.points, key)
+		}
+	}
+}
+
+// toDelta turns p into the delta since the previous point of its stream. It
+// returns false if no delta could be computed and the point must be dropped.
+func (c *expHistogramDeltaConverter) toDelta(key string, p pmetric.ExponentialHistogramDataPoint, now time.Time) bool {
+	if p.Flags().NoRecordedValue() {
+		return false
+	}
+
+	prev, found := c.points[key]
+	if found && p.StartTimestamp() == prev.startTs && p.Timestamp() <= prev.ts {
+		// out of order point, keep the most recent one as reference
+		return false
+	}
+
+	current := histogramFromDataPoint(p)
+	c.points[key] = expHistogramPoint{
+		startTs:   p.StartTimestamp(),
+		ts:        p.Timestamp(),
+		histogram: current,
+		lastSeen:  now,
+	}
+
+	if !found || p.StartTimestamp() != prev.startTs {
+		return false
+	}
+
+	delta, ok := current.Sub(prev.histogram)
+	if !ok {
+		return false
+	}
+
+	p.SetStartTimestamp(prev.ts)
+	p.SetScale(delta.Scale)
+	p.SetZeroCount(delta.ZeroCount)
+	p.SetCount(delta.Count())
+	if p.HasSum() {
+		p.SetSum(delta.Sum)
+	}
+	// min and max are cumulative too and can't be turned into deltas
+	p.RemoveMin()
+	p.RemoveMax()
+	p.Positive().SetOffset(delta.Positive.Offset)
+	p.Positive().BucketCounts().FromRaw(delta.Positive.Counts)
+	p.Negative().SetOffset(delta.Negative.Offset)
+	p.Negative().BucketCounts().FromRaw(delta.Negative.Counts)
+	return true
+}
+
+// expHistogramSketches computes the sketches of the delta exponential histograms of
+// md, their buckets being mapped directly onto the sketch bins. The translator
+// interpolates the buckets twice, through a DDSketch, so their points are marked
+// with expHistogramPointAttribute for the consumer to use these sketches instead.
+func expHistogramSketches(md pmetric.Metrics) []*quantile.Sketch {
+	var sketches []*quantile.Sketch
+
+	rms := md.ResourceMetrics()
+	for i := 0; i < rms.Len(); i++ {
+		sms := rms.At(i).ScopeMetrics()
+		for j := 0; j < sms.Len(); j++ {
+			ms := sms.At(j).Metrics()
+			for k := 0; k < ms.Len(); k++ {
+				m := ms.At(k)
+				if m.Type() != pmetric.MetricTypeExponentialHistogram {
+					continue
+				}
+				eh := m.ExponentialHistogram()
+				if eh.AggregationTemporality() != pmetric.AggregationTemporalityDelta {
+					continue
+				}
+
+				points := eh.DataPoints()
+				for l := 0; l < points.Len(); l++ {
+					p := points.At(l)
+					// without its sum the point is left to the translator, which
+					// estimates it from the buckets
+					if p.Flags().NoRecordedValue() || !p.HasSum() {
+						continue
+					}
+					sketch, err := histogramFromDataPoint(p).ToSketch()
+					if err != nil {
+						continue
+					}
+					if p.HasMin() {
+						sketch.Basic.Min = p.Min()
+					}
+					if p.HasMax() {
+						sketch.Basic.Max = p.Max()
+					}
+					p.Attributes().PutStr(expHistogramPointAttribute, strconv.Itoa(len(sketches)))
+					sketches = append(sketches, sketch)
+				}
+			}
+		}
+	}
+
+	return sketches
+}
+
+func histogramFromDataPoint(p pmetric.ExponentialHistogramDataPoint) *metrics.ExponentialHistogram {
+	return &metrics.ExponentialHistogram{
+		Scale:     p.Scale(),
+		ZeroCount: p.ZeroCount(),
+		Sum:       p.Sum(),
+		Positive: metrics.ExponentialBuckets{
+			Offset: p.Positive().Offset(),
+			Counts: p.Positive().BucketCounts().AsRaw(),
+		},
+		Negative: metrics.ExponentialBuckets{
+			Offset: p.Negative().Offset(),
+			Counts: p.Negative().BucketCounts().AsRaw(),
+		},
+	}
+}
+
+// streamPrefix returns the part of a stream identifier shared by all the points of a metric
+func streamPrefix(res pcommon.Resource, scope pcommon.InstrumentationScope, m pmetric.Metric) string {
+	var b strings.Builder
+	b.WriteString(m.Name())
+	b.WriteByte(0)
+	b.WriteString(scope.Name())
+	b.WriteByte(0)
+	b.WriteString(scope.Version())
+	b.WriteByte(0)
+	b.WriteString(attributesKey(res.Attributes()))
+	b.WriteByte(0)
+	return b.String()
+}
+
+// attributesKey returns a string uniquely identifying a set of attributes. The
+// type of the values is part of the key, for the int 1 and the string "1" to
+// identify different streams.
+func attributesKey(attrs pcommon.Map) string {
+	keys := make([]string, 0, attrs.Len())
+	attrs.Range(func(k string, _ pcommon.Value) bool {
+		keys = append(keys, k)
+		return true
+	})
+	sort.Strings(keys)
+
+	var b strings.Builder
+	for _, k := range keys {
+		v, _ := attrs.Get(k)
+		b.WriteString(k)
+		b.WriteByte('=')
+		b.WriteString(v.Type().String())
+		b.WriteByte(':')
+		b.WriteString(v.AsString())
+		b.WriteByte(0)
+	}
+	return b.String()
+}
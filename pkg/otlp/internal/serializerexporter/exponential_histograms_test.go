@@ -0,0 +1,142 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package serializerexporter
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.uber.org/zap"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/tagset"
+)
+
+func newCumulativeExpHistogram(start, ts pcommon.Timestamp, zeroCount uint64, counts []uint64, sum float64) pmetric.Metrics {
+	md := pmetric.NewMetrics()
+	m := md.ResourceMetrics().AppendEmpty().ScopeMetrics().AppendEmpty().Metrics().AppendEmpty()
+	m.SetName("test.exp_histogram")
+	eh := m.SetEmptyExponentialHistogram()
+	eh.SetAggregationTemporality(pmetric.AggregationTemporalityCumulative)
+
+	p := eh.DataPoints().AppendEmpty()
+	p.Attributes().PutStr("env", "test")
+	p.SetStartTimestamp(start)
+	p.SetTimestamp(ts)
+	p.SetScale(1)
+	p.SetZeroCount(zeroCount)
+	p.SetSum(sum)
+	p.SetMin(0)
+	p.SetMax(8)
+	p.Positive().SetOffset(2)
+	p.Positive().BucketCounts().FromRaw(counts)
+	count := zeroCount
+	for _, c := range counts {
+		count += c
+	}
+	p.SetCount(count)
+	return md
+}
+
+func TestExpHistogramDeltaConverter(t *testing.T) {
+	c := newExpHistogramDeltaConverter(time.Hour)
+
+	// the first point of a stream is dropped
+	first := newCumulativeExpHistogram(1, 10, 1, []uint64{1, 2}, 10)
+	c.convert(first)
+	eh := first.ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics().At(0).ExponentialHistogram()
+	assert.Equal(t, pmetric.AggregationTemporalityDelta, eh.AggregationTemporality())
+	assert.Equal(t, 0, eh.DataPoints().Len())
+
+	second := newCumulativeExpHistogram(1, 20, 3, []uint64{2, 2, 5}, 40)
+	c.convert(second)
+	eh = second.ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics().At(0).ExponentialHistogram()
+	require.Equal(t, 1, eh.DataPoints().Len())
+	p := eh.DataPoints().At(0)
+	assert.EqualValues(t, 10, p.StartTimestamp())
+	assert.EqualValues(t, 20, p.Timestamp())
+	assert.EqualValues(t, 2, p.ZeroCount())
+	assert.EqualValues(t, 8, p.Count())
+	assert.EqualValues(t, 30, p.Sum())
+	assert.False(t, p.HasMin())
+	assert.False(t, p.HasMax())
+	assert.EqualValues(t, 2, p.Positive().Offset())
+	assert.Equal(t, []uint64{1, 0, 5}, p.Positive().BucketCounts().AsRaw())
+
+	// a new start timestamp means the stream was reset
+	reset := newCumulativeExpHistogram(30, 40, 0, []uint64{1}, 2)
+	c.convert(reset)
+	eh = reset.ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics().At(0).ExponentialHistogram()
+	assert.Equal(t, 0, eh.DataPoints().Len())
+}
+
+func TestExpHistogramDeltaConverterExpiry(t *testing.T) {
+	c := newExpHistogramDeltaConverter(0)
+
+	c.convert(newCumulativeExpHistogram(1, 10, 1, []uint64{1}, 2))
+	time.Sleep(time.Millisecond)
+	c.convert(pmetric.NewMetrics())
+	assert.Empty(t, c.points)
+}
+
+func TestAttributesKey(t *testing.T) {
+	intAttrs := pcommon.NewMap()
+	intAttrs.PutInt("code", 1)
+	strAttrs := pcommon.NewMap()
+	strAttrs.PutStr("code", "1")
+	assert.NotEqual(t, attributesKey(intAttrs), attributesKey(strAttrs))
+
+	// the order of the attributes doesn't matter
+	first := pcommon.NewMap()
+	first.PutStr("a", "1")
+	first.PutStr("b", "2")
+	second := pcommon.NewMap()
+	second.PutStr("b", "2")
+	second.PutStr("a", "1")
+	assert.Equal(t, attributesKey(first), attributesKey(second))
+}
+
+func TestConsumeMetricsExpHistogramSketch(t *testing.T) {
+	config.Datadog.Set("hostname", "otlp-testhostname")
+	defer config.Datadog.Set("hostname", "")
+
+	md := newCumulativeExpHistogram(1, 10, 3, []uint64{2, 0, 5}, 40)
+	eh := md.ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics().At(0).ExponentialHistogram()
+	eh.SetAggregationTemporality(pmetric.AggregationTemporalityDelta)
+	expected, err := histogramFromDataPoint(eh.DataPoints().At(0)).ToSketch()
+	require.NoError(t, err)
+	expected.Basic.Min, expected.Basic.Max = 0, 8
+
+	rec := &metricRecorder{}
+	cfg := NewFactory(rec).CreateDefaultConfig().(*exporterConfig)
+	cfg.Metrics.HistConfig.SendAggregations = true
+	exp, err := newExporter(zap.NewNop(), rec, cfg)
+	require.NoError(t, err)
+	require.NoError(t, exp.ConsumeMetrics(context.Background(), md))
+
+	// the sketch is the one of the direct mapping of the buckets, not the
+	// translator's one
+	require.Len(t, rec.sketchSeriesList, 1)
+	assert.Equal(t, expected, rec.sketchSeriesList[0].Points[0].Sketch)
+
+	// the marker attribute doesn't leak in the tags
+	assert.Equal(t, tagset.NewCompositeTags([]string{"env:test"}, nil), rec.sketchSeriesList[0].Tags)
+	var aggregations int
+	for _, s := range rec.series {
+		if !strings.HasPrefix(s.Name, "test.exp_histogram.") {
+			continue
+		}
+		aggregations++
+		assert.Equal(t, tagset.NewCompositeTags([]string{"env:test"}, nil), s.Tags, s.Name)
+	}
+	assert.NotZero(t, aggregations)
+}
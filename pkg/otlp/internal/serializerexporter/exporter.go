@@ -8,6 +8,7 @@ package serializerexporter
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/DataDog/opentelemetry-mapping-go/pkg/otlp/attributes/source"
 	"github.com/DataDog/opentelemetry-mapping-go/pkg/otlp/metrics"
@@ -71,11 +72,12 @@ func (f sourceProviderFunc) Source(ctx context.Context) (source.Source, error) {
 // exporter translate OTLP metrics into the Datadog format and sends
 // them to the agent serializer.
 type exporter struct {
-	tr          *metrics.Translator
-	s           serializer.MetricSerializer
-	hostname    string
-	extraTags   []string
-	cardinality collectors.TagCardinality
+	tr            *metrics.Translator
+	expHistograms *expHistogramDeltaConverter
+	s             serializer.MetricSerializer
+	hostname      string
+	extraTags     []string
+	cardinality   collectors.TagCardinality
 }
 
 func translatorFromConfig(logger *zap.Logger, cfg *exporterConfig) (*metrics.Translator, error) {
@@ -160,16 +162,22 @@ func newExporter(logger *zap.Logger, s serializer.MetricSerializer, cfg *exporte
 	}
 
 	return &exporter{
-		tr:          tr,
-		s:           s,
-		hostname:    hname,
-		extraTags:   extraTags,
-		cardinality: cardinality,
+		tr:            tr,
+		expHistograms: newExpHistogramDeltaConverter(time.Duration(cfg.Metrics.DeltaTTL) * time.Second),
+		s:             s,
+		hostname:      hname,
+		extraTags:     extraTags,
+		cardinality:   cardinality,
 	}, nil
 }
 
 func (e *exporter) ConsumeMetrics(ctx context.Context, ld pmetric.Metrics) error {
-	consumer := &serializerConsumer{cardinality: e.cardinality, extraTags: e.extraTags}
+	e.expHistograms.convert(ld)
+	consumer := &serializerConsumer{
+		cardinality:          e.cardinality,
+		extraTags:            e.extraTags,
+		expHistogramSketches: expHistogramSketches(ld),
+	}
 	rmt, err := e.tr.MapMetrics(ctx, ld, consumer)
 	if err != nil {
 		return err
@@ -0,0 +1,27 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build secrets
+
+package secrets
+
+import (
+	"context"
+	"os/exec"
+)
+
+// CommandContext sets up an exec.Cmd for running with a context, the same way
+// the secret_backend_command is run. The returned function must be called once
+// the command is done.
+func CommandContext(ctx context.Context, name string, arg ...string) (*exec.Cmd, func(), error) {
+	return commandContext(ctx, name, arg...)
+}
+
+// CheckRights checks that the executable at path can only be modified by the
+// user running the Agent, with the same rules as the secret_backend_command.
+// If allowGroupExec is set, the executable can also be owned by one of its groups.
+func CheckRights(path string, allowGroupExec bool) error {
+	return checkRights(path, allowGroupExec)
+}
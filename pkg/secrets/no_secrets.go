@@ -10,8 +10,10 @@
 package secrets
 
 import (
+	"context"
 	"fmt"
 	"io"
+	"os/exec"
 )
 
 // SecretBackendOutputMaxSize defines max size of the JSON output from a secrets reader backend
@@ -30,3 +32,14 @@ func Decrypt(data []byte, origin string) ([]byte, error) {
 func GetDebugInfo(w io.Writer) {
 	fmt.Fprintf(w, "Secret feature is not available in this version of the agent")
 }
+
+// CommandContext sets up an exec.Cmd for running with a context
+func CommandContext(ctx context.Context, name string, arg ...string) (*exec.Cmd, func(), error) {
+	return exec.CommandContext(ctx, name, arg...), func() {}, nil
+}
+
+// CheckRights placeholder when compiled without the 'secrets' build tag: the
+// permissions of executables can't be checked, so none is allowed to run.
+func CheckRights(path string, allowGroupExec bool) error {
+	return fmt.Errorf("invalid executable '%s': permissions can't be checked in this version of the agent", path)
+}
@@ -14,6 +14,7 @@ import (
 	jsoniter "github.com/json-iterator/go"
 	"github.com/richardartoul/molecule"
 
+	"github.com/DataDog/datadog-agent/comp/forwarder/defaultforwarder/routing"
 	"github.com/DataDog/datadog-agent/comp/forwarder/defaultforwarder/transaction"
 	"github.com/DataDog/datadog-agent/pkg/config"
 	"github.com/DataDog/datadog-agent/pkg/metrics"
@@ -24,6 +25,7 @@ import (
 // IterableSeries is a serializer for metrics.IterableSeries
 type IterableSeries struct {
 	source metrics.SerieSource
+	router *routing.Router
 }
 
 // CreateIterableSeries creates a new instance of *IterableSeries
@@ -33,6 +35,15 @@ func CreateIterableSeries(source metrics.SerieSource) *IterableSeries {
 	}
 }
 
+// CreateRoutedIterableSeries creates a new instance of *IterableSeries whose
+// series are split into payloads per route, as decided by router.
+func CreateRoutedIterableSeries(source metrics.SerieSource, router *routing.Router) *IterableSeries {
+	return &IterableSeries{
+		source: source,
+		router: router,
+	}
+}
+
 // MoveNext moves to the next item.
 // This function skips the series when `NoIndex` is set at true as `NoIndex` is only supported by `MarshalSplitCompress`.
 func (series *IterableSeries) MoveNext() bool {
@@ -96,6 +107,18 @@ func (series *IterableSeries) DescribeCurrentItem() string {
 	return describeItem(current)
 }
 
+// CurrentItemRoute returns the route of the current serie
+func (series *IterableSeries) CurrentItemRoute() *routing.Route {
+	return series.route(series.source.Current())
+}
+
+func (series *IterableSeries) route(serie *metrics.Serie) *routing.Route {
+	if series.router == nil || serie == nil {
+		return nil
+	}
+	return series.router.RouteMetric(routing.PayloadTypeSeries, serie.Name, serie.Tags)
+}
+
 // GetCurrentItemPointCount gets the number of points in the current serie
 func (series *IterableSeries) GetCurrentItemPointCount() int {
 	return len(series.source.Current().Points)
@@ -109,15 +132,8 @@ func describeItem(serie *metrics.Serie) string {
 // If a compressed payload is larger than the max, a new payload will be generated. This method returns a slice of
 // compressed protobuf marshaled MetricPayload objects.
 func (series *IterableSeries) MarshalSplitCompress(bufferContext *marshaler.BufferContext) (transaction.BytesPayloads, error) {
-	var err error
-	var compressor *stream.Compressor
 	buf := bufferContext.PrecompressionBuf
 	ps := molecule.NewProtoStream(buf)
-	payloads := transaction.BytesPayloads{}
-
-	var pointsThisPayload int
-	var seriesThisPayload int
-	var serie *metrics.Serie
 
 	// the backend accepts payloads up to specific compressed / uncompressed
 	// sizes, but prefers small uncompressed payloads.  For series, there is
@@ -165,61 +181,27 @@ func (series *IterableSeries) MarshalSplitCompress(bufferContext *marshaler.Buff
 	//                       |-----------| 'OriginProduct' enum
 	//                                    |-------| 'Agent' enum value
 
-	// Prepare to write the next payload
-	startPayload := func() error {
-		var err error
-
-		pointsThisPayload = 0
-		seriesThisPayload = 0
-		bufferContext.CompressorInput.Reset()
-		bufferContext.CompressorOutput.Reset()
-
-		compressor, err = stream.NewCompressor(
-			bufferContext.CompressorInput, bufferContext.CompressorOutput,
+	routed := stream.NewRoutedPayloads(bufferContext.CompressorInput, bufferContext.CompressorOutput, false, func(input, output *bytes.Buffer) (*stream.Compressor, error) {
+		return stream.NewCompressor(
+			input, output,
 			maxPayloadSize, maxUncompressedSize,
-			[]byte{}, []byte{}, []byte{})
-		if err != nil {
-			return err
-		}
-
-		return nil
-	}
+			[]byte{}, []byte{}, []byte{}, bufferContext.Compressor)
+	})
 
-	addToPayload := func() error {
-		err = compressor.AddItem(buf.Bytes())
-		if err != nil {
-			return err
+	// Use series.source.MoveNext() instead of series.MoveNext() because this function supports
+	// the serie.NoIndex field.
+	for series.source.MoveNext() {
+		serie := series.source.Current()
+		route := series.route(serie)
+		if route != nil && len(route.Domains) == 0 {
+			// routed to no domain
+			continue
 		}
-		pointsThisPayload += len(serie.Points)
-		seriesThisPayload++
-		return nil
-	}
-
-	finishPayload := func() error {
-		var payload []byte
-		// Since the compression buffer is full - flush it and rotate
-		payload, err = compressor.Close()
+		payload, err := routed.Get(route)
 		if err != nil {
-			return err
-		}
-
-		if seriesThisPayload > 0 {
-			payloads = append(payloads, transaction.NewBytesPayload(payload, pointsThisPayload))
+			return nil, err
 		}
 
-		return nil
-	}
-
-	// start things off
-	err = startPayload()
-	if err != nil {
-		return nil, err
-	}
-
-	// Use series.source.MoveNext() instead of series.MoveNext() because this function supports
-	// the serie.NoIndex field.
-	for series.source.MoveNext() {
-		serie = series.source.Current()
 		serie.PopulateDeviceField()
 		serie.PopulateResources()
 
@@ -363,12 +345,12 @@ func (series *IterableSeries) MarshalSplitCompress(bufferContext *marshaler.Buff
 		if len(serie.Points) > maxPointsPerPayload {
 			// this series is just too big to fit in a payload (even alone)
 			err = stream.ErrItemTooBig
-		} else if pointsThisPayload+len(serie.Points) > maxPointsPerPayload {
+		} else if payload.PointCount()+len(serie.Points) > maxPointsPerPayload {
 			// this series won't fit in this payload, but will fit in the next
 			err = stream.ErrPayloadFull
 		} else {
 			// Compress the protobuf metadata and the marshaled series
-			err = addToPayload()
+			err = payload.AddItem(buf.Bytes(), len(serie.Points))
 		}
 
 		switch err {
@@ -376,18 +358,13 @@ func (series *IterableSeries) MarshalSplitCompress(bufferContext *marshaler.Buff
 			expvarsPayloadFull.Add(1)
 			tlmPayloadFull.Inc()
 
-			err = finishPayload()
-			if err != nil {
-				return nil, err
-			}
-
-			err = startPayload()
+			err = payload.Rotate()
 			if err != nil {
 				return nil, err
 			}
 
 			// Add it to the new compression buffer
-			err = addToPayload()
+			err = payload.AddItem(buf.Bytes(), len(serie.Points))
 			if err == stream.ErrItemTooBig {
 				// Item was too big, drop it
 				expvarsItemTooBig.Add(1)
@@ -414,13 +391,8 @@ func (series *IterableSeries) MarshalSplitCompress(bufferContext *marshaler.Buff
 		}
 	}
 
-	// if the last payload has any data, flush it
-	err = finishPayload()
-	if err != nil {
-		return nil, err
-	}
-
-	return payloads, nil
+	// flush the last payloads having any data
+	return routed.Close()
 }
 
 // MarshalJSON serializes timeseries to JSON so it can be sent to V1 endpoints
@@ -19,6 +19,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/DataDog/agent-payload/v5/gogen"
+	"github.com/DataDog/datadog-agent/comp/forwarder/defaultforwarder/routing"
 	"github.com/DataDog/datadog-agent/comp/forwarder/defaultforwarder/transaction"
 	"github.com/DataDog/datadog-agent/pkg/config"
 	"github.com/DataDog/datadog-agent/pkg/metrics"
@@ -471,6 +472,44 @@ func TestPayloadsSeries(t *testing.T) {
 	require.Equal(t, originalLength, newLength)
 }
 
+func TestRoutedSeriesJSONPayloads(t *testing.T) {
+	// every domain is the destination of a rule, series matching no rule are dropped
+	router, err := routing.NewRouter([]routing.RuleConfig{
+		{Name: "payments", MetricNames: []string{"payments.*"}, Domains: []string{"https://payments.example.com"}},
+		{Name: "other", MetricNames: []string{"other"}, Domains: []string{"https://other.example.com"}},
+	}, []string{"https://payments.example.com", "https://other.example.com"}, nil)
+	require.NoError(t, err)
+
+	testSeries := metrics.Series{
+		{Name: "payments.a", Points: []metrics.Point{{Ts: 10, Value: 1}}},
+		{Name: "other", Points: []metrics.Point{{Ts: 10, Value: 1}}},
+		{Name: "payments.b", Points: []metrics.Point{{Ts: 10, Value: 1}}},
+		{Name: "dropped", Points: []metrics.Point{{Ts: 10, Value: 1}}},
+	}
+	builder := stream.NewJSONPayloadBuilder(true)
+	iterableSeries := CreateRoutedIterableSeries(CreateSerieSource(testSeries), router)
+	payloads, err := builder.BuildWithOnErrItemTooBigPolicy(iterableSeries, stream.DropItemOnErrItemTooBig)
+	require.NoError(t, err)
+	require.Len(t, payloads, 2)
+
+	names := func(p *transaction.BytesPayload) []string {
+		payload, err := decompressPayload(p.GetContent())
+		require.NoError(t, err)
+		var s = map[string]Series{}
+		require.NoError(t, json.Unmarshal(payload, &s))
+		var res []string
+		for _, serie := range s["series"] {
+			res = append(res, serie.Name)
+		}
+		return res
+	}
+	assert.Equal(t, []string{"https://payments.example.com"}, payloads[0].GetDestinations())
+	assert.Equal(t, []string{"payments.a", "payments.b"}, names(payloads[0]))
+	assert.Equal(t, 2, payloads[0].GetPointCount())
+	assert.Equal(t, []string{"https://other.example.com"}, payloads[1].GetDestinations())
+	assert.Equal(t, []string{"other"}, names(payloads[1]))
+}
+
 var result transaction.BytesPayloads
 
 func BenchmarkPayloadsSeries(b *testing.B) {
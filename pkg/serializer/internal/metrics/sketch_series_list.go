@@ -12,6 +12,7 @@ import (
 	"github.com/DataDog/agent-payload/v5/gogen"
 	"github.com/richardartoul/molecule"
 
+	"github.com/DataDog/datadog-agent/comp/forwarder/defaultforwarder/routing"
 	"github.com/DataDog/datadog-agent/comp/forwarder/defaultforwarder/transaction"
 	"github.com/DataDog/datadog-agent/pkg/config"
 	"github.com/DataDog/datadog-agent/pkg/metrics"
@@ -24,6 +25,8 @@ import (
 // A SketchSeriesList implements marshaler.Marshaler
 type SketchSeriesList struct {
 	metrics.SketchesSource
+	// Router splits the sketches into payloads per route when set
+	Router *routing.Router
 }
 
 var (
@@ -51,11 +54,8 @@ func init() {
 // it's contents are marshaled individually, packed with the appropriate protobuf metadata, and compressed in stream.
 // The resulting payloads (when decompressed) are binary equal to the result of marshaling the whole object at once.
 func (sl SketchSeriesList) MarshalSplitCompress(bufferContext *marshaler.BufferContext) (transaction.BytesPayloads, error) {
-	var err error
-	var compressor *stream.Compressor
 	buf := bufferContext.PrecompressionBuf
 	ps := molecule.NewProtoStream(buf)
-	payloads := transaction.BytesPayloads{}
 
 	// constants for the protobuf data we will be writing, taken from
 	// https://github.com/DataDog/agent-payload/v5/blob/a2cd634bc9c088865b75c6410335270e6d780416/proto/metrics/agent_payload.proto#L47-L81
@@ -105,45 +105,32 @@ func (sl SketchSeriesList) MarshalSplitCompress(bufferContext *marshaler.BufferC
 		footer = buf.Bytes()
 	}
 
-	pointCount := 0
-	// Prepare to write the next payload
-	startPayload := func() error {
-		var err error
-
-		bufferContext.CompressorInput.Reset()
-		bufferContext.CompressorOutput.Reset()
-		pointCount = 0
-		compressor, err = stream.NewCompressor(
-			bufferContext.CompressorInput, bufferContext.CompressorOutput,
+	routed := stream.NewRoutedPayloads(bufferContext.CompressorInput, bufferContext.CompressorOutput, true, func(input, output *bytes.Buffer) (*stream.Compressor, error) {
+		return stream.NewCompressor(
+			input, output,
 			maxPayloadSize, maxUncompressedSize,
-			[]byte{}, footer, []byte{})
-		if err != nil {
-			return err
-		}
-
-		return nil
-	}
+			[]byte{}, footer, []byte{}, bufferContext.Compressor)
+	})
 
-	finishPayload := func() error {
-		var payload []byte
-		payload, err = compressor.Close()
-		if err != nil {
-			return err
+	// start things off, sketches are sent to every domain unless they are routed
+	if sl.Router == nil {
+		if _, err := routed.Get(nil); err != nil {
+			return nil, err
 		}
-
-		payloads = append(payloads, transaction.NewBytesPayload(payload, pointCount))
-
-		return nil
-	}
-
-	// start things off
-	err = startPayload()
-	if err != nil {
-		return nil, err
 	}
 
 	for sl.MoveNext() {
 		ss := sl.Current()
+		route := sl.route(ss)
+		if route != nil && len(route.Domains) == 0 {
+			// routed to no domain
+			continue
+		}
+		payload, err := routed.Get(route)
+		if err != nil {
+			return nil, err
+		}
+
 		buf.Reset()
 		err = ps.Embedded(payloadSketches, func(ps *molecule.ProtoStream) error {
 			var err error
@@ -224,25 +211,20 @@ func (sl SketchSeriesList) MarshalSplitCompress(bufferContext *marshaler.BufferC
 		}
 
 		// Compress the protobuf metadata and the marshaled sketch
-		err = compressor.AddItem(buf.Bytes())
+		err = payload.AddItem(buf.Bytes(), len(ss.Points))
 		switch err {
 		case stream.ErrPayloadFull:
 			expvarsPayloadFull.Add(1)
 			tlmPayloadFull.Inc()
 
 			// Since the compression buffer is full - flush it and start a new one
-			err = finishPayload()
-			if err != nil {
-				return nil, err
-			}
-
-			err = startPayload()
+			err = payload.Rotate()
 			if err != nil {
 				return nil, err
 			}
 
 			// Add it to the new compression buffer
-			err = compressor.AddItem(buf.Bytes())
+			err = payload.AddItem(buf.Bytes(), len(ss.Points))
 			if err == stream.ErrItemTooBig {
 				// Item was too big, drop it
 				expvarsItemTooBig.Add(1)
@@ -256,13 +238,11 @@ func (sl SketchSeriesList) MarshalSplitCompress(bufferContext *marshaler.BufferC
 				log.Debugf("Unexpected error trying to addItem to new payload after previous payload filled up: %v", err)
 				return nil, err
 			}
-			pointCount += len(ss.Points)
 		case stream.ErrItemTooBig:
 			// Item was too big, drop it
 			expvarsItemTooBig.Add(1)
 			tlmItemTooBig.Add(1)
 		case nil:
-			pointCount += len(ss.Points)
 			continue
 		default:
 			// Unexpected error bail out
@@ -273,7 +253,7 @@ func (sl SketchSeriesList) MarshalSplitCompress(bufferContext *marshaler.BufferC
 		}
 	}
 
-	err = finishPayload()
+	payloads, err := routed.Close()
 	if err != nil {
 		log.Debugf("Failed to finish payload with err %v", err)
 		return nil, err
@@ -282,6 +262,13 @@ func (sl SketchSeriesList) MarshalSplitCompress(bufferContext *marshaler.BufferC
 	return payloads, nil
 }
 
+func (sl SketchSeriesList) route(ss *metrics.SketchSeries) *routing.Route {
+	if sl.Router == nil {
+		return nil
+	}
+	return sl.Router.RouteMetric(routing.PayloadTypeSketches, ss.Name, ss.Tags)
+}
+
 // Marshal encodes this series list.
 func (sl SketchSeriesList) Marshal() ([]byte, error) {
 	pb := &gogen.SketchPayload{
@@ -0,0 +1,14 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build zlib && test
+
+package stream
+
+import "github.com/DataDog/datadog-agent/pkg/util/compression"
+
+// maxPayloadSizeThreeItems only fits the "{[A,B,C]}" payload: the compression
+// bound of the items, the footer and the zlib header written by the first write.
+var maxPayloadSizeThreeItems = compression.CompressBound(len("A,B,C")) + len("]}") + 2
@@ -3,7 +3,7 @@
 // This product includes software developed at Datadog (https://www.datadoghq.com/).
 // Copyright 2019-present Datadog, Inc.
 
-//go:build zlib
+//go:build zlib || zstd
 
 package stream
 
@@ -15,10 +15,12 @@ import (
 
 	jsoniter "github.com/json-iterator/go"
 
+	"github.com/DataDog/datadog-agent/comp/forwarder/defaultforwarder/routing"
 	"github.com/DataDog/datadog-agent/comp/forwarder/defaultforwarder/transaction"
 	"github.com/DataDog/datadog-agent/pkg/config"
 	"github.com/DataDog/datadog-agent/pkg/serializer/marshaler"
 	"github.com/DataDog/datadog-agent/pkg/telemetry"
+	"github.com/DataDog/datadog-agent/pkg/util/compression"
 	"github.com/DataDog/datadog-agent/pkg/util/log"
 )
 
@@ -67,10 +69,16 @@ type JSONPayloadBuilder struct {
 	shareAndLockBuffers           bool
 	input, output                 *bytes.Buffer
 	mu                            sync.Mutex
+	compressor                    compression.Compressor
 }
 
-// NewJSONPayloadBuilder returns a new JSONPayloadBuilder
+// NewJSONPayloadBuilder returns a new JSONPayloadBuilder using the compression selected at build time
 func NewJSONPayloadBuilder(shareAndLockBuffers bool) *JSONPayloadBuilder {
+	return NewJSONPayloadBuilderWithCompressor(shareAndLockBuffers, compression.DefaultCompressor())
+}
+
+// NewJSONPayloadBuilderWithCompressor returns a new JSONPayloadBuilder using compressor to compress the payloads
+func NewJSONPayloadBuilderWithCompressor(shareAndLockBuffers bool, compressor compression.Compressor) *JSONPayloadBuilder {
 	if shareAndLockBuffers {
 		return &JSONPayloadBuilder{
 			inputSizeHint:       4096,
@@ -78,12 +86,14 @@ func NewJSONPayloadBuilder(shareAndLockBuffers bool) *JSONPayloadBuilder {
 			shareAndLockBuffers: true,
 			input:               bytes.NewBuffer(make([]byte, 0, 4096)),
 			output:              bytes.NewBuffer(make([]byte, 0, 4096)),
+			compressor:          compressor,
 		}
 	}
 	return &JSONPayloadBuilder{
 		inputSizeHint:       4096,
 		outputSizeHint:      4096,
 		shareAndLockBuffers: false,
+		compressor:          compressor,
 	}
 }
 
@@ -131,7 +141,6 @@ func (b *JSONPayloadBuilder) BuildWithOnErrItemTooBigPolicy(
 		output = bytes.NewBuffer(make([]byte, 0, b.outputSizeHint))
 	}
 
-	var payloads transaction.BytesPayloads
 	expvarsTotalCalls.Add(1)
 	tlmTotalCalls.Inc()
 	start := time.Now()
@@ -151,21 +160,41 @@ func (b *JSONPayloadBuilder) BuildWithOnErrItemTooBigPolicy(
 		return nil, err
 	}
 
-	compressor, err := NewCompressor(
-		input, output,
-		maxPayloadSize, maxUncompressedSize,
-		header.Bytes(), footer.Bytes(), []byte(","))
-	if err != nil {
-		return nil, err
+	routed := NewRoutedPayloads(input, output, true, func(input, output *bytes.Buffer) (*Compressor, error) {
+		return NewCompressor(
+			input, output,
+			maxPayloadSize, maxUncompressedSize,
+			header.Bytes(), footer.Bytes(), []byte(","), b.compressor)
+	})
+
+	// Items are sent to every domain unless the marshaler routes them
+	router, _ := m.(ItemRouter)
+	if router == nil {
+		if _, err := routed.Get(nil); err != nil {
+			return nil, err
+		}
 	}
 
-	pointCount := 0
 	ok := m.MoveNext()
 	for ok {
+		var route *routing.Route
+		if router != nil {
+			route = router.CurrentItemRoute()
+			if route != nil && len(route.Domains) == 0 {
+				// routed to no domain
+				ok = m.MoveNext()
+				continue
+			}
+		}
+		payload, err := routed.Get(route)
+		if err != nil {
+			return nil, err
+		}
+
 		// We keep reusing the same small buffer in the jsoniter stream. Note that we can do so
 		// because compressor.addItem copies given buffer.
 		jsonStream.Reset(nil)
-		err := m.WriteCurrentItem(jsonStream)
+		err = m.WriteCurrentItem(jsonStream)
 		if err != nil {
 			log.Warnf("error marshalling an item, skipping: %s", err)
 			ok = m.MoveNext()
@@ -174,29 +203,16 @@ func (b *JSONPayloadBuilder) BuildWithOnErrItemTooBigPolicy(
 			continue
 		}
 
-		switch compressor.AddItem(jsonStream.Buffer()) {
+		switch payload.AddItem(jsonStream.Buffer(), m.GetCurrentItemPointCount()) {
 		case ErrPayloadFull:
 			expvarsPayloadFulls.Add(1)
 			tlmPayloadFull.Inc()
 			// payload is full, we need to create a new one
-			payload, err := compressor.Close()
-			if err != nil {
-				return payloads, err
-			}
-			payloads = append(payloads, transaction.NewBytesPayload(payload, pointCount))
-			pointCount = 0
-			input.Reset()
-			output.Reset()
-			compressor, err = NewCompressor(
-				input, output,
-				maxPayloadSize, maxUncompressedSize,
-				header.Bytes(), footer.Bytes(), []byte(","))
-			if err != nil {
+			if err := payload.Rotate(); err != nil {
 				return nil, err
 			}
 		case nil:
 			// All good, continue to next item
-			pointCount += m.GetCurrentItemPointCount()
 			ok = m.MoveNext()
 			expvarsTotalItems.Add(1)
 			tlmTotalItems.Inc()
@@ -216,12 +232,11 @@ func (b *JSONPayloadBuilder) BuildWithOnErrItemTooBigPolicy(
 		}
 	}
 
-	// Close last payload
-	payload, err := compressor.Close()
+	// Close last payloads
+	payloads, err := routed.Close()
 	if err != nil {
-		return payloads, err
+		return nil, err
 	}
-	payloads = append(payloads, transaction.NewBytesPayload(payload, pointCount))
 
 	if !b.shareAndLockBuffers {
 		b.inputSizeHint = input.Cap()
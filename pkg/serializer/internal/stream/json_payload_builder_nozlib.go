@@ -3,7 +3,7 @@
 // This product includes software developed at Datadog (https://www.datadoghq.com/).
 // Copyright 2019-present Datadog, Inc.
 
-//go:build !zlib
+//go:build !zlib && !zstd
 
 package stream
 
@@ -12,6 +12,7 @@ import (
 
 	"github.com/DataDog/datadog-agent/comp/forwarder/defaultforwarder/transaction"
 	"github.com/DataDog/datadog-agent/pkg/serializer/marshaler"
+	"github.com/DataDog/datadog-agent/pkg/util/compression"
 )
 
 // OnErrItemTooBigPolicy defines the behavior when OnErrItemTooBig occurs.
@@ -25,16 +26,21 @@ const (
 	FailOnErrItemTooBig
 )
 
-// JSONPayloadBuilder is not implemented when zlib is not available.
+// JSONPayloadBuilder is not implemented when neither zlib nor zstd is available.
 type JSONPayloadBuilder struct {
 }
 
-// NewJSONPayloadBuilder is not implemented when zlib is not available.
+// NewJSONPayloadBuilder is not implemented when neither zlib nor zstd is available.
 func NewJSONPayloadBuilder(shareAndLockBuffers bool) *JSONPayloadBuilder {
 	return nil
 }
 
-// BuildWithOnErrItemTooBigPolicy is not implemented when zlib is not available.
+// NewJSONPayloadBuilderWithCompressor is not implemented when neither zlib nor zstd is available.
+func NewJSONPayloadBuilderWithCompressor(shareAndLockBuffers bool, compressor compression.Compressor) *JSONPayloadBuilder {
+	return nil
+}
+
+// BuildWithOnErrItemTooBigPolicy is not implemented when neither zlib nor zstd is available.
 func (b *JSONPayloadBuilder) BuildWithOnErrItemTooBigPolicy(marshaler.IterableStreamJSONMarshaler, OnErrItemTooBigPolicy) (transaction.BytesPayloads, error) {
 	return nil, fmt.Errorf("not implemented")
 }
@@ -0,0 +1,143 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package stream
+
+import (
+	"bytes"
+
+	"github.com/DataDog/datadog-agent/comp/forwarder/defaultforwarder/routing"
+	"github.com/DataDog/datadog-agent/comp/forwarder/defaultforwarder/transaction"
+)
+
+// ItemRouter is implemented by the marshalers whose items can be sent to
+// different domains, according to the forwarder routing rules.
+type ItemRouter interface {
+	// CurrentItemRoute returns the route of the current item, or nil if it
+	// is sent to every domain.
+	CurrentItemRoute() *routing.Route
+}
+
+// RoutedPayloads builds the payloads of a stream of items sent to different
+// routes. Each route has its own payload in progress so items are routed while
+// they are streamed, and at most one payload per route is being built at any
+// time. The items of the nil route are sent to every domain.
+type RoutedPayloads struct {
+	newCompressor func(input, output *bytes.Buffer) (*Compressor, error)
+	input, output *bytes.Buffer
+	keepEmpty     bool
+	inProgress    []*RoutedPayload
+	payloads      transaction.BytesPayloads
+}
+
+// RoutedPayload is the payload being built for a route
+type RoutedPayload struct {
+	parent        *RoutedPayloads
+	route         *routing.Route
+	input, output *bytes.Buffer
+	compressor    *Compressor
+	itemCount     int
+	pointCount    int
+}
+
+// NewRoutedPayloads returns a new RoutedPayloads. The payload of the first
+// route uses the input and output buffers, the other routes allocate their
+// own. Payloads without items are dropped unless keepEmpty is set.
+func NewRoutedPayloads(input, output *bytes.Buffer, keepEmpty bool, newCompressor func(input, output *bytes.Buffer) (*Compressor, error)) *RoutedPayloads {
+	return &RoutedPayloads{
+		newCompressor: newCompressor,
+		input:         input,
+		output:        output,
+		keepEmpty:     keepEmpty,
+	}
+}
+
+// Get returns the payload in progress for route, starting it if needed
+func (r *RoutedPayloads) Get(route *routing.Route) (*RoutedPayload, error) {
+	// There are only a handful of routes, a linear search is cheaper than a map
+	for _, p := range r.inProgress {
+		if p.route == route {
+			return p, nil
+		}
+	}
+
+	p := &RoutedPayload{
+		parent: r,
+		route:  route,
+		input:  r.input,
+		output: r.output,
+	}
+	if len(r.inProgress) > 0 {
+		p.input = bytes.NewBuffer(make([]byte, 0, r.input.Cap()))
+		p.output = bytes.NewBuffer(make([]byte, 0, r.output.Cap()))
+	}
+	if err := p.start(); err != nil {
+		return nil, err
+	}
+	r.inProgress = append(r.inProgress, p)
+	return p, nil
+}
+
+// Close closes the payloads in progress and returns every payload built
+func (r *RoutedPayloads) Close() (transaction.BytesPayloads, error) {
+	for _, p := range r.inProgress {
+		if err := p.finish(); err != nil {
+			return nil, err
+		}
+	}
+	r.inProgress = nil
+	return r.payloads, nil
+}
+
+// AddItem adds an item holding pointCount points to the payload. It returns
+// ErrPayloadFull when the payload must be rotated before adding the item.
+func (p *RoutedPayload) AddItem(item []byte, pointCount int) error {
+	if err := p.compressor.AddItem(item); err != nil {
+		return err
+	}
+	p.itemCount++
+	p.pointCount += pointCount
+	return nil
+}
+
+// PointCount returns the number of points in the payload
+func (p *RoutedPayload) PointCount() int {
+	return p.pointCount
+}
+
+// Rotate closes the payload and starts a new one for the same route
+func (p *RoutedPayload) Rotate() error {
+	if err := p.finish(); err != nil {
+		return err
+	}
+	return p.start()
+}
+
+func (p *RoutedPayload) start() error {
+	var err error
+	p.input.Reset()
+	p.output.Reset()
+	p.itemCount = 0
+	p.pointCount = 0
+	p.compressor, err = p.parent.newCompressor(p.input, p.output)
+	return err
+}
+
+func (p *RoutedPayload) finish() error {
+	payload, err := p.compressor.Close()
+	if err != nil {
+		return err
+	}
+	if p.itemCount == 0 && !p.parent.keepEmpty {
+		return nil
+	}
+
+	bytesPayload := transaction.NewBytesPayload(payload, p.pointCount)
+	if p.route != nil {
+		bytesPayload.SetDestinations(p.route.Domains)
+	}
+	p.parent.payloads = append(p.parent.payloads, bytesPayload)
+	return nil
+}
@@ -9,6 +9,8 @@ import (
 	"bytes"
 
 	jsoniter "github.com/json-iterator/go"
+
+	"github.com/DataDog/datadog-agent/pkg/util/compression"
 )
 
 // JSONMarshaler is a AbstractMarshaler that implement JSON marshaling.
@@ -65,13 +67,21 @@ type BufferContext struct {
 	CompressorInput   *bytes.Buffer
 	CompressorOutput  *bytes.Buffer
 	PrecompressionBuf *bytes.Buffer
+	// Compressor compresses the payloads
+	Compressor compression.Compressor
 }
 
-// NewBufferContext initialize the default compression buffers
+// NewBufferContext initialize the default compression buffers, using the compression selected at build time
 func NewBufferContext() *BufferContext {
+	return NewBufferContextWithCompressor(compression.DefaultCompressor())
+}
+
+// NewBufferContextWithCompressor initialize the default compression buffers, using compressor
+func NewBufferContextWithCompressor(compressor compression.Compressor) *BufferContext {
 	return &BufferContext{
-		bytes.NewBuffer(make([]byte, 0, 1024)),
-		bytes.NewBuffer(make([]byte, 0, 1024)),
-		bytes.NewBuffer(make([]byte, 0, 1024)),
+		CompressorInput:   bytes.NewBuffer(make([]byte, 0, 1024)),
+		CompressorOutput:  bytes.NewBuffer(make([]byte, 0, 1024)),
+		PrecompressionBuf: bytes.NewBuffer(make([]byte, 0, 1024)),
+		Compressor:        compressor,
 	}
 }
@@ -0,0 +1,60 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package serializer
+
+import (
+	"net/http"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/serializer/internal/stream"
+	"github.com/DataDog/datadog-agent/pkg/util/compression"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// payloadCompression is a compressor and the extra headers of the payloads it compresses
+type payloadCompression struct {
+	compressor           compression.Compressor
+	jsonExtraHeaders     http.Header
+	protobufExtraHeaders http.Header
+}
+
+// newDefaultCompression returns the compression selected at build time
+func newDefaultCompression() payloadCompression {
+	return newPayloadCompression(compression.DefaultCompressor())
+}
+
+// newSeriesCompression returns the compression of the series, sketches and
+// service checks payloads, selected by `serializer_compressor_kind`. Agents
+// built without zlib use the compression selected at build time.
+func newSeriesCompression() payloadCompression {
+	if !stream.Available || compression.BuildKind != compression.ZlibKind {
+		return newDefaultCompression()
+	}
+
+	kind := config.Datadog.GetString("serializer_compressor_kind")
+	compressor, err := compression.NewCompressor(kind, config.Datadog.GetInt("serializer_zstd_compressor_level"))
+	if err != nil {
+		log.Errorf("Invalid serializer_compressor_kind, using %s: %v", compression.ZlibKind, err)
+		compressor, _ = compression.NewCompressor(compression.ZlibKind, 0)
+	}
+	return newPayloadCompression(compressor)
+}
+
+func newPayloadCompression(compressor compression.Compressor) payloadCompression {
+	return payloadCompression{
+		compressor:           compressor,
+		jsonExtraHeaders:     withContentEncoding(jsonExtraHeaders, compressor.ContentEncoding()),
+		protobufExtraHeaders: withContentEncoding(protobufExtraHeaders, compressor.ContentEncoding()),
+	}
+}
+
+func withContentEncoding(extraHeaders http.Header, contentEncoding string) http.Header {
+	headers := extraHeaders.Clone()
+	if contentEncoding != "" {
+		headers.Set("Content-Encoding", contentEncoding)
+	}
+	return headers
+}
@@ -0,0 +1,179 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package serializer
+
+import (
+	"net/http"
+
+	"github.com/DataDog/datadog-agent/comp/forwarder/defaultforwarder/routing"
+	"github.com/DataDog/datadog-agent/comp/forwarder/defaultforwarder/transaction"
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/config/utils"
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+	metricsserializer "github.com/DataDog/datadog-agent/pkg/serializer/internal/metrics"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// newRouter returns the router configured by `forwarder_routing_rules`, or nil
+// if routing is disabled.
+func newRouter() *routing.Router {
+	keysPerDomain, err := utils.GetMultipleEndpoints(config.Datadog)
+	if err != nil {
+		log.Errorf("Routing rules are disabled: %v", err)
+		return nil
+	}
+	domains := make([]string, 0, len(keysPerDomain))
+	for domain := range keysPerDomain {
+		domains = append(domains, domain)
+	}
+
+	router, err := routing.FromConfig(config.Datadog, domains)
+	if err != nil {
+		log.Errorf("Routing rules are disabled: %v", err)
+		return nil
+	}
+	return router
+}
+
+// routePayloads restricts the payloads to the domains of the route matching
+// their payload type.
+func (s *Serializer) routePayloads(payloads transaction.BytesPayloads, payloadType string) {
+	if s.router == nil {
+		return
+	}
+	setDestinations(payloads, s.router.RoutePayload(payloadType))
+}
+
+func setDestinations(payloads transaction.BytesPayloads, route *routing.Route) {
+	for _, payload := range payloads {
+		payload.SetDestinations(route.Domains)
+	}
+}
+
+// serializeRoutedSeriesJSON serializes series to JSON without streaming. The
+// stream serializers route each serie while building the payloads but this
+// serialization holds every serie in memory anyway, so series are grouped by
+// route and each group is serialized into payloads only sent to its domains.
+func (s *Serializer) serializeRoutedSeriesJSON(serieSource metrics.SerieSource) (transaction.BytesPayloads, http.Header, error) {
+	if s.router == nil {
+		return s.serializePayloadJSON(metricsserializer.CreateIterableSeries(serieSource), true, s.seriesCompression)
+	}
+
+	var routes []*routing.Route
+	seriesByRoute := make(map[*routing.Route]metrics.Series)
+	for serieSource.MoveNext() {
+		serie := serieSource.Current()
+		route := s.router.RouteMetric(routing.PayloadTypeSeries, serie.Name, serie.Tags)
+		if _, found := seriesByRoute[route]; !found {
+			routes = append(routes, route)
+		}
+		seriesByRoute[route] = append(seriesByRoute[route], serie)
+	}
+
+	var payloads transaction.BytesPayloads
+	var extraHeaders http.Header
+	for _, route := range routes {
+		if len(route.Domains) == 0 {
+			continue
+		}
+		routePayloads, headers, err := s.serializePayloadJSON(metricsserializer.CreateIterableSeries(newSerieSliceSource(seriesByRoute[route])), true, s.seriesCompression)
+		if err != nil {
+			return nil, nil, err
+		}
+		setDestinations(routePayloads, route)
+		payloads = append(payloads, routePayloads...)
+		extraHeaders = headers
+	}
+	return payloads, extraHeaders, nil
+}
+
+// serializeRoutedSketchesProto serializes sketches to protobuf without
+// streaming, grouping them by route like serializeRoutedSeriesJSON.
+func (s *Serializer) serializeRoutedSketchesProto(sketches metrics.SketchesSource) (transaction.BytesPayloads, http.Header, error) {
+	if s.router == nil {
+		return s.serializePayloadProto(metricsserializer.SketchSeriesList{SketchesSource: sketches}, true, s.seriesCompression)
+	}
+
+	var routes []*routing.Route
+	sketchesByRoute := make(map[*routing.Route]metrics.SketchSeriesList)
+	for sketches.MoveNext() {
+		sketch := sketches.Current()
+		if sketch == nil {
+			continue
+		}
+		route := s.router.RouteMetric(routing.PayloadTypeSketches, sketch.Name, sketch.Tags)
+		if _, found := sketchesByRoute[route]; !found {
+			routes = append(routes, route)
+		}
+		sketchesByRoute[route] = append(sketchesByRoute[route], sketch)
+	}
+
+	var payloads transaction.BytesPayloads
+	var extraHeaders http.Header
+	for _, route := range routes {
+		if len(route.Domains) == 0 {
+			continue
+		}
+		routePayloads, headers, err := s.serializePayloadProto(metricsserializer.SketchSeriesList{SketchesSource: newSketchesSliceSource(sketchesByRoute[route])}, true, s.seriesCompression)
+		if err != nil {
+			return nil, nil, err
+		}
+		setDestinations(routePayloads, route)
+		payloads = append(payloads, routePayloads...)
+		extraHeaders = headers
+	}
+	return payloads, extraHeaders, nil
+}
+
+// serieSliceSource is a metrics.SerieSource iterating over a slice of series
+type serieSliceSource struct {
+	series metrics.Series
+	index  int
+}
+
+func newSerieSliceSource(series metrics.Series) *serieSliceSource {
+	return &serieSliceSource{series: series, index: -1}
+}
+
+func (s *serieSliceSource) MoveNext() bool {
+	s.index++
+	return s.index < len(s.series)
+}
+
+func (s *serieSliceSource) Current() *metrics.Serie {
+	return s.series[s.index]
+}
+
+func (s *serieSliceSource) Count() uint64 {
+	return uint64(len(s.series))
+}
+
+// sketchesSliceSource is a metrics.SketchesSource iterating over a slice of sketches
+type sketchesSliceSource struct {
+	sketches metrics.SketchSeriesList
+	index    int
+}
+
+func newSketchesSliceSource(sketches metrics.SketchSeriesList) *sketchesSliceSource {
+	return &sketchesSliceSource{sketches: sketches, index: -1}
+}
+
+func (s *sketchesSliceSource) MoveNext() bool {
+	s.index++
+	return s.index < len(s.sketches)
+}
+
+func (s *sketchesSliceSource) Current() *metrics.SketchSeries {
+	return s.sketches[s.index]
+}
+
+func (s *sketchesSliceSource) Count() uint64 {
+	return uint64(len(s.sketches))
+}
+
+func (s *sketchesSliceSource) WaitForValue() bool {
+	return s.index+1 < len(s.sketches)
+}
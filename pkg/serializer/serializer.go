@@ -15,6 +15,7 @@ import (
 	"time"
 
 	forwarder "github.com/DataDog/datadog-agent/comp/forwarder/defaultforwarder"
+	"github.com/DataDog/datadog-agent/comp/forwarder/defaultforwarder/routing"
 	"github.com/DataDog/datadog-agent/comp/forwarder/defaultforwarder/transaction"
 	"github.com/DataDog/datadog-agent/pkg/config"
 	"github.com/DataDog/datadog-agent/pkg/metrics"
@@ -111,6 +112,17 @@ type Serializer struct {
 	orchestratorForwarder forwarder.Forwarder
 
 	seriesJSONPayloadBuilder *stream.JSONPayloadBuilder
+	eventsJSONPayloadBuilder *stream.JSONPayloadBuilder
+
+	// seriesCompression compresses the series, sketches and service checks
+	// payloads, as selected by `serializer_compressor_kind`. The other payloads
+	// use defaultCompression.
+	seriesCompression  payloadCompression
+	defaultCompression payloadCompression
+
+	// router restricts the domains payloads, or subsets of them, are sent to.
+	// It is nil when no routing rule is configured.
+	router *routing.Router
 
 	// Those variables allow users to blacklist any kind of payload
 	// from being sent by the agent. This was introduced for
@@ -131,11 +143,17 @@ type Serializer struct {
 
 // NewSerializer returns a new Serializer initialized
 func NewSerializer(forwarder, orchestratorForwarder forwarder.Forwarder) *Serializer {
+	shareAndLockBuffers := config.Datadog.GetBool("enable_json_stream_shared_compressor_buffers")
+	seriesCompression := newSeriesCompression()
+	defaultCompression := newDefaultCompression()
 	s := &Serializer{
 		clock:                         clock.New(),
 		Forwarder:                     forwarder,
 		orchestratorForwarder:         orchestratorForwarder,
-		seriesJSONPayloadBuilder:      stream.NewJSONPayloadBuilder(config.Datadog.GetBool("enable_json_stream_shared_compressor_buffers")),
+		seriesJSONPayloadBuilder:      stream.NewJSONPayloadBuilderWithCompressor(shareAndLockBuffers, seriesCompression.compressor),
+		eventsJSONPayloadBuilder:      stream.NewJSONPayloadBuilderWithCompressor(shareAndLockBuffers, defaultCompression.compressor),
+		seriesCompression:             seriesCompression,
+		defaultCompression:            defaultCompression,
 		enableEvents:                  config.Datadog.GetBool("enable_payloads.events"),
 		enableSeries:                  config.Datadog.GetBool("enable_payloads.series"),
 		enableServiceChecks:           config.Datadog.GetBool("enable_payloads.service_checks"),
@@ -145,6 +163,7 @@ func NewSerializer(forwarder, orchestratorForwarder forwarder.Forwarder) *Serial
 		enableServiceChecksJSONStream: stream.Available && config.Datadog.GetBool("enable_service_checks_stream_payload_serialization"),
 		enableEventsJSONStream:        stream.Available && config.Datadog.GetBool("enable_events_stream_payload_serialization"),
 		enableSketchProtobufStream:    stream.Available && config.Datadog.GetBool("enable_sketch_stream_payload_serialization"),
+		router:                        newRouter(),
 	}
 
 	if !s.enableEvents {
@@ -176,35 +195,35 @@ func (s Serializer) serializePayload(
 	compress bool,
 	useV1API bool) (transaction.BytesPayloads, http.Header, error) {
 	if useV1API {
-		return s.serializePayloadJSON(jsonMarshaler, compress)
+		return s.serializePayloadJSON(jsonMarshaler, compress, s.defaultCompression)
 	}
-	return s.serializePayloadProto(protoMarshaler, compress)
+	return s.serializePayloadProto(protoMarshaler, compress, s.defaultCompression)
 }
 
-func (s Serializer) serializePayloadJSON(payload marshaler.JSONMarshaler, compress bool) (transaction.BytesPayloads, http.Header, error) {
+func (s Serializer) serializePayloadJSON(payload marshaler.JSONMarshaler, compress bool, c payloadCompression) (transaction.BytesPayloads, http.Header, error) {
 	var extraHeaders http.Header
 
 	if compress {
-		extraHeaders = jsonExtraHeadersWithCompression
+		extraHeaders = c.jsonExtraHeaders
 	} else {
 		extraHeaders = jsonExtraHeaders
 	}
 
-	return s.serializePayloadInternal(payload, compress, extraHeaders, split.JSONMarshalFct)
+	return s.serializePayloadInternal(payload, compress, c.compressor, extraHeaders, split.JSONMarshalFct)
 }
 
-func (s Serializer) serializePayloadProto(payload marshaler.ProtoMarshaler, compress bool) (transaction.BytesPayloads, http.Header, error) {
+func (s Serializer) serializePayloadProto(payload marshaler.ProtoMarshaler, compress bool, c payloadCompression) (transaction.BytesPayloads, http.Header, error) {
 	var extraHeaders http.Header
 	if compress {
-		extraHeaders = protobufExtraHeadersWithCompression
+		extraHeaders = c.protobufExtraHeaders
 	} else {
 		extraHeaders = protobufExtraHeaders
 	}
-	return s.serializePayloadInternal(payload, compress, extraHeaders, split.ProtoMarshalFct)
+	return s.serializePayloadInternal(payload, compress, c.compressor, extraHeaders, split.ProtoMarshalFct)
 }
 
-func (s Serializer) serializePayloadInternal(payload marshaler.AbstractMarshaler, compress bool, extraHeaders http.Header, marshalFct split.MarshalFct) (transaction.BytesPayloads, http.Header, error) {
-	payloads, err := split.Payloads(payload, compress, marshalFct)
+func (s Serializer) serializePayloadInternal(payload marshaler.AbstractMarshaler, compress bool, compressor compression.Compressor, extraHeaders http.Header, marshalFct split.MarshalFct) (transaction.BytesPayloads, http.Header, error) {
+	payloads, err := split.Payloads(payload, compress, marshalFct, compressor)
 
 	if err != nil {
 		return nil, nil, fmt.Errorf("could not split payload into small enough chunks: %s", err)
@@ -215,13 +234,13 @@ func (s Serializer) serializePayloadInternal(payload marshaler.AbstractMarshaler
 
 func (s Serializer) serializeStreamablePayload(payload marshaler.StreamJSONMarshaler, policy stream.OnErrItemTooBigPolicy) (transaction.BytesPayloads, http.Header, error) {
 	adapter := marshaler.NewIterableStreamJSONMarshalerAdapter(payload)
-	payloads, err := s.seriesJSONPayloadBuilder.BuildWithOnErrItemTooBigPolicy(adapter, policy)
-	return payloads, jsonExtraHeadersWithCompression, err
+	payloads, err := s.eventsJSONPayloadBuilder.BuildWithOnErrItemTooBigPolicy(adapter, policy)
+	return payloads, s.defaultCompression.jsonExtraHeaders, err
 }
 
 func (s Serializer) serializeIterableStreamablePayload(payload marshaler.IterableStreamJSONMarshaler, policy stream.OnErrItemTooBigPolicy) (transaction.BytesPayloads, http.Header, error) {
 	payloads, err := s.seriesJSONPayloadBuilder.BuildWithOnErrItemTooBigPolicy(payload, policy)
-	return payloads, jsonExtraHeadersWithCompression, err
+	return payloads, s.seriesCompression.jsonExtraHeaders, err
 }
 
 // As events are gathered by SourceType, the serialization logic is more complex than for the other serializations.
@@ -281,6 +300,7 @@ func (s *Serializer) SendEvents(events event.Events) error {
 		return fmt.Errorf("dropping event payload: %s", err)
 	}
 
+	s.routePayloads(eventPayloads, routing.PayloadTypeEvents)
 	return s.Forwarder.SubmitV1Intake(eventPayloads, extraHeaders)
 }
 
@@ -297,14 +317,16 @@ func (s *Serializer) SendServiceChecks(serviceChecks servicecheck.ServiceChecks)
 	var err error
 
 	if s.enableServiceChecksJSONStream {
-		serviceCheckPayloads, extraHeaders, err = s.serializeStreamablePayload(serviceChecksSerializer, stream.DropItemOnErrItemTooBig)
+		adapter := marshaler.NewIterableStreamJSONMarshalerAdapter(serviceChecksSerializer)
+		serviceCheckPayloads, extraHeaders, err = s.serializeIterableStreamablePayload(adapter, stream.DropItemOnErrItemTooBig)
 	} else {
-		serviceCheckPayloads, extraHeaders, err = s.serializePayloadJSON(serviceChecksSerializer, true)
+		serviceCheckPayloads, extraHeaders, err = s.serializePayloadJSON(serviceChecksSerializer, true, s.seriesCompression)
 	}
 	if err != nil {
 		return fmt.Errorf("dropping service check payload: %s", err)
 	}
 
+	s.routePayloads(serviceCheckPayloads, routing.PayloadTypeServiceChecks)
 	return s.Forwarder.SubmitV1CheckRuns(serviceCheckPayloads, extraHeaders)
 }
 
@@ -320,7 +342,7 @@ func (s *Serializer) SendIterableSeries(serieSource metrics.SerieSource) error {
 		return nil
 	}
 
-	seriesSerializer := metricsserializer.CreateIterableSeries(serieSource)
+	seriesSerializer := metricsserializer.CreateRoutedIterableSeries(serieSource, s.router)
 	useV1API := !config.Datadog.GetBool("use_v2_api.series")
 
 	var seriesBytesPayloads transaction.BytesPayloads
@@ -330,10 +352,10 @@ func (s *Serializer) SendIterableSeries(serieSource metrics.SerieSource) error {
 	if useV1API && s.enableJSONStream {
 		seriesBytesPayloads, extraHeaders, err = s.serializeIterableStreamablePayload(seriesSerializer, stream.DropItemOnErrItemTooBig)
 	} else if useV1API && !s.enableJSONStream {
-		seriesBytesPayloads, extraHeaders, err = s.serializePayloadJSON(seriesSerializer, true)
+		seriesBytesPayloads, extraHeaders, err = s.serializeRoutedSeriesJSON(serieSource)
 	} else {
-		seriesBytesPayloads, err = seriesSerializer.MarshalSplitCompress(marshaler.NewBufferContext())
-		extraHeaders = protobufExtraHeadersWithCompression
+		seriesBytesPayloads, err = seriesSerializer.MarshalSplitCompress(marshaler.NewBufferContextWithCompressor(s.seriesCompression.compressor))
+		extraHeaders = s.seriesCompression.protobufExtraHeaders
 	}
 
 	if err != nil {
@@ -357,17 +379,16 @@ func (s *Serializer) SendSketch(sketches metrics.SketchesSource) error {
 		log.Debug("sketches payloads are disabled: dropping it")
 		return nil
 	}
-	sketchesSerializer := metricsserializer.SketchSeriesList{SketchesSource: sketches}
+	sketchesSerializer := metricsserializer.SketchSeriesList{SketchesSource: sketches, Router: s.router}
 	if s.enableSketchProtobufStream {
-		payloads, err := sketchesSerializer.MarshalSplitCompress(marshaler.NewBufferContext())
+		payloads, err := sketchesSerializer.MarshalSplitCompress(marshaler.NewBufferContextWithCompressor(s.seriesCompression.compressor))
 		if err != nil {
 			return fmt.Errorf("dropping sketch payload: %v", err)
 		}
 
-		return s.Forwarder.SubmitSketchSeries(payloads, protobufExtraHeadersWithCompression)
+		return s.Forwarder.SubmitSketchSeries(payloads, s.seriesCompression.protobufExtraHeaders)
 	} else {
-		compress := true
-		splitSketches, extraHeaders, err := s.serializePayloadProto(sketchesSerializer, compress)
+		splitSketches, extraHeaders, err := s.serializeRoutedSketchesProto(sketches)
 		if err != nil {
 			return fmt.Errorf("dropping sketch payload: %s", err)
 		}
@@ -392,7 +413,7 @@ func (s *Serializer) SendAgentchecksMetadata(m marshaler.JSONMarshaler) error {
 }
 
 func (s *Serializer) sendMetadata(m marshaler.JSONMarshaler, submit func(payload transaction.BytesPayloads, extra http.Header) error) error {
-	mustSplit, compressedPayload, payload, err := split.CheckSizeAndSerialize(m, true, split.JSONMarshalFct)
+	mustSplit, compressedPayload, payload, err := split.CheckSizeAndSerialize(m, true, split.JSONMarshalFct, s.defaultCompression.compressor)
 	if err != nil {
 		return fmt.Errorf("could not determine size of metadata payload: %s", err)
 	}
@@ -403,7 +424,9 @@ func (s *Serializer) sendMetadata(m marshaler.JSONMarshaler, submit func(payload
 		return fmt.Errorf("metadata payload was too big to send (%d bytes compressed, %d bytes uncompressed), metadata payloads cannot be split", len(compressedPayload), len(payload))
 	}
 
-	if err := submit(transaction.NewBytesPayloadsWithoutMetaData([]*[]byte{&compressedPayload}), jsonExtraHeadersWithCompression); err != nil {
+	payloads := transaction.NewBytesPayloadsWithoutMetaData([]*[]byte{&compressedPayload})
+	s.routePayloads(payloads, routing.PayloadTypeMetadata)
+	if err := submit(payloads, jsonExtraHeadersWithCompression); err != nil {
 		return err
 	}
 
@@ -427,7 +450,9 @@ func (s *Serializer) SendProcessesMetadata(data interface{}) error {
 	if err != nil {
 		return fmt.Errorf("could not compress processes metadata payload: %s", err)
 	}
-	if err := s.Forwarder.SubmitV1Intake(transaction.NewBytesPayloadsWithoutMetaData([]*[]byte{&compressedPayload}), jsonExtraHeadersWithCompression); err != nil {
+	payloads := transaction.NewBytesPayloadsWithoutMetaData([]*[]byte{&compressedPayload})
+	s.routePayloads(payloads, routing.PayloadTypeMetadata)
+	if err := s.Forwarder.SubmitV1Intake(payloads, jsonExtraHeadersWithCompression); err != nil {
 		return err
 	}
 
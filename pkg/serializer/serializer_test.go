@@ -27,7 +27,9 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/metrics/event"
 	"github.com/DataDog/datadog-agent/pkg/metrics/servicecheck"
 	metricsserializer "github.com/DataDog/datadog-agent/pkg/serializer/internal/metrics"
+	"github.com/DataDog/datadog-agent/pkg/serializer/internal/stream"
 	"github.com/DataDog/datadog-agent/pkg/serializer/marshaler"
+	"github.com/DataDog/datadog-agent/pkg/tagset"
 	"github.com/DataDog/datadog-agent/pkg/util/compression"
 )
 
@@ -319,6 +321,52 @@ func TestSendSeries(t *testing.T) {
 	f.AssertExpectations(t)
 }
 
+func TestSendRoutedSeries(t *testing.T) {
+	const paymentsDomain = "https://payments.example.com"
+	mainDomain, _ := config.AddAgentVersionToDomain("https://app.datadoghq.com", "app")
+
+	config.Datadog.Set("use_v2_api.series", true) // default value, but just to be sure
+	config.Datadog.Set("api_key", "key")
+	defer config.Datadog.Set("api_key", "")
+	config.Datadog.Set("additional_endpoints", map[string][]string{paymentsDomain: {"key"}})
+	defer config.Datadog.Set("additional_endpoints", map[string][]string{})
+	config.Datadog.Set("forwarder_routing_rules", []map[string]interface{}{
+		{"name": "payments", "tags": []string{"team:payments"}, "domains": []string{paymentsDomain}},
+		{"name": "metadata", "payload_types": []string{"metadata"}, "domains": []string{paymentsDomain}},
+	})
+	defer config.Datadog.Set("forwarder_routing_rules", nil)
+
+	// series are routed while they are streamed, into one payload per route
+	routed := mock.MatchedBy(func(payloads transaction.BytesPayloads) bool {
+		return len(payloads) == 2 &&
+			reflect.DeepEqual(payloads[0].GetDestinations(), []string{paymentsDomain}) &&
+			payloads[0].GetPointCount() == 2 &&
+			reflect.DeepEqual(payloads[1].GetDestinations(), []string{mainDomain}) &&
+			payloads[1].GetPointCount() == 1
+	})
+	f := &forwarder.MockedForwarder{}
+	f.On("SubmitSeries", routed, protobufExtraHeadersWithCompression).Return(nil).Times(1)
+
+	s := NewSerializer(f, nil)
+	require.NotNil(t, s.router)
+
+	series := metrics.Series{
+		{Name: "payments.a", Points: []metrics.Point{{Ts: 10, Value: 1}}, Tags: tagset.CompositeTagsFromSlice([]string{"team:payments"})},
+		{Name: "other", Points: []metrics.Point{{Ts: 10, Value: 1}}, Tags: tagset.CompositeTagsFromSlice([]string{"team:core"})},
+		{Name: "payments.b", Points: []metrics.Point{{Ts: 10, Value: 1}}, Tags: tagset.CompositeTagsFromSlice([]string{"env:prod", "team:payments"})},
+	}
+	err := s.SendIterableSeries(metricsserializer.CreateSerieSource(series))
+	require.Nil(t, err)
+	f.AssertExpectations(t)
+
+	// whole payloads are routed by their type
+	f.On("SubmitMetadata", mock.MatchedBy(func(payloads transaction.BytesPayloads) bool {
+		return len(payloads) == 1 && reflect.DeepEqual(payloads[0].GetDestinations(), []string{paymentsDomain})
+	}), jsonExtraHeadersWithCompression).Return(nil).Times(1)
+	require.Nil(t, s.SendMetadata(&testPayload{}))
+	f.AssertExpectations(t)
+}
+
 func TestSendSketch(t *testing.T) {
 	f := &forwarder.MockedForwarder{}
 
@@ -331,6 +379,42 @@ func TestSendSketch(t *testing.T) {
 	f.AssertExpectations(t)
 }
 
+func TestSendSeriesWithZstd(t *testing.T) {
+	if !stream.Available {
+		t.Skip("the stream compressor is not compiled in")
+	}
+	config.Datadog.Set("serializer_compressor_kind", compression.ZstdKind)
+	defer config.Datadog.Set("serializer_compressor_kind", nil)
+	config.Datadog.Set("use_v2_api.series", true) // default value, but just to be sure
+
+	zstd, err := compression.NewCompressor(compression.ZstdKind, 1)
+	require.NoError(t, err)
+	matcher := mock.MatchedBy(func(payloads transaction.BytesPayloads) bool {
+		for _, compressedPayload := range payloads {
+			if _, err := zstd.Decompress(compressedPayload.GetContent()); err != nil {
+				return false
+			}
+		}
+		return len(payloads) == 1
+	})
+	headers := protobufExtraHeaders.Clone()
+	headers.Set("Content-Encoding", "zstd")
+
+	f := &forwarder.MockedForwarder{}
+	f.On("SubmitSeries", matcher, headers).Return(nil).Times(1)
+	f.On("SubmitV1CheckRuns", mock.Anything, mock.MatchedBy(func(h http.Header) bool {
+		return h.Get("Content-Encoding") == "zstd"
+	})).Return(nil).Times(1)
+	// events keep the default compression
+	f.On("SubmitV1Intake", mock.Anything, jsonExtraHeadersWithCompression).Return(nil).Times(1)
+
+	s := NewSerializer(f, nil)
+	require.Nil(t, s.SendIterableSeries(metricsserializer.CreateSerieSource(metrics.Series{&metrics.Serie{}})))
+	require.Nil(t, s.SendServiceChecks(servicecheck.ServiceChecks{&servicecheck.ServiceCheck{}}))
+	require.Nil(t, s.SendEvents([]*event.Event{}))
+	f.AssertExpectations(t)
+}
+
 func TestSendMetadata(t *testing.T) {
 	f := &forwarder.MockedForwarder{}
 	f.On("SubmitMetadata", jsonPayloads, jsonExtraHeadersWithCompression).Return(nil).Times(1)
@@ -0,0 +1,146 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023-present Datadog, Inc.
+
+package gosnmplib
+
+import (
+	"bufio"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/gosnmp/gosnmp"
+)
+
+// The snmprec format, used by snmpsim, records a walk with one `<oid>|<tag>|<value>`
+// line per variable, where the tag is the ASN.1 BER type of the variable. The `x`
+// suffix of the tag means the value is hex encoded.
+// See https://github.com/etingof/snmpsim/blob/master/docs/source/documentation/building-simulation-data.rst
+
+// WriteSnmprecRecord writes a variable as a snmprec line
+func WriteSnmprecRecord(w io.Writer, pdu gosnmp.SnmpPDU) error {
+	oid := strings.TrimLeft(pdu.Name, ".")
+	var tag, value string
+	switch pdu.Type {
+	case gosnmp.OctetString, gosnmp.BitString, gosnmp.Opaque:
+		bytesValue, ok := pdu.Value.([]byte)
+		if !ok {
+			return fmt.Errorf("oid %s: %s should be []byte type but got type `%T`", oid, pdu.Type, pdu.Value)
+		}
+		tag = strconv.Itoa(int(pdu.Type))
+		if IsStringPrintable(bytesValue) && !strings.ContainsAny(string(bytesValue), "\n\r") {
+			value = string(bytesValue)
+		} else {
+			tag += "x"
+			value = hex.EncodeToString(bytesValue)
+		}
+	case gosnmp.Integer, gosnmp.Counter32, gosnmp.Gauge32, gosnmp.TimeTicks, gosnmp.Counter64, gosnmp.Uinteger32:
+		tag = strconv.Itoa(int(pdu.Type))
+		value = gosnmp.ToBigInt(pdu.Value).String()
+	case gosnmp.OpaqueFloat, gosnmp.OpaqueDouble:
+		floatValue, err := GetValueFromPDU(pdu)
+		if err != nil {
+			return err
+		}
+		tag = strconv.Itoa(int(pdu.Type))
+		value = strconv.FormatFloat(floatValue.(float64), 'g', -1, 64)
+	case gosnmp.IPAddress, gosnmp.ObjectIdentifier:
+		strValue, ok := pdu.Value.(string)
+		if !ok {
+			return fmt.Errorf("oid %s: %s should be string type but got type `%T`", oid, pdu.Type, pdu.Value)
+		}
+		tag = strconv.Itoa(int(pdu.Type))
+		value = strings.TrimLeft(strValue, ".")
+	case gosnmp.Null:
+		tag = strconv.Itoa(int(pdu.Type))
+	default:
+		return fmt.Errorf("oid %s: unsupported type: %s", oid, pdu.Type)
+	}
+	_, err := fmt.Fprintf(w, "%s|%s|%s\n", oid, tag, value)
+	return err
+}
+
+// ReadSnmprec reads the variables of a walk recorded in the snmprec format,
+// with their values in the types used by gosnmp
+func ReadSnmprec(r io.Reader) ([]gosnmp.SnmpPDU, error) {
+	var pdus []gosnmp.SnmpPDU
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		pdu, err := parseSnmprecRecord(line)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %s", lineNumber, err)
+		}
+		pdus = append(pdus, pdu)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return pdus, nil
+}
+
+func parseSnmprecRecord(line string) (gosnmp.SnmpPDU, error) {
+	parts := strings.SplitN(line, "|", 3)
+	if len(parts) != 3 {
+		return gosnmp.SnmpPDU{}, fmt.Errorf("expected `<oid>|<tag>|<value>` but got `%s`", line)
+	}
+	oid, tag, value := strings.TrimLeft(parts[0], "."), parts[1], parts[2]
+
+	hexEncoded := strings.HasSuffix(tag, "x")
+	tag = strings.TrimSuffix(tag, "x")
+	tagValue, err := strconv.ParseUint(tag, 10, 8)
+	if err != nil {
+		return gosnmp.SnmpPDU{}, fmt.Errorf("oid %s: invalid tag `%s`", oid, parts[1])
+	}
+	if hexEncoded {
+		decoded, err := hex.DecodeString(value)
+		if err != nil {
+			return gosnmp.SnmpPDU{}, fmt.Errorf("oid %s: invalid hex value `%s`: %s", oid, value, err)
+		}
+		value = string(decoded)
+	}
+
+	pdu := gosnmp.SnmpPDU{Name: oid, Type: gosnmp.Asn1BER(tagValue)}
+	switch pdu.Type {
+	case gosnmp.OctetString, gosnmp.BitString, gosnmp.Opaque:
+		pdu.Value = []byte(value)
+	case gosnmp.Integer:
+		pdu.Value, err = strconv.Atoi(value)
+	case gosnmp.Counter32, gosnmp.Gauge32:
+		var uintValue uint64
+		uintValue, err = strconv.ParseUint(value, 10, 32)
+		pdu.Value = uint(uintValue)
+	case gosnmp.TimeTicks, gosnmp.Uinteger32:
+		var uintValue uint64
+		uintValue, err = strconv.ParseUint(value, 10, 32)
+		pdu.Value = uint32(uintValue)
+	case gosnmp.Counter64:
+		pdu.Value, err = strconv.ParseUint(value, 10, 64)
+	case gosnmp.OpaqueFloat:
+		var floatValue float64
+		floatValue, err = strconv.ParseFloat(value, 32)
+		pdu.Value = float32(floatValue)
+	case gosnmp.OpaqueDouble:
+		pdu.Value, err = strconv.ParseFloat(value, 64)
+	case gosnmp.IPAddress, gosnmp.ObjectIdentifier:
+		pdu.Value = value
+	case gosnmp.Null:
+		pdu.Value = nil
+	default:
+		return gosnmp.SnmpPDU{}, fmt.Errorf("oid %s: unsupported tag `%s`", oid, parts[1])
+	}
+	if err != nil {
+		return gosnmp.SnmpPDU{}, fmt.Errorf("oid %s: invalid %s value `%s`: %s", oid, pdu.Type, value, err)
+	}
+	return pdu, nil
+}
@@ -0,0 +1,80 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023-present Datadog, Inc.
+
+package gosnmplib
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/gosnmp/gosnmp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSnmprec(t *testing.T) {
+	pdus := []gosnmp.SnmpPDU{
+		{Name: ".1.3.6.1.2.1.1.1.0", Type: gosnmp.OctetString, Value: []byte("Cisco IOS")},
+		{Name: ".1.3.6.1.2.1.1.2.0", Type: gosnmp.ObjectIdentifier, Value: ".1.3.6.1.4.1.9.1.1"},
+		{Name: ".1.3.6.1.2.1.1.3.0", Type: gosnmp.TimeTicks, Value: uint32(4226041)},
+		{Name: ".1.3.6.1.2.1.2.2.1.6.1", Type: gosnmp.OctetString, Value: []byte{0x00, 0x1c, 0x73, 0x0a, 0xff, 0x01}},
+		{Name: ".1.3.6.1.2.1.2.2.1.7.1", Type: gosnmp.Integer, Value: -1},
+		{Name: ".1.3.6.1.2.1.2.2.1.10.1", Type: gosnmp.Counter32, Value: uint(123)},
+		{Name: ".1.3.6.1.2.1.31.1.1.1.6.1", Type: gosnmp.Counter64, Value: uint64(18446744073709551615)},
+		{Name: ".1.3.6.1.2.1.31.1.1.1.15.1", Type: gosnmp.Gauge32, Value: uint(1000)},
+		{Name: ".1.3.6.1.2.1.4.20.1.1.10.0.0.1", Type: gosnmp.IPAddress, Value: "10.0.0.1"},
+		{Name: ".1.3.6.1.4.1.2021.10.1.6.1", Type: gosnmp.OpaqueFloat, Value: float32(0.5)},
+	}
+
+	var buf bytes.Buffer
+	for _, pdu := range pdus {
+		require.NoError(t, WriteSnmprecRecord(&buf, pdu))
+	}
+	assert.Equal(t, `1.3.6.1.2.1.1.1.0|4|Cisco IOS
+1.3.6.1.2.1.1.2.0|6|1.3.6.1.4.1.9.1.1
+1.3.6.1.2.1.1.3.0|67|4226041
+1.3.6.1.2.1.2.2.1.6.1|4x|001c730aff01
+1.3.6.1.2.1.2.2.1.7.1|2|-1
+1.3.6.1.2.1.2.2.1.10.1|65|123
+1.3.6.1.2.1.31.1.1.1.6.1|70|18446744073709551615
+1.3.6.1.2.1.31.1.1.1.15.1|66|1000
+1.3.6.1.2.1.4.20.1.1.10.0.0.1|64|10.0.0.1
+1.3.6.1.4.1.2021.10.1.6.1|120|0.5
+`, buf.String())
+
+	readPdus, err := ReadSnmprec(&buf)
+	require.NoError(t, err)
+	require.Len(t, readPdus, len(pdus))
+	for i, pdu := range pdus {
+		expectedValue, err := GetValueFromPDU(pdu)
+		require.NoError(t, err)
+		value, err := GetValueFromPDU(readPdus[i])
+		require.NoError(t, err)
+		assert.Equal(t, strings.TrimLeft(pdu.Name, "."), readPdus[i].Name)
+		assert.Equal(t, pdu.Type, readPdus[i].Type)
+		assert.Equal(t, expectedValue, value)
+	}
+}
+
+func TestReadSnmprec_errors(t *testing.T) {
+	tests := []struct {
+		name          string
+		content       string
+		expectedError string
+	}{
+		{"missing value", "1.3.6.1.2.1.1.1.0|4", "line 1: expected `<oid>|<tag>|<value>`"},
+		{"invalid tag", "# comment\n1.3.6.1.2.1.1.1.0|abc|foo", "line 2: oid 1.3.6.1.2.1.1.1.0: invalid tag `abc`"},
+		{"unsupported tag", "1.3.6.1.2.1.1.1.0|99|foo", "unsupported tag `99`"},
+		{"invalid hex", "1.3.6.1.2.1.1.1.0|4x|zz", "invalid hex value"},
+		{"invalid integer", "1.3.6.1.2.1.1.1.0|2|abc", "invalid Integer value `abc`"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ReadSnmprec(strings.NewReader(tt.content))
+			assert.ErrorContains(t, err, tt.expectedError)
+		})
+	}
+}
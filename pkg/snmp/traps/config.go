@@ -6,9 +6,12 @@
 package traps
 
 import (
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"hash/fnv"
+	"net"
+	"strconv"
 
 	"github.com/gosnmp/gosnmp"
 
@@ -32,17 +35,55 @@ type UserV3 struct {
 	PrivProtocol string `mapstructure:"privProtocol" yaml:"privProtocol"`
 }
 
+// DeduplicationConfig contains the configuration of the deduplication of the
+// identical traps sent by a device, e.g. by a flapping interface.
+type DeduplicationConfig struct {
+	Enabled bool `mapstructure:"enabled" yaml:"enabled"`
+	Window  int  `mapstructure:"window" yaml:"window"` // in seconds
+}
+
+// MetricRule converts the traps with the given OID into a metric.
+type MetricRule struct {
+	TrapOID  string          `mapstructure:"trap_oid" yaml:"trap_oid"`
+	Name     string          `mapstructure:"name" yaml:"name"`
+	Type     string          `mapstructure:"type" yaml:"type"`           // count or gauge
+	ValueOID string          `mapstructure:"value_oid" yaml:"value_oid"` // gauge only
+	Tags     []MetricRuleTag `mapstructure:"tags" yaml:"tags"`
+}
+
+// MetricRuleTag tags the metric of a MetricRule with the value of a trap variable.
+type MetricRuleTag struct {
+	OID string `mapstructure:"oid" yaml:"oid"`
+	Tag string `mapstructure:"tag" yaml:"tag"`
+}
+
+// RelayConfig contains the definition of a downstream trap receiver the received
+// traps are relayed to, as SNMPv2c or SNMPv3 traps.
+type RelayConfig struct {
+	Host      string `mapstructure:"host" yaml:"host"`
+	Port      uint16 `mapstructure:"port" yaml:"port"`
+	Version   string `mapstructure:"version" yaml:"version"`     // 2c or 3
+	Community string `mapstructure:"community" yaml:"community"` // v2c only
+	// SNMPv3 only: the user and the authoritative engine ID (hex) to send the
+	// traps with, the engine ID of the Agent by default
+	UserV3   `mapstructure:",squash" yaml:",inline"`
+	EngineID string `mapstructure:"engine_id" yaml:"engine_id"`
+}
+
 // Config contains configuration for SNMP trap listeners.
 // YAML field tags provided for test marshalling purposes.
 type Config struct {
-	Enabled               bool     `mapstructure:"enabled" yaml:"enabled"`
-	Port                  uint16   `mapstructure:"port" yaml:"port"`
-	Users                 []UserV3 `mapstructure:"users" yaml:"users"`
-	CommunityStrings      []string `mapstructure:"community_strings" yaml:"community_strings"`
-	BindHost              string   `mapstructure:"bind_host" yaml:"bind_host"`
-	StopTimeout           int      `mapstructure:"stop_timeout" yaml:"stop_timeout"`
-	Namespace             string   `mapstructure:"namespace" yaml:"namespace"`
-	authoritativeEngineID string   `mapstructure:"-" yaml:"-"`
+	Enabled               bool                `mapstructure:"enabled" yaml:"enabled"`
+	Port                  uint16              `mapstructure:"port" yaml:"port"`
+	Users                 []UserV3            `mapstructure:"users" yaml:"users"`
+	CommunityStrings      []string            `mapstructure:"community_strings" yaml:"community_strings"`
+	BindHost              string              `mapstructure:"bind_host" yaml:"bind_host"`
+	StopTimeout           int                 `mapstructure:"stop_timeout" yaml:"stop_timeout"`
+	Namespace             string              `mapstructure:"namespace" yaml:"namespace"`
+	Deduplication         DeduplicationConfig `mapstructure:"deduplication" yaml:"deduplication"`
+	Metrics               []MetricRule        `mapstructure:"metrics" yaml:"metrics"`
+	Relays                []RelayConfig       `mapstructure:"relays" yaml:"relays"`
+	authoritativeEngineID string              `mapstructure:"-" yaml:"-"`
 }
 
 // ReadConfig builds and returns configuration from Agent configuration.
@@ -73,6 +114,17 @@ func ReadConfig(agentHostname string) (*Config, error) {
 	if c.StopTimeout == 0 {
 		c.StopTimeout = defaultStopTimeout
 	}
+	if c.Deduplication.Window == 0 {
+		c.Deduplication.Window = defaultDeduplicationWindow
+	}
+	if c.Deduplication.Window < 0 {
+		return nil, fmt.Errorf("invalid deduplication window: %d", c.Deduplication.Window)
+	}
+	for i := range c.Metrics {
+		if err := c.Metrics[i].normalize(); err != nil {
+			return nil, fmt.Errorf("invalid metric rule %d: %w", i, err)
+		}
+	}
 
 	if agentHostname == "" {
 		// Make sure to have at least some unique bytes for the authoritative engineID.
@@ -87,6 +139,12 @@ func ReadConfig(agentHostname string) (*Config, error) {
 	engineID := h.Sum([]byte{0x80, 0xff, 0xff, 0xff, 0xff})
 	c.authoritativeEngineID = string(engineID)
 
+	for i := range c.Relays {
+		if err := c.Relays[i].normalize(c.authoritativeEngineID); err != nil {
+			return nil, fmt.Errorf("invalid relay %d: %w", i, err)
+		}
+	}
+
 	if c.Namespace == "" {
 		c.Namespace = config.Datadog.GetString("network_devices.namespace")
 	}
@@ -98,6 +156,130 @@ func ReadConfig(agentHostname string) (*Config, error) {
 	return &c, nil
 }
 
+// normalize validates a metric rule and sets its defaults
+func (r *MetricRule) normalize() error {
+	r.TrapOID = NormalizeOID(r.TrapOID)
+	if !IsValidOID(r.TrapOID) || r.TrapOID == "" {
+		return fmt.Errorf("invalid trap_oid: %q", r.TrapOID)
+	}
+	if r.Name == "" {
+		return errors.New("name is required")
+	}
+	switch r.Type {
+	case "":
+		r.Type = metricTypeCount
+	case metricTypeCount:
+	case metricTypeGauge:
+		r.ValueOID = NormalizeOID(r.ValueOID)
+		if !IsValidOID(r.ValueOID) || r.ValueOID == "" {
+			return fmt.Errorf("invalid value_oid: %q", r.ValueOID)
+		}
+	default:
+		return fmt.Errorf("invalid type: %q, must be %s or %s", r.Type, metricTypeCount, metricTypeGauge)
+	}
+	for i, tag := range r.Tags {
+		r.Tags[i].OID = NormalizeOID(tag.OID)
+		if !IsValidOID(r.Tags[i].OID) || r.Tags[i].OID == "" {
+			return fmt.Errorf("invalid tag oid: %q", tag.OID)
+		}
+		if tag.Tag == "" {
+			return fmt.Errorf("missing tag name for oid %s", r.Tags[i].OID)
+		}
+	}
+	return nil
+}
+
+// normalize validates a relay and sets its defaults
+func (r *RelayConfig) normalize(agentEngineID string) error {
+	if r.Host == "" {
+		return errors.New("host is required")
+	}
+	if r.Port == 0 {
+		r.Port = defaultRelayPort
+	}
+	switch r.Version {
+	case "", relayVersion2c:
+		r.Version = relayVersion2c
+		if r.Community == "" {
+			return errors.New("community is required for SNMPv2c relays")
+		}
+	case relayVersion3:
+		if r.Username == "" {
+			return errors.New("user is required for SNMPv3 relays")
+		}
+		if r.EngineID == "" {
+			r.EngineID = hex.EncodeToString([]byte(agentEngineID))
+		}
+		if _, err := hex.DecodeString(r.EngineID); err != nil {
+			return fmt.Errorf("invalid engine_id %q: %w", r.EngineID, err)
+		}
+		if _, err := gosnmplib.GetAuthProtocol(r.AuthProtocol); err != nil {
+			return err
+		}
+		if _, err := gosnmplib.GetPrivProtocol(r.PrivProtocol); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("invalid version %q, must be %s or %s", r.Version, relayVersion2c, relayVersion3)
+	}
+	return nil
+}
+
+// Target returns the host:port address of the relay.
+func (r *RelayConfig) Target() string {
+	return net.JoinHostPort(r.Host, strconv.Itoa(int(r.Port)))
+}
+
+// BuildSNMPParams returns the GoSNMP params used to send traps to the relay.
+func (r *RelayConfig) BuildSNMPParams() (*gosnmp.GoSNMP, error) {
+	params := &gosnmp.GoSNMP{
+		Target:    r.Host,
+		Port:      r.Port,
+		Transport: "udp",
+		Timeout:   defaultRelayTimeout,
+		Retries:   1, // Must be non-zero when sending traps.
+		Logger:    gosnmp.NewLogger(&trapLogger{}),
+	}
+	if r.Version == relayVersion2c {
+		params.Version = gosnmp.Version2c
+		params.Community = r.Community
+		return params, nil
+	}
+
+	authProtocol, err := gosnmplib.GetAuthProtocol(r.AuthProtocol)
+	if err != nil {
+		return nil, err
+	}
+	privProtocol, err := gosnmplib.GetPrivProtocol(r.PrivProtocol)
+	if err != nil {
+		return nil, err
+	}
+	engineID, err := hex.DecodeString(r.EngineID)
+	if err != nil {
+		return nil, err
+	}
+
+	msgFlags := gosnmp.NoAuthNoPriv
+	if r.PrivKey != "" {
+		msgFlags = gosnmp.AuthPriv
+	} else if r.AuthKey != "" {
+		msgFlags = gosnmp.AuthNoPriv
+	}
+
+	params.Version = gosnmp.Version3
+	params.SecurityModel = gosnmp.UserSecurityModel
+	params.MsgFlags = msgFlags
+	params.SecurityParameters = &gosnmp.UsmSecurityParameters{
+		UserName:                 r.Username,
+		AuthoritativeEngineID:    string(engineID),
+		AuthenticationProtocol:   authProtocol,
+		AuthenticationPassphrase: r.AuthKey,
+		PrivacyProtocol:          privProtocol,
+		PrivacyPassphrase:        r.PrivKey,
+	}
+	return params, nil
+}
+
 // Addr returns the host:port address to listen on.
 func (c *Config) Addr() string {
 	return fmt.Sprintf("%s:%d", c.BindHost, c.Port)
@@ -150,3 +150,89 @@ func TestNamespaceSetBothGloballyAndLocally(t *testing.T) {
 
 	assert.Equal(t, "bar", config.Namespace)
 }
+
+func TestDeduplicationAndMetricsConfig(t *testing.T) {
+	Configure(t, Config{
+		Deduplication: DeduplicationConfig{Enabled: true},
+		Metrics: []MetricRule{
+			{
+				TrapOID: ".1.3.6.1.6.3.1.1.5.3",
+				Name:    "snmp.traps.link_down",
+				Tags:    []MetricRuleTag{{OID: ".1.3.6.1.2.1.2.2.1.1", Tag: "interface_index"}},
+			},
+			{
+				TrapOID:  "1.3.6.1.4.1.8072.2.3.0.1",
+				Name:     "snmp.traps.heartbeat_rate",
+				Type:     "gauge",
+				ValueOID: ".1.3.6.1.4.1.8072.2.3.2.1",
+			},
+		},
+	})
+	config, err := ReadConfig("")
+	assert.NoError(t, err)
+	assert.Equal(t, DeduplicationConfig{Enabled: true, Window: 60}, config.Deduplication)
+	assert.Equal(t, []MetricRule{
+		{
+			TrapOID: "1.3.6.1.6.3.1.1.5.3",
+			Name:    "snmp.traps.link_down",
+			Type:    "count",
+			Tags:    []MetricRuleTag{{OID: "1.3.6.1.2.1.2.2.1.1", Tag: "interface_index"}},
+		},
+		{
+			TrapOID:  "1.3.6.1.4.1.8072.2.3.0.1",
+			Name:     "snmp.traps.heartbeat_rate",
+			Type:     "gauge",
+			ValueOID: "1.3.6.1.4.1.8072.2.3.2.1",
+			Tags:     []MetricRuleTag{},
+		},
+	}, config.Metrics)
+}
+
+func TestInvalidMetricRules(t *testing.T) {
+	for _, rule := range []MetricRule{
+		{TrapOID: "1.3.6.1.6.3.1.1.5.3"},
+		{TrapOID: "1.3.6..1", Name: "snmp.traps.link_down"},
+		{TrapOID: "1.3.6.1.6.3.1.1.5.3", Name: "snmp.traps.link_down", Type: "rate"},
+		{TrapOID: "1.3.6.1.6.3.1.1.5.3", Name: "snmp.traps.link_down", Type: "gauge"},
+		{TrapOID: "1.3.6.1.6.3.1.1.5.3", Name: "snmp.traps.link_down", Tags: []MetricRuleTag{{OID: "1.3.6.1.2.1.2.2.1.1"}}},
+	} {
+		Configure(t, Config{Metrics: []MetricRule{rule}})
+		_, err := ReadConfig("")
+		assert.Error(t, err, "rule: %+v", rule)
+	}
+
+	Configure(t, Config{Deduplication: DeduplicationConfig{Enabled: true, Window: -1}})
+	_, err := ReadConfig("")
+	assert.Error(t, err)
+}
+
+func TestRelaysConfig(t *testing.T) {
+	Configure(t, Config{
+		Relays: []RelayConfig{
+			{Host: "10.0.0.1", Community: "noc"},
+			{Host: "10.0.0.2", Port: 1162, Version: "3", UserV3: UserV3{Username: "noc", AuthKey: "password", AuthProtocol: "SHA"}},
+		},
+	})
+	config, err := ReadConfig(mockedHostname)
+	assert.NoError(t, err)
+	assert.Equal(t, []RelayConfig{
+		{Host: "10.0.0.1", Port: 162, Version: "2c", Community: "noc"},
+		{
+			Host:     "10.0.0.2",
+			Port:     1162,
+			Version:  "3",
+			UserV3:   UserV3{Username: "noc", AuthKey: "password", AuthProtocol: "SHA"},
+			EngineID: "80ffffffff67b20fe4df737ace2847038f57e65c98",
+		},
+	}, config.Relays)
+
+	params, err := config.Relays[1].BuildSNMPParams()
+	assert.NoError(t, err)
+	assert.Equal(t, gosnmp.Version3, params.Version)
+	assert.Equal(t, gosnmp.AuthNoPriv, params.MsgFlags)
+	assert.Equal(t, expectedEngineID, params.SecurityParameters.(*gosnmp.UsmSecurityParameters).AuthoritativeEngineID)
+
+	Configure(t, Config{Relays: []RelayConfig{{Host: "10.0.0.1"}}})
+	_, err = ReadConfig(mockedHostname)
+	assert.Error(t, err)
+}
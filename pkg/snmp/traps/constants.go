@@ -5,9 +5,23 @@
 
 package traps
 
+import "time"
+
 const (
 	defaultPort        = uint16(9162) // Standard UDP port for traps.
 	defaultStopTimeout = 5
 	packetsChanSize    = 100
 	genericTrapOid     = "1.3.6.1.6.3.1.1.5"
+
+	defaultDeduplicationWindow = 60 // in seconds
+	deduplicationFlushInterval = time.Second
+
+	metricTypeCount = "count"
+	metricTypeGauge = "gauge"
+
+	defaultRelayPort    = uint16(162)
+	defaultRelayTimeout = 2 * time.Second
+	relayQueueSize      = 1000 // traps waiting to be relayed, per relay
+	relayVersion2c      = "2c"
+	relayVersion3       = "3"
 )
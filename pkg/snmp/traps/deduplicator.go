@@ -0,0 +1,105 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023-present Datadog, Inc.
+
+package traps
+
+import (
+	"fmt"
+	"hash/fnv"
+	"time"
+
+	"github.com/gosnmp/gosnmp"
+)
+
+// duplicatesEntry holds the identical traps received during a window
+type duplicatesEntry struct {
+	windowEnd time.Time
+	repeats   int
+	last      *SnmpPacket
+}
+
+// trapDeduplicator suppresses the traps identical to one received less than
+// a window ago: the first trap is forwarded right away, and the last of its
+// duplicates is forwarded with their count once the window is over
+type trapDeduplicator struct {
+	window  time.Duration
+	entries map[uint64]*duplicatesEntry
+	timeNow func() time.Time
+}
+
+func newTrapDeduplicator(window time.Duration) *trapDeduplicator {
+	return &trapDeduplicator{
+		window:  window,
+		entries: make(map[uint64]*duplicatesEntry),
+		timeNow: time.Now,
+	}
+}
+
+// add returns whether the packet must be forwarded, i.e. it isn't a duplicate
+// of a trap received during the current window. When the packet starts a new
+// window before the previous one was flushed, the packet summarizing the
+// duplicates of the previous window is returned too, to be forwarded first.
+func (d *trapDeduplicator) add(packet *SnmpPacket) (bool, *SnmpPacket) {
+	now := d.timeNow()
+	key := trapHash(packet)
+	entry, ok := d.entries[key]
+	if ok && now.Before(entry.windowEnd) {
+		entry.repeats++
+		entry.last = packet
+		return false, nil
+	}
+	var summary *SnmpPacket
+	if ok {
+		summary = entry.summary()
+	}
+	d.entries[key] = &duplicatesEntry{windowEnd: now.Add(d.window)}
+	return true, summary
+}
+
+// flush ends the windows that are over, or all of them if `all` is set, and
+// returns the packets summarizing their duplicates
+func (d *trapDeduplicator) flush(all bool) []*SnmpPacket {
+	now := d.timeNow()
+	var packets []*SnmpPacket
+	for key, entry := range d.entries {
+		if !all && now.Before(entry.windowEnd) {
+			continue
+		}
+		delete(d.entries, key)
+		if packet := entry.summary(); packet != nil {
+			packets = append(packets, packet)
+		}
+	}
+	return packets
+}
+
+// summary returns the last duplicate with their count, or nil if the window
+// had no duplicates
+func (e *duplicatesEntry) summary() *SnmpPacket {
+	if e.repeats == 0 {
+		return nil
+	}
+	packet := *e.last
+	packet.RepeatCount = e.repeats
+	return &packet
+}
+
+// trapHash identifies the identical traps: same device, trap OID and variables
+func trapHash(packet *SnmpPacket) uint64 {
+	content := packet.Content
+	h := fnv.New64()
+	fmt.Fprintf(h, "%s|%s|%d|", packet.Namespace, packet.Addr.IP.String(), content.Version)
+	variables := content.Variables
+	if content.Version == gosnmp.Version1 {
+		fmt.Fprintf(h, "%s|%d|%d|", content.Enterprise, content.GenericTrap, content.SpecificTrap)
+	} else if len(variables) > 0 {
+		// skip the sysUpTime, it differs for each trap
+		variables = variables[1:]
+	}
+	for _, variable := range variables {
+		fmt.Fprintf(h, "%s=%d:%v|", NormalizeOID(variable.Name), variable.Type, variable.Value)
+	}
+	return h.Sum64()
+}
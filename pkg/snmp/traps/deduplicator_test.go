@@ -0,0 +1,92 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023-present Datadog, Inc.
+
+package traps
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/gosnmp/gosnmp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTrapDeduplicator(t *testing.T) {
+	now := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	deduplicator := newTrapDeduplicator(time.Minute)
+	deduplicator.timeNow = func() time.Time { return now }
+	add := func(packet *SnmpPacket) bool {
+		forward, summary := deduplicator.add(packet)
+		assert.Nil(t, summary)
+		return forward
+	}
+
+	linkDown := createTestPacket(NetSNMPExampleHeartbeatNotification)
+	assert.True(t, add(linkDown))
+
+	// the sysUpTime of the identical traps differs
+	duplicate := createTestPacket(NetSNMPExampleHeartbeatNotification)
+	duplicate.Content.Variables = append([]gosnmp.SnmpPDU{
+		{Name: "1.3.6.1.2.1.1.3.0", Type: gosnmp.TimeTicks, Value: uint32(2000)},
+	}, NetSNMPExampleHeartbeatNotification.Variables[1:]...)
+	now = now.Add(10 * time.Second)
+	assert.False(t, add(duplicate))
+	assert.False(t, add(duplicate))
+
+	// traps of other devices or with other variables aren't duplicates
+	otherDevice := createTestPacket(NetSNMPExampleHeartbeatNotification)
+	otherDevice.Addr = &net.UDPAddr{IP: net.ParseIP("127.0.0.2"), Port: 13156}
+	assert.True(t, add(otherDevice))
+	linkUp := createTestV1Packet(LinkDownv1GenericTrap)
+	linkUp.Content.GenericTrap = 3
+	assert.True(t, add(createTestV1Packet(LinkDownv1GenericTrap)))
+	assert.True(t, add(linkUp))
+
+	assert.Empty(t, deduplicator.flush(false))
+
+	now = now.Add(time.Minute)
+	packets := deduplicator.flush(false)
+	require.Len(t, packets, 1)
+	assert.Equal(t, 2, packets[0].RepeatCount)
+	assert.Equal(t, duplicate.Content, packets[0].Content)
+	assert.Equal(t, 0, duplicate.RepeatCount)
+	assert.Empty(t, deduplicator.entries)
+
+	// a new window starts once the previous one is over
+	assert.True(t, add(duplicate))
+	assert.False(t, add(duplicate))
+	packets = deduplicator.flush(true)
+	require.Len(t, packets, 1)
+	assert.Equal(t, 1, packets[0].RepeatCount)
+}
+
+func TestTrapDeduplicatorWindowOverBeforeFlush(t *testing.T) {
+	now := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	deduplicator := newTrapDeduplicator(time.Minute)
+	deduplicator.timeNow = func() time.Time { return now }
+
+	packet := createTestPacket(NetSNMPExampleHeartbeatNotification)
+	forward, summary := deduplicator.add(packet)
+	assert.True(t, forward)
+	assert.Nil(t, summary)
+	now = now.Add(10 * time.Second)
+	forward, summary = deduplicator.add(packet)
+	assert.False(t, forward)
+	assert.Nil(t, summary)
+
+	// the window is over but wasn't flushed yet: the trap starts a new window
+	// and the duplicates of the previous one are summarized right away
+	now = now.Add(time.Minute)
+	forward, summary = deduplicator.add(packet)
+	assert.True(t, forward)
+	require.NotNil(t, summary)
+	assert.Equal(t, 1, summary.RepeatCount)
+	assert.Equal(t, packet.Content, summary.Content)
+
+	assert.Empty(t, deduplicator.flush(false))
+	assert.Len(t, deduplicator.entries, 1)
+}
@@ -66,6 +66,7 @@ func NewJSONFormatter(oidResolver OIDResolver, aggregator sender.Sender) (JSONFo
 //	   "uptime": "12345",
 //	   "genericTrap": "5", # v1 only
 //	   "specificTrap": "0",  # v1 only
+//	   "repeatCount": 42, # deduplicated traps only
 //	   "variables": [
 //	     {
 //	       "oid": "1.3.4.1....",
@@ -91,6 +92,9 @@ func (f JSONFormatter) FormatPacket(packet *SnmpPacket) ([]byte, error) {
 	formattedTrap["ddsource"] = ddsource
 	formattedTrap["ddtags"] = strings.Join(packet.getTags(), ",")
 	formattedTrap["timestamp"] = packet.Timestamp
+	if packet.RepeatCount > 0 {
+		formattedTrap["repeatCount"] = packet.RepeatCount
+	}
 	payload["trap"] = formattedTrap
 	return json.Marshal(payload)
 }
@@ -104,14 +108,7 @@ func (f JSONFormatter) formatV1Trap(packet *SnmpPacket) map[string]interface{} {
 	enterpriseOid := NormalizeOID(content.Enterprise)
 	genericTrap := content.GenericTrap
 	specificTrap := content.SpecificTrap
-	var trapOID string
-	if genericTrap == 6 {
-		// Vendor-specific trap
-		trapOID = fmt.Sprintf("%s.0.%d", enterpriseOid, specificTrap)
-	} else {
-		// Generic trap
-		trapOID = fmt.Sprintf("%s.%d", genericTrapOid, genericTrap+1)
-	}
+	trapOID := v1TrapOID(content)
 	data["snmpTrapOID"] = trapOID
 	trapMetadata, err := f.oidResolver.GetTrapMetadata(trapOID)
 	if err != nil {
@@ -6,6 +6,9 @@
 package traps
 
 import (
+	"fmt"
+	"time"
+
 	"github.com/DataDog/datadog-agent/pkg/aggregator/sender"
 	"github.com/DataDog/datadog-agent/pkg/epforwarder"
 	"github.com/DataDog/datadog-agent/pkg/util/log"
@@ -15,26 +18,50 @@ import (
 // The TrapForwarder is an intermediate step between the listener and the epforwarder in order to limit the processing of the listener
 // to the minimum. The forwarder process payloads received by the listener via the trapsIn channel, formats them and finally
 // give them to the epforwarder for sending it to Datadog.
+// The traps are also converted into metrics by the configured rules, and the identical
+// traps can be deduplicated before being forwarded. All the received traps are relayed to the
+// configured downstream trap receivers.
 type TrapForwarder struct {
-	trapsIn   PacketsChannel
-	formatter Formatter
-	sender    sender.Sender
-	stopChan  chan struct{}
+	trapsIn      PacketsChannel
+	formatter    Formatter
+	sender       sender.Sender
+	metricRules  []MetricRule
+	deduplicator *trapDeduplicator // nil when the deduplication is disabled
+	relays       []*trapRelay
+	stopChan     chan struct{}
 }
 
 // NewTrapForwarder creates a simple TrapForwarder instance
-func NewTrapForwarder(formatter Formatter, sender sender.Sender, packets PacketsChannel) (*TrapForwarder, error) {
+func NewTrapForwarder(config Config, formatter Formatter, sender sender.Sender, packets PacketsChannel) (*TrapForwarder, error) {
+	var deduplicator *trapDeduplicator
+	if config.Deduplication.Enabled {
+		deduplicator = newTrapDeduplicator(time.Duration(config.Deduplication.Window) * time.Second)
+	}
+	relays := make([]*trapRelay, 0, len(config.Relays))
+	for _, relayConfig := range config.Relays {
+		relay, err := newTrapRelay(relayConfig)
+		if err != nil {
+			return nil, fmt.Errorf("unable to configure relay %s: %w", relayConfig.Target(), err)
+		}
+		relays = append(relays, relay)
+	}
 	return &TrapForwarder{
-		trapsIn:   packets,
-		formatter: formatter,
-		sender:    sender,
-		stopChan:  make(chan struct{}),
+		trapsIn:      packets,
+		formatter:    formatter,
+		sender:       sender,
+		metricRules:  config.Metrics,
+		deduplicator: deduplicator,
+		relays:       relays,
+		stopChan:     make(chan struct{}),
 	}, nil
 }
 
 // Start the TrapForwarder instance. Need to Stop it manually
 func (tf *TrapForwarder) Start() {
 	log.Info("Starting TrapForwarder")
+	for _, relay := range tf.relays {
+		relay.start(tf.sender)
+	}
 	go tf.run()
 }
 
@@ -44,14 +71,53 @@ func (tf *TrapForwarder) Stop() {
 }
 
 func (tf *TrapForwarder) run() {
+	var flushChan <-chan time.Time
+	if tf.deduplicator != nil {
+		flushTicker := time.NewTicker(deduplicationFlushInterval)
+		defer flushTicker.Stop()
+		flushChan = flushTicker.C
+	}
 	for {
 		select {
 		case <-tf.stopChan:
+			tf.flushDuplicates(true)
+			for _, relay := range tf.relays {
+				relay.stop()
+			}
 			log.Info("Stopped TrapForwarder")
 			return
 		case packet := <-tf.trapsIn:
-			tf.sendTrap(packet)
+			tf.processTrap(packet)
+		case <-flushChan:
+			tf.flushDuplicates(false)
+		}
+	}
+}
+
+func (tf *TrapForwarder) processTrap(packet *SnmpPacket) {
+	for _, relay := range tf.relays {
+		relay.enqueue(tf.sender, packet)
+	}
+	submitTrapMetrics(tf.sender, tf.metricRules, packet)
+	if tf.deduplicator != nil {
+		forward, summary := tf.deduplicator.add(packet)
+		if summary != nil {
+			tf.sendTrap(summary)
 		}
+		if !forward {
+			tf.sender.Count("datadog.snmp_traps.deduplicated", 1, "", packet.getTags())
+			return
+		}
+	}
+	tf.sendTrap(packet)
+}
+
+func (tf *TrapForwarder) flushDuplicates(all bool) {
+	if tf.deduplicator == nil {
+		return
+	}
+	for _, packet := range tf.deduplicator.flush(all) {
+		tf.sendTrap(packet)
 	}
 }
 
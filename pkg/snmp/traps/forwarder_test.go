@@ -15,6 +15,7 @@ import (
 	"time"
 
 	"github.com/gosnmp/gosnmp"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	"github.com/DataDog/datadog-agent/pkg/aggregator/mocksender"
@@ -48,7 +49,7 @@ func createForwarder(t *testing.T) (forwarder *TrapForwarder, err error) {
 	config := Config{Port: serverPort, CommunityStrings: []string{"public"}, Namespace: "default"}
 	Configure(t, config)
 
-	forwarder, err = NewTrapForwarder(&DummyFormatter{}, mockSender, packetsIn)
+	forwarder, err = NewTrapForwarder(config, &DummyFormatter{}, mockSender, packetsIn)
 	if err != nil {
 		return nil, err
 	}
@@ -63,7 +64,7 @@ func makeSnmpPacket(trap gosnmp.SnmpTrap) *SnmpPacket {
 		Variables: trap.Variables,
 		SnmpTrap:  trap,
 	}
-	return &SnmpPacket{gosnmpPacket, simpleUDPAddr, "totoro", time.Now().UnixMilli()}
+	return &SnmpPacket{Content: gosnmpPacket, Addr: simpleUDPAddr, Namespace: "totoro", Timestamp: time.Now().UnixMilli()}
 }
 
 func TestV1GenericTrapAreForwarder(t *testing.T) {
@@ -110,3 +111,37 @@ func TestForwarderTelemetry(t *testing.T) {
 	forwarder.Stop()
 	sender.AssertMetric(t, "Count", "datadog.snmp_traps.forwarded", 1, "", []string{"snmp_device:1.1.1.1", "device_namespace:totoro", "snmp_version:2"})
 }
+
+func TestForwarderDeduplication(t *testing.T) {
+	mockSender := mocksender.NewMockSender("snmp-traps-listener")
+	mockSender.SetupAcceptAll()
+	config := Config{Deduplication: DeduplicationConfig{Enabled: true, Window: 60}}
+	forwarder, err := NewTrapForwarder(config, &DummyFormatter{}, mockSender, make(PacketsChannel))
+	require.NoError(t, err)
+	now := time.Now()
+	forwarder.deduplicator.timeNow = func() time.Time { return now }
+
+	for i := 0; i < 5; i++ {
+		forwarder.processTrap(makeSnmpPacket(NetSNMPExampleHeartbeatNotification))
+	}
+	mockSender.AssertNumberOfCalls(t, "EventPlatformEvent", 1)
+	mockSender.AssertMetric(t, "Count", "datadog.snmp_traps.deduplicated", 1, "", []string{"snmp_device:1.1.1.1", "device_namespace:totoro", "snmp_version:2"})
+	mockSender.AssertNumberOfCalls(t, "Count", 1+4) // forwarded + deduplicated
+
+	// the duplicates are forwarded with their count once the window is over
+	forwarder.flushDuplicates(false)
+	mockSender.AssertNumberOfCalls(t, "EventPlatformEvent", 1)
+	now = now.Add(time.Minute)
+	forwarder.flushDuplicates(false)
+	mockSender.AssertNumberOfCalls(t, "EventPlatformEvent", 2)
+	assert.Empty(t, forwarder.deduplicator.entries)
+
+	// a trap received after the end of the window, before the next flush,
+	// forwards the duplicates of the window then itself
+	forwarder.processTrap(makeSnmpPacket(NetSNMPExampleHeartbeatNotification))
+	forwarder.processTrap(makeSnmpPacket(NetSNMPExampleHeartbeatNotification))
+	mockSender.AssertNumberOfCalls(t, "EventPlatformEvent", 3)
+	now = now.Add(time.Minute)
+	forwarder.processTrap(makeSnmpPacket(NetSNMPExampleHeartbeatNotification))
+	mockSender.AssertNumberOfCalls(t, "EventPlatformEvent", 5)
+}
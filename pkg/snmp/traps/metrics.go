@@ -0,0 +1,89 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023-present Datadog, Inc.
+
+package traps
+
+import (
+	"strings"
+
+	"github.com/gosnmp/gosnmp"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator/sender"
+	"github.com/DataDog/datadog-agent/pkg/snmp/gosnmplib"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// submitTrapMetrics submits the metrics of the rules matching the trap OID of
+// the packet, tagged with the device tags and the configured variables
+func submitTrapMetrics(aggregator sender.Sender, rules []MetricRule, packet *SnmpPacket) {
+	if len(rules) == 0 {
+		return
+	}
+	trapOID, err := packet.getTrapOID()
+	if err != nil {
+		log.Debugf("unable to convert trap to metrics: %s", err)
+		return
+	}
+	variables := packet.getTrapVariables()
+	for _, rule := range rules {
+		if rule.TrapOID != trapOID {
+			continue
+		}
+		tags := packet.getTags()
+		for _, ruleTag := range rule.Tags {
+			variable, found := findTrapVariable(variables, ruleTag.OID)
+			if !found {
+				continue
+			}
+			value, err := getVariableString(variable)
+			if err != nil {
+				log.Debugf("unable to tag metric %s with variable %s: %s", rule.Name, ruleTag.OID, err)
+				continue
+			}
+			tags = append(tags, ruleTag.Tag+":"+value)
+		}
+
+		switch rule.Type {
+		case metricTypeGauge:
+			variable, found := findTrapVariable(variables, rule.ValueOID)
+			if !found {
+				log.Debugf("unable to submit metric %s: trap %s has no variable %s", rule.Name, trapOID, rule.ValueOID)
+				continue
+			}
+			value, err := gosnmplib.GetValueFromPDU(variable)
+			floatValue, ok := value.(float64)
+			if err != nil || !ok {
+				log.Debugf("unable to submit metric %s: variable %s is not numeric: %v", rule.Name, rule.ValueOID, variable.Value)
+				continue
+			}
+			aggregator.Gauge(rule.Name, floatValue, "", tags)
+		default:
+			aggregator.Count(rule.Name, 1, "", tags)
+		}
+	}
+}
+
+// findTrapVariable returns the variable with the given OID, or one of its
+// instances, e.g. ifIndex.2 for ifIndex
+func findTrapVariable(variables []gosnmp.SnmpPDU, oid string) (gosnmp.SnmpPDU, bool) {
+	for _, variable := range variables {
+		name := NormalizeOID(variable.Name)
+		if name == oid || strings.HasPrefix(name, oid+".") {
+			return variable, true
+		}
+	}
+	return gosnmp.SnmpPDU{}, false
+}
+
+func getVariableString(variable gosnmp.SnmpPDU) (string, error) {
+	if value, ok := variable.Value.(string); ok && variable.Type == gosnmp.OctetString {
+		return value, nil
+	}
+	value, err := gosnmplib.GetValueFromPDU(variable)
+	if err != nil {
+		return "", err
+	}
+	return gosnmplib.StandardTypeToString(value)
+}
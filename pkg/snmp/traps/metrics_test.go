@@ -0,0 +1,65 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023-present Datadog, Inc.
+
+package traps
+
+import (
+	"testing"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator/mocksender"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestSubmitTrapMetrics(t *testing.T) {
+	rules := []MetricRule{
+		{
+			TrapOID: "1.3.6.1.6.3.1.1.5.3",
+			Name:    "snmp.traps.link_down",
+			Type:    metricTypeCount,
+			Tags: []MetricRuleTag{
+				{OID: "1.3.6.1.2.1.2.2.1.1", Tag: "interface_index"},
+				{OID: "1.3.6.1.2.1.2.2.1.8", Tag: "oper_status"},
+				{OID: "1.3.6.1.2.1.2.2.1.2", Tag: "interface"}, // not in the trap
+			},
+		},
+		{
+			TrapOID:  "1.3.6.1.4.1.8072.2.3.0.1",
+			Name:     "snmp.traps.heartbeat_rate",
+			Type:     metricTypeGauge,
+			ValueOID: "1.3.6.1.4.1.8072.2.3.2.1",
+		},
+		{
+			TrapOID:  "1.3.6.1.4.1.8072.2.3.0.1",
+			Name:     "snmp.traps.heartbeat_name",
+			Type:     metricTypeGauge,
+			ValueOID: "1.3.6.1.4.1.8072.2.3.2.2", // not numeric
+		},
+	}
+
+	mockSender := mocksender.NewMockSender("snmp-traps")
+	mockSender.SetupAcceptAll()
+
+	submitTrapMetrics(mockSender, rules, createTestV1GenericPacket())
+	mockSender.AssertMetric(t, "Count", "snmp.traps.link_down", 1, "", []string{
+		"snmp_version:1",
+		"device_namespace:the_baron",
+		"snmp_device:127.0.0.1",
+		"interface_index:2",
+		"oper_status:2",
+	})
+
+	submitTrapMetrics(mockSender, rules, createTestPacket(NetSNMPExampleHeartbeatNotification))
+	mockSender.AssertMetric(t, "Gauge", "snmp.traps.heartbeat_rate", 1024, "", []string{
+		"snmp_version:2",
+		"device_namespace:totoro",
+		"snmp_device:127.0.0.1",
+	})
+	mockSender.AssertNotCalled(t, "Gauge", "snmp.traps.heartbeat_name", mock.Anything, mock.Anything, mock.Anything)
+
+	// traps without rules aren't converted
+	submitTrapMetrics(mockSender, rules, createTestV1SpecificPacket())
+	mockSender.AssertNumberOfCalls(t, "Count", 1)
+	mockSender.AssertNumberOfCalls(t, "Gauge", 1)
+}
@@ -6,8 +6,10 @@
 package traps
 
 import (
-	"github.com/gosnmp/gosnmp"
+	"fmt"
 	"net"
+
+	"github.com/gosnmp/gosnmp"
 )
 
 // SnmpPacket is the type of packets yielded by server listeners.
@@ -16,6 +18,9 @@ type SnmpPacket struct {
 	Addr      *net.UDPAddr
 	Namespace string
 	Timestamp int64
+	// RepeatCount is the number of identical traps received after this one
+	// and deduplicated, if any
+	RepeatCount int
 }
 
 // PacketsChannel is the type of channels of trap packets.
@@ -29,3 +34,36 @@ func (p *SnmpPacket) getTags() []string {
 		"snmp_device:" + p.Addr.IP.String(),
 	}
 }
+
+// getTrapOID returns the OID identifying the trap, built from the enterprise
+// and trap numbers for SNMPv1 traps
+func (p *SnmpPacket) getTrapOID() (string, error) {
+	if p.Content.Version == gosnmp.Version1 {
+		return v1TrapOID(p.Content), nil
+	}
+	if len(p.Content.Variables) < 2 {
+		return "", fmt.Errorf("expected at least 2 variables, got %d", len(p.Content.Variables))
+	}
+	return parseSnmpTrapOID(p.Content.Variables[1])
+}
+
+// getTrapVariables returns the variables of the trap, without the sysUpTime
+// and snmpTrapOID ones of SNMPv2 and SNMPv3 traps
+func (p *SnmpPacket) getTrapVariables() []gosnmp.SnmpPDU {
+	if p.Content.Version == gosnmp.Version1 {
+		return p.Content.Variables
+	}
+	if len(p.Content.Variables) < 2 {
+		return nil
+	}
+	return p.Content.Variables[2:]
+}
+
+func v1TrapOID(content *gosnmp.SnmpPacket) string {
+	if content.GenericTrap == 6 {
+		// Vendor-specific trap
+		return fmt.Sprintf("%s.0.%d", NormalizeOID(content.Enterprise), content.SpecificTrap)
+	}
+	// Generic trap
+	return fmt.Sprintf("%s.%d", genericTrapOid, content.GenericTrap+1)
+}
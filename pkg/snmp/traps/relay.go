@@ -0,0 +1,149 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023-present Datadog, Inc.
+
+package traps
+
+import (
+	"expvar"
+
+	"github.com/gosnmp/gosnmp"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator/sender"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+const (
+	snmpTrapAddressOID    = "1.3.6.1.6.3.18.1.3.0"
+	snmpTrapEnterpriseOID = "1.3.6.1.6.3.1.1.4.3.0"
+)
+
+// trapRelay re-emits the received traps to a downstream trap receiver. The
+// traps are sent from a goroutine of its own so a slow or unreachable
+// receiver doesn't hold up the forwarder, and are dropped when too many of
+// them are waiting to be sent.
+type trapRelay struct {
+	target    string
+	params    *gosnmp.GoSNMP
+	connected bool
+	queue     chan *SnmpPacket
+	done      chan struct{}
+	sent      *expvar.Int
+	errors    *expvar.Int
+}
+
+func newTrapRelay(config RelayConfig) (*trapRelay, error) {
+	params, err := config.BuildSNMPParams()
+	if err != nil {
+		return nil, err
+	}
+	relay := &trapRelay{
+		target: config.Target(),
+		params: params,
+		queue:  make(chan *SnmpPacket, relayQueueSize),
+		done:   make(chan struct{}),
+		sent:   &expvar.Int{},
+		errors: &expvar.Int{},
+	}
+	relayExpvars := &expvar.Map{}
+	relayExpvars.Set("Sent", relay.sent)
+	relayExpvars.Set("Errors", relay.errors)
+	trapsRelaysExpvars.Set(relay.target, relayExpvars)
+	return relay, nil
+}
+
+// start starts sending the queued traps
+func (r *trapRelay) start(aggregator sender.Sender) {
+	go func() {
+		defer close(r.done)
+		for packet := range r.queue {
+			r.relay(aggregator, packet)
+		}
+		r.close()
+	}()
+}
+
+// stop sends the queued traps, then closes the connection
+func (r *trapRelay) stop() {
+	close(r.queue)
+	<-r.done
+}
+
+// enqueue queues the trap to be relayed, or drops it if the queue is full
+func (r *trapRelay) enqueue(aggregator sender.Sender, packet *SnmpPacket) {
+	select {
+	case r.queue <- packet:
+	default:
+		log.Debugf("unable to relay trap to %s: too many traps waiting to be relayed", r.target)
+		r.errors.Add(1)
+		aggregator.Count("datadog.snmp_traps.relay_dropped", 1, "", append(packet.getTags(), "relay:"+r.target))
+	}
+}
+
+// relay sends the trap to the downstream receiver, the connection being
+// (re)opened if needed as the receiver might not be resolvable yet
+func (r *trapRelay) relay(aggregator sender.Sender, packet *SnmpPacket) {
+	tags := append(packet.getTags(), "relay:"+r.target)
+	if err := r.send(packet); err != nil {
+		log.Debugf("unable to relay trap to %s: %s", r.target, err)
+		r.errors.Add(1)
+		aggregator.Count("datadog.snmp_traps.relay_errors", 1, "", tags)
+		return
+	}
+	r.sent.Add(1)
+	aggregator.Count("datadog.snmp_traps.relayed", 1, "", tags)
+}
+
+func (r *trapRelay) send(packet *SnmpPacket) error {
+	if !r.connected {
+		if err := r.params.Connect(); err != nil {
+			return err
+		}
+		r.connected = true
+	}
+	_, err := r.params.SendTrap(gosnmp.SnmpTrap{Variables: relayVariables(packet)})
+	if err != nil {
+		r.close()
+	}
+	return err
+}
+
+func (r *trapRelay) close() {
+	if r.connected {
+		r.params.Conn.Close()
+		r.connected = false
+	}
+}
+
+// relayVariables returns the variables of the trap to relay: the ones of
+// SNMPv2 and SNMPv3 traps are preserved, and SNMPv1 traps are translated into
+// SNMPv2 ones as described in RFC 3584 section 3.1. The address of the device
+// that sent the trap is added as snmpTrapAddress.0, as the downstream receiver
+// only sees the address of the agent.
+func relayVariables(packet *SnmpPacket) []gosnmp.SnmpPDU {
+	content := packet.Content
+	trapAddress := gosnmp.SnmpPDU{Name: snmpTrapAddressOID, Type: gosnmp.IPAddress, Value: packet.Addr.IP.String()}
+	if content.Version != gosnmp.Version1 {
+		for _, variable := range content.Variables {
+			// the trap was relayed before, keep the address of the device
+			if NormalizeOID(variable.Name) == snmpTrapAddressOID {
+				return content.Variables
+			}
+		}
+		variables := make([]gosnmp.SnmpPDU, 0, len(content.Variables)+1)
+		variables = append(variables, content.Variables...)
+		return append(variables, trapAddress)
+	}
+	variables := make([]gosnmp.SnmpPDU, 0, len(content.Variables)+4)
+	variables = append(variables,
+		gosnmp.SnmpPDU{Name: sysUpTimeInstanceOID, Type: gosnmp.TimeTicks, Value: uint32(content.Timestamp)},
+		gosnmp.SnmpPDU{Name: snmpTrapOID, Type: gosnmp.ObjectIdentifier, Value: v1TrapOID(content)},
+	)
+	variables = append(variables, content.Variables...)
+	variables = append(variables,
+		trapAddress,
+		gosnmp.SnmpPDU{Name: snmpTrapEnterpriseOID, Type: gosnmp.ObjectIdentifier, Value: NormalizeOID(content.Enterprise)},
+	)
+	return variables
+}
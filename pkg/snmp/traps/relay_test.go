@@ -0,0 +1,158 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023-present Datadog, Inc.
+
+package traps
+
+import (
+	"encoding/hex"
+	"net"
+	"testing"
+
+	"github.com/gosnmp/gosnmp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator/mocksender"
+)
+
+var relayPort = getFreePort()
+
+func startRelayReceiver(t *testing.T, config Config) *TrapListener {
+	mockSender := mocksender.NewMockSender("snmp-traps-relay-receiver")
+	mockSender.SetupAcceptAll()
+	receiver, err := startSNMPTrapListener(config, mockSender, make(PacketsChannel, 1))
+	require.NoError(t, err)
+	return receiver
+}
+
+func TestRelayV2c(t *testing.T) {
+	receiver := startRelayReceiver(t, Config{Port: relayPort, CommunityStrings: []string{"noc"}})
+	defer receiver.Stop()
+
+	mockSender := mocksender.NewMockSender("snmp-traps")
+	mockSender.SetupAcceptAll()
+	relayConfig := RelayConfig{Host: "127.0.0.1", Port: relayPort, Community: "noc"}
+	require.NoError(t, relayConfig.normalize(""))
+	relay, err := newTrapRelay(relayConfig)
+	require.NoError(t, err)
+	defer relay.close()
+
+	relay.relay(mockSender, createTestPacket(NetSNMPExampleHeartbeatNotification))
+	packet := receivePacket(t, receiver, defaultTimeout)
+	require.NotNil(t, packet)
+	assertIsValidV2Packet(t, packet, Config{CommunityStrings: []string{"noc"}})
+	assertRelayedVariables(t, packet)
+	mockSender.AssertMetric(t, "Count", "datadog.snmp_traps.relayed", 1, "", []string{"snmp_version:2", "device_namespace:totoro", "snmp_device:127.0.0.1", "relay:" + relay.target})
+	assert.Equal(t, int64(1), relay.sent.Value())
+	assert.Equal(t, int64(0), relay.errors.Value())
+
+	// SNMPv1 traps are translated into SNMPv2 ones
+	relay.relay(mockSender, createTestV1GenericPacket())
+	packet = receivePacket(t, receiver, defaultTimeout)
+	require.NotNil(t, packet)
+	require.Equal(t, gosnmp.Version2c, packet.Content.Version)
+	variables := packet.Content.Variables
+	require.Len(t, variables, 2+len(LinkDownv1GenericTrap.Variables)+2)
+	assert.Equal(t, ".1.3.6.1.2.1.1.3.0", variables[0].Name)
+	assert.Equal(t, uint32(1000), variables[0].Value)
+	assert.Equal(t, ".1.3.6.1.6.3.1.1.4.1.0", variables[1].Name)
+	assert.Equal(t, ".1.3.6.1.6.3.1.1.5.3", variables[1].Value)
+	assert.Equal(t, ".1.3.6.1.2.1.2.2.1.1", variables[2].Name)
+	assert.Equal(t, 2, variables[2].Value)
+	assert.Equal(t, ".1.3.6.1.6.3.18.1.3.0", variables[6].Name)
+	assert.Equal(t, "127.0.0.1", variables[6].Value)
+	assert.Equal(t, ".1.3.6.1.6.3.1.1.4.3.0", variables[7].Name)
+	assert.Equal(t, ".1.3.6.1.6.3.1.1.5", variables[7].Value)
+	assert.Equal(t, int64(2), relay.sent.Value())
+
+	status := GetStatus()
+	assert.Equal(t, map[string]interface{}{"Sent": float64(2), "Errors": float64(0)}, status["relays"].(map[string]interface{})[relay.target])
+}
+
+func TestRelayV3(t *testing.T) {
+	user := UserV3{Username: "noc", AuthKey: "password", AuthProtocol: "SHA", PrivKey: "password", PrivProtocol: "AES"}
+	receiverConfig := Config{Port: relayPort, Users: []UserV3{user}, authoritativeEngineID: "\x80\xff\xff\xff\xff\x01\x02\x03\x04"}
+	receiver := startRelayReceiver(t, receiverConfig)
+	defer receiver.Stop()
+
+	mockSender := mocksender.NewMockSender("snmp-traps")
+	mockSender.SetupAcceptAll()
+	relayConfig := RelayConfig{Host: "127.0.0.1", Port: relayPort, Version: "3", UserV3: user}
+	require.NoError(t, relayConfig.normalize(receiverConfig.authoritativeEngineID))
+	assert.Equal(t, hex.EncodeToString([]byte(receiverConfig.authoritativeEngineID)), relayConfig.EngineID)
+	relay, err := newTrapRelay(relayConfig)
+	require.NoError(t, err)
+	defer relay.close()
+
+	relay.relay(mockSender, createTestPacket(NetSNMPExampleHeartbeatNotification))
+	packet := receivePacket(t, receiver, defaultTimeout)
+	require.NotNil(t, packet)
+	require.Equal(t, gosnmp.Version3, packet.Content.Version)
+	assertRelayedVariables(t, packet)
+}
+
+func TestRelayQueue(t *testing.T) {
+	mockSender := mocksender.NewMockSender("snmp-traps")
+	mockSender.SetupAcceptAll()
+	relayConfig := RelayConfig{Host: "127.0.0.1", Port: relayPort, Community: "noc"}
+	require.NoError(t, relayConfig.normalize(""))
+	relay, err := newTrapRelay(relayConfig)
+	require.NoError(t, err)
+
+	// the relay isn't started, the traps stay queued
+	packet := createTestPacket(NetSNMPExampleHeartbeatNotification)
+	for i := 0; i < relayQueueSize; i++ {
+		relay.enqueue(mockSender, packet)
+	}
+	mockSender.AssertNotCalled(t, "Count", "datadog.snmp_traps.relay_dropped", mock.Anything, mock.Anything, mock.Anything)
+	relay.enqueue(mockSender, packet)
+	mockSender.AssertMetric(t, "Count", "datadog.snmp_traps.relay_dropped", 1, "", []string{"snmp_version:2", "device_namespace:totoro", "snmp_device:127.0.0.1", "relay:" + relay.target})
+	assert.Equal(t, int64(1), relay.errors.Value())
+	assert.Len(t, relay.queue, relayQueueSize)
+}
+
+func TestRelayVariablesTrapAddress(t *testing.T) {
+	packet := createTestPacket(NetSNMPExampleHeartbeatNotification)
+	packet.Addr = &net.UDPAddr{IP: net.ParseIP("10.0.0.1"), Port: 13156}
+	variables := relayVariables(packet)
+	require.Len(t, variables, len(NetSNMPExampleHeartbeatNotification.Variables)+1)
+	assert.Equal(t, gosnmp.SnmpPDU{Name: snmpTrapAddressOID, Type: gosnmp.IPAddress, Value: "10.0.0.1"}, variables[len(variables)-1])
+	assert.Len(t, packet.Content.Variables, len(NetSNMPExampleHeartbeatNotification.Variables))
+
+	// the address of a trap relayed before is kept
+	packet.Content.Variables = variables
+	packet.Addr = &net.UDPAddr{IP: net.ParseIP("10.0.0.2"), Port: 13156}
+	assert.Equal(t, variables, relayVariables(packet))
+}
+
+// assertRelayedVariables checks the variables of a relayed test trap: the
+// ones of the trap followed by the address of the device
+func assertRelayedVariables(t *testing.T, packet *SnmpPacket) {
+	variables := packet.Content.Variables
+	require.Len(t, variables, 5)
+	assert.Equal(t, ".1.3.6.1.6.3.18.1.3.0", variables[4].Name)
+	assert.Equal(t, "127.0.0.1", variables[4].Value)
+	packet.Content.Variables = variables[:4]
+	assertVariables(t, packet)
+}
+
+func TestInvalidRelays(t *testing.T) {
+	for _, relay := range []RelayConfig{
+		{Community: "public"},
+		{Host: "localhost"},
+		{Host: "localhost", Version: "1", Community: "public"},
+		{Host: "localhost", Version: "3"},
+		{Host: "localhost", Version: "3", UserV3: UserV3{Username: "noc"}, EngineID: "not-hex"},
+		{Host: "localhost", Version: "3", UserV3: UserV3{Username: "noc", AuthProtocol: "foo"}},
+	} {
+		assert.Error(t, relay.normalize(""), "relay: %+v", relay)
+	}
+
+	relay := RelayConfig{Host: "localhost", Community: "public"}
+	assert.NoError(t, relay.normalize(""))
+	assert.Equal(t, RelayConfig{Host: "localhost", Port: 162, Version: "2c", Community: "public"}, relay)
+	assert.Equal(t, "localhost:162", relay.Target())
+}
@@ -74,7 +74,7 @@ func NewTrapServer(config Config, formatter Formatter, aggregator sender.Sender)
 		return nil, err
 	}
 
-	trapForwarder, err := startSNMPTrapForwarder(formatter, aggregator, packets)
+	trapForwarder, err := startSNMPTrapForwarder(config, formatter, aggregator, packets)
 	if err != nil {
 		return nil, fmt.Errorf("unable to start trapForwarder: %w. Will not listen for SNMP traps", err)
 	}
@@ -87,8 +87,8 @@ func NewTrapServer(config Config, formatter Formatter, aggregator sender.Sender)
 	return server, nil
 }
 
-func startSNMPTrapForwarder(formatter Formatter, aggregator sender.Sender, packets PacketsChannel) (*TrapForwarder, error) {
-	trapForwarder, err := NewTrapForwarder(formatter, aggregator, packets)
+func startSNMPTrapForwarder(config Config, formatter Formatter, aggregator sender.Sender, packets PacketsChannel) (*TrapForwarder, error) {
+	trapForwarder, err := NewTrapForwarder(config, formatter, aggregator, packets)
 	if err != nil {
 		return nil, err
 	}
@@ -14,6 +14,7 @@ import (
 
 var (
 	trapsExpvars           = expvar.NewMap("snmp_traps")
+	trapsRelaysExpvars     = expvar.NewMap("snmp_traps_relays")
 	trapsPackets           = expvar.Int{}
 	trapsPacketsAuthErrors = expvar.Int{}
 )
@@ -54,6 +55,13 @@ func GetStatus() map[string]interface{} {
 	}
 	status["metrics"] = metrics
 
+	relaysJSON := []byte(trapsRelaysExpvars.String())
+	relays := make(map[string]interface{})
+	json.Unmarshal(relaysJSON, &relays) //nolint:errcheck
+	if len(relays) > 0 {
+		status["relays"] = relays
+	}
+
 	if startError != nil {
 		status["error"] = startError.Error()
 	}
@@ -37,9 +37,11 @@ func FormatStatus(data []byte) (string, error) {
 	}
 	runnerStats := stats["runnerStats"]
 	pyLoaderStats := stats["pyLoaderStats"]
+	wasmLoaderStats := stats["wasmLoaderStats"]
 	pythonInit := stats["pythonInit"]
 	autoConfigStats := stats["autoConfigStats"]
 	checkSchedulerStats := stats["checkSchedulerStats"]
+	schedulerStats := stats["schedulerStats"]
 	aggregatorStats := stats["aggregatorStats"]
 	s, err := checkstats.TranslateEventPlatformEventTypes(aggregatorStats)
 	if err != nil {
@@ -61,7 +63,7 @@ func FormatStatus(data []byte) (string, error) {
 	var b = new(bytes.Buffer)
 	headerFunc := func() error { return RenderStatusTemplate(b, "/header.tmpl", stats) }
 	checkStatsFunc := func() error {
-		return renderChecksStats(b, runnerStats, pyLoaderStats, pythonInit, autoConfigStats, checkSchedulerStats, inventoriesStats, "")
+		return renderChecksStats(b, runnerStats, pyLoaderStats, wasmLoaderStats, pythonInit, autoConfigStats, checkSchedulerStats, schedulerStats, inventoriesStats, "")
 	}
 	jmxFetchFunc := func() error { return RenderStatusTemplate(b, "/jmxfetch.tmpl", stats) }
 	forwarderFunc := func() error { return RenderStatusTemplate(b, "/forwarder.tmpl", forwarderStats) }
@@ -150,7 +152,7 @@ func FormatDCAStatus(data []byte) (string, error) {
 	if err := RenderStatusTemplate(b, "/header.tmpl", stats); err != nil {
 		errs = append(errs, err)
 	}
-	if err := renderChecksStats(b, runnerStats, nil, nil, autoConfigStats, checkSchedulerStats, nil, ""); err != nil {
+	if err := renderChecksStats(b, runnerStats, nil, nil, nil, autoConfigStats, checkSchedulerStats, nil, nil, ""); err != nil {
 		errs = append(errs, err)
 	}
 	if err := RenderStatusTemplate(b, "/forwarder.tmpl", forwarderStats); err != nil {
@@ -258,13 +260,15 @@ func FormatMetadataMapCLI(data []byte) (string, error) {
 	return b.String(), nil
 }
 
-func renderChecksStats(w io.Writer, runnerStats, pyLoaderStats, pythonInit, autoConfigStats, checkSchedulerStats, inventoriesStats interface{}, onlyCheck string) error {
+func renderChecksStats(w io.Writer, runnerStats, pyLoaderStats, wasmLoaderStats, pythonInit, autoConfigStats, checkSchedulerStats, schedulerStats, inventoriesStats interface{}, onlyCheck string) error {
 	checkStats := make(map[string]interface{})
 	checkStats["RunnerStats"] = runnerStats
 	checkStats["pyLoaderStats"] = pyLoaderStats
+	checkStats["wasmLoaderStats"] = wasmLoaderStats
 	checkStats["pythonInit"] = pythonInit
 	checkStats["AutoConfigStats"] = autoConfigStats
 	checkStats["CheckSchedulerStats"] = checkSchedulerStats
+	checkStats["SchedulerStats"] = schedulerStats
 	checkStats["OnlyCheck"] = onlyCheck
 	checkStats["CheckMetadata"] = inventoriesStats
 	return RenderStatusTemplate(w, "/collector.tmpl", checkStats)
@@ -277,13 +281,15 @@ func renderCheckStats(data []byte, checkName string) (string, error) {
 	}
 	runnerStats := stats["runnerStats"]
 	pyLoaderStats := stats["pyLoaderStats"]
+	wasmLoaderStats := stats["wasmLoaderStats"]
 	pythonInit := stats["pythonInit"]
 	autoConfigStats := stats["autoConfigStats"]
 	checkSchedulerStats := stats["checkSchedulerStats"]
+	schedulerStats := stats["schedulerStats"]
 	inventoriesStats := stats["inventories"]
 	var b = new(bytes.Buffer)
 	var errs []error
-	if err := renderChecksStats(b, runnerStats, pyLoaderStats, pythonInit, autoConfigStats, checkSchedulerStats, inventoriesStats, checkName); err != nil {
+	if err := renderChecksStats(b, runnerStats, pyLoaderStats, wasmLoaderStats, pythonInit, autoConfigStats, checkSchedulerStats, schedulerStats, inventoriesStats, checkName); err != nil {
 		errs = append(errs, err)
 	}
 	if err := renderErrors(b, errs); err != nil {
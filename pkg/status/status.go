@@ -367,6 +367,16 @@ func expvarStats(stats map[string]interface{}) (map[string]interface{}, error) {
 	json.Unmarshal(checkSchedulerStatsJSON, &checkSchedulerStats) //nolint:errcheck
 	stats["checkSchedulerStats"] = checkSchedulerStats
 
+	schedulerData := expvar.Get("scheduler")
+	if schedulerData != nil {
+		schedulerStatsJSON := []byte(schedulerData.String())
+		schedulerStats := make(map[string]interface{})
+		json.Unmarshal(schedulerStatsJSON, &schedulerStats) //nolint:errcheck
+		stats["schedulerStats"] = schedulerStats
+	} else {
+		stats["schedulerStats"] = nil
+	}
+
 	aggregatorStatsJSON := []byte(expvar.Get("aggregator").String())
 	aggregatorStats := make(map[string]interface{})
 	json.Unmarshal(aggregatorStatsJSON, &aggregatorStats) //nolint:errcheck
@@ -407,6 +417,16 @@ func expvarStats(stats map[string]interface{}) (map[string]interface{}, error) {
 		stats["pyLoaderStats"] = nil
 	}
 
+	wasmLoaderData := expvar.Get("wasmLoader")
+	if wasmLoaderData != nil {
+		wasmLoaderStatsJSON := []byte(wasmLoaderData.String())
+		wasmLoaderStats := make(map[string]interface{})
+		json.Unmarshal(wasmLoaderStatsJSON, &wasmLoaderStats) //nolint:errcheck
+		stats["wasmLoaderStats"] = wasmLoaderStats
+	} else {
+		stats["wasmLoaderStats"] = nil
+	}
+
 	pythonInitData := expvar.Get("pythonInit")
 	if pythonInitData != nil {
 		pythonInitJSON := []byte(pythonInitData.String())
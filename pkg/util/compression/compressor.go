@@ -0,0 +1,77 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package compression
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+)
+
+// Compression kinds selectable at runtime
+const (
+	ZlibKind = "zlib"
+	ZstdKind = "zstd"
+)
+
+// Compressor compresses payloads with a given algorithm
+type Compressor interface {
+	Compress(src []byte) ([]byte, error)
+	Decompress(src []byte) ([]byte, error)
+	// CompressBound returns the worst case size needed for a destination buffer
+	CompressBound(sourceLen int) int
+	// ContentEncoding returns the HTTP header value associated with the compression method
+	ContentEncoding() string
+	// NewStreamCompressor returns a StreamCompressor writing the compressed data to output
+	NewStreamCompressor(output *bytes.Buffer) StreamCompressor
+}
+
+// StreamCompressor compresses the data written to it
+type StreamCompressor interface {
+	io.WriteCloser
+	// Flush writes all the pending data to the output
+	Flush() error
+}
+
+// NewCompressor returns the Compressor of the given kind. zstdLevel is the
+// compression level used by zstd.
+func NewCompressor(kind string, zstdLevel int) (Compressor, error) {
+	switch kind {
+	case ZlibKind:
+		return zlibCompressor{}, nil
+	case ZstdKind:
+		return newZstdCompressor(zstdLevel)
+	default:
+		return nil, fmt.Errorf("unknown compression kind %q, valid kinds are %q and %q", kind, ZlibKind, ZstdKind)
+	}
+}
+
+// DefaultCompressor returns the Compressor using the compression selected at build time
+func DefaultCompressor() Compressor {
+	return defaultCompressor{}
+}
+
+type defaultCompressor struct{}
+
+func (defaultCompressor) Compress(src []byte) ([]byte, error) {
+	return Compress(src)
+}
+
+func (defaultCompressor) Decompress(src []byte) ([]byte, error) {
+	return Decompress(src)
+}
+
+func (defaultCompressor) CompressBound(sourceLen int) int {
+	return CompressBound(sourceLen)
+}
+
+func (defaultCompressor) ContentEncoding() string {
+	return ContentEncoding
+}
+
+func (defaultCompressor) NewStreamCompressor(output *bytes.Buffer) StreamCompressor {
+	return newStreamCompressor(output)
+}
@@ -7,6 +7,12 @@
 
 package compression
 
+import "bytes"
+
+// BuildKind is the kind of the compression selected at build time
+// empty here since there's no compression
+const BuildKind = ""
+
 // ContentEncoding describes the HTTP header value associated with the compression method
 // empty here since there's no compression
 // var instead of const to ease testing
@@ -26,3 +32,20 @@ func Decompress(src []byte) ([]byte, error) {
 func CompressBound(sourceLen int) int {
 	return sourceLen
 }
+
+func newStreamCompressor(output *bytes.Buffer) StreamCompressor {
+	return noopStreamCompressor{output}
+}
+
+// noopStreamCompressor writes the data to its output without compressing it
+type noopStreamCompressor struct {
+	*bytes.Buffer
+}
+
+func (noopStreamCompressor) Flush() error {
+	return nil
+}
+
+func (noopStreamCompressor) Close() error {
+	return nil
+}
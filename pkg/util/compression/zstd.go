@@ -8,12 +8,17 @@
 package compression
 
 import (
+	"bytes"
+
 	zstd_0 "github.com/DataDog/zstd_0"
 )
 
 // TODO: the intake still uses a pre-v1 (unstable) version of the zstd compression format.
 // The agent shouldn't use zstd compression until the intake supports a stable v1 format.
 
+// BuildKind is the kind of the compression selected at build time
+const BuildKind = ZstdKind
+
 // ContentEncoding describes the HTTP header value associated with the compression method
 // var instead of const to ease testing
 var ContentEncoding = "zstd"
@@ -32,3 +37,38 @@ func Decompress(src []byte) ([]byte, error) {
 func CompressBound(sourceLen int) int {
 	return zstd_0.CompressBound(sourceLen)
 }
+
+func newStreamCompressor(output *bytes.Buffer) StreamCompressor {
+	return &zstd0StreamCompressor{writer: zstd_0.NewWriter(output)}
+}
+
+// zstd0StreamCompressor is a zstd_0 writer. zstd_0 compresses every write
+// into its own blocks, so the writes are kept pending until Flush compresses
+// them together, like the zlib writer does.
+type zstd0StreamCompressor struct {
+	writer  *zstd_0.Writer
+	pending bytes.Buffer
+}
+
+// Write adds p to the pending data
+func (c *zstd0StreamCompressor) Write(p []byte) (int, error) {
+	return c.pending.Write(p)
+}
+
+// Flush compresses the pending data and writes it to the output
+func (c *zstd0StreamCompressor) Flush() error {
+	if c.pending.Len() == 0 {
+		return nil
+	}
+	_, err := c.writer.Write(c.pending.Bytes())
+	c.pending.Reset()
+	return err
+}
+
+// Close flushes the pending data and writes the end of the frame to the output
+func (c *zstd0StreamCompressor) Close() error {
+	if err := c.Flush(); err != nil {
+		return err
+	}
+	return c.writer.Close()
+}
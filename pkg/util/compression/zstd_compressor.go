@@ -0,0 +1,48 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build cgo
+
+package compression
+
+import (
+	"bytes"
+
+	"github.com/DataDog/zstd"
+)
+
+// zstdCompressor is a Compressor using zstd
+type zstdCompressor struct {
+	level int
+}
+
+func newZstdCompressor(level int) (Compressor, error) {
+	return &zstdCompressor{level: level}, nil
+}
+
+// Compress will compress the data with zstd
+func (c *zstdCompressor) Compress(src []byte) ([]byte, error) {
+	return zstd.CompressLevel(nil, src, c.level)
+}
+
+// Decompress will decompress the data with zstd
+func (c *zstdCompressor) Decompress(src []byte) ([]byte, error) {
+	return zstd.Decompress(nil, src)
+}
+
+// CompressBound returns the worst case size needed for a destination buffer
+func (c *zstdCompressor) CompressBound(sourceLen int) int {
+	return zstd.CompressBound(sourceLen)
+}
+
+// ContentEncoding returns the HTTP header value associated with zstd
+func (c *zstdCompressor) ContentEncoding() string {
+	return "zstd"
+}
+
+// NewStreamCompressor returns a zstd writer
+func (c *zstdCompressor) NewStreamCompressor(output *bytes.Buffer) StreamCompressor {
+	return zstd.NewWriterLevel(output, c.level)
+}
@@ -89,6 +89,7 @@ func (p podParser) Parse(obj interface{}) workloadmeta.Entity {
 		PersistentVolumeClaimNames: pvcNames,
 		Ready:                      ready,
 		IP:                         pod.Status.PodIP,
+		NodeName:                   pod.Spec.NodeName,
 		PriorityClass:              pod.Spec.PriorityClassName,
 		QOSClass:                   string(pod.Status.QOSClass),
 
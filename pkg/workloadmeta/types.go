@@ -558,6 +558,7 @@ type KubernetesPod struct {
 	Ready                      bool
 	Phase                      string
 	IP                         string
+	NodeName                   string
 	PriorityClass              string
 	QOSClass                   string
 	KubeServices               []string
@@ -616,6 +617,7 @@ func (p KubernetesPod) String(verbose bool) string {
 	_, _ = fmt.Fprintln(&sb, "IP:", p.IP)
 
 	if verbose {
+		_, _ = fmt.Fprintln(&sb, "Node Name:", p.NodeName)
 		_, _ = fmt.Fprintln(&sb, "Priority Class:", p.PriorityClass)
 		_, _ = fmt.Fprintln(&sb, "QOS Class:", p.QOSClass)
 		_, _ = fmt.Fprintln(&sb, "PVCs:", sliceToString(p.PersistentVolumeClaimNames))
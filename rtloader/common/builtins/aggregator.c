@@ -11,6 +11,7 @@ static cb_submit_metric_t cb_submit_metric = NULL;
 static cb_submit_service_check_t cb_submit_service_check = NULL;
 static cb_submit_event_t cb_submit_event = NULL;
 static cb_submit_histogram_bucket_t cb_submit_histogram_bucket = NULL;
+static cb_submit_exponential_histogram_t cb_submit_exponential_histogram = NULL;
 static cb_submit_event_platform_event_t cb_submit_event_platform_event = NULL;
 
 // forward declarations
@@ -18,6 +19,7 @@ static PyObject *submit_metric(PyObject *self, PyObject *args);
 static PyObject *submit_service_check(PyObject *self, PyObject *args);
 static PyObject *submit_event(PyObject *self, PyObject *args);
 static PyObject *submit_histogram_bucket(PyObject *self, PyObject *args);
+static PyObject *submit_exponential_histogram(PyObject *self, PyObject *args);
 static PyObject *submit_event_platform_event(PyObject *self, PyObject *args);
 
 static PyMethodDef methods[] = {
@@ -25,6 +27,7 @@ static PyMethodDef methods[] = {
     { "submit_service_check", (PyCFunction)submit_service_check, METH_VARARGS, "Submit service checks." },
     { "submit_event", (PyCFunction)submit_event, METH_VARARGS, "Submit events." },
     { "submit_histogram_bucket", (PyCFunction)submit_histogram_bucket, METH_VARARGS, "Submit histogram bucket." },
+    { "submit_exponential_histogram", (PyCFunction)submit_exponential_histogram, METH_VARARGS, "Submit exponential histogram." },
     { "submit_event_platform_event", (PyCFunction)submit_event_platform_event, METH_VARARGS, "Submit event platform event." },
     { NULL, NULL } // guards
 };
@@ -88,6 +91,11 @@ void _set_submit_histogram_bucket_cb(cb_submit_histogram_bucket_t cb)
     cb_submit_histogram_bucket = cb;
 }
 
+void _set_submit_exponential_histogram_cb(cb_submit_exponential_histogram_t cb)
+{
+    cb_submit_exponential_histogram = cb;
+}
+
 void _set_submit_event_platform_event_cb(cb_submit_event_platform_event_t cb)
 {
     cb_submit_event_platform_event = cb;
@@ -415,6 +423,113 @@ error:
     return NULL;
 }
 
+/*! \fn py_counts_to_c(PyObject *py_counts, int *len)
+    \brief A function to convert a list of python integers (bucket counts) into an
+    array of C unsigned long long.
+    \return a unsigned long long * pointer to the C-representation of the provided python
+    list, its length being stored in len. In the event of failure NULL is returned.
+
+    The returned pointer is heap allocated here and should be subsequently freed by the
+    caller. This function may set and raise python interpreter errors. The function is
+    static and not in the builtin's API.
+*/
+static unsigned long long *py_counts_to_c(PyObject *py_counts, int *len)
+{
+    unsigned long long *counts = NULL;
+    PyObject *py_counts_list = NULL; // new reference
+
+    if (!PySequence_Check(py_counts)) {
+        PyErr_SetString(PyExc_TypeError, "bucket counts must be a sequence");
+        return NULL;
+    }
+
+    *len = PySequence_Length(py_counts);
+    if (*len == -1) {
+        PyErr_SetString(PyExc_RuntimeError, "could not compute bucket counts length");
+        return NULL;
+    }
+
+    // always allocate at least one element so that an empty list isn't mistaken for an error
+    if (!(counts = _malloc(sizeof(*counts) * (*len + 1)))) {
+        PyErr_SetString(PyExc_RuntimeError, "could not allocate memory for bucket counts");
+        return NULL;
+    }
+
+    py_counts_list = PySequence_Fast(py_counts, "py_counts is not a sequence"); // new reference
+    if (py_counts_list == NULL) {
+        _free(counts);
+        return NULL;
+    }
+
+    int i;
+    for (i = 0; i < *len; i++) {
+        // `item` is borrowed, no need to decref
+        PyObject *item = PySequence_Fast_GET_ITEM(py_counts_list, i);
+
+        counts[i] = PyLong_AsUnsignedLongLong(item);
+        if (PyErr_Occurred()) {
+            _free(counts);
+            counts = NULL;
+            break;
+        }
+    }
+
+    Py_XDECREF(py_counts_list);
+    return counts;
+}
+
+static PyObject *submit_exponential_histogram(PyObject *self, PyObject *args)
+{
+    if (cb_submit_exponential_histogram == NULL) {
+        Py_RETURN_NONE;
+    }
+
+    PyGILState_STATE gstate = PyGILState_Ensure();
+
+    PyObject *check = NULL; // borrowed
+    PyObject *py_tags = NULL; // borrowed
+    PyObject *py_positive_counts = NULL; // borrowed
+    PyObject *py_negative_counts = NULL; // borrowed
+    char *check_id = NULL;
+    char *name = NULL;
+    int monotonic;
+    char *hostname = NULL;
+    char **tags = NULL;
+    bool flush_first_value = false;
+    exponential_histogram_t histogram = { 0 };
+    PyObject *retval = NULL;
+
+    // Python call: aggregator.submit_exponential_histogram(self, check_id, metric string, scale, sum, zero_count,
+    //   positive_offset, positive_counts, negative_offset, negative_counts, monotonic, hostname, tags, flush_first_value)
+    if (!PyArg_ParseTuple(args, "OssidKiOiOisO|b", &check, &check_id, &name, &histogram.scale, &histogram.sum,
+                          &histogram.zero_count, &histogram.positive_offset, &py_positive_counts,
+                          &histogram.negative_offset, &py_negative_counts, &monotonic, &hostname, &py_tags,
+                          &flush_first_value)) {
+        goto done;
+    }
+
+    if ((histogram.positive_counts = py_counts_to_c(py_positive_counts, &histogram.positive_len)) == NULL)
+        goto done;
+    if ((histogram.negative_counts = py_counts_to_c(py_negative_counts, &histogram.negative_len)) == NULL)
+        goto done;
+    if ((tags = py_tag_to_c(py_tags)) == NULL)
+        goto done;
+
+    cb_submit_exponential_histogram(check_id, name, &histogram, monotonic, hostname, tags, flush_first_value);
+
+    Py_INCREF(Py_None); // Increment, since we are not using the macro Py_RETURN_NONE that does it for us
+    retval = Py_None;
+
+done:
+    if (tags != NULL) {
+        free_tags(tags);
+    }
+    _free(histogram.positive_counts);
+    _free(histogram.negative_counts);
+    PyGILState_Release(gstate);
+    return retval;
+}
+
 static PyObject *submit_event_platform_event(PyObject *self, PyObject *args)
 {
     if (cb_submit_event_platform_event == NULL) {
@@ -197,6 +197,17 @@ DATADOG_AGENT_RTLOADER_API char *run_check(rtloader_t *, rtloader_pyobject_t *ch
 */
 DATADOG_AGENT_RTLOADER_API void cancel_check(rtloader_t *, rtloader_pyobject_t *check);
 
+/*! \fn int interrupt_check(rtloader_t *, rtloader_pyobject_t *check)
+    \brief Interrupts a running check instance, by raising a KeyboardInterrupt exception
+    in the thread running it. The exception is raised once the thread runs Python code again.
+    \param rtloader_t A rtloader_t * pointer to the RtLoader instance.
+    \param check A rtloader_pyobject_t * pointer to the check instance we wish to interrupt.
+    \return An integer with the success of the operation. Zero if the check isn't running,
+    non-zero if it was interrupted.
+    \sa rtloader_pyobject_t, rtloader_t
+*/
+DATADOG_AGENT_RTLOADER_API int interrupt_check(rtloader_t *, rtloader_pyobject_t *check);
+
 /*! \fn char **get_checks_warnings(rtloader_t *, rtloader_pyobject_t *check)
     \brief Get all warnings, if any, for a check instance.
     \param rtloader_t A rtloader_t * pointer to the RtLoader instance.
@@ -395,6 +406,15 @@ DATADOG_AGENT_RTLOADER_API void set_submit_event_cb(rtloader_t *, cb_submit_even
 */
 DATADOG_AGENT_RTLOADER_API void set_submit_histogram_bucket_cb(rtloader_t *, cb_submit_histogram_bucket_t);
 
+/*! \fn void set_submit_exponential_histogram_cb(rtloader_t *, cb_submit_exponential_histogram_t)
+    \brief Sets the submit exponential histogram callback to be used by rtloader for exponential histogram submission.
+    \param cb A function pointer with cb_submit_exponential_histogram_t prototype to the callback
+    function.
+
+    The callback is expected to be provided by the rtloader caller - in go-context: CGO.
+*/
+DATADOG_AGENT_RTLOADER_API void set_submit_exponential_histogram_cb(rtloader_t *, cb_submit_exponential_histogram_t);
+
 /*! \fn void set_submit_event_platform_event_cb(rtloader_t *, cb_submit_event_platform_event_t)
     \brief Sets the submit event callback to be used by rtloader for event-platform event.
     \param cb A function pointer with cb_submit_event_platform_event_t prototype to the callback
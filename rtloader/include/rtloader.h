@@ -115,6 +115,16 @@ public:
     */
     virtual void cancelCheck(RtLoaderPyObject *check) = 0;
 
+    //! Pure virtual interruptCheck member.
+    /*!
+      \param check The python object pointer to the check we wish to interrupt.
+      \return A boolean indicating if the check was running and an exception was raised in its thread.
+
+      The exception is raised asynchronously: the check is interrupted once its thread runs
+      Python code again.
+    */
+    virtual bool interruptCheck(RtLoaderPyObject *check) = 0;
+
     //! Pure virtual getCheckWarnings member.
     /*!
       \param check The python object pointer to the check we wish to collect existing warnings for.
@@ -258,6 +268,14 @@ public:
     */
     virtual void setSubmitHistogramBucketCb(cb_submit_histogram_bucket_t) = 0;
 
+    //! setSubmitExponentialHistogramCb member.
+    /*!
+      \param A cb_submit_exponential_histogram_t function pointer to the CGO callback.
+
+      Actual exponential histograms are submitted from go-land, this allows us to set the CGO callback.
+    */
+    virtual void setSubmitExponentialHistogramCb(cb_submit_exponential_histogram_t) = 0;
+
     //! setSubmitEventPlatformEventCb member.
     /*!
       \param A cb_submit_event_platform_event_t function pointer to the CGO callback.
@@ -72,6 +72,18 @@ typedef struct event_s {
     char *event_type;
 } event_t;
 
+typedef struct exponential_histogram_s {
+    int scale;
+    double sum;
+    unsigned long long zero_count;
+    int positive_offset;
+    unsigned long long *positive_counts;
+    int positive_len;
+    int negative_offset;
+    unsigned long long *negative_counts;
+    int negative_len;
+} exponential_histogram_t;
+
 typedef struct py_info_s {
     const char *version; // returned by Py_GetInfo(); is static string owned by python
     char *path; // allocated within getPyInfo()
@@ -104,6 +116,8 @@ typedef void (*cb_submit_service_check_t)(char *, char *, int, char **, char *,
 typedef void (*cb_submit_event_t)(char *, event_t *);
 // (id, metric_name, value, lower_bound, upper_bound, monotonic, hostname, tags, flush_first_value)
 typedef void (*cb_submit_histogram_bucket_t)(char *, char *, long long, float, float, int, char *, char **, bool);
+// (id, metric_name, histogram, monotonic, hostname, tags, flush_first_value)
+typedef void (*cb_submit_exponential_histogram_t)(char *, char *, exponential_histogram_t *, int, char *, char **, bool);
 // (id, event, event_type)
 typedef void (*cb_submit_event_platform_event_t)(char *, char *, int, char *);
 
@@ -322,6 +322,11 @@ void cancel_check(rtloader_t *rtloader, rtloader_pyobject_t *check)
     AS_TYPE(RtLoader, rtloader)->cancelCheck(AS_TYPE(RtLoaderPyObject, check));
 }
 
+int interrupt_check(rtloader_t *rtloader, rtloader_pyobject_t *check)
+{
+    return AS_TYPE(RtLoader, rtloader)->interruptCheck(AS_TYPE(RtLoaderPyObject, check)) ? 1 : 0;
+}
+
 char **get_checks_warnings(rtloader_t *rtloader, rtloader_pyobject_t *check)
 {
     return AS_TYPE(RtLoader, rtloader)->getCheckWarnings(AS_TYPE(RtLoaderPyObject, check));
@@ -478,6 +483,11 @@ void set_submit_histogram_bucket_cb(rtloader_t *rtloader, cb_submit_histogram_bu
     AS_TYPE(RtLoader, rtloader)->setSubmitHistogramBucketCb(cb);
 }
 
+void set_submit_exponential_histogram_cb(rtloader_t *rtloader, cb_submit_exponential_histogram_t cb)
+{
+    AS_TYPE(RtLoader, rtloader)->setSubmitExponentialHistogramCb(cb);
+}
+
 void set_submit_event_platform_event_cb(rtloader_t *rtloader, cb_submit_event_platform_event_t cb)
 {
     AS_TYPE(RtLoader, rtloader)->setSubmitEventPlatformEventCb(cb);
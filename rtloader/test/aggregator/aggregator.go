@@ -25,6 +25,7 @@ extern void submitMetric(char *, metric_type_t, char *, double, char **, char *,
 extern void submitServiceCheck(char *, char *, int, char **, char *, char *);
 extern void submitEvent(char*, event_t*);
 extern void submitHistogramBucket(char *, char *, long long, float, float, int, char *, char **, bool);
+extern void submitExponentialHistogram(char *, char *, exponential_histogram_t *, int, char *, char **, bool);
 extern void submitEventPlatformEvent(char *, char *, int, char *);
 
 static void initAggregatorTests(rtloader_t *rtloader) {
@@ -32,6 +33,7 @@ static void initAggregatorTests(rtloader_t *rtloader) {
    set_submit_service_check_cb(rtloader, submitServiceCheck);
    set_submit_event_cb(rtloader, submitEvent);
    set_submit_histogram_bucket_cb(rtloader, submitHistogramBucket);
+   set_submit_exponential_histogram_cb(rtloader, submitExponentialHistogram);
    set_submit_event_platform_event_cb(rtloader, submitEventPlatformEvent);
 }
 */
@@ -56,6 +58,12 @@ var (
 	lowerBound      float64
 	upperBound      float64
 	monotonic       bool
+	scale           int
+	zeroCount       uint64
+	positiveOffset  int
+	positiveCounts  []uint64
+	negativeOffset  int
+	negativeCounts  []uint64
 )
 
 type event struct {
@@ -86,6 +94,12 @@ func resetOuputValues() {
 	lowerBound = 1.0
 	upperBound = 1.0
 	monotonic = false
+	scale = 0
+	zeroCount = 0
+	positiveOffset = 0
+	positiveCounts = nil
+	negativeOffset = 0
+	negativeCounts = nil
 }
 
 func setUp() error {
@@ -235,6 +249,29 @@ func submitHistogramBucket(id *C.char, cMetricName *C.char, cVal C.longlong, cLo
 	flushFirstValue = bool(fFirstValue)
 }
 
+//export submitExponentialHistogram
+func submitExponentialHistogram(id *C.char, cMetricName *C.char, h *C.exponential_histogram_t, cMonotonic C.int, cHostname *C.char, t **C.char, fFirstValue C.bool) {
+	checkID = C.GoString(id)
+	name = C.GoString(cMetricName)
+	scale = int(h.scale)
+	value = float64(h.sum)
+	zeroCount = uint64(h.zero_count)
+	positiveOffset = int(h.positive_offset)
+	for _, c := range unsafe.Slice(h.positive_counts, h.positive_len) {
+		positiveCounts = append(positiveCounts, uint64(c))
+	}
+	negativeOffset = int(h.negative_offset)
+	for _, c := range unsafe.Slice(h.negative_counts, h.negative_len) {
+		negativeCounts = append(negativeCounts, uint64(c))
+	}
+	monotonic = (cMonotonic != 0)
+	hostname = C.GoString(cHostname)
+	if t != nil {
+		tags = append(tags, charArrayToSlice(t)...)
+	}
+	flushFirstValue = bool(fFirstValue)
+}
+
 //export submitEventPlatformEvent
 func submitEventPlatformEvent(id *C.char, _rawEventPtr *C.char, _rawEventSize C.int, _eventType *C.char) {
 	checkID = C.GoString(id)
@@ -456,6 +456,62 @@ func TestSubmitHistogramBucket(t *testing.T) {
 	helpers.AssertMemoryUsage(t)
 }
 
+func TestSubmitExponentialHistogram(t *testing.T) {
+	// Reset memory counters
+	helpers.ResetMemoryStats()
+
+	out, err := run(`aggregator.submit_exponential_histogram(None, 'id', 'name', 3, 21.5, 2, -1, [1, 0, 4], 5, [], 1, 'myhost', ['foo', 21, 'bar', ["hey"]], True)`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if out != "" {
+		t.Errorf("Unexpected printed value: '%s'", out)
+	}
+	if checkID != "id" {
+		t.Fatalf("Unexpected id value: %s", checkID)
+	}
+	if name != "name" {
+		t.Fatalf("Unexpected name value: %s", name)
+	}
+	if scale != 3 {
+		t.Fatalf("Unexpected scale value: %d", scale)
+	}
+	if value != 21.5 {
+		t.Fatalf("Unexpected sum value: %f", value)
+	}
+	if zeroCount != 2 {
+		t.Fatalf("Unexpected zero count value: %d", zeroCount)
+	}
+	if positiveOffset != -1 {
+		t.Fatalf("Unexpected positive offset value: %d", positiveOffset)
+	}
+	if len(positiveCounts) != 3 || positiveCounts[0] != 1 || positiveCounts[1] != 0 || positiveCounts[2] != 4 {
+		t.Fatalf("Unexpected positive counts: %v", positiveCounts)
+	}
+	if negativeOffset != 5 {
+		t.Fatalf("Unexpected negative offset value: %d", negativeOffset)
+	}
+	if len(negativeCounts) != 0 {
+		t.Fatalf("Unexpected negative counts: %v", negativeCounts)
+	}
+	if monotonic != true {
+		t.Fatalf("Unexpected monotonic value: %v", monotonic)
+	}
+	if hostname != "myhost" {
+		t.Fatalf("Unexpected hostname value: %s", hostname)
+	}
+	if len(tags) != 2 || tags[0] != "foo" || tags[1] != "bar" {
+		t.Fatalf("Unexpected tags: %v", tags)
+	}
+	if flushFirstValue != true {
+		t.Fatalf("Unexpected flushFirstValue value: %v", flushFirstValue)
+	}
+
+	// Check for leaks
+	helpers.AssertMemoryUsage(t)
+}
+
 func TestSubmitEventPlatformEvent(t *testing.T) {
 	// Reset memory counters
 	helpers.ResetMemoryStats()
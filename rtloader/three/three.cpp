@@ -445,7 +445,10 @@ char *Three::runCheck(RtLoaderPyObject *check)
     char run[] = "run";
     PyObject *result = NULL;
 
+    // remember the thread running the check, so that it can be interrupted
+    _runningChecks[py_check] = PyThread_get_thread_ident();
     result = PyObject_CallMethod(py_check, run, NULL);
+    _runningChecks.erase(py_check);
     if (result == NULL || !PyUnicode_Check(result)) {
         setError("error invoking 'run' method: " + _fetchPythonError());
         goto done;
@@ -482,6 +485,21 @@ void Three::cancelCheck(RtLoaderPyObject *check)
     Py_XDECREF(result);
 }
 
+bool Three::interruptCheck(RtLoaderPyObject *check)
+{
+    if (check == NULL) {
+        return false;
+    }
+
+    std::map<PyObject *, unsigned long>::iterator it = _runningChecks.find(reinterpret_cast<PyObject *>(check));
+    if (it == _runningChecks.end()) {
+        return false;
+    }
+
+    // KeyboardInterrupt isn't caught by the `except Exception` clauses of the checks
+    return PyThreadState_SetAsyncExc(it->second, PyExc_KeyboardInterrupt) == 1;
+}
+
 char **Three::getCheckWarnings(RtLoaderPyObject *check)
 {
     if (check == NULL) {
@@ -853,6 +871,11 @@ void Three::setSubmitHistogramBucketCb(cb_submit_histogram_bucket_t cb)
     _set_submit_histogram_bucket_cb(cb);
 }
 
+void Three::setSubmitExponentialHistogramCb(cb_submit_exponential_histogram_t cb)
+{
+    _set_submit_exponential_histogram_cb(cb);
+}
+
 void Three::setSubmitEventPlatformEventCb(cb_submit_event_platform_event_t cb)
 {
     _set_submit_event_platform_event_cb(cb);
@@ -70,6 +70,7 @@ public:
 
     char *runCheck(RtLoaderPyObject *check);
     void cancelCheck(RtLoaderPyObject *check);
+    bool interruptCheck(RtLoaderPyObject *check);
     char **getCheckWarnings(RtLoaderPyObject *check);
     void decref(RtLoaderPyObject *obj);
     void incref(RtLoaderPyObject *obj);
@@ -93,6 +94,7 @@ public:
     void setSubmitServiceCheckCb(cb_submit_service_check_t);
     void setSubmitEventCb(cb_submit_event_t);
     void setSubmitHistogramBucketCb(cb_submit_histogram_bucket_t);
+    void setSubmitExponentialHistogramCb(cb_submit_exponential_histogram_t);
     void setSubmitEventPlatformEventCb(cb_submit_event_platform_event_t);
 
     // datadog_agent API
@@ -188,6 +190,7 @@ private:
     wchar_t *_pythonExe; /*!< unicode string with the path to the executable of the underlying interpreter */
     PyObject *_baseClass; /*!< PyObject * pointer to the base Agent check class */
     PyPaths _pythonPaths; /*!< string vector containing paths in the PYTHONPATH */
+    std::map<PyObject *, unsigned long> _runningChecks; /*!< identifiers of the threads running the checks, protected by the GIL */
     PyThreadState *_threadState; /*!< PyThreadState * pointer to the saved Python interpreter thread state */
 
     //! pymallocAlloc member.
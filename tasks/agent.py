@@ -56,6 +56,7 @@ AGENT_CORECHECKS = [
     "io",
     "jmx",
     "kubernetes_apiserver",
+    "linux_kernel",
     "load",
     "memory",
     "ntp",